@@ -8,6 +8,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "put" {
+		if err := controller.Put(os.Args[2:], os.Stdin, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, "s3duck-tui put:", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if err := controller.NewController().Run(); err != nil {
 		// Run returns only once the tview loop has stopped, so the terminal is
 		// ours again and writing to stderr can't corrupt the display.
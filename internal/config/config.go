@@ -41,6 +41,10 @@ type Config struct {
 	// MaxBytesPerSec caps transfer throughput (upload + download) for this
 	// profile. 0 (the default) means unlimited.
 	MaxBytesPerSec int64 `json:"max_bytes_per_sec,omitempty"`
+	// ListCacheSecs is how long a folder listing is reused when the location
+	// is revisited (it is re-listed in the background either way). 0 (the
+	// default) means 300; a negative value turns the cache off.
+	ListCacheSecs int `json:"list_cache_secs,omitempty"`
 	// Bookmarks are saved bucket+prefix locations for this profile.
 	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
 }
@@ -52,6 +56,11 @@ type Bookmark struct {
 	Prefix string `json:"prefix"`
 }
 
+// IndexDir is where local search indexes are kept, beside the config file.
+func (p *Params) IndexDir() string {
+	return filepath.Join(filepath.Dir(p.FileName), "index")
+}
+
 func (p *Params) WriteConfig() error {
 	// If the last load failed, the in-memory list does NOT reflect what the
 	// file held — saving would overwrite every previously stored profile with
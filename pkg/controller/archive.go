@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// archiveEntry is one row of an archive listing: a member, or a directory
+// only implied by member names — archives often list a file without its
+// directories.
+type archiveEntry struct {
+	Name     string // relative to the listed directory; a directory's ends in "/"
+	Size     int64  // a directory's: of the files under it
+	Files    int    // a directory's: files under it
+	Modified time.Time
+	Link     string
+	Regular  bool
+}
+
+// archiveEntries lists what is directly in dir ("" for the top, otherwise
+// ending in "/") of an archive: directories first, then the rest, each by
+// name.
+func archiveEntries(members []model.ArchiveMember, dir string) []archiveEntry {
+	var out []archiveEntry
+	dirs := make(map[string]int)
+	for _, m := range members {
+		rest, ok := strings.CutPrefix(m.Name, dir)
+		if !ok || rest == "" {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			name := rest[:i+1]
+			j, ok := dirs[name]
+			if !ok {
+				j = len(out)
+				dirs[name] = j
+				out = append(out, archiveEntry{Name: name})
+			}
+			if rest == name { // the directory's own entry
+				out[j].Modified = m.Modified
+			} else if !m.IsDir() {
+				out[j].Size += m.Size
+				out[j].Files++
+			}
+			continue
+		}
+		out = append(out, archiveEntry{Name: rest, Size: m.Size, Modified: m.Modified, Link: m.Link, Regular: m.Mode.IsRegular()})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		di, dj := strings.HasSuffix(out[i].Name, "/"), strings.HasSuffix(out[j].Name, "/")
+		if di != dj {
+			return di
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// archiveRow renders an entry for the archive list.
+func archiveRow(e archiveEntry) string {
+	when := ""
+	if !e.Modified.IsZero() {
+		when = e.Modified.Format("2006-01-02 15:04")
+	}
+	switch {
+	case strings.HasSuffix(e.Name, "/"):
+		return fmt.Sprintf("[::b]%-40s[::-] %10s  %5d file(s)  %s", tview.Escape(e.Name), humanize.IBytes(uint64(e.Size)), e.Files, when)
+	case e.Link != "":
+		return fmt.Sprintf("%-40s %10s  %s", tview.Escape(e.Name), "", tview.Escape("→ "+e.Link))
+	}
+	return fmt.Sprintf("%-40s %10s  %16s", tview.Escape(e.Name), humanize.IBytes(uint64(e.Size)), when)
+}
+
+// archiveTargets is what extracting name in dir downloads: the member itself,
+// or for a directory every directory and regular file under it. Links and
+// devices have no content to extract.
+func archiveTargets(a *model.Archive, dir, name string) ([]model.DownloadTarget, int64) {
+	var out []model.DownloadTarget
+	var total int64
+	for _, m := range a.Under(dir + name) {
+		if !strings.HasSuffix(name, "/") && m.Name != dir+name {
+			continue
+		}
+		if !m.IsDir() && !m.Mode.IsRegular() {
+			continue
+		}
+		m := m
+		out = append(out, model.DownloadTarget{Key: m.Name, Size: m.Size, Archive: a, Member: &m})
+		total += m.Size
+	}
+	return out, total
+}
+
+// openedArchive is the archive listing last shown, kept so that coming back
+// to it after an extraction doesn't read a tar through again.
+type openedArchive struct {
+	mdl *model.Model
+	a   *model.Archive
+	dir string
+}
+
+// OpenArchive opens the zip or tar obj as a virtual listing. A zip costs a
+// couple of ranged GETs whatever its size; a tar is streamed through once,
+// behind a progress modal, since nothing but a full read says what is in it.
+func (c *Controller) OpenArchive(obj *model.Object) {
+	if c.currentBucket == nil || obj.FullPath == nil || obj.Size == nil {
+		return
+	}
+	bucket, key, size := c.currentBucket, *obj.FullPath, *obj.Size
+	mdl := c.model
+	if o := c.archive; o != nil && o.mdl == mdl && *o.a.Bucket.Key == *bucket.Key && o.a.Key == key && o.a.Size == size {
+		c.presentArchive(mdl, o.a, o.dir)
+		return
+	}
+
+	text := fmt.Sprintf("Reading %s...", *obj.Key)
+	modal, ctx, cancel := c.scanModal("progress", text)
+	var read atomic.Int64
+	var queued atomic.Bool
+	progress := func(n int64) {
+		read.Store(n)
+		if !queued.CompareAndSwap(false, true) {
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			queued.Store(false)
+			modal.SetText(fmt.Sprintf("%s\n\n%s of %s read", text,
+				humanize.IBytes(uint64(read.Load())), humanize.IBytes(uint64(size))))
+		})
+	}
+
+	go func() {
+		defer cancel()
+		a, err := mdl.OpenArchive(ctx, bucket, key, size, progress)
+		if ctx.Err() != nil {
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
+		if err != nil {
+			c.error("Failed to open the archive", err)
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() { c.presentArchive(mdl, a, "") })
+	}()
+}
+
+// presentArchive shows the members of a as a browsable list, opened at dir.
+// Runs on the UI goroutine.
+func (c *Controller) presentArchive(mdl *model.Model, a *model.Archive, dir string) {
+	c.archive = &openedArchive{mdl: mdl, a: a, dir: dir}
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true)
+	list.SetSelectedBackgroundColor(tcell.ColorBlue)
+	list.SetSelectedTextColor(tcell.ColorWhite)
+
+	help := tview.NewTextView().SetDynamicColors(true).SetText(
+		"  [::b]Enter[::-] open folder   [::b]Backspace[::-] up   " +
+			"[::b]Ctrl+D[::-] extract to the download dir   [::b]Esc[::-] close")
+
+	what := a.Kind
+	if a.Compression != "" {
+		what += "." + a.Compression
+	}
+	var entries []archiveEntry
+	show := func(d, want string) {
+		dir = d
+		c.archive.dir = d
+		entries = archiveEntries(a.Members, dir)
+		list.Clear()
+		list.SetTitle(fmt.Sprintf(" %s (%s, %d members) : /%s ", path.Base(a.Key), what, len(a.Members), dir))
+		list.AddItem("[..]", "", 0, nil)
+		for i, e := range entries {
+			list.AddItem(archiveRow(e), "", 0, nil)
+			if e.Name == want {
+				list.SetCurrentItem(i + 1)
+			}
+		}
+	}
+	closeList := func() {
+		c.view.Pages.RemovePage("modal-archive")
+		c.view.App.SetFocus(c.view.List)
+	}
+	up := func() {
+		if dir == "" {
+			closeList()
+			return
+		}
+		parent := path.Dir(strings.TrimSuffix(dir, "/")) + "/"
+		if parent == "./" {
+			parent = ""
+		}
+		show(parent, strings.TrimPrefix(dir, parent))
+	}
+	selected := func() (archiveEntry, bool) {
+		i := list.GetCurrentItem() - 1
+		if i < 0 || i >= len(entries) {
+			return archiveEntry{}, false
+		}
+		return entries[i], true
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			closeList()
+			return nil
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			up()
+			return nil
+		case tcell.KeyEnter:
+			e, ok := selected()
+			switch {
+			case !ok:
+				up()
+			case strings.HasSuffix(e.Name, "/"):
+				show(dir+e.Name, "")
+			}
+			return nil
+		case tcell.KeyCtrlD:
+			if e, ok := selected(); ok {
+				closeList()
+				c.extractArchive(mdl, a, dir, e)
+			}
+			return nil
+		}
+		return event
+	})
+
+	show(dir, "")
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	c.view.Pages.AddPage("modal-archive", c.view.ModalEdit(flex, 100, 30), true, true)
+	c.view.App.SetFocus(list)
+}
+
+// extractArchive extracts entry e of dir through the download machinery —
+// the overwrite prompts, the transfers panel and retry — into the download
+// directory, laid out as it is below dir. The listing is closed first, as the
+// download's dialogs return to the main page; Enter on the archive brings it
+// back where it was.
+func (c *Controller) extractArchive(mdl *model.Model, a *model.Archive, dir string, e archiveEntry) {
+	if !strings.HasSuffix(e.Name, "/") && !e.Regular {
+		go c.error("Extract", fmt.Errorf("%s is not a regular file", e.Name))
+		return
+	}
+	targets, total := archiveTargets(a, dir, e.Name)
+	if len(targets) == 0 {
+		// A directory only implied by its members' names, all of them links.
+		go c.error("Extract", fmt.Errorf("nothing to extract under %s", e.Name))
+		return
+	}
+	c.withDownloadDir(func(cwd string) {
+		c.runDownload(mdl, a.Bucket, dir, 1, targets, total, cwd)
+	})
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+func archiveTestMembers() []model.ArchiveMember {
+	return []model.ArchiveMember{
+		{Name: "README", Size: 10},
+		{Name: "src/", Mode: fs.ModeDir},
+		{Name: "src/main.go", Size: 100},
+		{Name: "src/lib/util.go", Size: 50}, // src/lib/ only implied
+		{Name: "link", Mode: fs.ModeSymlink, Link: "README"},
+		{Name: "docs/a.md", Size: 5},
+	}
+}
+
+func TestArchiveEntries(t *testing.T) {
+	var got []string
+	for _, e := range archiveEntries(archiveTestMembers(), "") {
+		got = append(got, e.Name)
+		if e.Name == "src/" && (e.Files != 2 || e.Size != 150) {
+			t.Errorf("src/ = %d files, %d bytes", e.Files, e.Size)
+		}
+	}
+	if s := strings.Join(got, " "); s != "docs/ src/ README link" {
+		t.Errorf("top = %s", s)
+	}
+
+	got = nil
+	for _, e := range archiveEntries(archiveTestMembers(), "src/") {
+		got = append(got, e.Name)
+	}
+	if s := strings.Join(got, " "); s != "lib/ main.go" {
+		t.Errorf("src/ = %s", s)
+	}
+}
+
+func TestArchiveTargets(t *testing.T) {
+	a := &model.Archive{Members: archiveTestMembers()}
+	for _, tc := range []struct {
+		dir, name string
+		want      string
+		total     int64
+	}{
+		{"", "src/", "src/ src/main.go src/lib/util.go", 150},
+		{"src/", "main.go", "src/main.go", 100},
+		{"", "README", "README", 10},
+		{"", "link", "", 0},
+		{"src/", "lib/", "src/lib/util.go", 50},
+	} {
+		ts, total := archiveTargets(a, tc.dir, tc.name)
+		var keys []string
+		for _, t := range ts {
+			keys = append(keys, t.Key)
+		}
+		if s := strings.Join(keys, " "); s != tc.want || total != tc.total {
+			t.Errorf("archiveTargets(%q, %q) = %s (%d), want %s (%d)", tc.dir, tc.name, s, total, tc.want, tc.total)
+		}
+		for _, t2 := range ts {
+			if t2.Archive != a || t2.Member == nil || t2.Member.Name != t2.Key {
+				t.Errorf("%s: target not tied to its member", t2.Key)
+			}
+		}
+	}
+}
+
+func TestArchiveRow(t *testing.T) {
+	row := archiveRow(archiveEntry{Name: "[x].txt", Size: 2048, Regular: true})
+	if !strings.Contains(row, "[x[].txt") || !strings.Contains(row, "2.0 KiB") {
+		t.Errorf("file row = %q", row)
+	}
+	if row := archiveRow(archiveEntry{Name: "l", Link: "target"}); !strings.Contains(row, "→ target") {
+		t.Errorf("link row = %q", row)
+	}
+}
@@ -192,16 +192,51 @@ type Controller struct {
 	// Dual-pane state. The controller's live per-location fields above ARE the
 	// active pane; panes[inactive] snapshots the other pane. active is 0 or 1;
 	// dual is whether both panes are shown; pane1Init guards one-time pane-1
-	// seeding. Both panes share one model/endpoint. See DESIGN.md.
+	// seeding. Each pane carries its own profile — c.model and c.activeConfig
+	// are the active pane's — so the two may be different endpoints. See
+	// DESIGN.md.
 	panes     [2]paneState
 	active    int
 	dual      bool
 	pane1Init bool
 
+	// navSeq numbers each pane's navigations and navWant holds where the
+	// latest one is heading (nil once it lands); a listing is applied only if
+	// its request is still the pane's latest; navStop cancels the listing of
+	// the one in flight when it is superseded. All guarded by mu. nav is the
+	// navigation backend (nil = the model itself; tests script it). See
+	// navigate.go.
+	navSeq  [2]uint64
+	navWant [2]*location
+	navStop [2]context.CancelFunc
+	nav     navBackend
+	// listing is each pane's partly fetched listing, nil when complete (see
+	// paging.go). Guarded by mu, indexed by pane like navSeq.
+	listing [2]*pagedListing
+
 	// activity is a capped, in-session log of operations shown via the palette.
 	activity   []activityEntry
 	activityMu sync.Mutex
 
+	// indexes are the local search indexes loaded this session, by file (see
+	// index.go). indexMu guards the map and serializes searches of an index
+	// with refreshes of it, which run on background goroutines.
+	indexes map[string]*model.LocalIndex
+	indexMu sync.Mutex
+
+	// preview is the preview panel's state (see preview.go). UI goroutine
+	// only.
+	preview previewState
+	// graphics is how the preview draws images, and what it has drawn (see
+	// imagepreview.go). UI goroutine only.
+	graphics termGraphics
+	// archive is the zip or tar listing last browsed (see archive.go). UI
+	// goroutine only.
+	archive *openedArchive
+	// lastPipe is the command objects were last piped through, offered
+	// again by the next pipe (see pipe.go). UI goroutine only.
+	lastPipe string
+
 	// clip is the object clipboard (yank/cut → paste).
 	clip clipboard
 
@@ -235,6 +270,8 @@ type Controller struct {
 // own fields; this holds the inactive pane. (Widgets are fixed per pane in the
 // view and are not part of this snapshot.)
 type paneState struct {
+	model           *model.Model
+	config          *cfg.Config
 	buckets         []*model.Object
 	objs            map[string]*model.Object
 	currentPath     string
@@ -278,6 +315,7 @@ func NewController() *Controller {
 	// writes to a nil map after a swap.
 	c.panes[1].selectedByScope = make(map[string]map[string]bool)
 	c.jobSem = make(chan struct{}, 2) // at most 2 concurrent byte transfers
+	c.graphics.proto = graphicsProtocol(os.Getenv)
 	c.wireFilter(v.PaneFilter(0))
 	c.wireFilter(v.PaneFilter(1))
 	return c
@@ -474,29 +512,6 @@ func objKey(o *model.Object) string {
 	return *o.FullPath
 }
 
-func (c *Controller) makeObjectMap() error {
-	var list []*model.Object
-	var err error
-	dirs := make(map[string]*model.Object)
-
-	if c.currentBucket == nil {
-		list, err = c.model.ListBuckets()
-		if err != nil {
-			return err
-		}
-	} else {
-		list, err = c.model.List(c.currentPath, c.currentBucket)
-	}
-	if err != nil {
-		return err
-	}
-	for _, obj := range list {
-		dirs[objKey(obj)] = obj
-	}
-	c.setObjs(dirs)
-	return nil
-}
-
 // localDownloadPath maps an S3 key onto its local destination path. It errors
 // on keys whose cleaned path would escape destPath — see model.SafeLocalPath.
 func localDownloadPath(currentPath, destPath, s3Key string) (string, error) {
@@ -680,12 +695,12 @@ func (c *Controller) Delete() {
 		return
 	}
 
-	scanning := tview.NewModal().SetText("Calculating delete size...")
-	c.view.Pages.AddPage("progress", scanning, true, true)
+	_, ctx, cancel := c.scanModal("progress", "Calculating delete size...")
 
 	bucket := c.currentBucket
 	mdl := c.model
 	go func() {
+		defer cancel()
 		for i := range targets {
 			if !targets[i].isFolder && !targets[i].isBucket {
 				continue
@@ -699,7 +714,10 @@ func (c *Controller) Delete() {
 				b := targets[i].key
 				scanBucket = &model.Object{Key: &b, Ot: model.Bucket}
 			}
-			objs, err := mdl.ListObjects(key, scanBucket)
+			objs, err := mdl.ListObjects(ctx, key, scanBucket)
+			if ctx.Err() != nil {
+				return
+			}
 			if err != nil {
 				targets[i].scanErr = err
 				continue
@@ -726,18 +744,23 @@ func (c *Controller) Delete() {
 	}()
 }
 
-// runDelete executes the confirmed deletes sequentially behind a progress
-// modal, then refreshes the listing and reports any failures.
+// runDelete executes the confirmed deletes sequentially behind a cancellable
+// progress modal, then refreshes the listing and reports any failures. Cancel
+// stops between targets and inside a folder's listing or batch deletes; what
+// was already deleted stays deleted.
 func (c *Controller) runDelete(targets []deleteTarget, bucket *model.Object) {
-	progress := tview.NewModal().SetText("Deleting...")
-	c.view.Pages.AddPage("progress", progress, true, true)
+	progress, ctx, cancel := c.scanModal("progress", "Deleting...")
 
 	mdl := c.model
 	go func() {
+		defer cancel()
 		var failed []string
 		okCount := 0
 
 		for i, t := range targets {
+			if ctx.Err() != nil {
+				break
+			}
 			c.view.App.QueueUpdateDraw(func() {
 				progress.SetText(fmt.Sprintf("Deleting\n%d/%d\n%s", i+1, len(targets), t.key))
 			})
@@ -748,12 +771,15 @@ func (c *Controller) runDelete(targets []deleteTarget, bucket *model.Object) {
 				// only deletes empty buckets — so empty it first.
 				name := t.key
 				b := &model.Object{Key: &name, Ot: model.Bucket}
-				if err = mdl.EmptyBucket(b); err == nil {
-					err = mdl.DeleteBucket(&name)
+				if err = mdl.EmptyBucket(ctx, b); err == nil {
+					err = mdl.DeleteBucket(ctx, &name)
 				}
 			} else {
 				key := t.key
-				err = mdl.Delete(&key, bucket)
+				err = mdl.Delete(ctx, &key, bucket)
+			}
+			if ctx.Err() != nil {
+				break
 			}
 			if err != nil {
 				failed = append(failed, fmt.Sprintf("%s: %v", t.key, err))
@@ -770,6 +796,11 @@ func (c *Controller) runDelete(targets []deleteTarget, bucket *model.Object) {
 			c.mu.Unlock()
 		}
 
+		if ctx.Err() != nil {
+			c.logActivity("Delete canceled: %d ok, %d failed", okCount, len(failed))
+			c.updateList()
+			return
+		}
 		c.logActivity("Delete: %d ok, %d failed", okCount, len(failed))
 
 		c.view.App.QueueUpdateDraw(func() {
@@ -784,6 +815,7 @@ func (c *Controller) runDelete(targets []deleteTarget, bucket *model.Object) {
 			msg += "\n\nPress Done to return."
 
 			progress.SetText(msg)
+			progress.ClearButtons()
 			progress.AddButtons([]string{"Done"})
 			progress.SetDoneFunc(func(_ int, _ string) {
 				c.view.Pages.RemovePage("progress").SwitchToPage("main")
@@ -818,6 +850,17 @@ func (c *Controller) resolveDownloadDir() string {
 	return dir
 }
 
+// withDownloadDir calls proceed with where a download goes: the active
+// profile's download directory if it defines one, otherwise the directory the
+// user picks. UI goroutine.
+func (c *Controller) withDownloadDir(proceed func(cwd string)) {
+	if c.activeConfig != nil && strings.TrimSpace(c.activeConfig.DownloadDir) != "" {
+		proceed(c.resolveDownloadDir())
+	} else {
+		c.chooseDir(c.params.HomeDir, proceed)
+	}
+}
+
 func (c *Controller) Download() {
 	if c.view.List.GetItemCount() == 0 || c.currentBucket == nil {
 		return
@@ -854,25 +897,32 @@ func (c *Controller) Download() {
 	// Folder resolution pages through the whole prefix over the network.
 	// Behind a modal, off the UI goroutine — inline it used to freeze the
 	// entire TUI for the length of the listing, and swallow its errors.
-	resolving := tview.NewModal().SetText("Resolving objects...")
-	c.view.Pages.AddPage("progress", resolving, true, true)
+	_, ctx, cancel := c.scanModal("progress", "Resolving objects...")
 
 	go func() {
+		defer cancel()
 		var allObjects []model.DownloadTarget
 		var totalSize int64
 		for _, val := range sel {
 			key := *val.FullPath
 			objs, size, err := mdl.ResolveDownloadObjects(
+				ctx,
 				key,
 				val.Ot == model.Folder,
 				val.Size,
 				srcBucket,
 			)
+			if ctx.Err() != nil {
+				return
+			}
 			if err != nil {
 				c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
 				c.error("Download: resolving objects failed", err)
 				return
 			}
+			if val.Ot == model.File && val.LastModified != nil && len(objs) == 1 {
+				objs[0].Modified = *val.LastModified
+			}
 			allObjects = append(allObjects, objs...)
 			totalSize += size
 		}
@@ -882,20 +932,38 @@ func (c *Controller) Download() {
 			if len(allObjects) == 0 {
 				return
 			}
-			proceed := func(cwd string) {
-				c.runDownload(mdl, srcBucket, srcPath, len(names), allObjects, totalSize, cwd)
-			}
-			// If the active profile defines a download directory, use it
-			// directly; otherwise let the user pick one.
-			if c.activeConfig != nil && strings.TrimSpace(c.activeConfig.DownloadDir) != "" {
-				proceed(c.resolveDownloadDir())
-			} else {
-				c.chooseDir(c.params.HomeDir, proceed)
-			}
+			c.askDownloadAs(len(allObjects), totalSize, func(format string) {
+				c.withDownloadDir(func(cwd string) {
+					if format == "" {
+						c.runDownload(mdl, srcBucket, srcPath, len(names), allObjects, totalSize, cwd)
+						return
+					}
+					name := packName(names, srcPath, *srcBucket.Key) + "." + format
+					c.runPackDownload(mdl, srcBucket, srcPath, allObjects, totalSize, cwd, name, format)
+				})
+			})
 		})
 	}()
 }
 
+// retryTargets picks the download targets whose keys are listed, keeping the
+// original order, and re-totals their bytes.
+func retryTargets(all []model.DownloadTarget, keys []string) ([]model.DownloadTarget, int64) {
+	want := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		want[k] = true
+	}
+	var out []model.DownloadTarget
+	var total int64
+	for _, t := range all {
+		if want[t.Key] {
+			out = append(out, t)
+			total += t.Size
+		}
+	}
+	return out, total
+}
+
 // runDownload confirms and executes the byte phase for already-resolved
 // objects. Runs on the UI goroutine.
 func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcPath string, selectedCount int, allObjects []model.DownloadTarget, totalSize int64, cwd string) {
@@ -916,6 +984,12 @@ func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcP
 
 			ctx, cancel := context.WithCancel(context.Background())
 			job := c.addJob("download", fmt.Sprintf("%d obj → %s", len(allObjects), cwd), totalSize, len(allObjects), cancel)
+			job.setRetry(func(keys []string) {
+				targets, size := retryTargets(allObjects, keys)
+				if len(targets) > 0 {
+					c.runDownload(mdl, srcBucket, srcPath, len(targets), targets, size, cwd)
+				}
+			})
 			progress := tview.NewModal().
 				SetText("Starting download...\n").
 				AddButtons([]string{"Background", "Cancel"})
@@ -1086,6 +1160,7 @@ func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcP
 					if keyStr == "" {
 						sumMu.Lock()
 						sum.addFailed("<nil-key>", fmt.Errorf("object key is empty"))
+						job.recordItem("<nil-key>", itemFailed, "object key is empty")
 						sumMu.Unlock()
 						continue
 					}
@@ -1103,6 +1178,7 @@ func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcP
 							sum.downloaded++
 						}
 						sumMu.Unlock()
+						job.recordErr(keyStr, pErr)
 						continue
 					}
 
@@ -1111,6 +1187,7 @@ func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcP
 						sumMu.Lock()
 						sum.addFailed(keyStr, pErr)
 						sumMu.Unlock()
+						job.recordErr(keyStr, pErr)
 						continue
 					}
 					overwrite := false
@@ -1123,6 +1200,7 @@ func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcP
 							sumMu.Lock()
 							sum.addSkipped(dst)
 							sumMu.Unlock()
+							job.recordItem(keyStr, itemSkipped, "exists locally: "+dst)
 							continue
 						}
 						if overwriteAll {
@@ -1133,12 +1211,14 @@ func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcP
 								sumMu.Lock()
 								sum.addSkipped(dst)
 								sumMu.Unlock()
+								job.recordItem(keyStr, itemSkipped, "exists locally: "+dst)
 								continue
 							case decSkipAll:
 								skipAll = true
 								sumMu.Lock()
 								sum.addSkipped(dst)
 								sumMu.Unlock()
+								job.recordItem(keyStr, itemSkipped, "exists locally: "+dst)
 								continue
 							case decOverwrite:
 								overwrite = true
@@ -1257,8 +1337,10 @@ func (c *Controller) runDownload(mdl *model.Model, srcBucket *model.Object, srcP
 							sumMu.Lock()
 							sum.addFailed(keyStr, err)
 							sumMu.Unlock()
+							job.recordErr(keyStr, err)
 							return
 						}
+						job.recordErr(keyStr, nil)
 						sumMu.Lock()
 						sum.downloaded++
 						if ri.overwrite {
@@ -1326,16 +1408,25 @@ func (c *Controller) ToggleSelectCurrent() {
 // updateList fetches the current bucket/prefix from S3 (network I/O) and then
 // re-renders the list. Use renderList directly when the object set is unchanged
 // (filtering, selection toggles) to avoid a redundant round-trip.
+//
+// It is a refresh navigation (see navigate.go): the pane and location are
+// captured here, and the result is dropped if that pane has moved on by the
+// time the listing returns.
 func (c *Controller) updateList() error {
 	c.refreshMu.Lock()
 	defer c.refreshMu.Unlock()
 
-	if err := c.makeObjectMap(); err != nil {
-		go c.error("Failed to fetch folder", err)
-		return err
+	c.mu.Lock()
+	req := navRequest{
+		pane:    c.active,
+		seq:     c.navSeq[c.active],
+		mdl:     c.model,
+		target:  location{bucket: c.currentBucket, path: c.currentPath},
+		refresh: true,
+		minObjs: len(c.objs),
 	}
-	c.renderList()
-	return nil
+	c.mu.Unlock()
+	return c.runNav(req)
 }
 
 // renderList repopulates the list widget from the in-memory object map,
@@ -1362,9 +1453,13 @@ func (c *Controller) renderList() {
 			keepCur = strings.TrimSpace(t)
 		}
 		want := keepCur
+		c.mu.Lock()
 		if c.restoreNext != "" {
 			want = c.restoreNext
 		}
+		c.restoreNext = ""
+		inBucket := c.currentBucket != nil
+		c.mu.Unlock()
 
 		// Column widths follow the pane's current width, so a narrow dual-pane
 		// drops columns instead of truncating names into uselessness. The rect
@@ -1389,7 +1484,7 @@ func (c *Controller) renderList() {
 		c.view.SetFrameText(fText)
 
 		offset := 0
-		if c.currentBucket != nil {
+		if inBucket {
 			c.view.List.AddItem("[..]", "..", 0, func() { c.Up() })
 			offset = 1
 		}
@@ -1417,6 +1512,8 @@ func (c *Controller) renderList() {
 							c.bucketPos = c.view.List.GetCurrentItem()
 						}
 						c.Down(cur)
+					} else if val.Ot == model.File && model.ArchiveKind(*val.FullPath) != "" {
+						c.OpenArchive(val)
 					}
 				}
 			})
@@ -1440,7 +1537,6 @@ func (c *Controller) renderList() {
 				c.view.List.SetCurrentItem(target)
 			}
 		}
-		c.restoreNext = ""
 	})
 }
 
@@ -1448,20 +1544,36 @@ func (c *Controller) renderList() {
 // screen (buckets vs objects), including the selection count and active filter.
 func (c *Controller) listChrome() (title, fText string) {
 	var suff string
-	if c.currentBucket == nil {
-		title = "(buckets)"
+	// Read under mu: this runs off the UI goroutine, where navigation lands.
+	c.mu.Lock()
+	tag := paneProfileTag(c.dual, c.activeConfig, c.panes[1-c.active].config)
+	bucket, path, mdl := c.currentBucket, c.currentPath, c.model
+	c.mu.Unlock()
+	if bucket == nil {
+		title = tag + "(buckets)"
 	} else {
-		base := fmt.Sprintf("(%s)/%s", *c.currentBucket.Key, c.currentPath)
+		base := fmt.Sprintf("%s(%s)/%s", tag, *bucket.Key, path)
+		if note := inventoryNote(mdl, *bucket.Key); note != "" {
+			base = fmt.Sprintf("%s  [orange]%s[-]", base, note)
+		}
 		if n := c.selectedCount(); n > 0 {
 			base = fmt.Sprintf("%s  [green]Selected: %d", base, n)
 		}
 		title = base
 		suff = "[::b][Ctrl+D[][::-]Download [::b][Ctrl+U[][::-]Upload [::b][Ctrl+F][::-]Search "
 	}
-	if f := c.getFilter(); f != "" {
+	f := c.getFilter()
+	if f != "" {
 		title = fmt.Sprintf("%s  [yellow]filter:%s", title, f)
 	}
-	title = fmt.Sprintf("%s  [blue]%s", title, sortLabel(c.getSort()))
+	sk, sd := c.getSort()
+	title = fmt.Sprintf("%s  [blue]%s", title, sortLabel(sk, sd))
+	if bucket != nil {
+		loaded, more, loading, cached := c.pageStatus()
+		if note := listingNote(loaded, more, loading, cached, sk, sd, f != ""); note != "" {
+			title = fmt.Sprintf("%s  [gray]%s", title, note)
+		}
+	}
 	fText = fmt.Sprintf("[::b][↓,↑][::-]D/U [::b][Ent/Bck][::-]L/U %s[::b][/][::-]Filter [::b][Del[][::-]Delete [::b][Ctrl+N][::-]Create [::b][Ctrl+P][::-]Profiles [::b][Ctrl+L][::-]Properties [::b][Ctrl+H][::-]Hotkeys [::b][Ctrl+Q][::-]Quit", suff)
 	return title, fText
 }
@@ -1518,19 +1630,22 @@ func (c *Controller) Refresh() {
 	go c.updateList()
 }
 
-// filterSortObjects returns the objects whose display name (o.Key) contains
-// filter (case-insensitive), ordered by the given sort key. Folders/buckets
-// always come before files regardless of the key or direction, so the listing
-// keeps its navigable shape. An empty filter returns every object. The input
-// slice is not mutated.
+// filterSortObjects returns the objects matching filter, ordered by the given
+// sort key. filter is a search query (see searchQuery) evaluated against the
+// display name (o.Key), so "size>1G ext:csv" works here as in Ctrl+F; while it
+// doesn't parse yet — a half-typed "re:(" — it is a plain case-insensitive
+// substring. Folders/buckets always come before files regardless of the key
+// or direction, so the listing keeps its navigable shape. An empty filter
+// returns every object. The input slice is not mutated.
 func filterSortObjects(objs []*model.Object, filter string, key sortKey, desc bool) []*model.Object {
 	f := strings.ToLower(strings.TrimSpace(filter))
+	q, err := parseQuery(filter, time.Now())
 	out := make([]*model.Object, 0, len(objs))
 	for _, o := range objs {
 		if o == nil || o.Key == nil {
 			continue
 		}
-		if f != "" && !strings.Contains(strings.ToLower(*o.Key), f) {
+		if err != nil && !strings.Contains(strings.ToLower(*o.Key), f) || err == nil && !q.match(targetOfEntry(o)) {
 			continue
 		}
 		out = append(out, o)
@@ -1585,80 +1700,47 @@ func lessBySortKey(a, b *model.Object, key sortKey) (less, tie bool) {
 	}
 }
 
-// findBucketByName resolves a bucket for navigation. A listing failure is
-// reported rather than swallowed — silently returning nil made a transient
-// error look like navigation being dead. Both callers run on background
-// goroutines, so c.error is called directly.
-func (c *Controller) findBucketByName(name string) *model.Object {
-	list, err := c.model.ListBuckets()
-	if err != nil {
-		c.error("Failed to list buckets", err)
-		return nil
-	}
-	c.buckets = list
-	for _, v := range c.buckets {
-		if name == *v.Key {
-			return v
-		}
-	}
-	return nil
-}
-
 // Down enters the bucket or folder identified by name (a unique objKey: the
 // bucket name when on the buckets screen, otherwise the full folder prefix).
 func (c *Controller) Down(name string) {
-	c.view.Details.Clear()
-	c.clearFilterUI() // a filter is scoped to one listing; reset on navigation
+	// name comes from the listing on screen, so it is read against the
+	// location that listing shows — not one a pending navigation is heading to.
+	base := c.shownLocation()
 	c.recordHistory()
 
-	if c.currentBucket == nil {
-		// Entering a bucket triggers network calls (ListBuckets +
-		// GetBucketLocation); run them off the UI goroutine so the TUI
-		// doesn't freeze on a slow or unreachable endpoint.
-		go func() {
-			bucket := c.findBucketByName(name)
-			if bucket == nil {
-				return
-			}
-			c.currentBucket = bucket
-			if err := c.model.RefreshClient(&name); err != nil {
-				c.error("Failed to resolve bucket region", err)
-			}
-			c.restoreNext = ".."
-			c.updateList()
-		}()
+	if base.bucket == nil {
+		// Entering a bucket resolves it by name and fixes up the client's
+		// region — network calls, made by the navigation goroutine.
+		c.navigate(navRequest{bucketName: name, enter: true, restore: ".."})
 		return
 	}
-
 	// name is the full folder prefix (already ends with "/").
-	c.currentPath = name
-	go c.updateList()
+	c.navigate(navRequest{target: location{bucket: base.bucket, path: name}})
 }
 
 func (c *Controller) Up() {
-	c.view.Details.Clear()
-	c.clearFilterUI() // a filter is scoped to one listing; reset on navigation
+	base := c.navBase()
 	c.recordHistory()
 
-	if c.currentPath == "" {
+	if base.path == "" {
 		// Leaving a bucket: restore the cursor onto it in the buckets list.
-		if c.currentBucket != nil {
-			c.restoreNext = *c.currentBucket.Key
+		restore := ""
+		if base.bucket != nil {
+			restore = *base.bucket.Key
 		}
-		c.currentBucket = nil
-		go c.updateList()
+		c.navigate(navRequest{restore: restore})
 		return
 	}
 
 	// Restore the cursor onto the folder we're leaving. Its unique objKey is
-	// the full prefix, which equals the current path.
-	c.restoreNext = c.currentPath
-
-	// parentPrefix trims exactly one trailing segment. Splitting on "/" and
-	// re-joining would collapse empty segments, so leaving a folder with an
-	// empty-named component ("a//") would jump two levels instead of one.
-	c.currentPath = parentPrefix(c.currentPath)
-	go c.updateList()
+	// the full prefix, which equals the current path. parentPrefix trims
+	// exactly one trailing segment: splitting on "/" and re-joining would
+	// collapse empty segments, so leaving a folder with an empty-named
+	// component ("a//") would jump two levels instead of one.
+	c.navigate(navRequest{
+		target:  location{bucket: base.bucket, path: parentPrefix(base.path)},
+		restore: base.path,
+	})
 }
 
 func (c *Controller) Stop() {
@@ -1675,6 +1757,35 @@ func parseMaxBps(s string) int64 {
 	return n
 }
 
+// defaultListCacheTTL is how long listings are reused when a profile does not
+// say otherwise.
+const defaultListCacheTTL = 5 * time.Minute
+
+// parseListCacheSecs reads the profile form's "list cache secs" field. Blank
+// or invalid input means the default (0); a negative number turns the cache
+// off.
+func parseListCacheSecs(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	if n < 0 {
+		return -1
+	}
+	return n
+}
+
+// listCacheTTL is the listing-cache TTL a profile asks for (0 = off).
+func listCacheTTL(p *cfg.Config) time.Duration {
+	switch {
+	case p == nil || p.ListCacheSecs == 0:
+		return defaultListCacheTTL
+	case p.ListCacheSecs < 0:
+		return 0
+	}
+	return time.Duration(p.ListCacheSecs) * time.Second
+}
+
 func (c *Controller) CreateConfigEntry() {
 	cForm := c.view.NewCreateProfileForm("Create config entry")
 	cForm.AddButton("Save", func() {
@@ -1689,6 +1800,7 @@ func (c *Controller) CreateConfigEntry() {
 		downloadDir := cForm.GetFormItem(6).(*tview.InputField).GetText()
 		ignoreSsl := cForm.GetFormItem(7).(*tview.Checkbox).IsChecked()
 		maxBps := parseMaxBps(cForm.GetFormItem(8).(*tview.InputField).GetText())
+		cacheSecs := parseListCacheSecs(cForm.GetFormItem(9).(*tview.InputField).GetText())
 
 		if reg != "" {
 			region = &reg
@@ -1703,6 +1815,7 @@ func (c *Controller) CreateConfigEntry() {
 			IgnoreSsl:      ignoreSsl,
 			DownloadDir:    strings.TrimSpace(downloadDir),
 			MaxBytesPerSec: maxBps,
+			ListCacheSecs:  cacheSecs,
 		}
 		err := c.params.NewConfiguration(&conf)
 
@@ -1718,7 +1831,7 @@ func (c *Controller) CreateConfigEntry() {
 		c.view.Pages.RemovePage("modal")
 	})
 
-	c.view.Pages.AddPage("modal", c.view.ModalEdit(cForm, 75, 23), true, true)
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(cForm, 75, 25), true, true)
 }
 
 func (c *Controller) EditConfigEntry() {
@@ -1743,6 +1856,9 @@ func (c *Controller) EditConfigEntry() {
 	if entry.MaxBytesPerSec > 0 {
 		cForm.GetFormItem(8).(*tview.InputField).SetText(strconv.FormatInt(entry.MaxBytesPerSec, 10))
 	}
+	if entry.ListCacheSecs != 0 {
+		cForm.GetFormItem(9).(*tview.InputField).SetText(strconv.Itoa(entry.ListCacheSecs))
+	}
 
 	cForm.AddButton("Save", func() {
 		name := cForm.GetFormItem(0).(*tview.InputField).GetText()
@@ -1767,6 +1883,7 @@ func (c *Controller) EditConfigEntry() {
 		entry.IgnoreSsl = ignoreSsl
 		entry.DownloadDir = strings.TrimSpace(downloadDir)
 		entry.MaxBytesPerSec = parseMaxBps(cForm.GetFormItem(8).(*tview.InputField).GetText())
+		entry.ListCacheSecs = parseListCacheSecs(cForm.GetFormItem(9).(*tview.InputField).GetText())
 
 		err := c.params.WriteConfig()
 		c.view.Pages.RemovePage("modal")
@@ -1780,7 +1897,7 @@ func (c *Controller) EditConfigEntry() {
 		c.view.Pages.RemovePage("modal")
 	})
 
-	c.view.Pages.AddPage("modal", c.view.ModalEdit(cForm, 75, 23), true, true)
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(cForm, 75, 25), true, true)
 }
 
 func (c *Controller) CopyProfile() {
@@ -1966,7 +2083,7 @@ func (c *Controller) create(isBucket bool) {
 		}
 
 		c.view.Pages.RemovePage("modal")
-		c.restoreNext = restore
+		c.setRestore(restore)
 		go c.updateList()
 	})
 
@@ -2006,7 +2123,7 @@ func (c *Controller) CheckProfile() {
 	// ListBuckets is a network round-trip; run it off the UI goroutine so a
 	// slow or unreachable endpoint can't freeze the TUI.
 	go func() {
-		if _, err := probe.ListBuckets(); err != nil {
+		if _, err := probe.ListBuckets(context.Background()); err != nil {
 			c.error(fmt.Sprintf("error checking profile %s", cf.Name), err)
 		} else {
 			c.success(fmt.Sprintf("successfully checked profile %s", cf.Name))
@@ -2170,11 +2287,12 @@ func (c *Controller) renameSingle() {
 						return
 					}
 					c.setUndo(&undoOp{
+						mdl:   mdl,
 						items: invertOps([]transferPair{{srcBucket: bucket, dstBucket: bucket, srcKey: srcKey, dstKey: dstKey, isFolder: isFolder}}),
 						desc:  "rename " + short,
 					})
 					c.logActivity("renamed %s → %s", short, newName)
-					c.restoreNext = dstKey // unique objKey of the renamed item
+					c.setRestore(dstKey) // unique objKey of the renamed item
 					c.updateList()
 					c.success("Renamed")
 				}()
@@ -2399,7 +2517,7 @@ func (c *Controller) runBatchRename(ops []renameOp, skip map[string]bool) {
 		}
 
 		if len(moved) > 0 {
-			c.setUndo(&undoOp{items: invertOps(moved), desc: fmt.Sprintf("batch rename of %d item(s)", len(moved))})
+			c.setUndo(&undoOp{mdl: mdl, items: invertOps(moved), desc: fmt.Sprintf("batch rename of %d item(s)", len(moved))})
 			c.logActivity("batch renamed %d item(s)", len(moved))
 		}
 
@@ -2445,11 +2563,15 @@ type copyMoveItem struct {
 
 // clipboard holds objects yanked (copy) or cut (move) for a later paste.
 type clipboard struct {
-	op     string // "copy" or "cut"; "" = empty
-	bucket *model.Object
-	prefix string // path the items were yanked from (same-destination guard)
-	scope  string // selection-scope key at yank time (marks cleared after a cut-paste)
-	items  []copyMoveItem
+	op string // "copy" or "cut"; "" = empty
+	// model and profile are the client and profile name the items were
+	// yanked through: the pane pasted into may be on another endpoint.
+	model   *model.Model
+	profile string
+	bucket  *model.Object
+	prefix  string // path the items were yanked from (same-destination guard)
+	scope   string // selection-scope key at yank time (marks cleared after a cut-paste)
+	items   []copyMoveItem
 }
 
 // transferPair is one src→dst object move (used for undo bookkeeping).
@@ -2461,6 +2583,9 @@ type transferPair struct {
 
 // undoOp is a one-step undo: reverse moves (already inverted) plus a label.
 type undoOp struct {
+	// mdl is the client the moves ran through, so an undo issued from the
+	// other pane (possibly another profile) still reverses them in place.
+	mdl   *model.Model
 	items []transferPair
 	desc  string
 }
@@ -2520,16 +2645,29 @@ func (c *Controller) copyOrMove(isMove bool) {
 		if !ok || (o.Ot != model.File && o.Ot != model.Folder) {
 			continue
 		}
-		items = append(items, copyMoveItem{
+		it := copyMoveItem{
 			shortName: *o.Key,
 			srcKey:    *o.FullPath,
 			isFolder:  o.Ot == model.Folder,
-		})
+		}
+		if o.Size != nil {
+			it.size = *o.Size
+		}
+		items = append(items, it)
 	}
 	if len(items) == 0 {
 		return
 	}
 
+	// The other pane on another endpoint can only be reached by streaming, so
+	// copy/move there goes through the cross-profile transfer instead.
+	if c.dual {
+		if other := c.panes[1-c.active]; other.currentBucket != nil && !model.SameEndpoint(c.model, other.model) {
+			c.copyToOtherPane(isMove, title, items)
+			return
+		}
+	}
+
 	srcBucketName := *c.currentBucket.Key
 	srcPrefix := c.currentPath
 
@@ -2548,9 +2686,10 @@ func (c *Controller) copyOrMove(isMove bool) {
 	// List buckets so the destination dropdown offers cross-bucket targets. Off
 	// the UI goroutine (network); if it fails, fall back to the current bucket
 	// only so same-bucket copy/move keeps working.
+	mdl := c.model
 	go func() {
 		bucketNames := []string{srcBucketName}
-		if list, err := c.model.ListBuckets(); err == nil {
+		if list, err := mdl.ListBuckets(context.Background()); err == nil {
 			bucketNames = bucketNames[:0]
 			seen := false
 			for _, b := range list {
@@ -2566,11 +2705,25 @@ func (c *Controller) copyOrMove(isMove bool) {
 				bucketNames = append([]string{srcBucketName}, bucketNames...)
 			}
 		}
+		// On AWS a bucket in another region is still a valid target (the
+		// model copies through a client for that region), so say where each
+		// one lives.
+		labels := bucketPickerLabels(bucketNames, c.model.BucketRegions(bucketNames))
 
 		c.view.App.QueueUpdateDraw(func() {
-			form := c.view.NewCopyMoveForm(title, bucketNames, dstBucketName, dstPrefix)
+			initial := dstBucketName
+			for i, n := range bucketNames {
+				if n == dstBucketName {
+					initial = labels[i]
+				}
+			}
+			form := c.view.NewCopyMoveForm(title, labels, initial, dstPrefix)
 			form.AddButton(title, func() {
-				_, dstBucketName := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+				idx, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+				if idx < 0 || idx >= len(bucketNames) {
+					return
+				}
+				dstBucketName := bucketNames[idx]
 				dstPrefix := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
 				c.view.Pages.RemovePage("modal")
 
@@ -2603,6 +2756,19 @@ func (c *Controller) copyOrMove(isMove bool) {
 	}()
 }
 
+// bucketPickerLabels labels each bucket with its region for the destination
+// dropdown; buckets with no known region keep their bare name.
+func bucketPickerLabels(names []string, regions map[string]string) []string {
+	labels := make([]string, len(names))
+	for i, n := range names {
+		labels[i] = n
+		if r := regions[n]; r != "" {
+			labels[i] = fmt.Sprintf("%s  (%s)", n, r)
+		}
+	}
+	return labels
+}
+
 // runCopyOrMove executes the resolved copy/move against dstBucket, showing a
 // cancellable progress modal with per-item object rate + ETA.
 func (c *Controller) runCopyOrMove(isMove bool, title string, items []copyMoveItem, srcBucket, dstBucket *model.Object, dstBucketName, dstPrefix, srcScope string, afterMove func(), skip map[string]bool) {
@@ -2673,7 +2839,7 @@ func (c *Controller) runCopyOrMove(isMove bool, title string, items []copyMoveIt
 		}
 
 		if isMove && len(moved) > 0 {
-			c.setUndo(&undoOp{items: invertOps(moved), desc: fmt.Sprintf("%s of %d item(s)", title, len(moved))})
+			c.setUndo(&undoOp{mdl: mdl, items: invertOps(moved), desc: fmt.Sprintf("%s of %d item(s)", title, len(moved))})
 		}
 		// A cut clipboard is consumed only when something actually moved: a
 		// fully failed (or same-place) paste keeps the cut so it can be
@@ -2736,7 +2902,11 @@ func clipItems(objs []*model.Object) []copyMoveItem {
 		if o.Ot != model.File && o.Ot != model.Folder {
 			continue
 		}
-		items = append(items, copyMoveItem{shortName: *o.Key, srcKey: *o.FullPath, isFolder: o.Ot == model.Folder})
+		it := copyMoveItem{shortName: *o.Key, srcKey: *o.FullPath, isFolder: o.Ot == model.Folder}
+		if o.Size != nil {
+			it.size = *o.Size
+		}
+		items = append(items, it)
 	}
 	return items
 }
@@ -2765,7 +2935,8 @@ func (c *Controller) yank(op string) {
 	if len(items) == 0 {
 		return
 	}
-	c.clip = clipboard{op: op, bucket: c.currentBucket, prefix: c.currentPath, scope: c.scopeKey(), items: items}
+	c.clip = clipboard{op: op, model: c.model, profile: c.profileName(), bucket: c.currentBucket,
+		prefix: c.currentPath, scope: c.scopeKey(), items: items}
 	verb := "Copied"
 	if op == "cut" {
 		verb = "Cut"
@@ -2780,6 +2951,12 @@ func (c *Controller) paste() {
 		return
 	}
 	dstBucketName := *c.currentBucket.Key
+	if c.clip.model != nil && !model.SameEndpoint(c.clip.model, c.model) {
+		// Yanked in a pane on another endpoint: no server-side copy can reach
+		// it, so the paste streams through this process instead.
+		c.pasteAcrossEndpoints()
+		return
+	}
 	if c.clip.bucket.Key != nil && *c.clip.bucket.Key == dstBucketName &&
 		model.NormalizePrefix(c.clip.prefix) == model.NormalizePrefix(c.currentPath) {
 		go c.error("Paste failed", fmt.Errorf("destination equals source"))
@@ -2823,7 +3000,10 @@ func (c *Controller) Undo() {
 
 // runUndo applies the reverse moves behind a cancellable progress modal.
 func (c *Controller) runUndo(op *undoOp) {
-	mdl := c.model
+	mdl := op.mdl
+	if mdl == nil {
+		mdl = c.model
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	progress := tview.NewModal().
 		SetText("Undoing...\n").
@@ -2978,10 +3158,22 @@ func (c *Controller) showSummaryModalFor(bucket *model.Object, prefix string) {
 	if prefix != "" {
 		scopeLabel = fmt.Sprintf("%s/%s", *bucket.Key, strings.TrimSuffix(prefix, "/"))
 	}
+	mdl := c.model
+	if note := inventoryNote(mdl, *bucket.Key); note != "" {
+		scopeLabel = fmt.Sprintf("%s (%s)", scopeLabel, note)
+	}
 
+	text := fmt.Sprintf("Summarizing %s ...", scopeLabel)
+	modal, ctx, cancel := c.scanModal("progress", text)
+	scanned := c.scanCounter(modal, text)
 	go func() {
-		objects, err := c.model.ListObjects(prefix, bucket)
+		defer cancel()
+		objects, err := mdl.ListObjectsSharded(ctx, prefix, bucket, scanned)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
+			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
 			c.error("Failed to build summary", err)
 			return
 		}
@@ -2989,6 +3181,7 @@ func (c *Controller) showSummaryModalFor(bucket *model.Object, prefix string) {
 		total, catRows, groupRows := buildSummary(objects, prefix)
 
 		c.view.App.QueueUpdateDraw(func() {
+			c.view.Pages.RemovePage("progress")
 			graph := c.view.NewSummaryGraph(" Summary ", scopeLabel, total, catRows, groupRows, func(groupName string) {
 				if groupName == "" || groupName == "(root)" {
 					return
@@ -3129,9 +3322,12 @@ func detectCategory(key string) string {
 // watchResize re-renders the active pane when its width changes, so the column
 // layout follows terminal resizes and dual-pane toggles instead of keeping the
 // widths it was built with. Converges after one extra render: the re-render
-// records the new width, so the next draw sees no change.
+// records the new width, so the next draw sees no change. Preview images
+// drawn with terminal graphics are placed from the same hook, once the frame
+// is drawn.
 func (c *Controller) watchResize() {
-	c.view.App.SetAfterDrawFunc(func(_ tcell.Screen) {
+	c.view.App.SetAfterDrawFunc(func(screen tcell.Screen) {
+		c.placeGraphics(screen)
 		if !c.browsing {
 			return
 		}
@@ -3159,14 +3355,28 @@ func (c *Controller) setInput() {
 		c.view.PaneList(i).SetInputCapture(c.listInputCapture)
 		c.wireListChanged(c.view.PaneList(i))
 	}
+	c.view.Preview.SetInputCapture(c.previewInputCapture)
+	c.view.PreviewTree.SetInputCapture(c.previewInputCapture)
+	c.view.PreviewTable.SetInputCapture(c.previewInputCapture)
+	c.view.PreviewImage.SetInputCapture(c.previewInputCapture)
+	c.view.PreviewTree.SetSelectedFunc(func(n *tview.TreeNode) {
+		n.SetExpanded(!n.IsExpanded())
+		foldMark(n)
+	})
 }
 
 // wireListChanged makes a list update the details panel as its selection moves.
 // It reads the secondary text of the changed row (the object key) directly, so
 // it works regardless of which pane fired it.
+//
+// It also pulls in the next page of a partly loaded listing once the cursor
+// comes within listPrefetchRows of the end.
 func (c *Controller) wireListChanged(list *tview.List) {
-	list.SetChangedFunc(func(_ int, _ string, secondary string, _ rune) {
+	list.SetChangedFunc(func(i int, _ string, secondary string, _ rune) {
 		c.fillDetails(strings.TrimSpace(secondary))
+		if i >= list.GetItemCount()-listPrefetchRows {
+			c.loadMore()
+		}
 	})
 }
 
@@ -3174,13 +3384,21 @@ func (c *Controller) wireListChanged(list *tview.List) {
 func (c *Controller) listInputCapture(event *tcell.EventKey) *tcell.EventKey {
 	switch event.Key() {
 	case tcell.KeyTab:
-		if c.dual {
+		switch {
+		case c.dual:
 			c.swapAndFocus()
+		case c.preview.on:
+			c.focusPreview()
 		}
 		return nil
 	case tcell.KeyCtrlO:
 		c.ToggleDualPane()
 		return nil
+	case tcell.KeyEsc:
+		if c.StopListing() {
+			return nil
+		}
+		return event
 	case tcell.KeyRune:
 		switch event.Rune() {
 		case ' ': // Space
@@ -3234,12 +3452,27 @@ func (c *Controller) listInputCapture(event *tcell.EventKey) *tcell.EventKey {
 		case 'D':
 			c.FindDuplicates()
 			return nil
+		case 'g':
+			c.ContentSearch()
+			return nil
 		case 'e':
 			c.EditObject()
 			return nil
+		case 'n':
+			c.NewObject()
+			return nil
+		case 'f':
+			c.Follow()
+			return nil
+		case '|':
+			c.PipeObjects()
+			return nil
 		case '>':
 			c.CopyToProfile()
 			return nil
+		case 'P':
+			c.OpenProfileInPane()
+			return nil
 		}
 	case tcell.KeyLeft:
 		if event.Modifiers()&tcell.ModAlt != 0 {
@@ -3251,6 +3484,9 @@ func (c *Controller) listInputCapture(event *tcell.EventKey) *tcell.EventKey {
 			c.HistoryForward()
 			return nil
 		}
+	case tcell.KeyF3:
+		c.TogglePreview()
+		return nil
 	case tcell.KeyF5:
 		c.Refresh()
 		return nil
@@ -3331,6 +3567,8 @@ func (c *Controller) listInputCapture(event *tcell.EventKey) *tcell.EventKey {
 func (c *Controller) swapPane() {
 	c.mu.Lock()
 	c.panes[c.active] = paneState{
+		model:           c.model,
+		config:          c.activeConfig,
 		buckets:         c.buckets,
 		objs:            c.objs,
 		currentPath:     c.currentPath,
@@ -3343,6 +3581,8 @@ func (c *Controller) swapPane() {
 	}
 	c.active ^= 1
 	p := c.panes[c.active]
+	c.model = p.model
+	c.activeConfig = p.config
 	c.buckets = p.buckets
 	c.objs = p.objs
 	c.currentPath = p.currentPath
@@ -3380,15 +3620,21 @@ func (c *Controller) ToggleDualPane() {
 		c.dual = false
 		c.view.ShowSinglePane()
 		c.view.App.SetFocus(c.view.List)
+		c.previewHighlighted()
 		return
 	}
 
+	c.stopPreview()
 	c.dual = true
 	c.view.ShowDualPane()
 	if !c.pane1Init {
 		c.pane1Init = true
+		c.mu.Lock()
+		c.panes[1].model = c.model
+		c.panes[1].config = c.activeConfig
 		c.panes[1].currentBucket = c.currentBucket
 		c.panes[1].currentPath = c.currentPath
+		c.mu.Unlock()
 	}
 	c.swapAndFocus()
 }
@@ -3405,11 +3651,15 @@ func (c *Controller) resetPanes() {
 	c.mu.Lock()
 	c.selectedByScope = make(map[string]map[string]bool)
 	c.filter = ""
-	c.mu.Unlock()
-	c.hist = histStack{}
+	c.cancelNavLocked(0)
+	c.cancelNavLocked(1)
+	c.listing = [2]*pagedListing{}
 	c.panes[0] = paneState{}
 	c.panes[1] = paneState{selectedByScope: make(map[string]map[string]bool)}
+	c.mu.Unlock()
+	c.hist = histStack{}
 	c.view.ShowSinglePane()
+	c.closePreview()
 	c.filterSuppress = true
 	c.view.PaneFilter(0).SetText("")
 	c.view.PaneFilter(1).SetText("")
@@ -3464,7 +3714,8 @@ func (c *Controller) fillConfigData() {
 func (c *Controller) fillDetails(key string) {
 	c.view.Details.Clear()
 	var otype string
-	if val, ok := c.lookupObj(key); ok {
+	val, ok := c.lookupObj(key)
+	if ok {
 		switch ot := val.Ot; ot {
 		case model.File:
 			otype = "File"
@@ -3492,6 +3743,7 @@ func (c *Controller) fillDetails(key string) {
 			fmt.Fprintf(c.view.Details, "[green] Storage class: [white] %s\n", *val.StorageClass)
 		}
 	}
+	c.previewObject(val)
 }
 
 func (c *Controller) Duck(url string, region *string, acc string, sec string, ssl bool) {
@@ -3504,6 +3756,7 @@ func (c *Controller) Duck(url string, region *string, acc string, sec string, ss
 		token = c.activeConfig.SessionToken
 	}
 	mCf := model.NewConfig(url, region, acc, sec, token, ssl, maxBps)
+	mCf.ListCacheTTL = listCacheTTL(c.activeConfig)
 	mdl, err := model.NewModel(mCf)
 	if err != nil {
 		go c.error("Cannot open profile", err)
@@ -3519,8 +3772,10 @@ func (c *Controller) Duck(url string, region *string, acc string, sec string, ss
 	c.browsing = true // the browser owns the shared list widget from here on
 	c.resetPanes()    // fresh single-pane browser for this profile
 	c.wireListChanged(c.view.PaneList(0))
+	c.mu.Lock()
 	c.currentBucket = nil
 	c.currentPath = ""
+	c.mu.Unlock()
 	c.bucketPos = 0
 	c.setInput()
 	go c.updateList()
@@ -3701,6 +3956,22 @@ func (c *Controller) ShowLocalFSModal(startPath string) {
 		c.Upload(fullPath)
 	})
 
+	// Upload extracted sends an archive's members rather than the archive.
+	extractBtn := tview.NewButton("Upload extracted").SetSelectedFunc(func() {
+		i := localList.GetCurrentItem()
+		if i < 0 || localList.GetItemCount() == 0 {
+			return
+		}
+		_, raw := localList.GetItemText(i)
+		fullPath := filepath.Join(currentPath, raw)
+		if fi, err := os.Stat(fullPath); raw == "" || raw == ".." || err != nil || fi.IsDir() || model.ArchiveKind(raw) == "" {
+			go c.error("Upload extracted", fmt.Errorf("pick a .zip, .tar, .tar.gz, .tar.bz2 or .tar.zst file"))
+			return
+		}
+		c.view.Pages.RemovePage("modal")
+		c.UploadExtracted(fullPath)
+	})
+
 	cancelBtn := tview.NewButton("Cancel").SetSelectedFunc(func() {
 		c.view.Pages.RemovePage("modal")
 	})
@@ -3709,12 +3980,14 @@ func (c *Controller) ShowLocalFSModal(startPath string) {
 		SetDirection(tview.FlexColumn).
 		AddItem(okBtn, 0, 1, false).
 		AddItem(tview.NewBox(), 2, 0, false).
+		AddItem(extractBtn, 0, 2, false).
+		AddItem(tview.NewBox(), 2, 0, false).
 		AddItem(cancelBtn, 0, 1, false)
 
 	flex, _ := layout.(*tview.Flex)
 	flex.AddItem(buttonRow, 1, 0, false)
 
-	focusables := []tview.Primitive{localList, okBtn, cancelBtn}
+	focusables := []tview.Primitive{localList, okBtn, extractBtn, cancelBtn}
 	focusIndex := 0
 	setNextFocus := func() {
 		focusIndex = (focusIndex + 1) % len(focusables)
@@ -3788,20 +4061,15 @@ func (c *Controller) ShowLocalFSModal(startPath string) {
 		return event
 	})
 
-	okBtn.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyTab {
-			setNextFocus()
-			return nil
-		}
-		return event
-	})
-	cancelBtn.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyTab {
-			setNextFocus()
-			return nil
-		}
-		return event
-	})
+	for _, btn := range []*tview.Button{okBtn, extractBtn, cancelBtn} {
+		btn.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyTab {
+				setNextFocus()
+				return nil
+			}
+			return event
+		})
+	}
 
 	modal := c.view.ModalEdit(layout, 60, 25)
 	c.view.Pages.AddPage("modal", modal, true, true)
@@ -3844,7 +4112,7 @@ func (c *Controller) Upload(localPath string) {
 
 	// PrepareUpload already knows every key this would write, so the overwrite
 	// scan needs no extra listing of the source.
-	plan := func() ([]string, error) {
+	plan := func(context.Context) ([]string, error) {
 		keys := make([]string, 0, len(files))
 		for _, f := range files {
 			keys = append(keys, f.RemotePath)
@@ -3857,7 +4125,40 @@ func (c *Controller) Upload(localPath string) {
 			go c.success("Nothing to upload")
 			return
 		}
-		c.runUpload(mdl, localPath, dstPath, dstBucket, kept, keptSize, skip)
+		c.runUpload(mdl, localPath, dstPath, dstBucket, kept, keptSize, skip, false)
+	})
+}
+
+// UploadExtracted uploads the members of the local zip or tar at localPath
+// under the current prefix, streamed out of the archive, through the same
+// overwrite check and transfer job as Upload. Listing the members can mean
+// reading a compressed tar through, so it is part of the checked plan, off
+// the UI goroutine.
+func (c *Controller) UploadExtracted(localPath string) {
+	dstBucket := c.currentBucket
+	dstPath := c.currentPath
+	mdl := c.model
+
+	var files []model.UploadTarget
+	plan := func(context.Context) ([]string, error) {
+		var err error
+		files, _, err = mdl.PrepareArchiveUpload(localPath, dstPath)
+		if err == nil && len(files) == 0 {
+			err = fmt.Errorf("%s has no files to upload", filepath.Base(localPath))
+		}
+		keys := make([]string, 0, len(files))
+		for _, f := range files {
+			keys = append(keys, f.RemotePath)
+		}
+		return keys, err
+	}
+	c.confirmOverwrites(mdl, dstBucket, "Upload extracted", plan, func(skip map[string]bool) {
+		kept, keptSize := unskippedUploads(files, skip)
+		if len(kept) == 0 {
+			go c.success("Nothing to upload")
+			return
+		}
+		c.runUpload(mdl, localPath, dstPath, dstBucket, kept, keptSize, skip, true)
 	})
 }
 
@@ -3883,12 +4184,47 @@ func unskippedUploads(files []model.UploadTarget, skip map[string]bool) ([]model
 	return kept, total
 }
 
+// retryUploads picks the listed keys out of an upload's files and returns
+// them with their bytes and the skip set that confines a re-run of the same
+// source to them: the original skips plus every other planned key.
+func retryUploads(files []model.UploadTarget, skip map[string]bool, keys []string) ([]model.UploadTarget, int64, map[string]bool) {
+	want := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		want[k] = true
+	}
+	rest := make(map[string]bool, len(skip)+len(files))
+	for k := range skip {
+		rest[k] = true
+	}
+	var out []model.UploadTarget
+	var total int64
+	for _, f := range files {
+		if want[f.RemotePath] {
+			out = append(out, f)
+			total += f.Size
+			continue
+		}
+		rest[f.RemotePath] = true
+	}
+	return out, total, rest
+}
+
 // runUpload transfers the approved files as a cancellable, backgroundable job.
-func (c *Controller) runUpload(mdl *model.Model, localPath, dstPath string, dstBucket *model.Object, files []model.UploadTarget, totalSize int64, skip map[string]bool) {
+// extracted uploads the members of the archive at localPath instead
+// (UploadExtracted).
+func (c *Controller) runUpload(mdl *model.Model, localPath, dstPath string, dstBucket *model.Object, files []model.UploadTarget, totalSize int64, skip map[string]bool, extracted bool) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	first := files[0]
 	job := c.addJob("upload", fmt.Sprintf("%s → %s/%s", filepath.Base(localPath), *dstBucket.Key, dstPath), totalSize, len(files), cancel)
+	// A retry walks the same source again with everything but the failed
+	// files skipped; a file added there since is sent with them.
+	job.setRetry(func(keys []string) {
+		again, size, rest := retryUploads(files, skip, keys)
+		if len(again) > 0 {
+			c.runUpload(mdl, localPath, dstPath, dstBucket, again, size, rest, extracted)
+		}
+	})
 
 	progress := tview.NewModal().
 		SetText("Starting upload...\n").
@@ -3934,7 +4270,18 @@ func (c *Controller) runUpload(mdl *model.Model, localPath, dstPath string, dstB
 		job.setStatus(jobRunning)
 		startTime = time.Now()
 
-		err := mdl.Upload(ctx, localPath, dstPath, dstBucket, skip, func(n, total int64, i, count int, local, remote string) {
+		send := mdl.Upload
+		if extracted {
+			send = mdl.UploadArchive
+		}
+		var failed []string
+		fileDone := func(key string, err error) {
+			job.recordErr(key, err)
+			if err != nil {
+				failed = append(failed, err.Error())
+			}
+		}
+		err := send(ctx, localPath, dstPath, dstBucket, skip, func(n, total int64, i, count int, local, remote string) {
 			job.setProgress(n, i)
 			select {
 			case <-ctx.Done():
@@ -3967,33 +4314,50 @@ func (c *Controller) runUpload(mdl *model.Model, localPath, dstPath string, dstB
 					remote,
 				))
 			})
-		})
+		}, fileDone)
 
 		select {
 		case <-ctx.Done():
-			c.finalizeJob(job, true, 0)
+			c.finalizeJob(job, true, len(failed))
 			go c.updateList()
 			return
 		default:
 			if err != nil {
-				c.finalizeJob(job, false, 1)
+				// Not one file's: the tree or a folder marker failed, and the
+				// files after it were never tried.
+				job.recordItem(localPath, itemFailed, err.Error())
+				c.finalizeJob(job, false, len(failed)+1)
 				if !job.isBackgrounded() {
 					c.view.App.QueueUpdateDraw(func() {
 						c.view.Pages.RemovePage("progress").SwitchToPage("main")
 					})
 				}
+				go c.updateList()
 				c.error("Upload failed", err)
 				return
 			}
 
-			c.finalizeJob(job, false, 0)
+			c.finalizeJob(job, false, len(failed))
 			if job.isBackgrounded() {
 				go c.updateList()
 				return
 			}
+			msg := "Upload complete."
+			if len(failed) > 0 {
+				msg = fmt.Sprintf("Upload finished with errors.\n\nSent: %d\nFailed: %d", len(files)-len(failed), len(failed))
+				for i, f := range failed {
+					if i == 8 {
+						msg += fmt.Sprintf("\n  ...and %d more", len(failed)-8)
+						break
+					}
+					msg += "\n  - " + f
+				}
+				msg += "\n\nThe transfers panel (t) can retry them."
+			}
+			msg += "\n\nPress Done to return."
 			c.view.App.QueueUpdateDraw(func() {
 				progress.ClearButtons()
-				progress.SetText("Upload complete.\n\nPress Done to return.")
+				progress.SetText(msg)
 				progress.AddButtons([]string{"Done"})
 				progress.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 					c.view.Pages.RemovePage("progress").SwitchToPage("main")
@@ -4011,7 +4375,7 @@ func (c *Controller) runUpload(mdl *model.Model, localPath, dstPath string, dstB
 func (c *Controller) uploadEmptyDir(localPath, dstPath string, dstBucket *model.Object) {
 	mdl := c.model
 	go func() {
-		if err := mdl.Upload(context.Background(), localPath, dstPath, dstBucket, nil, nil); err != nil {
+		if err := mdl.Upload(context.Background(), localPath, dstPath, dstBucket, nil, nil, nil); err != nil {
 			c.error("Upload failed", err)
 			return
 		}
@@ -4147,11 +4511,14 @@ func (c *Controller) clearDetailsIfNoSelection() {
 // huge bucket can't build an unbounded modal; the cap is surfaced in the title.
 const searchMaxResults = 1000
 
-// searchHit is one recursive-search result: a full object key and its size.
+// searchHit is one recursive-search result: a full object key and its size,
+// or for a content search a key and a matching line.
 type searchHit struct {
 	bucket string // "" = current bucket (single-bucket search)
 	key    string
 	size   int64
+	line   int    // content search: 1-based line number; 0 otherwise
+	text   string // content search: the line, trimmed by grepPreview
 }
 
 // searchMatch reports whether a full object key matches query (case-insensitive
@@ -4164,24 +4531,44 @@ func searchMatch(key, query string) bool {
 	return strings.Contains(strings.ToLower(key), strings.ToLower(query))
 }
 
-// computeHits filters a recursive object listing to those matching query,
-// returning at most max hits and whether more were dropped (max <= 0: no cap).
+// hitCollector gathers search hits, at most max of them (max <= 0: no cap).
+type hitCollector struct {
+	query     *searchQuery
+	max       int
+	hits      []searchHit
+	truncated bool
+}
+
+// add records o if it matches and reports whether to keep going: false once
+// a match past the cap has been seen. Folder markers never match; search
+// targets real objects.
+func (h *hitCollector) add(o s3t.Object) bool {
+	if o.Key == nil || *o.Key == "" || strings.HasSuffix(*o.Key, "/") || !h.query.match(targetOfObject(o)) {
+		return true
+	}
+	if h.max > 0 && len(h.hits) >= h.max {
+		h.truncated = true
+		return false
+	}
+	h.hits = append(h.hits, searchHit{key: *o.Key, size: o.Size})
+	return true
+}
+
+// computeHits filters a recursive object listing to those matching query (see
+// searchQuery; an invalid query matches nothing), returning at most max hits
+// and whether more were dropped (max <= 0: no cap).
 func computeHits(objs []s3t.Object, query string, max int) (hits []searchHit, truncated bool) {
+	q, err := parseQuery(query, time.Now())
+	if err != nil {
+		return nil, false
+	}
+	h := hitCollector{query: q, max: max}
 	for _, o := range objs {
-		if o.Key == nil {
-			continue
-		}
-		k := *o.Key
-		if !searchMatch(k, query) {
-			continue
-		}
-		if max > 0 && len(hits) >= max {
-			truncated = true
+		if !h.add(o) {
 			break
 		}
-		hits = append(hits, searchHit{key: k, size: o.Size})
 	}
-	return hits, truncated
+	return h.hits, h.truncated
 }
 
 // parentPrefix returns the S3 prefix of the folder containing key (terminated
@@ -4206,23 +4593,36 @@ func (c *Controller) RecursiveSearch() {
 	}
 	bucket := c.currentBucket
 	prefix := c.currentPath
+	index := c.findIndex(*bucket.Key, model.NormalizePrefix(prefix))
 
-	form := c.view.NewSearchForm("Recursive search")
+	form := c.view.NewSearchForm("Recursive search", index != "")
 	form.AddButton("Search", func() {
 		q := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
 		allBuckets := form.GetFormItem(1).(*tview.Checkbox).IsChecked()
+		useIndex := index != "" && form.GetFormItem(2).(*tview.Checkbox).IsChecked()
 		c.view.Pages.RemovePage("modal")
 		if q == "" {
 			return
 		}
-		if allBuckets {
-			c.runAllBucketsSearch(q)
+		if _, err := parseQuery(q, time.Now()); err != nil {
+			go c.error("Search", err)
 			return
 		}
-		c.runRecursiveSearch(bucket, prefix, q)
+		switch {
+		case allBuckets:
+			c.runAllBucketsSearch(q)
+		case useIndex:
+			c.runIndexSearch(index, model.NormalizePrefix(prefix), q)
+		default:
+			c.runRecursiveSearch(bucket, prefix, q)
+		}
 	})
 	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
-	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 65, 9), true, true)
+	height := 9
+	if index != "" {
+		height = 11
+	}
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 65, height), true, true)
 }
 
 // runAllBucketsSearch lists every bucket recursively (off the UI goroutine) and
@@ -4230,11 +4630,17 @@ func (c *Controller) RecursiveSearch() {
 // Buckets that can't be listed (e.g. a different region on the shared client)
 // are skipped.
 func (c *Controller) runAllBucketsSearch(query string) {
-	searching := tview.NewModal().SetText(fmt.Sprintf("Searching all buckets for %q ...", query))
-	c.view.Pages.AddPage("searching", searching, true, true)
+	text := fmt.Sprintf("Searching all buckets for %q ...", query)
+	modal, ctx, cancel := c.scanModal("searching", text)
+	scanned := c.scanCounter(modal, text)
+	mdl := c.model
 
 	go func() {
-		buckets, err := c.model.ListBuckets()
+		defer cancel()
+		buckets, err := mdl.ListBuckets(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("searching") })
 			c.error("Search failed", err)
@@ -4242,6 +4648,7 @@ func (c *Controller) runAllBucketsSearch(query string) {
 		}
 		var hits []searchHit
 		truncated := false
+		done := 0 // objects scanned in the buckets already searched
 		for _, b := range buckets {
 			if b == nil || b.Key == nil {
 				continue
@@ -4250,10 +4657,15 @@ func (c *Controller) runAllBucketsSearch(query string) {
 				truncated = true
 				break
 			}
-			objs, err := c.model.ListObjects("", b)
+			base := done
+			objs, err := mdl.ListObjectsSharded(ctx, "", b, func(n int) { scanned(base + n) })
+			if ctx.Err() != nil {
+				return
+			}
 			if err != nil {
 				continue // skip buckets we can't list (region/permission)
 			}
+			done += len(objs)
 			bh, tr := computeHits(objs, query, searchMaxResults-len(hits))
 			for _, h := range bh {
 				hits = append(hits, searchHit{bucket: *b.Key, key: h.key, size: h.size})
@@ -4264,7 +4676,7 @@ func (c *Controller) runAllBucketsSearch(query string) {
 		}
 		c.view.App.QueueUpdateDraw(func() {
 			c.view.Pages.RemovePage("searching")
-			c.presentSearchResults(query, hits, truncated)
+			c.presentSearchResults(query, hits, truncated, inventoryNote(mdl, ""))
 		})
 	}()
 }
@@ -4272,11 +4684,17 @@ func (c *Controller) runAllBucketsSearch(query string) {
 // runRecursiveSearch lists the prefix recursively off the UI goroutine, then
 // shows the matches (or an error / "no matches" note).
 func (c *Controller) runRecursiveSearch(bucket *model.Object, prefix, query string) {
-	searching := tview.NewModal().SetText(fmt.Sprintf("Searching for %q ...", query))
-	c.view.Pages.AddPage("searching", searching, true, true)
+	text := fmt.Sprintf("Searching for %q ...", query)
+	modal, ctx, cancel := c.scanModal("searching", text)
+	scanned := c.scanCounter(modal, text)
+	mdl := c.model
 
 	go func() {
-		objs, err := c.model.ListObjects(prefix, bucket)
+		defer cancel()
+		objs, err := mdl.ListObjectsSharded(ctx, prefix, bucket, scanned)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("searching") })
 			c.error("Search failed", err)
@@ -4285,17 +4703,22 @@ func (c *Controller) runRecursiveSearch(bucket *model.Object, prefix, query stri
 		hits, truncated := computeHits(objs, query, searchMaxResults)
 		c.view.App.QueueUpdateDraw(func() {
 			c.view.Pages.RemovePage("searching")
-			c.presentSearchResults(query, hits, truncated)
+			c.presentSearchResults(query, hits, truncated, inventoryNote(mdl, *bucket.Key))
 		})
 	}()
 }
 
 // presentSearchResults builds the results list (or a "no matches" note) and
-// shows it. Must run on the UI goroutine.
-func (c *Controller) presentSearchResults(query string, hits []searchHit, truncated bool) {
+// shows it. note, if set, says where the hits came from (an inventory report).
+// Must run on the UI goroutine.
+func (c *Controller) presentSearchResults(query string, hits []searchHit, truncated bool, note string) {
 	if len(hits) == 0 {
+		text := fmt.Sprintf("No matches for %q", query)
+		if note != "" {
+			text = fmt.Sprintf("%s\n(%s)", text, note)
+		}
 		m := tview.NewModal().
-			SetText(fmt.Sprintf("No matches for %q", query)).
+			SetText(text).
 			AddButtons([]string{"OK"})
 		m.SetDoneFunc(func(int, string) {
 			c.view.Pages.RemovePage("search-results")
@@ -4314,11 +4737,17 @@ func (c *Controller) presentSearchResults(query string, hits []searchHit, trunca
 	if truncated {
 		countLabel = fmt.Sprintf("first %d match(es)", len(hits))
 	}
+	if note != "" {
+		countLabel = fmt.Sprintf("%s, %s", countLabel, note)
+	}
 	results.SetTitle(fmt.Sprintf(" Search %q — %s (Enter: reveal, Esc: close) ", query, countLabel))
 
 	for _, h := range hits {
 		h := h
 		label := fmt.Sprintf("📄 %s  [gray](%s)[-]", h.key, humanize.IBytes(uint64(h.size)))
+		if h.line > 0 {
+			label = fmt.Sprintf("📄 %s[gray]:%d:[-] %s", tview.Escape(h.key), h.line, tview.Escape(h.text))
+		}
 		if h.bucket != "" {
 			label = fmt.Sprintf("[yellow]%s[-]/%s  [gray](%s)[-]", h.bucket, h.key, humanize.IBytes(uint64(h.size)))
 		}
@@ -4348,11 +4777,9 @@ func (c *Controller) presentSearchResults(query string, hits []searchHit, trunca
 // revealKey navigates to the folder containing key and highlights it. key is a
 // full object key inside the current bucket (from a search result).
 func (c *Controller) revealKey(key string) {
-	c.clearFilterUI() // ensure the revealed item isn't hidden by an old filter
+	base := c.shownLocation()
 	c.recordHistory()
-	c.currentPath = parentPrefix(key)
-	c.restoreNext = key
-	go c.updateList()
+	c.navigate(navRequest{target: location{bucket: base.bucket, path: parentPrefix(key)}, restore: key})
 }
 
 // addBookmark appends bm unless an entry with the same Bucket+Prefix already
@@ -4371,28 +4798,18 @@ func addBookmark(list []cfg.Bookmark, bm cfg.Bookmark) ([]cfg.Bookmark, bool) {
 // mirroring Down. selectKey, if non-empty, is the object to highlight on
 // arrival; otherwise the cursor lands on "..".
 func (c *Controller) jumpTo(bucketName, prefix, selectKey string) {
-	c.clearFilterUI()
-	c.view.Details.Clear()
 	c.recordHistory()
-	go func() {
-		bucket := c.findBucketByName(bucketName)
-		if bucket == nil {
-			c.error("Jump", fmt.Errorf("bucket %q not found", bucketName))
-			return
-		}
-		c.currentBucket = bucket
-		if err := c.model.RefreshClient(&bucketName); err != nil {
-			c.error("Failed to resolve bucket region", err)
-		}
-		c.currentPath = prefix
-		if selectKey != "" {
-			c.restoreNext = selectKey
-		} else {
-			c.restoreNext = ".."
-		}
-		c.updateList()
-		c.view.App.QueueUpdateDraw(func() { c.view.App.SetFocus(c.view.List) })
-	}()
+	restore := selectKey
+	if restore == "" {
+		restore = ".."
+	}
+	c.navigate(navRequest{
+		target:     location{path: prefix},
+		bucketName: bucketName,
+		enter:      true,
+		restore:    restore,
+	})
+	c.view.App.SetFocus(c.view.List)
 }
 
 // Bookmarks opens the per-profile bookmark manager: Enter jumps to a bookmark,
@@ -4533,12 +4950,13 @@ func (h *histStack) goForward(cur location) (location, bool) {
 	return next, true
 }
 
+// curLocation is where the active pane is, or is heading (navBase).
 func (c *Controller) curLocation() location {
-	return location{bucket: c.currentBucket, path: c.currentPath}
+	return c.navBase()
 }
 
 // recordHistory pushes the current location so a later Back returns to it.
-// Called at the start of user-initiated navigation, before state changes.
+// Called at the start of user-initiated navigation, before it is issued.
 func (c *Controller) recordHistory() {
 	c.hist.record(c.curLocation())
 }
@@ -4559,25 +4977,11 @@ func (c *Controller) HistoryForward() {
 // navigateTo jumps straight to loc without touching the history stacks (the
 // history machinery drives it). Mirrors Down/jumpTo's client handling.
 func (c *Controller) navigateTo(loc location) {
-	c.clearFilterUI()
-	c.view.Details.Clear()
 	if loc.bucket == nil {
-		c.currentBucket = nil
-		c.currentPath = ""
-		c.restoreNext = ""
-		go c.updateList()
+		c.navigate(navRequest{})
 		return
 	}
-	go func() {
-		name := *loc.bucket.Key
-		c.currentBucket = loc.bucket
-		if err := c.model.RefreshClient(&name); err != nil {
-			c.error("Failed to resolve bucket region", err)
-		}
-		c.currentPath = loc.path
-		c.restoreNext = ".."
-		c.updateList()
-	}()
+	c.navigate(navRequest{target: loc, enter: true, restore: ".."})
 }
 
 // paletteAction is one entry in the command palette: a label and the action to
@@ -4633,21 +5037,30 @@ func (c *Controller) paletteActions() []paletteAction {
 		{"Clipboard: paste", c.paste},
 		{"Undo last move/rename", c.Undo},
 		{"Sync (local ⇄ remote, or remote → remote)", c.Sync},
-		{"Compare the two panes", c.ComparePanes},
+		{"Compare the two panes / diff with…", c.ComparePanes},
 		{"Find duplicates (size + ETag)", c.FindDuplicates},
+		{"New object in $EDITOR…", c.NewObject},
 		{"Edit in $EDITOR", c.EditObject},
-		{"Copy to another profile…", c.CopyToProfile},
+		{"Follow (tail -f)", c.Follow},
+		{"Pipe through a command…", c.PipeObjects},
+		{"S3 Select query (CSV / JSON / Parquet)…", c.SelectConsole},
+		{"Copy / move / sync to another profile…", c.CopyToProfile},
+		{"Open profile in this pane…", c.OpenProfileInPane},
 		{"Transfers", c.ShowTransfers},
 		{"Filter listing", c.focusFilter},
 		{"Refresh listing", c.Refresh},
 		{"Sort: cycle name/size/date", c.CycleSort},
 		{"Sort: reverse direction", c.ToggleSortDir},
 		{"Recursive search", c.RecursiveSearch},
+		{"Build / refresh local search index", c.BuildIndex},
+		{"Search object contents (grep)", c.ContentSearch},
 		{"Bookmarks", c.Bookmarks},
 		{"Toggle dual-pane", c.ToggleDualPane},
+		{"Toggle preview pane", c.TogglePreview},
 		{"History back", c.HistoryBack},
 		{"History forward", c.HistoryForward},
 		{"Size summary", c.ShowSummaryModal},
+		{"Open S3 Inventory report…", c.OpenInventory},
 		{"Properties", func() { c.ShowFileProperties(c.getSelectedObjectName()) }},
 		{"Versions (history / restore)", c.ShowVersions},
 		{"Metadata & tags", c.EditObjectMeta},
@@ -4966,6 +5379,11 @@ type transferJob struct {
 	doneCount int
 	failed    int
 	bg        bool
+
+	// results keeps every item's outcome once the job has run; retry, when
+	// set, re-enqueues a subset of those keys as a new job.
+	results []itemResult
+	retry   func(keys []string)
 }
 
 func (j *transferJob) setStatus(s jobStatus) { j.mu.Lock(); j.status = s; j.mu.Unlock() }
@@ -5114,10 +5532,12 @@ func (c *Controller) renderTransfers() {
 }
 
 // ShowTransfers opens the background-transfers panel, refreshing every 300ms
-// while open. d/Del cancels the selected job, c clears finished, Esc closes.
+// while open. d/Del cancels the selected job, c clears finished, Enter browses
+// the job's per-item results, r retries its failures, e exports its results,
+// Esc closes.
 func (c *Controller) ShowTransfers() {
 	list := tview.NewList().ShowSecondaryText(true)
-	list.SetBorder(true).SetTitle(" Transfers — d: cancel • c: clear finished • Esc: close ")
+	list.SetBorder(true).SetTitle(" Transfers — Enter: results • r: retry failed • e: export • d: cancel • c: clear finished • Esc: close ")
 	list.SetSelectedBackgroundColor(tcell.ColorBlue)
 	list.SetSelectedTextColor(tcell.ColorWhite)
 
@@ -5154,6 +5574,11 @@ func (c *Controller) ShowTransfers() {
 		case tcell.KeyEsc:
 			closePanel()
 			return nil
+		case tcell.KeyEnter:
+			if j := c.jobAt(list.GetCurrentItem()); j != nil {
+				c.showJobResults(j, closePanel)
+			}
+			return nil
 		case tcell.KeyDelete:
 			c.cancelJobAt(list.GetCurrentItem())
 			return nil
@@ -5166,6 +5591,17 @@ func (c *Controller) ShowTransfers() {
 				c.clearFinishedJobs()
 				c.renderTransfers()
 				return nil
+			case 'r':
+				if j := c.jobAt(list.GetCurrentItem()); j != nil {
+					closePanel()
+					c.retryFailed(j)
+				}
+				return nil
+			case 'e':
+				if j := c.jobAt(list.GetCurrentItem()); j != nil {
+					c.exportResults(j)
+				}
+				return nil
 			}
 		}
 		return ev
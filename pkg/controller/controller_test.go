@@ -606,8 +606,13 @@ func TestSwapPane(t *testing.T) {
 	c.objs = map[string]*model.Object{"a0": nil}
 	c.selectedByScope = map[string]map[string]bool{"s0": {}}
 	c.hist = histStack{back: []location{{path: "h0"}}}
+	m0, m1 := &model.Model{}, &model.Model{}
+	p0, p1 := &cfg.Config{Name: "p0"}, &cfg.Config{Name: "p1"}
+	c.model, c.activeConfig = m0, p0
 
 	c.panes[1] = paneState{
+		model:           m1,
+		config:          p1,
 		currentBucket:   bB,
 		currentPath:     "p1/",
 		filter:          "f1",
@@ -642,6 +647,9 @@ func TestSwapPane(t *testing.T) {
 	if len(c.hist.back) != 1 || c.hist.back[0].path != "h1" {
 		t.Errorf("hist not swapped to pane 1: %+v", c.hist)
 	}
+	if c.model != m1 || c.activeConfig != p1 {
+		t.Errorf("profile not swapped to pane 1: model=%p config=%v", c.model, c.activeConfig)
+	}
 	if c.view.List != c.view.PaneList(1) || c.view.Filter != c.view.PaneFilter(1) {
 		t.Errorf("view widgets not repointed to pane 1")
 	}
@@ -667,6 +675,9 @@ func TestSwapPane(t *testing.T) {
 	if _, ok := c.objs["a0"]; !ok {
 		t.Errorf("round-trip objs wrong")
 	}
+	if c.model != m0 || c.activeConfig != p0 || c.panes[1].model != m1 || c.panes[1].config != p1 {
+		t.Errorf("round-trip profiles wrong")
+	}
 	if c.view.List != c.view.PaneList(0) {
 		t.Errorf("view.List not repointed back to pane 0")
 	}
@@ -1089,3 +1100,77 @@ func TestValidateEntryName(t *testing.T) {
 		}
 	}
 }
+
+func TestBucketPickerLabels(t *testing.T) {
+	got := bucketPickerLabels(
+		[]string{"logs", "media", "legacy"},
+		map[string]string{"logs": "eu-west-1", "media": "us-east-1"},
+	)
+	want := []string{"logs  (eu-west-1)", "media  (us-east-1)", "legacy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bucketPickerLabels = %q, want %q", got, want)
+	}
+	// Off AWS there are no regions: the names come through unchanged.
+	if got := bucketPickerLabels([]string{"a"}, nil); got[0] != "a" {
+		t.Errorf("label without region = %q", got[0])
+	}
+}
+
+func TestPaneProfileTag(t *testing.T) {
+	a, b := &cfg.Config{Name: "staging"}, &cfg.Config{Name: "prod"}
+	if got := paneProfileTag(true, a, b); !strings.Contains(got, "staging") {
+		t.Errorf("different profiles: got %q, want the pane's profile named", got)
+	}
+	for name, got := range map[string]string{
+		"single pane":  paneProfileTag(false, a, b),
+		"same profile": paneProfileTag(true, a, &cfg.Config{Name: "staging"}),
+		"other unset":  paneProfileTag(true, a, nil),
+		"mine unset":   paneProfileTag(true, nil, b),
+	} {
+		if got != "" {
+			t.Errorf("%s: got %q, want no tag", name, got)
+		}
+	}
+}
+
+func TestSetPaneProfileInactive(t *testing.T) {
+	c := &Controller{view: view.NewView()}
+	m0, m1 := &model.Model{}, &model.Model{}
+	p0, p1 := &cfg.Config{Name: "p0"}, &cfg.Config{Name: "p1"}
+	c.model, c.activeConfig = m0, p0
+	c.panes[1] = paneState{model: m0, config: p0, currentBucket: &model.Object{Key: strptr("b")}, currentPath: "x/"}
+
+	c.setPaneProfile(1, m1, p1)
+
+	// The inactive pane starts over on the new profile; the active one is
+	// untouched.
+	if got := c.panes[1]; got.model != m1 || got.config != p1 || got.currentBucket != nil || got.currentPath != "" {
+		t.Errorf("pane 1 = %+v, want a fresh pane on p1", got)
+	}
+	if c.panes[1].selectedByScope == nil {
+		t.Errorf("pane 1 needs a live selection map")
+	}
+	if c.model != m0 || c.activeConfig != p0 {
+		t.Errorf("active pane changed")
+	}
+}
+
+func TestListCacheTTL(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"":     defaultListCacheTTL,
+		"junk": defaultListCacheTTL,
+		"0":    defaultListCacheTTL,
+		"30":   30 * time.Second,
+		" 90 ": 90 * time.Second,
+		"-1":   0,
+		"-600": 0,
+	} {
+		p := &cfg.Config{ListCacheSecs: parseListCacheSecs(in)}
+		if got := listCacheTTL(p); got != want {
+			t.Errorf("%q: TTL %v, want %v", in, got, want)
+		}
+	}
+	if listCacheTTL(nil) != defaultListCacheTTL {
+		t.Error("no profile must mean the default TTL")
+	}
+}
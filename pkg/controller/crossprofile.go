@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -34,16 +35,17 @@ func crossDstKey(srcPrefix, dstPrefix, key string) string {
 // modelForProfile builds an independent client for another profile, the same
 // way opening the profile would.
 func modelForProfile(p *cfg.Config) (*model.Model, error) {
-	return model.NewModel(model.NewConfig(
-		p.BaseUrl, p.Region, p.AccessKey, p.SecretKey, p.SessionToken,
-		!p.IgnoreSsl, p.MaxBytesPerSec))
+	mCf := model.NewConfig(p.BaseUrl, p.Region, p.AccessKey, p.SecretKey, p.SessionToken,
+		!p.IgnoreSsl, p.MaxBytesPerSec)
+	mCf.ListCacheTTL = listCacheTTL(p)
+	return model.NewModel(mCf)
 }
 
-// CopyToProfile copies the marked objects — or the highlighted one — to a
-// bucket in a *different profile*, streaming each object through this process
-// (GET here → PUT there). This is the one copy that works across endpoints;
-// the server-side Ctrl+Y copy requires both buckets behind one endpoint.
-// Runs on the UI goroutine.
+// CopyToProfile copies or moves the marked objects — or the highlighted one —
+// to a bucket in a *different profile*, streaming each object through this
+// process (GET here → PUT there), or syncs the current location into one. This
+// is the one transfer that works across endpoints; the server-side Ctrl+Y copy
+// requires both buckets behind one endpoint. Runs on the UI goroutine.
 func (c *Controller) CopyToProfile() {
 	if c.currentBucket == nil || c.view.List.GetItemCount() == 0 {
 		return
@@ -104,7 +106,7 @@ func (c *Controller) CopyToProfile() {
 	}
 
 	form := tview.NewForm()
-	form.SetTitle(fmt.Sprintf(" Copy %d item(s) to another profile ", len(items)))
+	form.SetTitle(fmt.Sprintf(" Transfer %d item(s) to another profile ", len(items)))
 	form.AddDropDown("Destination profile", names, 0, nil)
 	form.SetBorder(true)
 	form.AddButton("Next", func() {
@@ -121,6 +123,24 @@ func (c *Controller) CopyToProfile() {
 	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 60, 9), true, true)
 }
 
+// crossOperation is what the cross-profile transfer does; the order matches
+// crossOperationLabels, so the dropdown index is the operation.
+type crossOperation int
+
+const (
+	crossCopyOp crossOperation = iota
+	crossMoveOp
+	crossSyncOp
+)
+
+func crossOperationLabels() []string {
+	return []string{
+		"Copy marked items",
+		"Move marked items (verify, then delete source)",
+		"Sync this location (preview first)",
+	}
+}
+
 // pickCrossDestination builds the destination client, lists its buckets, and
 // asks for bucket + prefix. Runs on the UI goroutine; the listing is not.
 func (c *Controller) pickCrossDestination(items []copyMoveItem, src *model.Model, srcBucket *model.Object, srcPrefix string, dstProfile *cfg.Config) {
@@ -134,7 +154,7 @@ func (c *Controller) pickCrossDestination(items []copyMoveItem, src *model.Model
 			c.error(fmt.Sprintf("Cannot connect to %s", dstProfile.Name), err)
 			return
 		}
-		buckets, err := dst.ListBuckets()
+		buckets, err := dst.ListBuckets(context.Background())
 		if err != nil {
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
 			c.error(fmt.Sprintf("Cannot list buckets on %s", dstProfile.Name), err)
@@ -157,36 +177,87 @@ func (c *Controller) pickCrossDestination(items []copyMoveItem, src *model.Model
 
 			form := tview.NewForm()
 			form.SetTitle(fmt.Sprintf(" Destination on %s ", dstProfile.Name))
+			form.AddDropDown("Operation", crossOperationLabels(), 0, nil)
 			form.AddDropDown("Bucket", bucketNames, 0, nil)
 			form.AddInputField("Prefix", "", 50, nil, nil)
+			form.AddCheckbox("Sync: delete extraneous at destination", false, nil)
 			form.SetBorder(true)
-			form.AddButton("Copy", func() {
+			form.AddButton("Go", func() {
+				opIdx, _ := form.GetFormItemByLabel("Operation").(*tview.DropDown).GetCurrentOption()
 				_, bucketName := form.GetFormItemByLabel("Bucket").(*tview.DropDown).GetCurrentOption()
 				prefix := model.NormalizePrefix(form.GetFormItemByLabel("Prefix").(*tview.InputField).GetText())
+				del := form.GetFormItemByLabel("Sync: delete extraneous at destination").(*tview.Checkbox).IsChecked()
 				c.view.Pages.RemovePage("modal")
-				c.runCrossCopy(items, src, srcBucket, srcPrefix, dst, dstProfile.Name, bucketName, prefix)
+				switch crossOperation(opIdx) {
+				case crossSyncOp:
+					// Sync mirrors the current location as a whole, whatever
+					// is marked — the plan preview shows exactly what that is.
+					name := bucketName
+					c.previewSync(syncSpec{
+						dir:        syncCrossProfile,
+						srcBucket:  srcBucket,
+						srcPrefix:  srcPrefix,
+						dstBucket:  &model.Object{Key: &name, Ot: model.Bucket},
+						dstPrefix:  prefix,
+						del:        del,
+						dst:        dst,
+						dstProfile: dstProfile.Name,
+					})
+				default:
+					c.runCrossCopy(items, src, srcBucket, srcPrefix, dst, dstProfile.Name, bucketName, prefix,
+						crossOperation(opIdx) == crossMoveOp, nil)
+				}
 			})
 			form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
 			form.SetInputCapture(escapeCloses(c, "modal"))
 
-			c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 66, 11), true, true)
+			c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 70, 15), true, true)
 		})
 	}()
 }
 
+// retryCrossItems turns the listed source keys back into single-object copy
+// items, carrying the sizes the expanded operations recorded.
+func retryCrossItems(ops []crossOp, keys []string) []copyMoveItem {
+	want := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		want[k] = true
+	}
+	var items []copyMoveItem
+	for _, op := range ops {
+		if want[op.SrcKey] {
+			items = append(items, copyMoveItem{shortName: path.Base(op.SrcKey), srcKey: op.SrcKey, size: op.Size})
+		}
+	}
+	return items
+}
+
+// crossTitle names a cross-profile transfer in job rows, logs and reports.
+func crossTitle(move bool) (kind, title string) {
+	if move {
+		return "xmove", "Cross-profile move"
+	}
+	return "xcopy", "Cross-profile copy"
+}
+
 // runCrossCopy expands folders into concrete objects, then streams every
 // object through this process as a cancellable, backgroundable transfer job.
-// The source is never modified.
-func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBucket *model.Object, srcPrefix string, dst *model.Model, dstProfileName, dstBucketName, dstPrefix string) {
+// A copy never modifies the source; a move deletes each source object only
+// after its copy was verified (model.CrossMove), so an unverifiable copy
+// leaves the object on both sides rather than on neither. afterMove, when set,
+// runs on the UI goroutine once a move has moved anything (a cut-paste
+// consuming the clipboard).
+func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBucket *model.Object, srcPrefix string, dst *model.Model, dstProfileName, dstBucketName, dstPrefix string, move bool, afterMove func()) {
 	ctx, cancel := context.WithCancel(context.Background())
 	name := dstBucketName
 	dstBucket := &model.Object{Key: &name, Ot: model.Bucket}
+	kind, title := crossTitle(move)
 
-	job := c.addJob("xcopy", fmt.Sprintf("%s/%s → %s:%s/%s",
+	job := c.addJob(kind, fmt.Sprintf("%s/%s → %s:%s/%s",
 		*srcBucket.Key, srcPrefix, dstProfileName, dstBucketName, dstPrefix), 0, 0, cancel)
 
 	progress := tview.NewModal().
-		SetText("Preparing cross-profile copy...\n").
+		SetText(fmt.Sprintf("Preparing %s...\n", strings.ToLower(title))).
 		AddButtons([]string{"Background", "Cancel"}).
 		SetDoneFunc(func(_ int, buttonLabel string) {
 			switch buttonLabel {
@@ -202,9 +273,11 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 	c.view.Pages.AddPage("progress", progress, true, true)
 
 	go func() {
-		// The destination bucket's region matters only on AWS; RefreshClient
-		// is fail-safe and keeps the client usable on error.
-		if err := dst.RefreshClient(&name); err != nil {
+		// Write through the client for the destination bucket's region. dst
+		// itself is left alone — the other pane may be browsing through it —
+		// and on a failed lookup ForBucket hands it back unchanged.
+		dst, err := dst.ForBucket(name)
+		if err != nil {
 			c.error("Failed to resolve destination region", err)
 		}
 
@@ -221,7 +294,12 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 				total += it.size
 				continue
 			}
-			objs, err := src.ListObjects(it.srcKey, srcBucket)
+			objs, err := src.ListObjects(ctx, it.srcKey, srcBucket)
+			if ctx.Err() != nil {
+				// Cancel already closed the modal; stop paging and bow out.
+				c.finalizeJob(job, true, 0)
+				return
+			}
 			if err != nil {
 				c.finalizeJob(job, false, 1)
 				c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
@@ -243,7 +321,7 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 		if len(ops) == 0 {
 			c.finalizeJob(job, false, 0)
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
-			c.error("Copy to profile", fmt.Errorf("nothing to copy"))
+			c.error(title, fmt.Errorf("nothing to copy"))
 			return
 		}
 
@@ -259,11 +337,11 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 		if cErr != nil {
 			c.finalizeJob(job, false, 1)
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
-			c.error("Copy to profile", fmt.Errorf("checking the destination: %w", cErr))
+			c.error(title, fmt.Errorf("checking the destination: %w", cErr))
 			return
 		}
 		if len(conflicts) > 0 {
-			skip, proceed := c.askOverwriteBlocking("Copy to profile", conflicts, len(dstKeys))
+			skip, proceed := c.askOverwriteBlocking(title, conflicts, len(dstKeys))
 			if !proceed {
 				cancel()
 				c.finalizeJob(job, true, 0)
@@ -289,6 +367,11 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 			return
 		}
 		job.setTotals(total, len(ops))
+		job.setRetry(func(keys []string) {
+			if again := retryCrossItems(ops, keys); len(again) > 0 {
+				c.runCrossCopy(again, src, srcBucket, srcPrefix, dst, dstProfileName, dstBucketName, dstPrefix, move, nil)
+			}
+		})
 
 		job.setStatus(jobQueued)
 		select {
@@ -329,8 +412,8 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 			}
 			c.view.App.QueueUpdateDraw(func() {
 				progress.SetText(fmt.Sprintf(
-					"Copying to %s\n%d/%d object(s)\n%s/%s (%.1f%%)\n%s\n%s",
-					dstProfileName, i, len(ops),
+					"%s to %s\n%d/%d object(s)\n%s/%s (%.1f%%)\n%s\n%s",
+					title, dstProfileName, i, len(ops),
 					humanize.IBytes(uint64(n)), humanize.IBytes(uint64(total)), pct,
 					byteRateETA(n, total, time.Since(start)),
 					op.SrcKey,
@@ -348,8 +431,13 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 			}
 
 			draw(i, op, 0)
-			err := model.CrossCopy(ctx, src, srcBucket, op.SrcKey, dst, dstBucket, op.DstKey,
+			transfer := model.CrossCopy
+			if move {
+				transfer = model.CrossMove
+			}
+			err := transfer(ctx, src, srcBucket, op.SrcKey, dst, dstBucket, op.DstKey,
 				func(written, _ int64) { draw(i, op, written) })
+			job.recordErr(op.SrcKey, err)
 			if err != nil {
 				failed = append(failed, fmt.Sprintf("%s: %v", op.SrcKey, err))
 				continue
@@ -361,9 +449,16 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 			job.setProgress(doneBytes, i+1)
 		}
 
-		c.logActivity("Cross-profile copy → %s: %d ok, %d failed (%s)",
-			dstProfileName, okCount, len(failed), humanize.IBytes(uint64(doneBytes)))
+		c.logActivity("%s → %s: %d ok, %d failed (%s)",
+			title, dstProfileName, okCount, len(failed), humanize.IBytes(uint64(doneBytes)))
 		c.finalizeJob(job, canceled, len(failed))
+		if move {
+			if okCount > 0 && afterMove != nil {
+				c.view.App.QueueUpdateDraw(afterMove)
+			}
+			// The moved objects are gone from the listing they were marked in.
+			go c.updateList()
+		}
 
 		if canceled || job.isBackgrounded() {
 			return
@@ -376,11 +471,11 @@ func (c *Controller) runCrossCopy(items []copyMoveItem, src *model.Model, srcBuc
 			if job.isBackgrounded() || !c.view.Pages.HasPage("progress") {
 				return
 			}
-			status := "Cross-profile copy complete."
+			status := title + " complete."
 			if len(failed) > 0 {
-				status = "Cross-profile copy finished with errors."
+				status = title + " finished with errors."
 			}
-			msg := fmt.Sprintf("%s\n\nCopied: %d\nFailed: %d\nTransferred: %s",
+			msg := fmt.Sprintf("%s\n\nObjects: %d\nFailed: %d\nTransferred: %s",
 				status, okCount, len(failed), humanize.IBytes(uint64(doneBytes)))
 			for i, f := range failed {
 				if i == 8 {
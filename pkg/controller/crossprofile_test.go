@@ -1,6 +1,9 @@
 package controller
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestCrossDstKey(t *testing.T) {
 	cases := []struct {
@@ -24,3 +27,29 @@ func TestCrossDstKey(t *testing.T) {
 		}
 	}
 }
+
+func TestCrossOperationLabelsMatchConstants(t *testing.T) {
+	// The dropdown index IS the operation, so the two must stay in lockstep.
+	labels := crossOperationLabels()
+	if len(labels) != 3 {
+		t.Fatalf("got %d labels, want 3", len(labels))
+	}
+	for op, want := range map[crossOperation]string{
+		crossCopyOp: "Copy",
+		crossMoveOp: "Move",
+		crossSyncOp: "Sync",
+	} {
+		if !strings.HasPrefix(labels[int(op)], want) {
+			t.Errorf("labels[%d] = %q, want it to start with %q", int(op), labels[int(op)], want)
+		}
+	}
+}
+
+func TestCrossTitle(t *testing.T) {
+	if kind, title := crossTitle(false); kind != "xcopy" || title != "Cross-profile copy" {
+		t.Errorf("copy: got %q / %q", kind, title)
+	}
+	if kind, title := crossTitle(true); kind != "xmove" || title != "Cross-profile move" {
+		t.Errorf("move: got %q / %q", kind, title)
+	}
+}
@@ -0,0 +1,633 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// diffMaxEdits caps the edit distance the line diff searches for a minimal
+// script. Its memory grows with the square of the distance, and past a few
+// thousand changed lines nobody reads the diff line by line anyway: beyond the
+// cap the changed middle is shown as replaced wholesale.
+const diffMaxEdits = 2000
+
+// diffContext is how many unchanged lines a hunk keeps around its changes.
+const diffContext = 3
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffLine is one line of a diff. A and B are its 1-based line numbers on
+// each side; on the side a line is missing from, the number is that of the
+// line it comes after (0 at the top), as unified hunk headers count.
+type diffLine struct {
+	Op   diffOp
+	A, B int
+	Text string
+}
+
+// splitLines splits text into lines without their "\n". A final newline ends
+// the last line rather than starting an empty one.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// diffLines is the line diff turning a into b. Common head and tail lines
+// are matched first, then the rest with Myers' algorithm. minimal is false
+// when the middle needed more than diffMaxEdits edits and was reported as
+// removed and re-added in full.
+func diffLines(a, b []string) (lines []diffLine, minimal bool) {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	midA, midB := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	ops, minimal := myers(midA, midB, diffMaxEdits)
+	if !minimal {
+		ops = ops[:0]
+		for range midA {
+			ops = append(ops, diffDelete)
+		}
+		for range midB {
+			ops = append(ops, diffInsert)
+		}
+	}
+
+	all := make([]diffOp, 0, pre+len(ops)+suf)
+	for i := 0; i < pre; i++ {
+		all = append(all, diffEqual)
+	}
+	all = append(all, ops...)
+	for i := 0; i < suf; i++ {
+		all = append(all, diffEqual)
+	}
+
+	lines = make([]diffLine, 0, len(all))
+	i, j := 0, 0
+	for _, op := range all {
+		switch op {
+		case diffEqual:
+			i++
+			j++
+			lines = append(lines, diffLine{Op: op, A: i, B: j, Text: a[i-1]})
+		case diffDelete:
+			i++
+			lines = append(lines, diffLine{Op: op, A: i, B: j, Text: a[i-1]})
+		case diffInsert:
+			j++
+			lines = append(lines, diffLine{Op: op, A: i, B: j, Text: b[j-1]})
+		}
+	}
+	return lines, minimal
+}
+
+// myers finds a shortest edit script from a to b of at most maxD edits, in
+// order; ok is false when there is none that short. It keeps the frontier of
+// every round to walk the path back, so memory is O(D²).
+func myers(a, b []string, maxD int) (ops []diffOp, ok bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxD)
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+				return myersPath(trace, a, b), true
+			}
+		}
+		trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+	}
+	return nil, false
+}
+
+// myersPath walks the frontiers myers kept back from the end of both
+// sequences to the start. trace[d][k+d] is the furthest x reached on
+// diagonal k after d edits.
+func myersPath(trace [][]int, a, b []string) []diffOp {
+	x, y := len(a), len(b)
+	var rev []diffOp
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		var pk int
+		if k == -d || k != d && prev[k-1+d-1] < prev[k+1+d-1] {
+			pk = k + 1
+		} else {
+			pk = k - 1
+		}
+		px := prev[pk+d-1]
+		py := px - pk
+		// The edit leads from (px, py) to (sx, sy); the snake after it runs
+		// diagonally on to (x, y).
+		sx, sy := px+1, py
+		if pk == k+1 {
+			sx, sy = px, py+1
+		}
+		for x > sx && y > sy {
+			rev = append(rev, diffEqual)
+			x--
+			y--
+		}
+		if pk == k+1 {
+			rev = append(rev, diffInsert)
+		} else {
+			rev = append(rev, diffDelete)
+		}
+		x, y = px, py
+	}
+	for ; x > 0; x-- {
+		rev = append(rev, diffEqual)
+	}
+	ops := make([]diffOp, len(rev))
+	for i, op := range rev {
+		ops[len(rev)-1-i] = op
+	}
+	return ops
+}
+
+// diffHunk is a run of changes with up to context unchanged lines around
+// it, as a unified diff prints under one "@@" header.
+type diffHunk struct {
+	AStart, ALen int
+	BStart, BLen int
+	Lines        []diffLine
+}
+
+// diffHunks groups the changes in lines into hunks keeping context
+// unchanged lines on either side; changes closer than twice that share a
+// hunk.
+func diffHunks(lines []diffLine, context int) []diffHunk {
+	var out []diffHunk
+	for i := 0; i < len(lines); {
+		if lines[i].Op == diffEqual {
+			i++
+			continue
+		}
+		start := max(0, i-context)
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Op != diffEqual {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		stop := min(len(lines), end+context)
+		out = append(out, newDiffHunk(lines[start:stop]))
+		i = stop
+	}
+	return out
+}
+
+func newDiffHunk(lines []diffLine) diffHunk {
+	h := diffHunk{Lines: lines, AStart: -1, BStart: -1}
+	for _, l := range lines {
+		if l.Op != diffInsert {
+			h.ALen++
+			if h.AStart < 0 {
+				h.AStart = l.A
+			}
+		}
+		if l.Op != diffDelete {
+			h.BLen++
+			if h.BStart < 0 {
+				h.BStart = l.B
+			}
+		}
+	}
+	// A side with no lines in the hunk is numbered by the line the change
+	// follows there.
+	if h.AStart < 0 {
+		h.AStart = lines[0].A
+	}
+	if h.BStart < 0 {
+		h.BStart = lines[0].B
+	}
+	return h
+}
+
+// diffStat counts the lines removed from a and added in b.
+func diffStat(lines []diffLine) (removed, added int) {
+	for _, l := range lines {
+		switch l.Op {
+		case diffDelete:
+			removed++
+		case diffInsert:
+			added++
+		}
+	}
+	return removed, added
+}
+
+// diffShown is a line's text as the viewer draws it: tabs expanded and a
+// CRLF file's carriage returns dropped, so neither upsets the columns.
+func diffShown(s string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(s, "\r"), "\t", "    ")
+}
+
+// renderUnified draws hunks as a coloured unified diff and returns, with the
+// text, the row each hunk's header is on.
+func renderUnified(hunks []diffHunk) (string, []int) {
+	var sb strings.Builder
+	rows := make([]int, 0, len(hunks))
+	row := 0
+	for _, h := range hunks {
+		rows = append(rows, row)
+		fmt.Fprintf(&sb, "[aqua]@@ -%d,%d +%d,%d @@[-]\n", h.AStart, h.ALen, h.BStart, h.BLen)
+		row++
+		for _, l := range h.Lines {
+			text := tview.Escape(diffShown(l.Text))
+			switch l.Op {
+			case diffDelete:
+				fmt.Fprintf(&sb, "[red]-%s[-]\n", text)
+			case diffInsert:
+				fmt.Fprintf(&sb, "[green]+%s[-]\n", text)
+			default:
+				fmt.Fprintf(&sb, " %s\n", text)
+			}
+			row++
+		}
+	}
+	return sb.String(), rows
+}
+
+// renderSideBySide draws hunks in two columns fitting width cells, a on the
+// left and b on the right, each line numbered. Removed and added lines of a
+// change face each other; longer lines are cut. It returns the hunk rows as
+// renderUnified does.
+func renderSideBySide(hunks []diffHunk, width int) (string, []int) {
+	col := max(12, (width-3)/2)
+	textW := col - 6
+	cell := func(no int, text, color string) string {
+		if no == 0 {
+			return strings.Repeat(" ", col)
+		}
+		s := fmt.Sprintf("%5d ", no) + padDisplay(tview.Escape(truncateDisplay(diffShown(text), textW)), textW)
+		if color != "" {
+			s = color + s + "[-]"
+		}
+		return s
+	}
+
+	var sb strings.Builder
+	rows := make([]int, 0, len(hunks))
+	row := 0
+	emit := func(left, right string) {
+		sb.WriteString(left + " [gray]│[-] " + right + "\n")
+		row++
+	}
+	for _, h := range hunks {
+		rows = append(rows, row)
+		head := fmt.Sprintf("[aqua]@@ -%d,%d +%d,%d @@[-]", h.AStart, h.ALen, h.BStart, h.BLen)
+		sb.WriteString(head + "\n")
+		row++
+		for i := 0; i < len(h.Lines); {
+			l := h.Lines[i]
+			if l.Op == diffEqual {
+				emit(cell(l.A, l.Text, ""), cell(l.B, l.Text, ""))
+				i++
+				continue
+			}
+			var del, ins []diffLine
+			for ; i < len(h.Lines) && h.Lines[i].Op == diffDelete; i++ {
+				del = append(del, h.Lines[i])
+			}
+			for ; i < len(h.Lines) && h.Lines[i].Op == diffInsert; i++ {
+				ins = append(ins, h.Lines[i])
+			}
+			for j := 0; j < max(len(del), len(ins)); j++ {
+				left, right := cell(0, "", ""), cell(0, "", "")
+				if j < len(del) {
+					left = cell(del[j].A, del[j].Text, "[red]")
+				}
+				if j < len(ins) {
+					right = cell(ins[j].B, ins[j].Text, "[green]")
+				}
+				emit(left, right)
+			}
+		}
+	}
+	return sb.String(), rows
+}
+
+// diffSide is one side of a diff: what to call it and how to fetch it.
+type diffSide struct {
+	label string
+	load  func(ctx context.Context) ([]byte, error)
+}
+
+// diffRead reads key, or one version of it, for a diff: its body alone, in
+// one ranged GET no longer than editMaxSize, refused if the object is longer
+// than that.
+func diffRead(ctx context.Context, mdl *model.Model, bucket *model.Object, key, versionID string) ([]byte, error) {
+	var r model.ObjectRange
+	var err error
+	if versionID != "" {
+		r, err = mdl.ReadVersionRange(ctx, bucket, key, versionID, 0, editMaxSize)
+	} else {
+		r, err = mdl.ReadRange(ctx, bucket, key, 0, editMaxSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.Total > editMaxSize {
+		return nil, fmt.Errorf("%s is %s; the diff cap is %s",
+			key, humanize.IBytes(uint64(r.Total)), humanize.IBytes(editMaxSize))
+	}
+	return r.Data, nil
+}
+
+// objectDiffSide is key in bucket, through mdl.
+func objectDiffSide(mdl *model.Model, bucket *model.Object, key string) diffSide {
+	return diffSide{
+		label: "s3://" + *bucket.Key + "/" + key,
+		load: func(ctx context.Context) ([]byte, error) {
+			return diffRead(ctx, mdl, bucket, key, "")
+		},
+	}
+}
+
+// versionDiffSide is one version of key.
+func versionDiffSide(mdl *model.Model, bucket *model.Object, key string, v model.ObjectVersion) diffSide {
+	when := ""
+	if v.LastModified != nil {
+		when = " " + v.LastModified.Format("2006-01-02 15:04")
+	}
+	return diffSide{
+		label: fmt.Sprintf("%s@%s%s", path.Base(key), shortVersion(v.VersionID), when),
+		load: func(ctx context.Context) ([]byte, error) {
+			return diffRead(ctx, mdl, bucket, key, v.VersionID)
+		},
+	}
+}
+
+// shortVersion cuts a version id to something a title has room for.
+func shortVersion(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// localDiffSide is a local file, held to the same size cap as an object.
+func localDiffSide(file string) diffSide {
+	return diffSide{
+		label: file,
+		load: func(context.Context) ([]byte, error) {
+			st, err := os.Stat(file)
+			if err != nil {
+				return nil, err
+			}
+			if !st.Mode().IsRegular() {
+				return nil, fmt.Errorf("%s is not a regular file", file)
+			}
+			if st.Size() > editMaxSize {
+				return nil, fmt.Errorf("%s is %s, over the %s diff limit",
+					file, humanize.IBytes(uint64(st.Size())), humanize.IBytes(editMaxSize))
+			}
+			return os.ReadFile(file)
+		},
+	}
+}
+
+// ShowDiff fetches both sides behind a cancellable modal and opens the diff
+// viewer on them. Like the editor it holds both in memory, so each is capped
+// at editMaxSize, and a side that looks binary is refused rather than
+// diffed as lines. UI goroutine.
+func (c *Controller) ShowDiff(a, b diffSide) {
+	_, ctx, cancel := c.scanModal("progress", "Fetching both sides...")
+	go func() {
+		defer cancel()
+		var data [2][]byte
+		var err error
+		for i, s := range []diffSide{a, b} {
+			data[i], err = s.load(ctx)
+			if err == nil && isProbablyBinary(data[i]) {
+				err = errors.New("it looks like a binary file")
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %w", s.label, err)
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
+		if err != nil {
+			c.error("Diff", err)
+			return
+		}
+		lines, minimal := diffLines(splitLines(data[0]), splitLines(data[1]))
+		c.view.App.QueueUpdateDraw(func() { c.presentDiff(a.label, b.label, lines, minimal) })
+	}()
+}
+
+// presentDiff shows a diff as a scrollable, coloured view that switches
+// between unified and side-by-side and jumps from hunk to hunk. UI
+// goroutine.
+func (c *Controller) presentDiff(aLabel, bLabel string, lines []diffLine, minimal bool) {
+	hunks := diffHunks(lines, diffContext)
+	removed, added := diffStat(lines)
+
+	tv := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(false)
+	tv.SetBorder(true)
+	title := fmt.Sprintf(" %s ↔ %s  [red]-%d[-] [green]+%d[-] ", tview.Escape(aLabel), tview.Escape(bLabel), removed, added)
+	if !minimal {
+		title += "(not minimal) "
+	}
+	tv.SetTitle(title)
+
+	help := tview.NewTextView().SetDynamicColors(true).SetText(
+		"  [::b]n[::-]/[::b]N[::-] next/previous change   [::b]s[::-] side by side / unified   [::b]Esc[::-] close")
+
+	sideBySide := false
+	var rows []int
+	render := func() {
+		if len(hunks) == 0 {
+			tv.SetText("The two sides are identical.")
+			return
+		}
+		var text string
+		if sideBySide {
+			_, _, w, _ := tv.GetInnerRect()
+			text, rows = renderSideBySide(hunks, w)
+		} else {
+			text, rows = renderUnified(hunks)
+		}
+		tv.SetText(text).ScrollToBeginning()
+	}
+	jump := func(forward bool) {
+		at, _ := tv.GetScrollOffset()
+		target := -1
+		for _, r := range rows {
+			if forward && r > at {
+				target = r
+				break
+			}
+			if !forward && r < at {
+				target = r
+			}
+		}
+		if target >= 0 {
+			tv.ScrollTo(target, 0)
+		}
+	}
+	closeDiff := func() {
+		c.view.Pages.RemovePage("modal-diff")
+	}
+
+	tv.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			closeDiff()
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q':
+				closeDiff()
+				return nil
+			case 'n':
+				jump(true)
+				return nil
+			case 'N':
+				jump(false)
+				return nil
+			case 's':
+				sideBySide = !sideBySide
+				render()
+				return nil
+			}
+		}
+		return event
+	})
+
+	render()
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tv, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	c.view.Pages.AddPage("modal-diff", c.view.ModalClamped(flex, 160, 44), true, true)
+	c.view.App.SetFocus(tv)
+}
+
+// DiffWith asks what to diff the highlighted object against — an
+// s3://bucket/key or a local file — and opens the diff viewer on the pair.
+// It is what "=" does in single-pane mode, where there is no other pane to
+// compare with. UI goroutine.
+func (c *Controller) DiffWith() {
+	_, obj, ok := c.currentObject()
+	if !ok || obj.Ot != model.File || c.currentBucket == nil {
+		go c.error("Diff", fmt.Errorf("highlight a file to diff, or open a second pane (Ctrl+O) to compare folders"))
+		return
+	}
+	mdl, bucket, key := c.model, c.currentBucket, *obj.FullPath
+
+	form := c.view.NewInputForm("Diff "+*obj.Key+" with", "s3:// URI or local file", "")
+	form.AddButton("Diff", func() {
+		text := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		c.view.Pages.RemovePage("modal")
+		if text == "" {
+			return
+		}
+		other := localDiffSide(text)
+		if strings.HasPrefix(text, "s3://") {
+			otherBucket, otherKey, err := model.ParseS3URI(text)
+			if err != nil {
+				go c.error("Diff", err)
+				return
+			}
+			otherObj := &model.Object{Key: &otherBucket, Ot: model.Bucket}
+			other = objectDiffSide(mdl, otherObj, otherKey)
+			other.load = func(ctx context.Context) ([]byte, error) {
+				// Another bucket may live in another region.
+				m, err := mdl.Home().ForBucket(otherBucket)
+				if err != nil {
+					c.error("Failed to resolve bucket region", err)
+				}
+				return objectDiffSide(m, otherObj, otherKey).load(ctx)
+			}
+		}
+		c.ShowDiff(objectDiffSide(mdl, bucket, key), other)
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 80, 7), true, true)
+}
+
+// showComparison is showPlan for a pane comparison, with a "Diff a file"
+// button listing the files that differ: picking one opens the diff viewer on
+// its two copies through open. UI goroutine.
+func (c *Controller) showComparison(text string, differing []string, open func(rel string)) {
+	tv := tview.NewTextView().SetText(text).SetScrollable(true)
+	tv.SetBorder(true).SetTitle(" Pane comparison ")
+
+	buttons := tview.NewForm()
+	if len(differing) > 0 {
+		buttons.AddButton("Diff a file", func() { c.pickDiff(differing, open) })
+	}
+	buttons.AddButton("Close", func() { c.view.Pages.RemovePage("modal") })
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tv, 0, 1, false).
+		AddItem(buttons, 3, 0, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			c.view.Pages.RemovePage("modal")
+			return nil
+		}
+		return event
+	})
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(flex, 86, 28), true, true)
+}
+
+// pickDiff lists rels to diff; it stays open under the viewer, so one file
+// after another can be looked at.
+func (c *Controller) pickDiff(rels []string, open func(rel string)) {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(fmt.Sprintf(" %d differing file(s) ", len(rels)))
+	list.SetSelectedBackgroundColor(tcell.ColorBlue)
+	list.SetSelectedTextColor(tcell.ColorWhite)
+	for _, rel := range rels {
+		list.AddItem(tview.Escape(rel), "", 0, nil)
+	}
+	list.SetSelectedFunc(func(i int, _, _ string, _ rune) { open(rels[i]) })
+	list.SetDoneFunc(func() { c.view.Pages.RemovePage("modal-diffpick") })
+	c.view.Pages.AddPage("modal-diffpick", c.view.ModalClamped(list, 80, 20), true, true)
+	c.view.App.SetFocus(list)
+}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+// applyDiff rebuilds both sides from a diff, to check it describes them.
+func applyDiff(lines []diffLine) (a, b []string) {
+	for _, l := range lines {
+		if l.Op != diffInsert {
+			a = append(a, l.Text)
+		}
+		if l.Op != diffDelete {
+			b = append(b, l.Text)
+		}
+	}
+	return a, b
+}
+
+// lcsLen is the longest common subsequence's length, the slow way.
+func lcsLen(a, b []string) int {
+	t := make([][]int, len(a)+1)
+	for i := range t {
+		t[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				t[i][j] = t[i+1][j+1] + 1
+			} else {
+				t[i][j] = max(t[i+1][j], t[i][j+1])
+			}
+		}
+	}
+	return t[0][0]
+}
+
+func TestSplitLines(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a"}},
+		{"a\n\nb\n", []string{"a", "", "b"}},
+		{"a\r\nb", []string{"a\r", "b"}},
+	} {
+		if got := splitLines([]byte(tc.in)); fmt.Sprint(got) != fmt.Sprint(tc.want) || len(got) != len(tc.want) {
+			t.Errorf("splitLines(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	cases := []struct {
+		name           string
+		a, b           string
+		removed, added int
+	}{
+		{"identical", "a b c", "a b c", 0, 0},
+		{"both empty", "", "", 0, 0},
+		{"from nothing", "", "a b", 0, 2},
+		{"to nothing", "a b", "", 2, 0},
+		{"one line changed", "a b c d", "a x c d", 1, 1},
+		{"insert at the top", "b c", "a b c", 0, 1},
+		{"delete at the end", "a b c", "a b", 1, 0},
+		{"moved line", "a b c d e", "b c d a e", 1, 1},
+		{"classic", "a b c a b b a", "c b a b a c", 3, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := strings.Fields(tc.a), strings.Fields(tc.b)
+			lines, minimal := diffLines(a, b)
+			if !minimal {
+				t.Fatal("a small diff should be minimal")
+			}
+			gotA, gotB := applyDiff(lines)
+			if strings.Join(gotA, " ") != tc.a || strings.Join(gotB, " ") != tc.b {
+				t.Fatalf("diff rebuilds %q / %q", gotA, gotB)
+			}
+			if removed, added := diffStat(lines); removed != tc.removed || added != tc.added {
+				t.Errorf("-%d +%d, want -%d +%d", removed, added, tc.removed, tc.added)
+			}
+			for _, l := range lines {
+				if l.Op == diffEqual && (a[l.A-1] != l.Text || b[l.B-1] != l.Text) {
+					t.Errorf("line numbers %d/%d don't point at %q", l.A, l.B, l.Text)
+				}
+			}
+		})
+	}
+
+	t.Run("random inputs get a shortest script", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		gen := func() []string {
+			out := make([]string, rng.Intn(12))
+			for i := range out {
+				out[i] = string(rune('a' + rng.Intn(3)))
+			}
+			return out
+		}
+		for n := 0; n < 500; n++ {
+			a, b := gen(), gen()
+			lines, _ := diffLines(a, b)
+			gotA, gotB := applyDiff(lines)
+			if fmt.Sprint(gotA) != fmt.Sprint(a) || fmt.Sprint(gotB) != fmt.Sprint(b) {
+				t.Fatalf("%q → %q: diff rebuilds %q / %q", a, b, gotA, gotB)
+			}
+			removed, added := diffStat(lines)
+			if want := len(a) + len(b) - 2*lcsLen(a, b); removed+added != want {
+				t.Fatalf("%q → %q: %d edits, want %d", a, b, removed+added, want)
+			}
+		}
+	})
+
+	t.Run("past the edit cap the middle is replaced whole", func(t *testing.T) {
+		var a, b []string
+		a = append(a, "head")
+		b = append(b, "head")
+		for i := 0; i < diffMaxEdits; i++ {
+			a = append(a, fmt.Sprint("a", i))
+			b = append(b, fmt.Sprint("b", i))
+		}
+		a = append(a, "tail")
+		b = append(b, "tail")
+		lines, minimal := diffLines(a, b)
+		if minimal {
+			t.Fatal("expected a non-minimal diff")
+		}
+		gotA, gotB := applyDiff(lines)
+		if len(gotA) != len(a) || len(gotB) != len(b) || gotA[1] != "a0" || gotB[len(b)-2] != fmt.Sprint("b", diffMaxEdits-1) {
+			t.Fatal("the fallback diff doesn't rebuild both sides")
+		}
+		if lines[0].Op != diffEqual || lines[len(lines)-1].Op != diffEqual {
+			t.Error("the common head and tail should stay matched")
+		}
+	})
+}
+
+func TestDiffHunks(t *testing.T) {
+	var a []string
+	for i := 1; i <= 30; i++ {
+		a = append(a, fmt.Sprint(i))
+	}
+	b := append([]string(nil), a...)
+	b[1] = "two"                                              // line 2
+	b[6] = "seven"                                            // line 7: within 2×3 of line 2
+	b = append(b[:20], append([]string{"new"}, b[20:]...)...) // after line 20
+
+	lines, _ := diffLines(a, b)
+	hunks := diffHunks(lines, 3)
+	if len(hunks) != 2 {
+		t.Fatalf("%d hunks, want 2", len(hunks))
+	}
+	h := hunks[0]
+	if h.AStart != 1 || h.ALen != 10 || h.BStart != 1 || h.BLen != 10 {
+		t.Errorf("first hunk -%d,%d +%d,%d, want -1,10 +1,10", h.AStart, h.ALen, h.BStart, h.BLen)
+	}
+	h = hunks[1]
+	if h.AStart != 18 || h.ALen != 6 || h.BStart != 18 || h.BLen != 7 {
+		t.Errorf("second hunk -%d,%d +%d,%d, want -18,6 +18,7", h.AStart, h.ALen, h.BStart, h.BLen)
+	}
+
+	t.Run("a pure insertion is numbered after the line it follows", func(t *testing.T) {
+		lines, _ := diffLines([]string{"a"}, []string{"a", "b"})
+		h := diffHunks(lines, 0)
+		if len(h) != 1 || h[0].AStart != 1 || h[0].ALen != 0 || h[0].BStart != 2 || h[0].BLen != 1 {
+			t.Errorf("hunks = %+v", h)
+		}
+	})
+
+	t.Run("no changes, no hunks", func(t *testing.T) {
+		lines, _ := diffLines(a, a)
+		if h := diffHunks(lines, 3); len(h) != 0 {
+			t.Errorf("hunks = %+v", h)
+		}
+	})
+}
+
+func TestRenderDiff(t *testing.T) {
+	a := []string{"same", "old [red]", "tail"}
+	b := []string{"same", "new", "added", "tail"}
+	lines, _ := diffLines(a, b)
+	hunks := diffHunks(lines, 1)
+
+	t.Run("unified", func(t *testing.T) {
+		text, rows := renderUnified(hunks)
+		if len(rows) != 1 || rows[0] != 0 {
+			t.Errorf("rows = %v", rows)
+		}
+		for _, want := range []string{"@@ -1,3 +1,4 @@", "[red]-old [red[]", "[green]+new[-]", "[green]+added[-]", " same\n"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("side by side", func(t *testing.T) {
+		text, rows := renderSideBySide(hunks, 61)
+		if len(rows) != 1 {
+			t.Errorf("rows = %v", rows)
+		}
+		out := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+		// The header, then same, old|new, |added, tail.
+		if len(out) != 5 {
+			t.Fatalf("%d rows:\n%s", len(out), text)
+		}
+		width := tview.TaggedStringWidth(out[1])
+		for _, row := range out[1:] {
+			if w := tview.TaggedStringWidth(row); w != width || w > 61 {
+				t.Errorf("row %q is %d cells wide", row, w)
+			}
+		}
+		if !strings.Contains(out[2], "old") || !strings.Contains(out[2], "new") {
+			t.Errorf("the change should face itself: %q", out[2])
+		}
+		if !strings.Contains(out[3], "added") || strings.Contains(out[3], "[red]") {
+			t.Errorf("an addition has nothing opposite: %q", out[3])
+		}
+	})
+}
@@ -195,11 +195,16 @@ func (c *Controller) FindDuplicates() {
 	prefix := model.NormalizePrefix(c.currentPath)
 	mdl := c.model
 
-	scanning := tview.NewModal().SetText("Scanning for duplicates...")
-	c.view.Pages.AddPage("progress", scanning, true, true)
+	const text = "Scanning for duplicates..."
+	modal, ctx, cancel := c.scanModal("progress", text)
+	scanned := c.scanCounter(modal, text)
 
 	go func() {
-		objs, err := mdl.ListObjects(prefix, bucket)
+		defer cancel()
+		objs, err := mdl.ListObjectsSharded(ctx, prefix, bucket, scanned)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
 			c.error("Duplicate scan failed", err)
@@ -229,7 +234,11 @@ func (c *Controller) presentDupGroups(mdl *model.Model, bucket *model.Object, pr
 	}
 
 	list := tview.NewList().ShowSecondaryText(false)
-	list.SetBorder(true).SetTitle(fmt.Sprintf(" Duplicates under %s/%s — %s ", *bucket.Key, prefix, dupSummary(groups)))
+	title := fmt.Sprintf(" Duplicates under %s/%s — %s ", *bucket.Key, prefix, dupSummary(groups))
+	if note := inventoryNote(mdl, *bucket.Key); note != "" {
+		title = fmt.Sprintf("%s(%s) ", title, note)
+	}
+	list.SetBorder(true).SetTitle(title)
 	list.SetSelectedBackgroundColor(tcell.ColorBlue)
 	list.SetSelectedTextColor(tcell.ColorWhite)
 
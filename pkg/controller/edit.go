@@ -3,6 +3,7 @@ package controller
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/dustin/go-humanize"
+	"github.com/rivo/tview"
 
 	"github.com/nexusriot/s3duck-tui/pkg/model"
 )
@@ -61,6 +63,29 @@ func tempSuffix(key string) string {
 	return ""
 }
 
+// runEditor opens file in editor with the TUI suspended, and repaints once
+// the editor exits. Any goroutine but the UI one: it blocks while the editor
+// runs — Suspend takes the app's own locks.
+func (c *Controller) runEditor(editor string, args []string, file string) error {
+	var runErr error
+	suspended := c.view.App.Suspend(func() {
+		cmd := exec.Command(editor, append(args, file)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		runErr = cmd.Run()
+	})
+	// Repaint after the editor owned the screen, a preview image drawn with
+	// terminal graphics included.
+	c.view.App.QueueUpdateDraw(func() { c.graphics.gen++ })
+
+	if !suspended {
+		return fmt.Errorf("could not suspend the UI")
+	}
+	if runErr != nil {
+		return fmt.Errorf("%s failed: %w", editor, runErr)
+	}
+	return nil
+}
+
 // EditObject opens the highlighted object in $EDITOR: download to a temp file,
 // suspend the TUI while the editor owns the terminal, and re-upload on change
 // with the Content-Type and user metadata preserved. Guarded by a size cap and
@@ -124,23 +149,8 @@ func (c *Controller) EditObject() {
 			return
 		}
 
-		// Suspend hands the terminal to the editor and restores the TUI when it
-		// exits. Safe from this goroutine: Suspend takes the app's own locks.
-		var runErr error
-		suspended := c.view.App.Suspend(func() {
-			cmd := exec.Command(editor, append(editorArgs, tmpPath)...)
-			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
-			runErr = cmd.Run()
-		})
-		// Repaint after the editor owned the screen.
-		c.view.App.QueueUpdateDraw(func() {})
-
-		if !suspended {
-			c.error("Edit", fmt.Errorf("could not suspend the UI"))
-			return
-		}
-		if runErr != nil {
-			c.error(fmt.Sprintf("Edit: %s failed", editor), runErr)
+		if err := c.runEditor(editor, editorArgs, tmpPath); err != nil {
+			c.error("Edit", err)
 			return
 		}
 
@@ -179,3 +189,111 @@ func (c *Controller) EditObject() {
 		c.updateList()
 	}()
 }
+
+// NewObject asks for a name under the current folder, opens $EDITOR on an
+// empty file and PUTs what was written there to the new key, with the
+// Content-Type guessed from its extension. Nothing is created if the file is
+// left empty. An existing key is refused before the editor opens, and the
+// upload only creates: a key taken meanwhile is left alone and the text kept.
+func (c *Controller) NewObject() {
+	if c.currentBucket == nil {
+		return
+	}
+	editor, editorArgs, err := editorCommand(os.Getenv)
+	if err != nil {
+		go c.error("New object", err)
+		return
+	}
+	mdl, bucket := c.model, c.currentBucket
+	prefix := model.NormalizePrefix(c.currentPath)
+
+	form := c.view.NewInputForm("New object in /"+prefix, "Name", "")
+	form.AddButton("Create", func() {
+		name := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		c.view.Pages.RemovePage("modal")
+		if name == "" {
+			return
+		}
+		if err := validateEntryName(name); err != nil {
+			go c.error("New object", err)
+			return
+		}
+		go c.newObject(mdl, bucket, prefix+name, editor, editorArgs)
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 70, 7), true, true)
+}
+
+// newObject is NewObject's work once the key is chosen. Any goroutine but
+// the UI one.
+func (c *Controller) newObject(mdl *model.Model, bucket *model.Object, key, editor string, editorArgs []string) {
+	ctx := context.Background()
+	exists, err := mdl.ObjectExists(ctx, bucket, key)
+	if err != nil {
+		c.error("New object", fmt.Errorf("checking whether %s exists: %w", key, err))
+		return
+	}
+	if exists {
+		c.error("New object", fmt.Errorf("%s already exists; e edits it", key))
+		return
+	}
+
+	tmp, err := os.CreateTemp("", "s3duck-new-*"+tempSuffix(key))
+	if err != nil {
+		c.error("New object", err)
+		return
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	// As for EditObject: the file goes unless the upload failed or was
+	// refused, when it's all there is of what was written.
+	keepTemp := false
+	defer func() {
+		if !keepTemp {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := c.runEditor(editor, editorArgs, tmpPath); err != nil {
+		c.error("New object", err)
+		return
+	}
+	data, err := os.ReadFile(tmpPath)
+	if err != nil {
+		c.error("New object: reading temp file back", err)
+		return
+	}
+	if len(data) == 0 {
+		c.success("Nothing written; no object created")
+		return
+	}
+	// The editor may have been open for minutes. CreateBytes refuses a key
+	// taken since; looking first as well covers backends that ignore its
+	// condition.
+	exists, err = mdl.ObjectExists(ctx, bucket, key)
+	if err != nil {
+		keepTemp = true
+		c.error("New object: not saved", fmt.Errorf(
+			"checking whether %s exists: %v\nyour text is kept at %s", key, err, tmpPath))
+		return
+	}
+	if !exists {
+		contentType := model.GuessContentType(key)
+		err = mdl.CreateBytes(ctx, bucket, key, data, model.ObjectContent{ContentType: contentType})
+	}
+	if exists || errors.Is(err, model.ErrObjectExists) {
+		keepTemp = true
+		c.error("New object: not saved", fmt.Errorf(
+			"%s was created by someone else while you were editing; your text is kept at %s", key, tmpPath))
+		return
+	}
+	if err != nil {
+		keepTemp = true
+		c.error("New object: upload failed", fmt.Errorf("%v\nyour text is kept at %s", err, tmpPath))
+		return
+	}
+	c.logActivity("Created in %s: %s (%s)", editor, key, humanize.IBytes(uint64(len(data))))
+	c.success(fmt.Sprintf("Created %s (%s)", key, humanize.IBytes(uint64(len(data)))))
+	c.setRestore(key)
+	c.updateList()
+}
@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// followInterval is how often a follow polls: one HEAD each time, plus a
+// listing in prefix mode.
+const followInterval = 2 * time.Second
+
+// followMaxLines is how many lines the follow view keeps; older ones scroll
+// away for good, as in a terminal.
+const followMaxLines = 5000
+
+// followLine is a line of the follow view: one from the object, or a note
+// of the view's own ("replaced", "now following").
+type followLine struct {
+	text string
+	note bool
+}
+
+// followLines splits data, coming after the unfinished line pending, into
+// the lines it completes and what is left of a line still being written.
+func followLines(pending string, data []byte) (lines []string, rest string) {
+	s := pending + string(data)
+	for {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			return lines, s
+		}
+		lines = append(lines, s[:i])
+		s = s[i+1:]
+	}
+}
+
+// followRow renders a line for the follow view, escaped, with the matches
+// of re, if set, highlighted.
+func followRow(l followLine, re *regexp.Regexp) string {
+	text := strings.ReplaceAll(strings.TrimSuffix(l.text, "\r"), "\t", "    ")
+	if l.note {
+		return "[gray::b]── " + tview.Escape(text) + " ──[-::-]"
+	}
+	if re == nil {
+		return tview.Escape(text)
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringIndex(text, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		sb.WriteString(tview.Escape(text[last:m[0]]))
+		sb.WriteString("[black:yellow]" + tview.Escape(text[m[0]:m[1]]) + "[-:-]")
+		last = m[1]
+	}
+	sb.WriteString(tview.Escape(text[last:]))
+	return sb.String()
+}
+
+// Follow opens a tail -f view of the highlighted object or, on a folder or
+// "..", of the newest object under it — for logs rewritten in place or
+// rolled over to new keys. It polls until closed.
+func (c *Controller) Follow() {
+	if c.currentBucket == nil {
+		return
+	}
+	target, prefix := c.currentPath, true
+	if _, obj, ok := c.currentObject(); ok {
+		target, prefix = *obj.FullPath, obj.Ot == model.Folder
+	}
+	follower := c.model.NewFollower(c.currentBucket, target, prefix)
+	what := "s3://" + *c.currentBucket.Key + "/" + target
+	if prefix {
+		what = "newest under s3://" + *c.currentBucket.Key + "/" + model.NormalizePrefix(target)
+	}
+
+	tv := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetWrap(false)
+	tv.SetBorder(true).SetTitle(" Follow " + tview.Escape(what) + " ")
+	status := tview.NewTextView().SetDynamicColors(true)
+	help := tview.NewTextView().SetDynamicColors(true).SetText(
+		"  [::b]Space[::-] pause / resume scrolling   [::b]/[::-] highlight regexp   [::b]Esc[::-] close")
+
+	var (
+		lines   []followLine
+		pending string
+		key     string
+		re      *regexp.Regexp
+		paused  bool
+		last    model.FollowUpdate
+		lastErr error
+		checked time.Time
+	)
+	render := func() {
+		rows := make([]string, len(lines))
+		for i, l := range lines {
+			rows[i] = followRow(l, re)
+		}
+		text := strings.Join(rows, "\n")
+		if pending != "" {
+			text += "\n" + followRow(followLine{text: pending}, re)
+		}
+		tv.SetText(text)
+		if !paused {
+			tv.ScrollToEnd()
+		}
+	}
+	showStatus := func() {
+		s := "  waiting for an object"
+		if key != "" {
+			s = fmt.Sprintf("  %s  %s", tview.Escape(key), humanize.IBytes(uint64(last.Size)))
+		}
+		if !checked.IsZero() {
+			s += "  checked " + checked.Format("15:04:05")
+		}
+		if paused {
+			s += "  [yellow::b]PAUSED[-::-]"
+		}
+		if re != nil {
+			s += "  highlight /" + tview.Escape(re.String()) + "/"
+		}
+		if lastErr != nil {
+			s += "  [red]" + tview.Escape(lastErr.Error()) + "[-]"
+		}
+		status.SetText(s)
+	}
+	add := func(ls ...followLine) { lines = append(lines, ls...) }
+	apply := func(up model.FollowUpdate, err error) {
+		checked, lastErr = time.Now(), err
+		if err == nil {
+			last = up
+		}
+		if err != nil || !up.Changed {
+			showStatus()
+			return
+		}
+		data := up.Data
+		if up.Reset {
+			if pending != "" {
+				add(followLine{text: pending})
+				pending = ""
+			}
+			switch {
+			case key != "" && up.Key != key:
+				add(followLine{text: "now following " + up.Key, note: true})
+			case key != "":
+				add(followLine{text: up.Key + " was replaced", note: true})
+			}
+		}
+		if up.Skipped > 0 {
+			add(followLine{text: humanize.IBytes(uint64(up.Skipped)) + " skipped", note: true})
+			// Start on a whole line.
+			if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+				data = data[i+1:]
+			}
+			pending = ""
+		}
+		key = up.Key
+		done, rest := followLines(pending, data)
+		pending = rest
+		for _, l := range done {
+			add(followLine{text: l})
+		}
+		if n := len(lines) - followMaxLines; n > 0 {
+			lines = append(lines[:0], lines[n:]...)
+		}
+		render()
+		showStatus()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		t := time.NewTicker(followInterval)
+		defer t.Stop()
+		for {
+			up, err := follower.Poll(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			c.view.App.QueueUpdateDraw(func() { apply(up, err) })
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+
+	closeFollow := func() {
+		cancel()
+		c.view.Pages.RemovePage("modal-follow")
+		c.view.App.SetFocus(c.view.List)
+	}
+	tv.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			closeFollow()
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q':
+				closeFollow()
+				return nil
+			case ' ':
+				paused = !paused
+				if paused {
+					// Stop tracking the end where the view is now.
+					tv.ScrollTo(tv.GetScrollOffset())
+				} else {
+					tv.ScrollToEnd()
+				}
+				showStatus()
+				return nil
+			case '/':
+				expr := ""
+				if re != nil {
+					expr = re.String()
+				}
+				c.askFollowHighlight(expr, func(r *regexp.Regexp) {
+					re = r
+					render()
+					showStatus()
+					c.view.App.SetFocus(tv)
+				})
+				return nil
+			}
+		}
+		return event
+	})
+
+	showStatus()
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tv, 0, 1, true).
+		AddItem(status, 1, 0, false).
+		AddItem(help, 1, 0, false)
+	c.view.Pages.AddPage("modal-follow", c.view.ModalClamped(flex, 160, 44), true, true)
+	c.view.App.SetFocus(tv)
+}
+
+// askFollowHighlight asks for the regexp the follow view highlights, starting
+// from expr; an empty one turns highlighting off. UI goroutine.
+func (c *Controller) askFollowHighlight(expr string, set func(*regexp.Regexp)) {
+	form := c.view.NewInputForm("Highlight", "Regexp", expr)
+	form.AddButton("Apply", func() {
+		text := form.GetFormItem(0).(*tview.InputField).GetText()
+		c.view.Pages.RemovePage("modal")
+		if text == "" {
+			set(nil)
+			return
+		}
+		re, err := regexp.Compile(text)
+		if err != nil {
+			go c.error("Highlight", err)
+			return
+		}
+		set(re)
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 70, 7), true, true)
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFollowLines(t *testing.T) {
+	for _, tc := range []struct {
+		name, pending, data string
+		want                []string
+		rest                string
+	}{
+		{"whole lines", "", "a\nb\n", []string{"a", "b"}, ""},
+		{"a line still being written", "", "a\nb", []string{"a"}, "b"},
+		{"finishing the pending line", "par", "tial\nnext", []string{"partial"}, "next"},
+		{"nothing new", "x", "", nil, "x"},
+		{"an empty line", "", "\n", []string{""}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lines, rest := followLines(tc.pending, []byte(tc.data))
+			if len(lines) != len(tc.want) || rest != tc.rest {
+				t.Fatalf("followLines = %q, %q; want %q, %q", lines, rest, tc.want, tc.rest)
+			}
+			for i := range lines {
+				if lines[i] != tc.want[i] {
+					t.Errorf("line %d = %q, want %q", i, lines[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFollowRow(t *testing.T) {
+	re := regexp.MustCompile(`ERR\w*`)
+	for _, tc := range []struct {
+		name string
+		line followLine
+		re   *regexp.Regexp
+		want string
+	}{
+		{"plain", followLine{text: "ok [red]\r"}, nil, "ok [red[]"},
+		{"tabs expand", followLine{text: "a\tb"}, nil, "a    b"},
+		{"matches highlighted", followLine{text: "1 ERROR 2 ERR"}, re, "1 [black:yellow]ERROR[-:-] 2 [black:yellow]ERR[-:-]"},
+		{"no match", followLine{text: "fine"}, re, "fine"},
+		{"empty matches are not highlighted", followLine{text: "ab"}, regexp.MustCompile(`x*`), "ab"},
+		{"a note stands out and is never highlighted", followLine{text: "ERR was replaced", note: true}, re, "[gray::b]── ERR was replaced ──[-::-]"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := followRow(tc.line, tc.re); got != tc.want {
+				t.Errorf("followRow = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
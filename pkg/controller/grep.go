@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/dustin/go-humanize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+const (
+	// grepDefaultMaxSize is the size cap the content-search form starts with:
+	// objects over it are not fetched at all.
+	grepDefaultMaxSize = "10MiB"
+	// grepInflate bounds how far a gzip'd object may expand while it is
+	// searched, as a multiple of the size cap, so a small object that unpacks
+	// to gigabytes is read no further than a plain one would be.
+	grepInflate = 16
+	// grepMaxLine is the longest line searched; an object with a longer one is
+	// searched up to it and no further — minified JSON or a CSV without
+	// newlines is more data than text.
+	grepMaxLine = 1 << 20
+	// grepPreviewLen is how much of a matching line a result shows.
+	grepPreviewLen = 120
+)
+
+// grepCandidates picks the objects a content search reads: real objects
+// matching filter (a searchQuery; nil = all) no larger than maxSize. It also
+// returns how many matched the filter but were over the cap.
+func grepCandidates(objs []s3t.Object, filter *searchQuery, maxSize int64) (keys []string, oversize int) {
+	for _, o := range objs {
+		if o.Key == nil || *o.Key == "" || strings.HasSuffix(*o.Key, "/") {
+			continue
+		}
+		if filter != nil && !filter.match(targetOfObject(o)) {
+			continue
+		}
+		if o.Size > maxSize {
+			oversize++
+			continue
+		}
+		keys = append(keys, *o.Key)
+	}
+	return keys, oversize
+}
+
+// grepPreview trims a matching line for display: leading blanks go, and a long
+// line is cut around the first match so the part that matched is what shows.
+func grepPreview(line string, re *regexp.Regexp) string {
+	line = strings.TrimLeft(line, " \t")
+	if len(line) <= grepPreviewLen {
+		return line
+	}
+	start := 0
+	if loc := re.FindStringIndex(line); loc != nil && loc[1] > grepPreviewLen {
+		start = max(0, loc[0]-grepPreviewLen/4)
+	}
+	end := min(len(line), start+grepPreviewLen)
+	// Don't cut a UTF-8 sequence in half.
+	for start > 0 && start < len(line) && line[start]&0xC0 == 0x80 {
+		start--
+	}
+	for end < len(line) && line[end]&0xC0 == 0x80 {
+		end++
+	}
+	out := line[start:end]
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(line) {
+		out += "…"
+	}
+	return out
+}
+
+// grepReader searches r line by line, calling hit with the 1-based number and
+// text of each line re matches until it returns false. A body that looks
+// binary (isProbablyBinary on its head) is not searched; binary reports that.
+func grepReader(r io.Reader, re *regexp.Regexp, hit func(line int, text string) bool) (binary bool, err error) {
+	br := bufio.NewReaderSize(r, binarySniffLen)
+	head, err := br.Peek(binarySniffLen)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return false, err
+	}
+	if isProbablyBinary(head) {
+		return true, nil
+	}
+	sc := bufio.NewScanner(br)
+	sc.Buffer(make([]byte, 0, 64*1024), grepMaxLine)
+	for n := 1; sc.Scan(); n++ {
+		if re.Match(sc.Bytes()) && !hit(n, strings.TrimRight(sc.Text(), "\r")) {
+			return false, nil
+		}
+	}
+	if errors.Is(sc.Err(), bufio.ErrTooLong) {
+		return false, nil
+	}
+	return false, sc.Err()
+}
+
+// grepStats counts what a content search did with the objects it considered.
+type grepStats struct {
+	read, binary, failed, oversize int
+}
+
+// note summarises the stats for the results title.
+func (s grepStats) note() string {
+	parts := []string{fmt.Sprintf("%s object(s) read", humanize.Comma(int64(s.read)))}
+	if s.binary > 0 {
+		parts = append(parts, fmt.Sprintf("%d binary skipped", s.binary))
+	}
+	if s.oversize > 0 {
+		parts = append(parts, fmt.Sprintf("%d over the size cap", s.oversize))
+	}
+	if s.failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d unreadable", s.failed))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ContentSearch prompts for a regular expression and greps the content of the
+// objects under the current prefix for it: the prefix is listed, narrowed by
+// an optional filter query (same language as Ctrl+F) and a size cap, and the
+// survivors are fetched and searched concurrently. Hits — key:line and the
+// line — open in the search results list, where Enter reveals the object. UI
+// goroutine.
+func (c *Controller) ContentSearch() {
+	if c.currentBucket == nil {
+		go c.error("Content search", fmt.Errorf("open a bucket first"))
+		return
+	}
+	bucket := c.currentBucket
+	prefix := model.NormalizePrefix(c.currentPath)
+
+	form := tview.NewForm()
+	form.SetTitle(fmt.Sprintf("Search contents of %s/%s", *bucket.Key, prefix))
+	form.AddInputField("Regex", "", 50, nil, nil)
+	form.AddInputField("Objects (query)", "", 50, nil, nil)
+	form.GetFormItem(1).(*tview.InputField).SetPlaceholder("e.g. ext:conf,yaml modified>30d (empty: all)")
+	form.AddInputField("Max object size", grepDefaultMaxSize, 12, nil, nil)
+	form.AddCheckbox("Ignore case", true, nil)
+	form.SetBorder(true)
+	form.AddButton("Search", func() {
+		pattern := form.GetFormItem(0).(*tview.InputField).GetText()
+		filter := form.GetFormItem(1).(*tview.InputField).GetText()
+		capText := form.GetFormItem(2).(*tview.InputField).GetText()
+		fold := form.GetFormItem(3).(*tview.Checkbox).IsChecked()
+		c.view.Pages.RemovePage("modal")
+		if pattern == "" {
+			return
+		}
+		expr := pattern
+		if fold {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			go c.error("Content search", fmt.Errorf("bad regular expression: %w", err))
+			return
+		}
+		q, err := parseQuery(filter, time.Now())
+		if err != nil {
+			go c.error("Content search", err)
+			return
+		}
+		maxSize, err := humanize.ParseBytes(capText)
+		if err != nil {
+			go c.error("Content search", fmt.Errorf("bad size cap %q: %w", capText, err))
+			return
+		}
+		c.runContentSearch(bucket, prefix, pattern, re, q, int64(maxSize))
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			c.view.Pages.RemovePage("modal")
+		}
+		return event
+	})
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 80, 13), true, true)
+}
+
+// runContentSearch lists prefix, then reads the candidates through
+// model.ReadObjects, stopping everything once searchMaxResults lines have
+// matched. UI goroutine; the listing and reading are not.
+func (c *Controller) runContentSearch(bucket *model.Object, prefix, pattern string, re *regexp.Regexp, filter *searchQuery, maxSize int64) {
+	text := fmt.Sprintf("Searching contents of %s/%s ...", *bucket.Key, prefix)
+	modal, ctx, cancel := c.scanModal("searching", text)
+	scanned := c.scanCounter(modal, text)
+	mdl := c.model
+
+	go func() {
+		defer cancel()
+		objs, err := mdl.ListObjectsSharded(ctx, prefix, bucket, scanned)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("searching") })
+			c.error("Content search failed", err)
+			return
+		}
+		keys, oversize := grepCandidates(objs, filter, maxSize)
+		reading := fmt.Sprintf("Searching contents of %s object(s) under %s/%s ...", humanize.Comma(int64(len(keys))), *bucket.Key, prefix)
+		progress := c.scanCounter(modal, reading)
+
+		var (
+			mu        sync.Mutex
+			hits      []searchHit
+			truncated bool
+			stats     = grepStats{oversize: oversize}
+		)
+		// full stops the readers once the cap is reached, without looking
+		// like the user's Cancel.
+		readCtx, full := context.WithCancel(ctx)
+		defer full()
+		err = mdl.ReadObjects(readCtx, bucket, keys, func(key string, body io.Reader, err error) error {
+			if err != nil {
+				mu.Lock()
+				stats.failed++
+				mu.Unlock()
+				return nil
+			}
+			binary, err := grepReader(io.LimitReader(body, maxSize*grepInflate), re, func(line int, text string) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				if len(hits) >= searchMaxResults {
+					truncated = true
+					full()
+					return false
+				}
+				hits = append(hits, searchHit{key: key, line: line, text: grepPreview(text, re)})
+				return true
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case binary:
+				stats.binary++
+			case err != nil && readCtx.Err() == nil:
+				stats.failed++
+			default:
+				stats.read++
+			}
+			return nil
+		}, progress)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && readCtx.Err() == nil {
+			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("searching") })
+			c.error("Content search failed", err)
+			return
+		}
+		sort.SliceStable(hits, func(i, j int) bool {
+			if hits[i].key != hits[j].key {
+				return hits[i].key < hits[j].key
+			}
+			return hits[i].line < hits[j].line
+		})
+		note := stats.note()
+		if inv := inventoryNote(mdl, *bucket.Key); inv != "" {
+			note += ", listed from the " + inv
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			c.view.Pages.RemovePage("searching")
+			c.presentSearchResults(pattern, hits, truncated, note)
+		})
+		c.logActivity("content search %q under %s/%s: %d hit(s), %s", pattern, *bucket.Key, prefix, len(hits), stats.note())
+	}()
+}
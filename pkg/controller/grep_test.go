@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestGrepCandidates(t *testing.T) {
+	key := func(s string) *string { return &s }
+	objs := []s3t.Object{
+		{Key: key("etc/"), Size: 0},
+		{Key: key("etc/app.conf"), Size: 10},
+		{Key: key("etc/big.conf"), Size: 1 << 30},
+		{Key: key("etc/notes.txt"), Size: 10},
+		{Key: nil, Size: 1},
+	}
+	q, err := parseQuery("ext:conf", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, oversize := grepCandidates(objs, q, 1<<20)
+	if !reflect.DeepEqual(keys, []string{"etc/app.conf"}) || oversize != 1 {
+		t.Errorf("got %q, %d over the cap", keys, oversize)
+	}
+	if keys, _ := grepCandidates(objs, nil, 1<<20); len(keys) != 2 {
+		t.Errorf("no filter: got %q", keys)
+	}
+}
+
+func TestGrepReader(t *testing.T) {
+	re := regexp.MustCompile("(?i)host")
+	type hit struct {
+		line int
+		text string
+	}
+	var got []hit
+	binary, err := grepReader(strings.NewReader("a=1\r\nHostname=db1\r\nb=2\nhost=db2\n"), re, func(line int, text string) bool {
+		got = append(got, hit{line, text})
+		return true
+	})
+	if err != nil || binary {
+		t.Fatalf("binary=%v err=%v", binary, err)
+	}
+	if want := []hit{{2, "Hostname=db1"}, {4, "host=db2"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// The callback stops the scan.
+	n := 0
+	grepReader(strings.NewReader("host\nhost\nhost\n"), re, func(int, string) bool { n++; return false })
+	if n != 1 {
+		t.Errorf("kept going after false: %d hits", n)
+	}
+
+	if binary, _ := grepReader(bytes.NewReader([]byte("host\x00\x01")), re, func(int, string) bool { return true }); !binary {
+		t.Error("a NUL in the head was not binary")
+	}
+
+	// A line past grepMaxLine ends the object, keeping what matched before it.
+	got = nil
+	long := "host\n" + strings.Repeat("x", grepMaxLine+1) + "\nhost\n"
+	if _, err := grepReader(strings.NewReader(long), re, func(line int, text string) bool {
+		got = append(got, hit{line, text})
+		return true
+	}); err != nil || len(got) != 1 {
+		t.Errorf("long line: %+v, %v", got, err)
+	}
+}
+
+func TestGrepPreview(t *testing.T) {
+	re := regexp.MustCompile("needle")
+	if got := grepPreview("\t  short needle", re); got != "short needle" {
+		t.Errorf("got %q", got)
+	}
+	line := strings.Repeat("a", 500) + "needle" + strings.Repeat("é", 200)
+	got := grepPreview(line, re)
+	if !strings.Contains(got, "needle") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("match not in view: %q", got)
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("cut a rune: %q", got)
+	}
+}
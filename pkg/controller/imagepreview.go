@@ -0,0 +1,332 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	_ "image/gif" // registered for image.Decode
+	_ "image/jpeg"
+	"image/png"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// previewImageMaxPixels refuses images whose header promises more pixels
+	// than this before decoding them: a small file can claim a huge canvas.
+	previewImageMaxPixels = 50_000_000
+	// previewImageMaxDim bounds the copy of a decoded image the preview keeps;
+	// every later fit to the panel starts from it rather than the original.
+	previewImageMaxDim = 1600
+	// kittyImageID is the kitty graphics image id the preview places, so it
+	// can delete its own image and nothing else.
+	kittyImageID = 4242
+)
+
+// Terminal graphics protocols the image preview can use. Half blocks work in
+// any truecolor terminal; sixel and kitty draw real pixels where supported.
+const (
+	graphicsBlocks = "blocks"
+	graphicsSixel  = "sixel"
+	graphicsKitty  = "kitty"
+)
+
+// termGraphics is the protocol preview images are drawn with and the sixel or
+// kitty image on screen, if any. gen counts drawPreviewImage calls; placed is
+// the gen of the image on screen (0 for none) and rect the cells it covers.
+type termGraphics struct {
+	proto  string
+	gen    uint64
+	placed uint64
+	rect   [4]int
+}
+
+// graphicsProtocol picks how images are drawn: S3DUCK_GRAPHICS (blocks,
+// sixel or kitty) if set, else a guess from the environment. Terminals can't
+// be asked without reading their reply off stdin, which tview owns, so the
+// guess is conservative — inside tmux or screen, which don't pass graphics
+// through, it is always half blocks.
+func graphicsProtocol(getenv func(string) string) string {
+	switch strings.ToLower(getenv("S3DUCK_GRAPHICS")) {
+	case graphicsKitty:
+		return graphicsKitty
+	case graphicsSixel:
+		return graphicsSixel
+	case graphicsBlocks, "halfblocks", "none":
+		return graphicsBlocks
+	}
+	term, prog := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("TMUX") != "", strings.HasPrefix(term, "screen"), strings.HasPrefix(term, "tmux"):
+		return graphicsBlocks
+	case term == "xterm-kitty", getenv("KITTY_WINDOW_ID") != "", term == "xterm-ghostty", prog == "ghostty":
+		return graphicsKitty
+	case prog == "WezTerm", strings.HasPrefix(term, "foot"), strings.Contains(term, "mlterm"),
+		strings.HasPrefix(term, "contour"), strings.Contains(term, "sixel"):
+		return graphicsSixel
+	}
+	return graphicsBlocks
+}
+
+// decodePreviewImage decodes a PNG, JPEG or GIF (its first frame) and returns
+// it shrunk to previewImageMaxDim, with its format and original size.
+func decodePreviewImage(data []byte) (img *image.RGBA, format string, w, h int, err error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	if cfg.Width*cfg.Height > previewImageMaxPixels {
+		return nil, format, cfg.Width, cfg.Height, fmt.Errorf("%d×%d is too many pixels to preview", cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, format, cfg.Width, cfg.Height, err
+	}
+	return fitImage(src, previewImageMaxDim, previewImageMaxDim), format, cfg.Width, cfg.Height, nil
+}
+
+// isPreviewImage reports whether data starts with the signature of an image
+// decodePreviewImage reads. Only the signature: a JPEG's size can sit behind
+// more EXIF than the first ranged chunk holds.
+func isPreviewImage(data []byte) bool {
+	for _, magic := range []string{"\x89PNG\r\n\x1a\n", "\xff\xd8\xff", "GIF87a", "GIF89a"} {
+		if bytes.HasPrefix(data, []byte(magic)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fitImage scales src to fit within maxW×maxH keeping its aspect ratio,
+// averaging each block of source pixels into one, and flattens transparency
+// onto black. It never enlarges.
+func fitImage(src image.Image, maxW, maxH int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= 0 || sh <= 0 || maxW <= 0 || maxH <= 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	scale := min(1, float64(maxW)/float64(sw), float64(maxH)/float64(sh))
+	dw, dh := max(1, int(float64(sw)*scale)), max(1, int(float64(sh)*scale))
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := b.Min.Y + y*sh/dh
+		y1 := max(y0+1, b.Min.Y+(y+1)*sh/dh)
+		for x := 0; x < dw; x++ {
+			x0 := b.Min.X + x*sw/dw
+			x1 := max(x0+1, b.Min.X+(x+1)*sw/dw)
+			var r, g, bl, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// RGBA is alpha-premultiplied: dropping alpha is
+					// compositing over black.
+					cr, cg, cb, _ := src.At(sx, sy).RGBA()
+					r, g, bl, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(bl / n >> 8), 0xff})
+		}
+	}
+	return dst
+}
+
+// halfBlocks renders img as text for a dynamic-colour TextView, two pixel
+// rows per line: each cell is an upper half block with the top pixel as its
+// foreground and the bottom one as its background. img must be at most as
+// wide as the view, in cells.
+func halfBlocks(img *image.RGBA) string {
+	b := img.Bounds()
+	var out strings.Builder
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		last := ""
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top, bottom := img.RGBAAt(x, y), color.RGBA{A: 0xff}
+			if y+1 < b.Max.Y {
+				bottom = img.RGBAAt(x, y+1)
+			}
+			tag := fmt.Sprintf("[#%02x%02x%02x:#%02x%02x%02x]", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			if tag != last {
+				out.WriteString(tag)
+				last = tag
+			}
+			out.WriteString("▀")
+		}
+		out.WriteString("[-:-]")
+		if y+2 < b.Max.Y {
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+// sixelEncode encodes img as a DEC sixel image, dithered to the 256-colour
+// Plan 9 palette. Sixel bands are six pixels high; callers size img to a
+// multiple of six so nothing spills past the cells it is meant for.
+func sixelEncode(img image.Image) []byte {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	pal := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+	draw.FloydSteinberg.Draw(pal, pal.Bounds(), img, b.Min)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	var used [256]bool
+	for _, i := range pal.Pix {
+		used[i] = true
+	}
+	for i, c := range palette.Plan9 {
+		if used[i] {
+			r, g, bl, _ := c.RGBA()
+			fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+		}
+	}
+	row := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		var inBand [256]bool
+		for y := top; y < min(top+6, h); y++ {
+			for _, i := range pal.Pix[y*pal.Stride : y*pal.Stride+w] {
+				inBand[i] = true
+			}
+		}
+		for ci := range inBand {
+			if !inBand[ci] {
+				continue
+			}
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if int(pal.Pix[(top+dy)*pal.Stride+x]) == ci {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = 63 + bits
+			}
+			fmt.Fprintf(&out, "#%d", ci)
+			sixelRuns(&out, row)
+			out.WriteByte('$')
+		}
+		out.WriteByte('-')
+	}
+	out.WriteString("\x1b\\")
+	return out.Bytes()
+}
+
+// sixelRuns writes a row of sixel characters with runs of four or more
+// compressed to !n.
+func sixelRuns(out *bytes.Buffer, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n >= 4 {
+			fmt.Fprintf(out, "!%d%c", n, row[i])
+		} else {
+			out.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// kittyEncode encodes img for the kitty graphics protocol as a PNG placed over
+// cols×rows cells at the cursor, in 4096-byte base64 chunks. The cursor stays
+// put (C=1) and the terminal is told not to reply (q=2): a reply would arrive
+// on stdin as keystrokes.
+func kittyEncode(img image.Image, cols, rows int) []byte {
+	var raw bytes.Buffer
+	_ = png.Encode(&raw, img) // writes to memory; can't fail
+	data := base64.StdEncoding.EncodeToString(raw.Bytes())
+	var out bytes.Buffer
+	for i := 0; i < len(data); i += 4096 {
+		chunk := data[i:min(i+4096, len(data))]
+		more := 0
+		if i+4096 < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&out, "\x1b_Ga=T,f=100,i=%d,c=%d,r=%d,C=1,q=2,m=%d;%s\x1b\\", kittyImageID, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&out, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return out.Bytes()
+}
+
+// kittyDelete removes the preview's kitty image and frees its data.
+func kittyDelete() []byte {
+	return []byte(fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyImageID))
+}
+
+// placeGraphics runs after every draw, before tcell writes the frame out. It
+// re-fits a previewed image whose panel changed size, and keeps a sixel or
+// kitty image on screen over the image box: written straight to the terminal
+// at the box, with the cells under it locked so tcell leaves them alone. The
+// image comes down again — cells unlocked, so tcell repaints them, and the
+// kitty image deleted — when the preview moves on, the box moves, or a dialog
+// opens over the main page, which locked cells would hide. Without a pixel
+// size for the cells there is nothing to fit the image to, and half blocks
+// take over. UI goroutine.
+func (c *Controller) placeGraphics(screen tcell.Screen) {
+	g, p := &c.graphics, &c.preview
+	showing := p.on && !c.dual && p.img != nil && p.structured()
+	if _, _, w, h := c.view.PreviewPages.GetInnerRect(); showing && [2]int{w, h} != p.imgFit {
+		p.imgFit = [2]int{w, h} // one re-fit per change
+		go c.refitPreviewImage(p.seq)
+	}
+
+	x, y, w, h := c.view.PreviewImage.GetInnerRect()
+	rect := [4]int{x, y, w, h}
+	page, _ := c.view.PreviewPages.GetFrontPage()
+	front, _ := c.view.Pages.GetFrontPage()
+	want := showing && g.proto != graphicsBlocks && page == "image" && front == "main" && w > 0 && h > 0
+	tty, ok := screen.Tty()
+	if g.placed != 0 && (!want || g.placed != g.gen || g.rect != rect) {
+		screen.LockRegion(g.rect[0], g.rect[1], g.rect[2], g.rect[3], false)
+		if g.proto == graphicsKitty && ok {
+			_, _ = tty.Write(kittyDelete())
+		}
+		g.placed = 0
+	}
+	if !want || g.placed != 0 {
+		return
+	}
+	var cw, ch int
+	if ok {
+		if ws, err := tty.WindowSize(); err == nil {
+			cw, ch = ws.CellDimensions()
+		}
+	}
+	if cw == 0 || ch == 0 || h*ch < 6 {
+		g.proto = graphicsBlocks
+		go c.refitPreviewImage(p.seq)
+		return
+	}
+	var out []byte
+	switch g.proto {
+	case graphicsSixel:
+		out = sixelEncode(fitImage(p.img, w*cw, h*ch/6*6))
+	case graphicsKitty:
+		img := fitImage(p.img, w*cw, h*ch)
+		out = kittyEncode(img, (img.Rect.Dx()+cw-1)/cw, (img.Rect.Dy()+ch-1)/ch)
+	}
+	// Save the cursor, draw at the box and restore it, so tcell's idea of
+	// where the cursor is stays true.
+	_, _ = fmt.Fprintf(tty, "\x1b7\x1b[%d;%dH%s\x1b8", y+1, x+1, out)
+	screen.LockRegion(x, y, w, h, true)
+	g.placed, g.rect = g.gen, rect
+}
+
+// refitPreviewImage draws the previewed image again for the panel's current
+// size, if the preview still shows the image of seq. Any goroutine.
+func (c *Controller) refitPreviewImage(seq uint64) {
+	c.view.App.QueueUpdateDraw(func() {
+		if p := &c.preview; p.seq == seq && p.img != nil && p.structured() {
+			c.drawPreviewImage()
+		}
+	})
+}
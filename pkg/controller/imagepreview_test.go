@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGraphicsProtocol(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, graphicsBlocks},
+		{map[string]string{"TERM": "xterm-kitty"}, graphicsKitty},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "ghostty"}, graphicsKitty},
+		{map[string]string{"TERM": "foot"}, graphicsSixel},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, graphicsSixel},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-0/default,1,0"}, graphicsBlocks},
+		{map[string]string{"TERM": "screen-256color", "S3DUCK_GRAPHICS": "Sixel"}, graphicsSixel},
+		{map[string]string{"TERM": "xterm-kitty", "S3DUCK_GRAPHICS": "blocks"}, graphicsBlocks},
+	} {
+		if got := graphicsProtocol(func(k string) string { return tc.env[k] }); got != tc.want {
+			t.Errorf("graphicsProtocol(%v) = %s, want %s", tc.env, got, tc.want)
+		}
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		src.Set(x, 0, color.NRGBA{R: 200, A: 0xff})
+		src.Set(x, 1, color.NRGBA{B: 100, A: 0xff})
+	}
+	src.Set(3, 0, color.NRGBA{R: 200, A: 0}) // transparent: black
+	got := fitImage(src, 2, 10)
+	if got.Rect != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("fitImage bounds = %v, want 2×1", got.Rect)
+	}
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{R: 100, B: 50, A: 0xff}) {
+		t.Errorf("left pixel = %v", c)
+	}
+	if c := got.RGBAAt(1, 0); c != (color.RGBA{R: 50, B: 50, A: 0xff}) {
+		t.Errorf("right pixel = %v", c)
+	}
+	if got := fitImage(src, 100, 100); got.Rect != image.Rect(0, 0, 4, 2) {
+		t.Errorf("fitImage enlarged to %v", got.Rect)
+	}
+}
+
+func TestHalfBlocks(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	img.SetRGBA(0, 0, color.RGBA{R: 0xff, A: 0xff})
+	img.SetRGBA(1, 0, color.RGBA{R: 0xff, A: 0xff})
+	img.SetRGBA(0, 2, color.RGBA{G: 0xff, A: 0xff})
+	want := "[#ff0000:#000000]▀▀[-:-]\n" +
+		"[#00ff00:#000000]▀[#000000:#000000]▀[-:-]"
+	if got := halfBlocks(img); got != want {
+		t.Errorf("halfBlocks =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSixelEncode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 5, 6))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff // white
+	}
+	got := string(sixelEncode(img))
+	if !strings.HasPrefix(got, "\x1bP0;1;0q\"1;1;5;6#") || !strings.HasSuffix(got, "\x1b\\") {
+		t.Fatalf("sixel framing wrong: %q", got)
+	}
+	// One colour, a full six-pixel band five wide: "!5~" after selecting it.
+	if !regexp.MustCompile(`#\d+;2;100;100;100#\d+!5~\$-\x1b\\$`).MatchString(got) {
+		t.Errorf("sixel body = %q", got)
+	}
+}
+
+func TestKittyEncode(t *testing.T) {
+	// Noise so the PNG doesn't compress below one chunk.
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	x := uint32(1)
+	for i := range img.Pix {
+		x = x*1664525 + 1013904223
+		img.Pix[i] = byte(x >> 24)
+	}
+	got := string(kittyEncode(img, 8, 4))
+	chunks := strings.Split(strings.TrimSuffix(got, "\x1b\\"), "\x1b\\")
+	if len(chunks) < 2 {
+		t.Fatalf("want several chunks, got %d", len(chunks))
+	}
+	var data strings.Builder
+	for i, ch := range chunks {
+		ctl, payload, _ := strings.Cut(strings.TrimPrefix(ch, "\x1b_G"), ";")
+		switch {
+		case i == 0 && ctl != "a=T,f=100,i=4242,c=8,r=4,C=1,q=2,m=1":
+			t.Errorf("first chunk control %q", ctl)
+		case i > 0 && i < len(chunks)-1 && ctl != "m=1", i == len(chunks)-1 && ctl != "m=0":
+			t.Errorf("chunk %d control %q", i, ctl)
+		}
+		if len(payload) > 4096 {
+			t.Errorf("chunk %d is %d bytes", i, len(payload))
+		}
+		data.WriteString(payload)
+	}
+	raw, err := base64.StdEncoding.DecodeString(data.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back, err := png.Decode(bytes.NewReader(raw)); err != nil || back.Bounds() != img.Rect {
+		t.Errorf("payload is not the image: %v", err)
+	}
+}
+
+func TestDecodePreviewImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 3200, 10))); err != nil {
+		t.Fatal(err)
+	}
+	if !isPreviewImage(buf.Bytes()[:64]) {
+		t.Error("a PNG header should be recognised")
+	}
+	img, format, w, h, err := decodePreviewImage(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "png" || w != 3200 || h != 10 || img.Rect != image.Rect(0, 0, 1600, 5) {
+		t.Errorf("decodePreviewImage = %s %d×%d shrunk to %v", format, w, h, img.Rect)
+	}
+
+	// A valid header claiming 100000×100000 is refused before decoding.
+	bomb := []byte("\x89PNG\r\n\x1a\n")
+	ihdr := binary.BigEndian.AppendUint32([]byte("IHDR"), 100000)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, 100000)
+	ihdr = append(ihdr, 8, 0, 0, 0, 0)
+	bomb = binary.BigEndian.AppendUint32(bomb, 13)
+	bomb = append(bomb, ihdr...)
+	bomb = binary.BigEndian.AppendUint32(bomb, crc32.ChecksumIEEE(ihdr))
+	if _, _, _, _, err := decodePreviewImage(bomb); err == nil || !strings.Contains(err.Error(), "too many pixels") {
+		t.Errorf("oversized image: err = %v", err)
+	}
+	if isPreviewImage([]byte("GIF-ish text, not an image")) {
+		t.Error("text taken for an image")
+	}
+}
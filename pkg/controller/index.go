@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/dustin/go-humanize"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// indexStaleAfter is the age past which a search of a local index warns that
+// its results may be out of date.
+const indexStaleAfter = 24 * time.Hour
+
+// indexFile is where the local index of prefix in bucket lives for profile.
+// The prefix is hashed — it may hold anything a key can — and the file's
+// header records bucket and prefix in full.
+func indexFile(dir, profile, bucket, prefix string) string {
+	sum := sha256.Sum256([]byte(prefix))
+	return filepath.Join(dir, url.PathEscape(profile), url.PathEscape(bucket), hex.EncodeToString(sum[:8])+".idx.gz")
+}
+
+// prefixChain is every prefix whose index would cover path, most specific
+// first: path itself, then each folder above it up to the bucket root ("").
+func prefixChain(path string) []string {
+	chain := []string{path}
+	for path != "" {
+		path = parentPrefix(path)
+		chain = append(chain, path)
+	}
+	return chain
+}
+
+// findIndex returns the file of the most specific local index of the active
+// profile covering path in bucket, or "" if there is none. It only stats
+// files, so it is cheap enough for the UI goroutine.
+func (c *Controller) findIndex(bucket, path string) string {
+	for _, p := range prefixChain(path) {
+		f := indexFile(c.params.IndexDir(), c.profileName(), bucket, p)
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return ""
+}
+
+// openIndexLocked returns the index in file, reading it on first use. Callers
+// hold indexMu.
+func (c *Controller) openIndexLocked(file string) (*model.LocalIndex, error) {
+	if ix, ok := c.indexes[file]; ok {
+		return ix, nil
+	}
+	ix, err := model.LoadIndex(file)
+	if err != nil {
+		return nil, err
+	}
+	if c.indexes == nil {
+		c.indexes = map[string]*model.LocalIndex{}
+	}
+	c.indexes[file] = ix
+	return ix, nil
+}
+
+// indexNote describes an index's age for a search-results title, warning once
+// it is older than indexStaleAfter.
+func indexNote(built, now time.Time) string {
+	if built.IsZero() {
+		return "local index"
+	}
+	note := "local index from " + humanize.RelTime(built, now, "ago", "from now")
+	if now.Sub(built) > indexStaleAfter {
+		note = "[yellow]" + note + ", may be stale — rebuild it from the palette[-]"
+	}
+	return note
+}
+
+// BuildIndex snapshots the current location into a local search index that
+// Ctrl+F can search without listing anything. When an index already covers
+// the location, only the location is re-listed and spliced into it, so
+// refreshing a busy folder of a big index is cheap. UI goroutine; the listing
+// is not.
+func (c *Controller) BuildIndex() {
+	if c.currentBucket == nil {
+		go c.error("Build index", fmt.Errorf("open a bucket first"))
+		return
+	}
+	bucket, path := *c.currentBucket.Key, model.NormalizePrefix(c.currentPath)
+	own := indexFile(c.params.IndexDir(), c.profileName(), bucket, path)
+	file := c.findIndex(bucket, path)
+	if file == "" {
+		file = own
+	}
+
+	text := fmt.Sprintf("Indexing %s/%s ...", bucket, path)
+	modal, ctx, cancel := c.scanModal("progress", text)
+	scanned := c.scanCounter(modal, text)
+	mdl := c.model
+
+	go func() {
+		defer cancel()
+		if mdl.Inventory() != nil {
+			// Index the bucket, not a report of it.
+			live, err := mdl.ForBucket(bucket)
+			if err != nil {
+				c.error("Failed to resolve bucket region", err)
+				live = mdl.WithInventory(nil)
+			}
+			mdl = live
+		}
+		c.indexMu.Lock()
+		defer c.indexMu.Unlock()
+		ix, err := c.openIndexLocked(file)
+		if err != nil {
+			// No index yet, or one that can't be read: start this location's
+			// own. An unreadable index further up is left for its own rebuild.
+			if !errors.Is(err, fs.ErrNotExist) {
+				file = own
+			}
+			ix, err = model.NewLocalIndex(bucket, path), nil
+		}
+		delta, err := ix.Refresh(ctx, mdl, path, scanned)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = ix.Save(file)
+		}
+		if err != nil {
+			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
+			c.error("Failed to build index", err)
+			return
+		}
+		if c.indexes == nil {
+			c.indexes = map[string]*model.LocalIndex{}
+		}
+		c.indexes[file] = ix
+
+		scope := strings.TrimSuffix(bucket+"/"+path, "/")
+		c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
+		c.success(fmt.Sprintf("Indexed %s/%s: %s objects (%d added, %d changed, %d removed). Ctrl+F searches the index.",
+			bucket, path, humanize.Comma(int64(ix.Len())), delta.Added, delta.Changed, delta.Removed))
+		c.logActivity("indexed %s: +%d ~%d -%d", scope, delta.Added, delta.Changed, delta.Removed)
+	}()
+}
+
+// runIndexSearch searches the local index in file for query under prefix. It
+// lists nothing; reading the index from disk the first time is the only wait.
+func (c *Controller) runIndexSearch(file, prefix, query string) {
+	_, ctx, cancel := c.scanModal("searching", fmt.Sprintf("Searching the local index for %q ...", query))
+
+	go func() {
+		defer cancel()
+		q, err := parseQuery(query, time.Now())
+		var h hitCollector
+		var built time.Time
+		if err == nil {
+			c.indexMu.Lock()
+			var ix *model.LocalIndex
+			if ix, err = c.openIndexLocked(file); err == nil {
+				h = hitCollector{query: q, max: searchMaxResults}
+				ix.Walk(prefix, func(o s3t.Object) bool { return ctx.Err() == nil && h.add(o) })
+				built = ix.Built(prefix)
+			}
+			c.indexMu.Unlock()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("searching") })
+			c.error("Search failed", err)
+			return
+		}
+		note := indexNote(built, time.Now())
+		c.view.App.QueueUpdateDraw(func() {
+			c.view.Pages.RemovePage("searching")
+			c.presentSearchResults(query, h.hits, h.truncated, note)
+		})
+	}()
+}
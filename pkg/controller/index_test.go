@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrefixChain(t *testing.T) {
+	if got, want := prefixChain("a/b/"), []string{"a/b/", "a/", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := prefixChain(""); !reflect.DeepEqual(got, []string{""}) {
+		t.Errorf("root: %q", got)
+	}
+}
+
+func TestIndexFile(t *testing.T) {
+	a := indexFile("/d", "prod/eu", "b", "logs/")
+	if a != indexFile("/d", "prod/eu", "b", "logs/") || a == indexFile("/d", "prod/eu", "b", "") {
+		t.Errorf("index files not stable per prefix: %s", a)
+	}
+	if !strings.HasPrefix(a, "/d/prod%2Feu/b/") {
+		t.Errorf("profile not escaped into one directory: %s", a)
+	}
+}
+
+func TestIndexNote(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := indexNote(now.Add(-3*time.Hour), now); got != "local index from 3 hours ago" {
+		t.Errorf("fresh: %q", got)
+	}
+	if got := indexNote(now.Add(-72*time.Hour), now); !strings.Contains(got, "3 days ago") || !strings.Contains(got, "stale") {
+		t.Errorf("old: %q", got)
+	}
+	if got := indexNote(time.Time{}, now); got != "local index" {
+		t.Errorf("undated: %q", got)
+	}
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// inventoryNote labels a listing or scan that mdl answers from an inventory
+// report rather than the live bucket, with the report's date: "" when mdl is
+// live or its report describes another bucket. An empty bucket matches any.
+func inventoryNote(mdl *model.Model, bucket string) string {
+	inv := mdl.Inventory()
+	if inv == nil || bucket != "" && bucket != inv.Bucket {
+		return ""
+	}
+	if inv.Created.IsZero() {
+		return "inventory (undated)"
+	}
+	return "inventory of " + inv.Created.Format("2006-01-02 15:04 MST")
+}
+
+// OpenInventory asks for the manifest.json of an S3 Inventory report, loads
+// it and switches the active pane to browsing the report's bucket from it.
+// The field starts on the highlighted object, so browsing to the manifest in
+// the destination bucket and opening it from the palette needs no typing.
+// UI goroutine.
+func (c *Controller) OpenInventory() {
+	uri := "s3://"
+	if c.currentBucket != nil {
+		uri += *c.currentBucket.Key + "/" + c.currentPath
+		if _, obj, ok := c.currentObject(); ok && obj.Ot == model.File && obj.FullPath != nil {
+			uri = "s3://" + *c.currentBucket.Key + "/" + *obj.FullPath
+		}
+	}
+
+	form := c.view.NewInputForm("Open S3 Inventory report", "manifest.json", uri)
+	form.AddButton("Open", func() {
+		text := form.GetFormItem(0).(*tview.InputField).GetText()
+		c.view.Pages.RemovePage("modal")
+		bucket, key, err := model.ParseS3URI(text)
+		if err != nil {
+			go c.error("Open inventory", err)
+			return
+		}
+		c.loadInventory(bucket, key)
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 80, 7), true, true)
+}
+
+// loadInventory reads the report behind a cancellable progress modal, then
+// navigates the active pane to the root of the bucket it describes, through a
+// model that lists it from the report. The pane is fixed when the report
+// lands, like any navigation: whichever pane is active then gets it.
+func (c *Controller) loadInventory(bucket, key string) {
+	text := fmt.Sprintf("Loading inventory s3://%s/%s ...", bucket, key)
+	modal, ctx, cancel := c.scanModal("progress", text)
+	scanned := c.scanCounter(modal, text)
+	live := c.model.Home()
+
+	go func() {
+		defer cancel()
+		reader, err := live.ForBucket(bucket)
+		if err != nil {
+			c.error("Failed to resolve bucket region", err)
+		}
+		inv, err := reader.LoadInventory(ctx, bucket, key, scanned)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
+			c.error("Failed to load inventory", err)
+			return
+		}
+		src, err := live.ForBucket(inv.Bucket)
+		if err != nil {
+			c.error("Failed to resolve bucket region", err)
+		}
+		mdl := src.WithInventory(inv)
+		name := inv.Bucket
+		c.view.App.QueueUpdateDraw(func() {
+			c.view.Pages.RemovePage("progress")
+			c.recordHistory()
+			c.navigate(navRequest{
+				mdl:     mdl,
+				target:  location{bucket: &model.Object{Key: &name, Ot: model.Bucket}},
+				restore: "..",
+			})
+			c.view.App.SetFocus(c.view.List)
+		})
+		c.logActivity("opened %s: %d object(s), %s", inv.Manifest, inv.Len(), inventoryNote(mdl, ""))
+	}()
+}
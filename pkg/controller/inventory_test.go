@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+func TestInventoryNote(t *testing.T) {
+	live := &model.Model{}
+	if got := inventoryNote(live, "big"); got != "" {
+		t.Errorf("live model labelled %q", got)
+	}
+	if got := inventoryNote(nil, ""); got != "" {
+		t.Errorf("nil model labelled %q", got)
+	}
+	inv := live.WithInventory(&model.Inventory{Bucket: "big"})
+	if got := inventoryNote(inv, "other"); got != "" {
+		t.Errorf("another bucket labelled %q", got)
+	}
+	if got := inventoryNote(inv, "big"); got != "inventory (undated)" {
+		t.Errorf("undated report: %q", got)
+	}
+	dated := live.WithInventory(&model.Inventory{Bucket: "big", Created: time.Date(2025, 10, 17, 1, 0, 0, 0, time.UTC)})
+	if got, want := inventoryNote(dated, ""), "inventory of 2025-10-17 01:00 UTC"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
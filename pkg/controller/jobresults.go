@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// itemOutcome is what happened to one object of a finished transfer job.
+type itemOutcome int
+
+const (
+	itemOK itemOutcome = iota
+	itemSkipped
+	itemFailed
+)
+
+func (o itemOutcome) String() string {
+	switch o {
+	case itemOK:
+		return "ok"
+	case itemSkipped:
+		return "skipped"
+	case itemFailed:
+		return "failed"
+	default:
+		return "?"
+	}
+}
+
+// itemResult is one per-item outcome kept on a transfer job. key is what the
+// job's retry function understands (an object key, or a sync-relative path);
+// reason is the error text for failures and the cause for skips.
+type itemResult struct {
+	key     string
+	outcome itemOutcome
+	reason  string
+}
+
+// recordItem appends a per-item outcome. Safe from worker goroutines.
+func (j *transferJob) recordItem(key string, outcome itemOutcome, reason string) {
+	j.mu.Lock()
+	j.results = append(j.results, itemResult{key: key, outcome: outcome, reason: reason})
+	j.mu.Unlock()
+}
+
+// recordErr is recordItem for the common success/failure split.
+func (j *transferJob) recordErr(key string, err error) {
+	if err != nil {
+		j.recordItem(key, itemFailed, err.Error())
+		return
+	}
+	j.recordItem(key, itemOK, "")
+}
+
+// setRetry installs the function that re-enqueues a subset of this job's keys
+// as a new job. Jobs whose failures can't be retried item by item leave it nil.
+// retry runs on the UI goroutine.
+func (j *transferJob) setRetry(fn func(keys []string)) {
+	j.mu.Lock()
+	j.retry = fn
+	j.mu.Unlock()
+}
+
+// resultsSnapshot returns a copy of the recorded outcomes and the retry hook.
+func (j *transferJob) resultsSnapshot() ([]itemResult, func(keys []string)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	out := make([]itemResult, len(j.results))
+	copy(out, j.results)
+	return out, j.retry
+}
+
+// failedKeys returns the keys of the failed items, in recorded order and
+// without duplicates.
+func failedKeys(results []itemResult) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, r := range results {
+		if r.outcome != itemFailed || seen[r.key] {
+			continue
+		}
+		seen[r.key] = true
+		keys = append(keys, r.key)
+	}
+	return keys
+}
+
+// resultCounts tallies the outcomes.
+func resultCounts(results []itemResult) (ok, skipped, failed int) {
+	for _, r := range results {
+		switch r.outcome {
+		case itemOK:
+			ok++
+		case itemSkipped:
+			skipped++
+		case itemFailed:
+			failed++
+		}
+	}
+	return ok, skipped, failed
+}
+
+// resultsReport renders a job's outcomes as plain text for export: a header,
+// then one tab-separated "outcome, key, reason" line per item, failures first
+// so the interesting part of a long report is at the top.
+func resultsReport(jv jobView, results []itemResult) string {
+	ok, skipped, failed := resultCounts(results)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# job %d: %s — %s\n", jv.id, jv.kind, jv.desc)
+	fmt.Fprintf(&b, "# status: %s, started %s\n", jv.status, jv.start.Format(time.RFC3339))
+	fmt.Fprintf(&b, "# ok: %d, skipped: %d, failed: %d\n", ok, skipped, failed)
+	for _, want := range []itemOutcome{itemFailed, itemSkipped, itemOK} {
+		for _, r := range results {
+			if r.outcome != want {
+				continue
+			}
+			fmt.Fprintf(&b, "%s\t%s\t%s\n", r.outcome, r.key, r.reason)
+		}
+	}
+	return b.String()
+}
+
+// resultsFileName is the export file name for a job's report.
+func resultsFileName(id int, now time.Time) string {
+	return fmt.Sprintf("s3duck-job-%d-%s.tsv", id, now.Format("20060102-150405"))
+}
+
+// resultLabel formats one outcome for the results browser.
+func resultLabel(r itemResult) string {
+	switch r.outcome {
+	case itemFailed:
+		return fmt.Sprintf("[red]✗[-] %s  [gray]%s[-]", tview.Escape(r.key), tview.Escape(r.reason))
+	case itemSkipped:
+		return fmt.Sprintf("[yellow]–[-] %s  [gray]%s[-]", tview.Escape(r.key), tview.Escape(r.reason))
+	default:
+		return "[green]✓[-] " + tview.Escape(r.key)
+	}
+}
+
+// jobAt returns the job shown at row i of the transfers panel, or nil.
+func (c *Controller) jobAt(i int) *transferJob {
+	c.jobsMu.Lock()
+	defer c.jobsMu.Unlock()
+	if i < 0 || i >= len(c.jobs) {
+		return nil
+	}
+	return c.jobs[i]
+}
+
+// retryFailed enqueues a new job holding only j's failed items. Runs on the UI
+// goroutine; the caller has already closed the transfers panel, since the
+// retry's confirm dialog returns to the main page.
+func (c *Controller) retryFailed(j *transferJob) {
+	results, retry := j.resultsSnapshot()
+	keys := failedKeys(results)
+	switch st := j.view().status; {
+	case st == jobRunning || st == jobQueued:
+		go c.error("Retry", fmt.Errorf("job %d is still %s", j.id, st))
+	case len(keys) == 0:
+		go c.success("No failed items to retry")
+	case retry == nil:
+		go c.error("Retry", fmt.Errorf("%s jobs can't be retried item by item", j.kind))
+	default:
+		c.logActivity("retrying %d failed item(s) of %s %s", len(keys), j.kind, j.desc)
+		retry(keys)
+	}
+}
+
+// exportResults writes j's report next to downloads and reports the path.
+func (c *Controller) exportResults(j *transferJob) {
+	results, _ := j.resultsSnapshot()
+	dir := c.resolveDownloadDir()
+	dst := filepath.Join(dir, resultsFileName(j.id, time.Now()))
+	go func() {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			c.error("Export results", err)
+			return
+		}
+		if err := os.WriteFile(dst, []byte(resultsReport(j.view(), results)), 0o644); err != nil {
+			c.error("Export results", err)
+			return
+		}
+		c.logActivity("exported results of %s %s to %s", j.kind, j.desc, dst)
+		c.success("Results saved to " + dst)
+	}()
+}
+
+// showJobResults browses one job's per-item outcomes on top of the transfers
+// panel. f toggles failed-only, r retries the failures, e exports, Esc returns
+// to the panel. onRetry closes the panel before a retry is enqueued.
+func (c *Controller) showJobResults(j *transferJob, onRetry func()) {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetSelectedBackgroundColor(tcell.ColorBlue)
+	list.SetSelectedTextColor(tcell.ColorWhite)
+	list.SetBorder(true)
+
+	failedOnly := false
+	fill := func() {
+		results, _ := j.resultsSnapshot()
+		ok, skipped, failed := resultCounts(results)
+		scope := "all"
+		if failedOnly {
+			scope = "failed only"
+		}
+		list.SetTitle(fmt.Sprintf(" Job %d — %d ok • %d skipped • %d failed (%s) — f: filter • r: retry failed • e: export • Esc: back ",
+			j.id, ok, skipped, failed, scope))
+		list.Clear()
+		for _, r := range results {
+			if failedOnly && r.outcome != itemFailed {
+				continue
+			}
+			list.AddItem(resultLabel(r), "", 0, nil)
+		}
+		if list.GetItemCount() == 0 {
+			list.AddItem("[gray](no item results recorded)[-]", "", 0, nil)
+		}
+	}
+	fill()
+
+	closeResults := func() {
+		c.view.Pages.RemovePage("modal-results")
+		if c.transfersList != nil {
+			c.view.App.SetFocus(c.transfersList)
+		}
+	}
+	list.SetInputCapture(func(ev *tcell.EventKey) *tcell.EventKey {
+		switch ev.Key() {
+		case tcell.KeyEsc:
+			closeResults()
+			return nil
+		case tcell.KeyRune:
+			switch ev.Rune() {
+			case 'f':
+				failedOnly = !failedOnly
+				fill()
+				return nil
+			case 'r':
+				c.view.Pages.RemovePage("modal-results")
+				onRetry()
+				c.retryFailed(j)
+				return nil
+			case 'e':
+				c.exportResults(j)
+				return nil
+			}
+		}
+		return ev
+	})
+
+	c.view.Pages.AddPage("modal-results", c.view.ModalEdit(list, 110, 30), true, true)
+	c.view.App.SetFocus(list)
+}
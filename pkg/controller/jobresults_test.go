@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+func sampleResults() []itemResult {
+	return []itemResult{
+		{key: "a.txt", outcome: itemOK},
+		{key: "b.txt", outcome: itemFailed, reason: "access denied"},
+		{key: "c.txt", outcome: itemSkipped, reason: "exists locally: /dl/c.txt"},
+		{key: "d.txt", outcome: itemFailed, reason: "timeout"},
+		// A key that failed twice (e.g. recorded by two phases) is retried once.
+		{key: "b.txt", outcome: itemFailed, reason: "access denied"},
+	}
+}
+
+func TestFailedKeys(t *testing.T) {
+	got := failedKeys(sampleResults())
+	if want := []string{"b.txt", "d.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failedKeys = %v, want %v", got, want)
+	}
+	if got := failedKeys([]itemResult{{key: "a", outcome: itemOK}}); got != nil {
+		t.Errorf("failedKeys with no failures = %v, want nil", got)
+	}
+}
+
+func TestResultCounts(t *testing.T) {
+	ok, skipped, failed := resultCounts(sampleResults())
+	if ok != 1 || skipped != 1 || failed != 3 {
+		t.Errorf("resultCounts = %d/%d/%d, want 1/1/3", ok, skipped, failed)
+	}
+}
+
+func TestResultsReport(t *testing.T) {
+	jv := jobView{id: 7, kind: "download", desc: "4 obj → /dl", status: jobFailed,
+		start: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}
+	report := resultsReport(jv, sampleResults())
+	lines := strings.Split(strings.TrimSuffix(report, "\n"), "\n")
+
+	if !strings.HasPrefix(lines[0], "# job 7: download") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if lines[2] != "# ok: 1, skipped: 1, failed: 3" {
+		t.Errorf("counts line = %q", lines[2])
+	}
+	// Failures first, then skips, then successes; each line tab-separated.
+	body := lines[3:]
+	want := []string{
+		"failed\tb.txt\taccess denied",
+		"failed\td.txt\ttimeout",
+		"failed\tb.txt\taccess denied",
+		"skipped\tc.txt\texists locally: /dl/c.txt",
+		"ok\ta.txt\t",
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("report body:\n%q\nwant:\n%q", body, want)
+	}
+}
+
+func TestResultsFileName(t *testing.T) {
+	got := resultsFileName(3, time.Date(2026, 10, 18, 14, 5, 9, 0, time.UTC))
+	if got != "s3duck-job-3-20261018-140509.tsv" {
+		t.Errorf("resultsFileName = %q", got)
+	}
+}
+
+func TestItemOutcomeString(t *testing.T) {
+	for o, want := range map[itemOutcome]string{itemOK: "ok", itemSkipped: "skipped", itemFailed: "failed"} {
+		if got := o.String(); got != want {
+			t.Errorf("itemOutcome(%d).String() = %q, want %q", int(o), got, want)
+		}
+	}
+}
+
+func TestRetryTargets(t *testing.T) {
+	all := []model.DownloadTarget{{Key: "a", Size: 1}, {Key: "b", Size: 2}, {Key: "c", Size: 4}}
+	got, total := retryTargets(all, []string{"c", "a", "missing"})
+	want := []model.DownloadTarget{{Key: "a", Size: 1}, {Key: "c", Size: 4}}
+	if !reflect.DeepEqual(got, want) || total != 5 {
+		t.Errorf("retryTargets = %v (%d bytes), want %v (5 bytes)", got, total, want)
+	}
+}
+
+func TestRetrySyncOps(t *testing.T) {
+	ops := []syncOp{
+		{Kind: syncCreate, Rel: "a", Bytes: 1},
+		{Kind: syncUpdate, Rel: "b", Bytes: 2},
+		{Kind: syncDelete, Rel: "c"},
+	}
+	got := retrySyncOps(ops, []string{"c", "a"})
+	if len(got) != 2 || got[0].Rel != "a" || got[1].Rel != "c" {
+		t.Errorf("retrySyncOps = %v, want a then c in plan order", got)
+	}
+}
+
+func TestRetryCrossItems(t *testing.T) {
+	ops := []crossOp{
+		{SrcKey: "p/x.bin", DstKey: "q/x.bin", Size: 10},
+		{SrcKey: "p/sub/y.bin", DstKey: "q/sub/y.bin", Size: 20},
+	}
+	got := retryCrossItems(ops, []string{"p/sub/y.bin"})
+	want := []copyMoveItem{{shortName: "y.bin", srcKey: "p/sub/y.bin", size: 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("retryCrossItems = %+v, want %+v", got, want)
+	}
+}
+
+func TestRetryUploads(t *testing.T) {
+	files := []model.UploadTarget{{RemotePath: "p/a", Size: 1}, {RemotePath: "p/b", Size: 2}, {RemotePath: "p/c", Size: 4}}
+	got, total, skip := retryUploads(files, map[string]bool{"p/kept": true}, []string{"p/c", "p/a"})
+	want := []model.UploadTarget{{RemotePath: "p/a", Size: 1}, {RemotePath: "p/c", Size: 4}}
+	if !reflect.DeepEqual(got, want) || total != 5 {
+		t.Errorf("retryUploads = %v (%d bytes), want %v (5 bytes)", got, total, want)
+	}
+	if wantSkip := map[string]bool{"p/kept": true, "p/b": true}; !reflect.DeepEqual(skip, wantSkip) {
+		t.Errorf("skip = %v, want %v", skip, wantSkip)
+	}
+}
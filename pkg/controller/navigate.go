@@ -0,0 +1,373 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// navBackend is what navigation needs from a profile's client: the bucket
+// list, the client for a bucket's region, and a pager over one level of a
+// prefix. Listings take the navigation's context, cancelled once a newer
+// navigation of the same pane supersedes it.
+// The controller talks to the model through modelNav; tests substitute a
+// scripted backend so they can hold a listing open while they press Tab.
+type navBackend interface {
+	buckets(ctx context.Context, mdl *model.Model) ([]*model.Object, error)
+	enterBucket(mdl *model.Model, name string) (*model.Model, error)
+	pager(mdl *model.Model, bucket *model.Object, path string) (listPager, error)
+}
+
+// modelNav is the production navBackend.
+type modelNav struct{}
+
+func (modelNav) buckets(ctx context.Context, mdl *model.Model) ([]*model.Object, error) {
+	return mdl.ListBuckets(ctx)
+}
+
+func (modelNav) enterBucket(mdl *model.Model, name string) (*model.Model, error) {
+	return mdl.ForBucket(name)
+}
+
+func (modelNav) pager(mdl *model.Model, bucket *model.Object, path string) (listPager, error) {
+	return mdl.NewListPager(path, bucket)
+}
+
+func (c *Controller) backend() navBackend {
+	if c.nav != nil {
+		return c.nav
+	}
+	return modelNav{}
+}
+
+// navRequest is one navigation, fixed when it is issued on the UI goroutine:
+// the pane it belongs to, the client it lists through, where it goes and the
+// cursor to restore on arrival. Nothing about the pane changes until its
+// listing returns; applyNav then installs client, location and listing in one
+// step — or drops them, if a newer navigation of the same pane started
+// meanwhile. Entering a bucket switches the pane to the profile's client for
+// that bucket's region instead of re-pinning the one it had, so whatever
+// already holds the old client (a transfer, the clipboard) keeps it intact.
+type navRequest struct {
+	pane int
+	seq  uint64
+	// mdl is the client the request lists through: the pane's own, unless the
+	// request brings one (opening an inventory report).
+	mdl *model.Model
+	// ctx is cancelled (by stop) when the request is superseded; nil for a
+	// refresh, which is never cancelled (it is dropped on arrival instead).
+	ctx  context.Context
+	stop context.CancelFunc
+
+	target location
+	// bucketName, when set, is resolved against a fresh bucket list into
+	// target.bucket (entering a bucket by name: Down, jumpTo).
+	bucketName string
+	// enter resolves the client for target.bucket's region first.
+	enter   bool
+	restore string
+	// refresh marks a re-listing of the location the pane already shows. It
+	// does not supersede navigations, and is itself dropped if the pane has
+	// moved on by the time it returns.
+	refresh bool
+	// minObjs is how much of a paged listing to re-read before landing: a
+	// refresh re-reads as far as the user had scrolled, a navigation lands
+	// with the first page.
+	minObjs int
+}
+
+// objectMap indexes a listing by objKey.
+func objectMap(list []*model.Object) map[string]*model.Object {
+	out := make(map[string]*model.Object, len(list))
+	for _, o := range list {
+		out[objKey(o)] = o
+	}
+	return out
+}
+
+// findBucket returns the bucket named name from list, or nil.
+func findBucket(list []*model.Object, name string) *model.Object {
+	for _, b := range list {
+		if b != nil && b.Key != nil && *b.Key == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// sameLocation reports whether two locations name the same bucket and prefix.
+// Buckets compare by name: a re-listed bucket list yields new objects.
+func sameLocation(a, b location) bool {
+	if (a.bucket == nil) != (b.bucket == nil) {
+		return false
+	}
+	if a.bucket != nil && (a.bucket.Key == nil || b.bucket.Key == nil || *a.bucket.Key != *b.bucket.Key) {
+		return false
+	}
+	return a.path == b.path
+}
+
+// navBase is where the active pane is, or is heading: the target of its
+// in-flight navigation if there is one, else its current location. Relative
+// moves and history build on it, so a quick second Backspace climbs from
+// where the first one is going rather than repeating it. UI goroutine.
+func (c *Controller) navBase() location {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if w := c.navWant[c.active]; w != nil {
+		return *w
+	}
+	return location{bucket: c.currentBucket, path: c.currentPath}
+}
+
+// shownLocation is the location the active pane's listing shows. UI goroutine.
+func (c *Controller) shownLocation() location {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return location{bucket: c.currentBucket, path: c.currentPath}
+}
+
+// navigate issues req for the active pane, superseding any navigation of that
+// pane still in flight. UI goroutine.
+func (c *Controller) navigate(req navRequest) {
+	c.clearFilterUI() // a filter is scoped to one listing; reset on navigation
+	c.view.Details.Clear()
+	c.previewObject(nil)
+
+	want := req.target
+	if req.bucketName != "" {
+		name := req.bucketName
+		want.bucket = &model.Object{Key: &name, Ot: model.Bucket}
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	c.mu.Lock()
+	req.pane = c.active
+	if req.mdl == nil {
+		req.mdl = c.model
+	}
+	req.ctx, req.stop = ctx, stop
+	c.cancelNavLocked(req.pane)
+	req.seq = c.navSeq[req.pane]
+	c.navWant[req.pane] = &want
+	c.navStop[req.pane] = stop
+	c.mu.Unlock()
+
+	go c.runNav(req)
+}
+
+// cancelNavLocked abandons pane's in-flight navigation, if any: its listing
+// stops paging and its result will be dropped. The page fetch of the listing
+// it would replace is stopped too. Used when a newer navigation supersedes it,
+// on Esc, and when the pane is reset under it (profile change). Callers hold
+// mu.
+func (c *Controller) cancelNavLocked(pane int) {
+	c.navSeq[pane]++
+	c.navWant[pane] = nil
+	if stop := c.navStop[pane]; stop != nil {
+		stop()
+		c.navStop[pane] = nil
+	}
+	c.stopPageLocked(pane)
+}
+
+// runNav performs req's network calls off the UI goroutine and hands the
+// result to applyNav. Errors are reported only while req is still current;
+// a superseded navigation fails silently, its successor already under way.
+func (c *Controller) runNav(req navRequest) error {
+	be := c.backend()
+	ctx := req.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	bucket := req.target.bucket
+	var buckets []*model.Object
+
+	// A location visited within the profile's cache TTL lands at once with
+	// the listing it had; it is then re-listed like a refresh.
+	var cached *model.CachedListing
+	if !req.refresh && req.mdl != nil {
+		name := req.bucketName
+		if bucket != nil {
+			name = *bucket.Key
+		}
+		if cl, ok := req.mdl.CachedList(name, req.target.path); name != "" && ok {
+			cached, bucket, req.bucketName = &cl, cl.Bucket, ""
+		}
+	}
+
+	if req.bucketName != "" {
+		list, err := be.buckets(ctx, req.mdl)
+		if err != nil {
+			c.navFailed(req, "Failed to list buckets", err)
+			return err
+		}
+		buckets = list
+		if bucket = findBucket(list, req.bucketName); bucket == nil {
+			err := fmt.Errorf("bucket %q not found", req.bucketName)
+			c.navFailed(req, "Jump", err)
+			return err
+		}
+	}
+	if bucket != nil && req.enter {
+		// Fail-safe: on error enterBucket hands back the client it was given.
+		mdl, err := be.enterBucket(req.mdl, *bucket.Key)
+		if err != nil && c.navCurrent(req) {
+			c.error("Failed to resolve bucket region", err)
+		}
+		if mdl != nil {
+			req.mdl = mdl
+		}
+	} else if bucket == nil && req.mdl != nil {
+		req.mdl = req.mdl.Home()
+	}
+
+	if cached != nil {
+		if !c.landCached(req, cached) {
+			return nil
+		}
+		req.refresh, req.minObjs = true, len(cached.Objects)
+		req.target.bucket = bucket
+	}
+
+	var list []*model.Object
+	var pl *pagedListing
+	var err error
+	epoch := req.mdl.ListingEpoch()
+	if bucket == nil {
+		list, err = be.buckets(ctx, req.mdl)
+		buckets = list
+	} else {
+		var pg listPager
+		if pg, err = be.pager(req.mdl, bucket, req.target.path); err == nil {
+			list, err = fetchPages(ctx, pg, req.minObjs)
+		}
+		if err == nil && pg.More() {
+			pl = &pagedListing{pager: pg, more: true}
+		}
+	}
+	if err != nil {
+		c.navFailed(req, "Failed to fetch folder", err)
+		return err
+	}
+	if bucket != nil {
+		req.mdl.CacheList(epoch, bucket, req.target.path, list, pl != nil)
+	}
+
+	objs := objectMap(list)
+	applied := make(chan bool, 1)
+	c.view.App.QueueUpdateDraw(func() {
+		applied <- c.applyNav(req, bucket, objs, buckets, pl)
+	})
+	if <-applied {
+		c.renderList()
+	}
+	return nil
+}
+
+// landCached applies a cached listing for req and reports whether it landed.
+// The listing has no pager: it shows as cached, and refreshing until req's
+// own fetch replaces it. Esc stops that fetch through the listing, the same
+// as a page fetch.
+func (c *Controller) landCached(req navRequest, cl *model.CachedListing) bool {
+	pl := &pagedListing{more: cl.More, stop: req.stop}
+	objs := objectMap(cl.Objects)
+	applied := make(chan bool, 1)
+	c.view.App.QueueUpdateDraw(func() {
+		applied <- c.applyNav(req, cl.Bucket, objs, nil, pl)
+	})
+	if !<-applied {
+		return false
+	}
+	c.renderList()
+	return true
+}
+
+// navCurrent reports whether req is still its pane's latest navigation.
+func (c *Controller) navCurrent(req navRequest) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.navSeq[req.pane] == req.seq
+}
+
+// navFailed ends a failed navigation: the pane stays where it was, and the
+// error is shown unless a newer navigation already replaced this one.
+func (c *Controller) navFailed(req navRequest, header string, err error) {
+	c.mu.Lock()
+	current := c.navSeq[req.pane] == req.seq
+	if current && !req.refresh {
+		c.navWant[req.pane] = nil
+	}
+	c.mu.Unlock()
+	if current && !errors.Is(err, context.Canceled) {
+		c.error(header, err)
+	}
+}
+
+// applyNav installs a finished listing into the pane req was issued for —
+// the live fields when that pane is still active, its snapshot otherwise —
+// along with pl, the rest of it still to fetch (nil when complete), and
+// reports whether the active pane changed and needs rendering. A result
+// from a superseded navigation, or a refresh of a location the pane has since
+// left, is dropped. Runs on the UI goroutine, so it is atomic with respect to
+// Tab and every key handler; mu is taken for the background readers.
+func (c *Controller) applyNav(req navRequest, bucket *model.Object, objs map[string]*model.Object, buckets []*model.Object, pl *pagedListing) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.navSeq[req.pane] != req.seq {
+		return false
+	}
+	active := req.pane == c.active
+
+	if req.refresh {
+		cur := location{bucket: c.currentBucket, path: c.currentPath}
+		if !active {
+			cur = location{bucket: c.panes[req.pane].currentBucket, path: c.panes[req.pane].currentPath}
+		}
+		if c.navWant[req.pane] != nil || !sameLocation(cur, req.target) {
+			return false
+		}
+	} else {
+		c.navWant[req.pane] = nil
+	}
+	c.stopPageLocked(req.pane)
+	c.listing[req.pane] = pl
+
+	if active {
+		if req.mdl != nil {
+			c.model = req.mdl
+		}
+		c.currentBucket = bucket
+		c.currentPath = req.target.path
+		c.objs = objs
+		if buckets != nil {
+			c.buckets = buckets
+		}
+		if !req.refresh {
+			c.restoreNext = req.restore
+		}
+		return true
+	}
+	p := &c.panes[req.pane]
+	if req.mdl != nil {
+		p.model = req.mdl
+	}
+	p.currentBucket = bucket
+	p.currentPath = req.target.path
+	p.objs = objs
+	if buckets != nil {
+		p.buckets = buckets
+	}
+	if !req.refresh {
+		p.restoreNext = req.restore
+	}
+	return false
+}
+
+// setRestore sets the cursor target for the active pane's next render.
+func (c *Controller) setRestore(key string) {
+	c.mu.Lock()
+	c.restoreNext = key
+	c.mu.Unlock()
+}
@@ -0,0 +1,491 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+	"github.com/nexusriot/s3duck-tui/pkg/view"
+)
+
+// scriptedNav is a navBackend whose listings can be held open: a listing of
+// "bucket/path" (its page n > 1: "bucket/path#n") blocks while a gate is set
+// for it, or until it is cancelled. Every bucket lists one
+// object named after itself, so a landed listing shows where it came from.
+type scriptedNav struct {
+	mu      sync.Mutex
+	names   []string
+	gates   map[string]chan struct{}
+	started map[string]chan struct{}
+	jitter  bool
+	// regional, when set, is the client entering each bucket resolves to.
+	regional map[string]*model.Model
+	// aborted collects the locations whose held listing was cancelled.
+	aborted []string
+	// pages is how many pages "bucket/path" lists in; 1 when unset.
+	pages map[string]int
+}
+
+func newScriptedNav(names ...string) *scriptedNav {
+	return &scriptedNav{names: names, gates: map[string]chan struct{}{}, started: map[string]chan struct{}{}}
+}
+
+// hold makes the next listings of loc block until the returned release is
+// called, and returns a channel closed once one of them has started.
+func (s *scriptedNav) hold(loc string) (started <-chan struct{}, release func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gate, st := make(chan struct{}), make(chan struct{})
+	s.gates[loc], s.started[loc] = gate, st
+	var once sync.Once
+	return st, func() { once.Do(func() { close(gate) }) }
+}
+
+func (s *scriptedNav) buckets(context.Context, *model.Model) ([]*model.Object, error) {
+	out := make([]*model.Object, 0, len(s.names))
+	for _, n := range s.names {
+		out = append(out, &model.Object{Key: strptr(n), Ot: model.Bucket})
+	}
+	return out, nil
+}
+
+func (s *scriptedNav) enterBucket(mdl *model.Model, name string) (*model.Model, error) {
+	if w, ok := s.regional[name]; ok {
+		return w, nil
+	}
+	return mdl, nil
+}
+
+func (s *scriptedNav) pager(_ *model.Model, bucket *model.Object, path string) (listPager, error) {
+	s.mu.Lock()
+	pages := s.pages[*bucket.Key+"/"+path]
+	s.mu.Unlock()
+	if pages == 0 {
+		pages = 1
+	}
+	return &scriptedPager{s: s, bucket: *bucket.Key, path: path, pages: pages}, nil
+}
+
+// scriptedPager pages a scriptedNav listing. Its first page is the object
+// named after the bucket; later pages hold one "<bucket>-<n>" object each.
+type scriptedPager struct {
+	s            *scriptedNav
+	bucket, path string
+	pages, next  int
+}
+
+func (p *scriptedPager) More() bool { return p.next < p.pages }
+
+func (p *scriptedPager) Next(ctx context.Context) ([]*model.Object, error) {
+	s := p.s
+	loc := p.bucket + "/" + p.path
+	if p.next > 0 {
+		loc += fmt.Sprintf("#%d", p.next+1)
+	}
+	s.mu.Lock()
+	gate, st := s.gates[loc], s.started[loc]
+	jitter := s.jitter
+	s.mu.Unlock()
+	if st != nil {
+		select {
+		case <-st:
+		default:
+			close(st)
+		}
+	}
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.aborted = append(s.aborted, loc)
+			s.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+	if jitter {
+		time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+	}
+	p.next++
+	key := p.bucket
+	if p.next > 1 {
+		key = fmt.Sprintf("%s-%d", p.bucket, p.next)
+	}
+	full := p.bucket + "/" + p.path + key
+	return []*model.Object{{Key: &key, FullPath: &full, Ot: model.File}}, nil
+}
+
+// newNavController returns a controller browsing the buckets screen through
+// be, with its tview app running on a simulated screen so queued UI updates
+// really run on a separate UI goroutine, as they do in the program.
+func newNavController(t *testing.T, be navBackend) *Controller {
+	t.Helper()
+	c := &Controller{
+		view:            view.NewView(),
+		model:           &model.Model{},
+		selectedByScope: make(map[string]map[string]bool),
+		nav:             be,
+		browsing:        true,
+	}
+	c.panes[1].selectedByScope = make(map[string]map[string]bool)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.SetSize(120, 40)
+	c.view.App.SetScreen(screen)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.view.App.Run()
+	}()
+	t.Cleanup(func() {
+		c.view.App.Stop()
+		<-done
+	})
+	return c
+}
+
+// onUI runs fn on the UI goroutine and waits for it, the way a key press
+// would run its handler.
+func onUI(t *testing.T, c *Controller, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	c.view.App.QueueUpdate(func() {
+		fn()
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("UI goroutine did not run the update")
+	}
+}
+
+// waitFor polls cond on the UI goroutine until it holds.
+func waitFor(t *testing.T, c *Controller, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var ok bool
+		onUI(t, c, func() { ok = cond() })
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func bucketName(b *model.Object) string {
+	if b == nil || b.Key == nil {
+		return ""
+	}
+	return *b.Key
+}
+
+// settled reports that neither pane has a navigation in flight.
+func settled(c *Controller) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.navWant[0] == nil && c.navWant[1] == nil
+}
+
+func TestSameLocation(t *testing.T) {
+	a, a2, b := obj("a"), obj("a"), obj("b")
+	cases := []struct {
+		x, y location
+		want bool
+	}{
+		{location{}, location{}, true},
+		{location{bucket: a, path: "p/"}, location{bucket: a2, path: "p/"}, true},
+		{location{bucket: a, path: "p/"}, location{bucket: a, path: "q/"}, false},
+		{location{bucket: a}, location{bucket: b}, false},
+		{location{bucket: a}, location{}, false},
+	}
+	for i, tc := range cases {
+		if got := sameLocation(tc.x, tc.y); got != tc.want {
+			t.Errorf("case %d: got %v, want %v", i, got, tc.want)
+		}
+	}
+}
+
+func TestApplyNavDropsSuperseded(t *testing.T) {
+	c := &Controller{view: view.NewView()}
+	b1, b2 := obj("one"), obj("two")
+
+	c.navSeq[0] = 2
+	c.navWant[0] = &location{bucket: b2}
+	stale := navRequest{pane: 0, seq: 1, target: location{bucket: b1}}
+	if c.applyNav(stale, b1, map[string]*model.Object{}, nil, nil) || c.currentBucket != nil {
+		t.Fatalf("a superseded navigation was applied")
+	}
+
+	latest := navRequest{pane: 0, seq: 2, target: location{bucket: b2, path: "p/"}, restore: ".."}
+	if !c.applyNav(latest, b2, map[string]*model.Object{}, nil, nil) {
+		t.Fatalf("the latest navigation was dropped")
+	}
+	if c.currentBucket != b2 || c.currentPath != "p/" || c.restoreNext != ".." || c.navWant[0] != nil {
+		t.Errorf("live state = %v %q %q want=%v", bucketName(c.currentBucket), c.currentPath, c.restoreNext, c.navWant[0])
+	}
+}
+
+func TestApplyNavTargetsInactivePane(t *testing.T) {
+	c := &Controller{view: view.NewView()}
+	c.active = 0
+	c.navSeq[1] = 1
+	b := obj("other")
+	req := navRequest{pane: 1, seq: 1, target: location{bucket: b, path: "x/"}}
+	if c.applyNav(req, b, map[string]*model.Object{"k": nil}, nil, nil) {
+		t.Errorf("an inactive pane's navigation asked for a render")
+	}
+	if c.currentBucket != nil {
+		t.Errorf("the active pane moved")
+	}
+	if p := c.panes[1]; p.currentBucket != b || p.currentPath != "x/" || len(p.objs) != 1 {
+		t.Errorf("pane 1 = %+v", p)
+	}
+}
+
+func TestApplyNavDropsRefreshOfLeftLocation(t *testing.T) {
+	c := &Controller{view: view.NewView()}
+	b := obj("b")
+	c.currentBucket, c.currentPath = b, "new/"
+	refresh := navRequest{pane: 0, seq: 0, target: location{bucket: b, path: "old/"}, refresh: true}
+	if c.applyNav(refresh, b, map[string]*model.Object{"stale": nil}, nil, nil) {
+		t.Errorf("a refresh of a location the pane left was applied")
+	}
+	if _, ok := c.objs["stale"]; ok {
+		t.Errorf("stale objects installed")
+	}
+}
+
+// Enter on a slow bucket, Tab before it lands, Enter in the other pane: each
+// listing must land in the pane it was issued from. This is the race the old
+// in-place navigation lost.
+func TestNavigationLandsInIssuingPane(t *testing.T) {
+	be := newScriptedNav("slow", "fast")
+	c := newNavController(t, be)
+
+	onUI(t, c, c.ToggleDualPane) // pane 1 active, on the buckets screen
+	waitFor(t, c, "pane 1 listing", func() bool { return settled(c) && len(c.objs) == 2 })
+
+	started, release := be.hold("slow/")
+	defer release()
+	onUI(t, c, func() { c.Down("slow") })
+	<-started
+
+	onUI(t, c, c.swapAndFocus) // Tab: pane 0 active
+	onUI(t, c, func() { c.Down("fast") })
+	waitFor(t, c, "fast to land in pane 0", func() bool { return bucketName(c.currentBucket) == "fast" })
+
+	release()
+	waitFor(t, c, "slow to land in pane 1", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return bucketName(c.panes[1].currentBucket) == "slow"
+	})
+	onUI(t, c, func() {
+		if c.active != 0 || bucketName(c.currentBucket) != "fast" {
+			t.Errorf("pane 0 = active %d bucket %q, want active 0 on fast", c.active, bucketName(c.currentBucket))
+		}
+		if _, ok := c.panes[1].objs["slow/slow"]; !ok {
+			t.Errorf("pane 1 objects = %v, want the slow listing", c.panes[1].objs)
+		}
+	})
+}
+
+// A navigation overtaken by a newer one in the same pane is discarded when it
+// finally returns.
+func TestSupersededNavigationDiscarded(t *testing.T) {
+	be := newScriptedNav("slow", "fast")
+	c := newNavController(t, be)
+	onUI(t, c, func() { go c.updateList() })
+	waitFor(t, c, "bucket list", func() bool { return len(c.objs) == 2 })
+
+	started, release := be.hold("slow/")
+	onUI(t, c, func() { c.Down("slow") })
+	<-started
+	onUI(t, c, func() { c.Down("fast") })
+	waitFor(t, c, "fast to land", func() bool { return bucketName(c.currentBucket) == "fast" && settled(c) })
+
+	release()
+	// Nothing signals a dropped result, so watch for a while: slow must never
+	// replace fast.
+	for end := time.Now().Add(100 * time.Millisecond); time.Now().Before(end); {
+		onUI(t, c, func() {
+			if bucketName(c.currentBucket) != "fast" {
+				t.Fatalf("superseded navigation applied: now on %q", bucketName(c.currentBucket))
+			}
+		})
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// A superseded navigation stops listing at once rather than paging on to a
+// result that will only be dropped.
+func TestSupersededNavigationCancelsListing(t *testing.T) {
+	be := newScriptedNav("slow", "fast")
+	c := newNavController(t, be)
+	onUI(t, c, func() { go c.updateList() })
+	waitFor(t, c, "bucket list", func() bool { return len(c.objs) == 2 })
+
+	started, release := be.hold("slow/")
+	defer release()
+	onUI(t, c, func() { c.Down("slow") })
+	<-started
+	onUI(t, c, func() { c.Down("fast") })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		be.mu.Lock()
+		n := len(be.aborted)
+		be.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the superseded listing was never cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	waitFor(t, c, "fast to land", func() bool { return bucketName(c.currentBucket) == "fast" && settled(c) })
+}
+
+// Random interleavings of Enter (jumpTo) and Tab with jittery listings. Run
+// under -race: besides the final positions, the detector checks that nothing
+// touches navigation state off the UI goroutine without mu.
+func TestConcurrentTabAndEnter(t *testing.T) {
+	names := []string{"a", "b", "c", "d"}
+	be := newScriptedNav(names...)
+	be.jitter = true
+	c := newNavController(t, be)
+	onUI(t, c, c.ToggleDualPane)
+
+	rng := rand.New(rand.NewSource(1))
+	var last [2]string
+	for i := 0; i < 60; i++ {
+		name := names[rng.Intn(len(names))]
+		onUI(t, c, func() {
+			last[c.active] = name
+			c.jumpTo(name, "", "")
+		})
+		if rng.Intn(2) == 0 {
+			onUI(t, c, c.swapAndFocus)
+		}
+	}
+	waitFor(t, c, "navigations to settle", func() bool { return settled(c) })
+	onUI(t, c, func() {
+		got := [2]string{}
+		got[c.active] = bucketName(c.currentBucket)
+		got[1-c.active] = bucketName(c.panes[1-c.active].currentBucket)
+		if got != last {
+			t.Errorf("panes ended on %v, want %v", got, last)
+		}
+	})
+}
+
+// Entering a bucket switches the pane to that bucket's client without touching
+// the one it had — a transfer started through it keeps its region — and the
+// bucket list goes back to the profile's own client.
+func TestEnteringBucketSwitchesPaneClient(t *testing.T) {
+	home, err := model.NewModel(model.NewConfig("https://s3.us-east-1.amazonaws.com", strptr("us-east-1"), "ak", "sk", "", true, 0))
+	if err != nil {
+		t.Fatalf("NewModel: %v", err)
+	}
+	eu, err := home.ForRegion("eu-west-1")
+	if err != nil {
+		t.Fatalf("ForRegion: %v", err)
+	}
+	be := newScriptedNav("logs")
+	be.regional = map[string]*model.Model{"logs": eu}
+	c := newNavController(t, be)
+	onUI(t, c, func() { c.model = home })
+
+	held := home // what a transfer started on the bucket list would hold
+	onUI(t, c, func() { c.jumpTo("logs", "", "") })
+	waitFor(t, c, "logs to land", func() bool { return bucketName(c.currentBucket) == "logs" && settled(c) })
+	onUI(t, c, func() {
+		if c.model != eu {
+			t.Errorf("pane client = %p, want the bucket's regional client %p", c.model, eu)
+		}
+	})
+	if got := *held.Cf.Region; got != "us-east-1" {
+		t.Errorf("client held from before was re-pinned to %q", got)
+	}
+
+	onUI(t, c, c.Up)
+	waitFor(t, c, "bucket list", func() bool { return c.currentBucket == nil && settled(c) })
+	onUI(t, c, func() {
+		if c.model != home {
+			t.Errorf("bucket list client = %p, want the profile's own %p", c.model, home)
+		}
+	})
+}
+
+// Revisiting a folder lands at once with its cached listing, then the
+// re-listing replaces it; Esc stops that re-listing and keeps the cached one.
+func TestRevisitLandsCachedListing(t *testing.T) {
+	cf := model.NewConfig("http://s3.test", strptr("us-east-1"), "ak", "sk", "", false, 0)
+	cf.ListCacheTTL = time.Hour
+	mdl, err := model.NewModel(cf)
+	if err != nil {
+		t.Fatalf("NewModel: %v", err)
+	}
+	be := newScriptedNav("b")
+	c := newNavController(t, be)
+	onUI(t, c, func() { c.model = mdl })
+
+	visit := func(path string) {
+		onUI(t, c, func() { c.jumpTo("b", path, "") })
+	}
+	visit("p/")
+	waitFor(t, c, "first visit", func() bool { return c.currentPath == "p/" && settled(c) })
+	visit("")
+	waitFor(t, c, "bucket root", func() bool { return c.currentPath == "" && settled(c) })
+
+	started, release := be.hold("b/p/")
+	defer release()
+	visit("p/")
+	waitFor(t, c, "the cached listing", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, ok := c.objs["b/p/b"]
+		return c.currentPath == "p/" && ok && c.listing[0] != nil && c.listing[0].pager == nil
+	})
+	<-started
+	release()
+	waitFor(t, c, "the re-listing", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.listing[0] == nil
+	})
+
+	visit("")
+	waitFor(t, c, "bucket root", func() bool { return c.currentPath == "" && settled(c) })
+	started, release = be.hold("b/p/")
+	defer release()
+	visit("p/")
+	<-started
+	onUI(t, c, func() {
+		if !c.StopListing() {
+			t.Error("Esc found nothing to stop while re-listing")
+		}
+	})
+	waitFor(t, c, "the re-listing to stop", func() bool {
+		be.mu.Lock()
+		defer be.mu.Unlock()
+		return len(be.aborted) == 1
+	})
+	onUI(t, c, func() {
+		if c.currentPath != "p/" || len(c.objs) != 1 || c.listing[0] == nil || c.listing[0].stop != nil {
+			t.Errorf("after Esc: at %q with %d objects; want the cached listing kept", c.currentPath, len(c.objs))
+		}
+	})
+}
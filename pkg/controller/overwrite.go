@@ -43,16 +43,16 @@ func overwriteText(title string, conflicts []string, candidates int) string {
 //
 // plan runs off the UI goroutine (it may list a folder) and returns the exact
 // destination keys. proceed then runs on the UI goroutine with the keys to
-// leave alone: nil means overwrite everything. Cancelling never calls proceed.
+// leave alone: nil means overwrite everything. Cancelling — the check itself
+// or the question — never calls proceed.
 func (c *Controller) confirmOverwrites(
 	mdl *model.Model,
 	bucket *model.Object,
 	title string,
-	plan func() ([]string, error),
+	plan func(ctx context.Context) ([]string, error),
 	proceed func(skip map[string]bool),
 ) {
-	scanning := tview.NewModal().SetText("Checking the destination...")
-	c.view.Pages.AddPage("progress", scanning, true, true)
+	_, ctx, cancel := c.scanModal("progress", "Checking the destination...")
 
 	// fail reports and stops. A destination that can't be checked is not a
 	// destination that may be silently overwritten, so there is no fallthrough
@@ -63,14 +63,21 @@ func (c *Controller) confirmOverwrites(
 	}
 
 	go func() {
-		keys, err := plan()
+		defer cancel()
+		keys, err := plan(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			// A planning failure is about the operation itself (an impossible
 			// folder copy, an unreadable source), not about the destination.
 			fail(err)
 			return
 		}
-		conflicts, err := mdl.Conflicts(context.Background(), bucket, keys)
+		conflicts, err := mdl.Conflicts(ctx, bucket, keys)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			fail(fmt.Errorf("checking the destination: %w", err))
 			return
@@ -157,15 +164,15 @@ func (c *Controller) askOverwriteBlocking(title string, conflicts []string, cand
 
 // plannedCopyKeys is the plan function for a copy/move/rename: the exact
 // destination keys of every item, folders expanded at the source.
-func plannedCopyKeys(mdl *model.Model, srcBucket, dstBucket *model.Object, items []copyMoveItem, dstPrefix string) func() ([]string, error) {
-	return func() ([]string, error) {
+func plannedCopyKeys(mdl *model.Model, srcBucket, dstBucket *model.Object, items []copyMoveItem, dstPrefix string) func(context.Context) ([]string, error) {
+	return func(ctx context.Context) ([]string, error) {
 		var keys []string
 		for _, it := range items {
 			dstKey := dstPrefix + it.shortName
 			if it.isFolder {
 				dstKey += "/"
 			}
-			planned, err := mdl.PlannedCopyKeys(srcBucket, dstBucket, it.srcKey, dstKey, it.isFolder)
+			planned, err := mdl.PlannedCopyKeys(ctx, srcBucket, dstBucket, it.srcKey, dstKey, it.isFolder)
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// packName is the default name, without extension, of an archive of the
+// items names in srcPath of bucket: the item's own name for one, otherwise
+// the folder's, or the bucket's at its top.
+func packName(names []string, srcPath, bucket string) string {
+	name := ""
+	if len(names) == 1 {
+		name = path.Base(strings.TrimSuffix(names[0], "/"))
+	} else if p := strings.Trim(srcPath, "/"); p != "" {
+		name = path.Base(p)
+	} else {
+		name = bucket
+	}
+	if name == "" || name == "." || name == "/" {
+		return "download"
+	}
+	return name
+}
+
+// askDownloadAs asks whether n objects of total bytes download as files, as
+// they are laid out remotely, or into one zip or tar.gz, and calls proceed
+// with "" or the archive format. UI goroutine.
+func (c *Controller) askDownloadAs(n int, total int64, proceed func(format string)) {
+	m := tview.NewModal().
+		SetText(fmt.Sprintf("Download %d object(s), %s, as:", n, humanize.IBytes(uint64(total)))).
+		AddButtons([]string{"Files", "Zip", "Tar.gz", "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			c.view.Pages.RemovePage("download-as")
+			switch label {
+			case "Files":
+				proceed("")
+			case "Zip":
+				proceed(model.ArchiveZip)
+			case "Tar.gz":
+				proceed(model.ArchiveTarGz)
+			}
+		})
+	c.view.Pages.AddPage("download-as", m, true, true)
+	c.view.App.SetFocus(m)
+}
+
+// runPackDownload asks for the archive's file name in cwd, then streams the
+// objects into it (model.WriteArchive) as a transfer job, behind the same
+// Background / Cancel progress modal as a download. UI goroutine.
+func (c *Controller) runPackDownload(mdl *model.Model, srcBucket *model.Object, srcPath string, objs []model.DownloadTarget, totalSize int64, cwd, name, format string) {
+	form := tview.NewForm()
+	form.SetTitle(fmt.Sprintf("%d object(s), %s, into a %s in %s", len(objs), humanize.IBytes(uint64(totalSize)), format, cwd))
+	form.AddInputField("File name", name, 50, nil, nil)
+	form.SetBorder(true)
+	closeForm := func() { c.view.Pages.RemovePage("modal") }
+	form.AddButton("Create", func() {
+		name := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		closeForm()
+		if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+			go c.error("Download", fmt.Errorf("%q is not a file name", name))
+			return
+		}
+		dest := filepath.Join(cwd, name)
+		start := func() { c.startPackDownload(mdl, srcBucket, srcPath, objs, totalSize, dest, format) }
+		if _, err := os.Stat(dest); err != nil {
+			start()
+			return
+		}
+		confirm := c.view.NewConfirm()
+		confirm.SetText(fmt.Sprintf("%s already exists.\n\nReplace it once the archive is complete?", dest)).
+			SetDoneFunc(func(_ int, label string) {
+				c.view.Pages.RemovePage("confirm")
+				if label == "OK" {
+					start()
+				}
+			})
+		c.view.Pages.AddPage("confirm", confirm, true, true)
+	})
+	form.AddButton("Cancel", closeForm)
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			closeForm()
+		}
+		return event
+	})
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 90, 7), true, true)
+}
+
+func (c *Controller) startPackDownload(mdl *model.Model, srcBucket *model.Object, srcPath string, objs []model.DownloadTarget, totalSize int64, dest, format string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := c.addJob("download", fmt.Sprintf("%d obj → %s", len(objs), dest), totalSize, len(objs), cancel)
+	// An archive is all or nothing, so its one item is the archive and a
+	// retry writes it again whole.
+	job.setRetry(func([]string) {
+		c.startPackDownload(mdl, srcBucket, srcPath, objs, totalSize, dest, format)
+	})
+	progress := tview.NewModal().
+		SetText("Starting archive...\n").
+		AddButtons([]string{"Background", "Cancel"})
+	progress.SetDoneFunc(func(_ int, label string) {
+		switch label {
+		case "Background":
+			job.setBackgrounded()
+			c.view.Pages.RemovePage("progress").SwitchToPage("main")
+			c.view.App.SetFocus(c.view.List)
+		default:
+			// As in runDownload: only signal, and let the finished run turn
+			// this modal into the report.
+			cancel()
+			progress.SetText("Canceling, please wait...")
+		}
+	})
+	c.view.Pages.AddPage("progress", progress, true, true)
+
+	go func() {
+		defer cancel()
+		const throttle = 100 * time.Millisecond
+		var (
+			mu       sync.Mutex
+			lastDraw time.Time
+			started  = time.Now()
+			base     = filepath.Base(dest)
+		)
+		n, err := mdl.WriteArchive(ctx, srcBucket.Key, objs, srcPath, dest, format, func(written int64, key string) {
+			mu.Lock()
+			if time.Since(lastDraw) < throttle {
+				mu.Unlock()
+				return
+			}
+			lastDraw = time.Now()
+			mu.Unlock()
+			job.setProgress(written, 0)
+			if job.isBackgrounded() {
+				return
+			}
+			pct := 0.0
+			if totalSize > 0 {
+				pct = float64(written) / float64(totalSize) * 100
+			}
+			c.view.App.QueueUpdateDraw(func() {
+				progress.SetText(fmt.Sprintf("Archiving into %s\n%s / %s (%.1f%%)\n%s\n\n%s",
+					base, humanize.IBytes(uint64(written)), humanize.IBytes(uint64(totalSize)), pct,
+					byteRateETA(written, totalSize, time.Since(started)), path.Base(key)))
+			})
+		})
+
+		canceled := ctx.Err() != nil && err != nil
+		failed := 0
+		report := fmt.Sprintf("Archived %d object(s), %s, into\n%s", len(objs), humanize.IBytes(uint64(n)), dest)
+		switch {
+		case canceled:
+			report = "Canceled; no archive was written."
+			job.recordItem(dest, itemSkipped, "canceled")
+		case err != nil:
+			failed = 1
+			report = fmt.Sprintf("The archive was not written:\n%v", err)
+			job.recordErr(dest, err)
+		default:
+			job.recordItem(dest, itemOK, "")
+			job.setProgress(n, len(objs))
+		}
+		c.finalizeJob(job, canceled, failed)
+		if job.isBackgrounded() {
+			if err != nil && !canceled {
+				c.error("Archive download failed", err)
+			}
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			progress.ClearButtons()
+			progress.AddButtons([]string{"Done"})
+			progress.SetText(report)
+			progress.SetDoneFunc(func(_ int, _ string) {
+				c.view.Pages.RemovePage("progress").SwitchToPage("main")
+			})
+			c.view.App.SetFocus(progress)
+		})
+	}()
+}
@@ -0,0 +1,20 @@
+package controller
+
+import "testing"
+
+func TestPackName(t *testing.T) {
+	for _, tc := range []struct {
+		names         []string
+		srcPath, want string
+	}{
+		{[]string{"photos/"}, "2024/", "photos"},
+		{[]string{"report.csv"}, "", "report.csv"},
+		{[]string{"a", "b/"}, "logs/2024/", "2024"},
+		{[]string{"a", "b"}, "", "my-bucket"},
+		{[]string{"/"}, "", "download"},
+	} {
+		if got := packName(tc.names, tc.srcPath, "my-bucket"); got != tc.want {
+			t.Errorf("packName(%v, %q) = %q, want %q", tc.names, tc.srcPath, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// listPrefetchRows is how close to the end of a partly loaded listing the
+// cursor may get before the next page is fetched.
+const listPrefetchRows = 50
+
+// listPager is a folder listing fetched a page at a time (model.ListPager).
+type listPager interface {
+	More() bool
+	Next(ctx context.Context) ([]*model.Object, error)
+}
+
+// pagedListing is the part of a pane's listing still on the server: the
+// pager the rest of it comes from, whether there is more, and the fetch in
+// flight. c.mu guards every field. There is one per pane, indexed like
+// navSeq, replaced whenever a listing lands. A listing served from the
+// profile's cache has no pager, and stop cancels the re-listing that will
+// replace it.
+type pagedListing struct {
+	pager listPager
+	more  bool
+	// stop cancels the page being fetched; nil when none is.
+	stop context.CancelFunc
+}
+
+// fetchPages reads pages from pg until at least min objects are in or it runs
+// out. It always reads one page, so min 0 is "the first page".
+func fetchPages(ctx context.Context, pg listPager, min int) ([]*model.Object, error) {
+	var out []*model.Object
+	for {
+		page, err := pg.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		if !pg.More() || len(out) >= min {
+			return out, nil
+		}
+	}
+}
+
+// stopPageLocked cancels pane's in-flight page fetch, if any. The listing
+// itself stays: scrolling to the end again resumes it. Callers hold mu.
+func (c *Controller) stopPageLocked(pane int) {
+	if pl := c.listing[pane]; pl != nil && pl.stop != nil {
+		pl.stop()
+		pl.stop = nil
+	}
+}
+
+// loadMore fetches the next page of the active pane's listing, unless it is
+// complete or a page is already on its way. UI goroutine.
+func (c *Controller) loadMore() {
+	c.mu.Lock()
+	pane := c.active
+	pl := c.listing[pane]
+	if pl == nil || pl.pager == nil || !pl.more || pl.stop != nil {
+		c.mu.Unlock()
+		return
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	pl.stop = stop
+	c.mu.Unlock()
+
+	go c.renderList() // show "loading…" in the title
+	go func() {
+		page, err := pl.pager.Next(ctx)
+		more := err == nil && pl.pager.More()
+		c.view.App.QueueUpdateDraw(func() {
+			if c.applyPage(pane, pl, page, more, err) {
+				go c.renderList()
+			}
+		})
+	}()
+}
+
+// applyPage merges a fetched page into the pane it was fetched for and
+// reports whether the active pane needs rendering. A page for a listing the
+// pane has since replaced (navigation, refresh, profile change) is dropped.
+// A cancelled fetch (Esc) leaves the listing resumable; any other error is
+// shown and also leaves it resumable, so scrolling retries. UI goroutine.
+func (c *Controller) applyPage(pane int, pl *pagedListing, page []*model.Object, more bool, err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.listing[pane] != pl {
+		return false
+	}
+	pl.stop = nil
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			go c.error("Failed to fetch the next page", err)
+		}
+		return pane == c.active
+	}
+	pl.more = more
+	objs := c.objs
+	if pane != c.active {
+		objs = c.panes[pane].objs
+	}
+	if objs == nil {
+		objs = make(map[string]*model.Object, len(page))
+	}
+	for _, o := range page {
+		objs[objKey(o)] = o
+	}
+	if pane == c.active {
+		c.objs = objs
+		return true
+	}
+	c.panes[pane].objs = objs
+	return false
+}
+
+// StopListing is Esc in the browser: it abandons the active pane's navigation
+// still in flight (the pane stays where it is) and any page being fetched.
+// It reports whether there was anything to stop. UI goroutine.
+func (c *Controller) StopListing() bool {
+	c.mu.Lock()
+	pane := c.active
+	pending := c.navWant[pane] != nil
+	fetching := c.listing[pane] != nil && c.listing[pane].stop != nil
+	if pending {
+		c.cancelNavLocked(pane)
+	} else if fetching {
+		c.stopPageLocked(pane)
+	}
+	c.mu.Unlock()
+	if pending || fetching {
+		go c.renderList()
+	}
+	return pending || fetching
+}
+
+// listingNote is the list-title note for a partly loaded listing: how much is
+// in, whether a page is on its way, and — since sort and filter only see what
+// is loaded — that the order or the matches are "so far". Name order is the
+// exception: S3 lists keys in that order, so the loaded part of an ascending
+// name sort is already final. A cached listing says so, and whether it is
+// being re-listed. Empty for a complete, fresh listing.
+func listingNote(loaded int, more, loading, cached bool, key sortKey, desc, filtered bool) string {
+	var note string
+	switch {
+	case cached && loading:
+		note = "cached, refreshing…"
+	case cached:
+		note = "cached, r refreshes"
+	case !more:
+		return ""
+	case loading:
+		note = fmt.Sprintf("%d loaded, loading…", loaded)
+	default:
+		note = fmt.Sprintf("%d loaded, more ↓", loaded)
+	}
+	if !more {
+		return note
+	}
+	switch {
+	case filtered:
+		note += ", filtered so far"
+	case key != sortName || desc:
+		note += ", sorted so far"
+	}
+	return note
+}
+
+// pageStatus returns the active pane's listing state for the title.
+func (c *Controller) pageStatus() (loaded int, more, loading, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pl := c.listing[c.active]
+	if pl == nil {
+		return len(c.objs), false, false, false
+	}
+	return len(c.objs), pl.more, pl.stop != nil, pl.pager == nil
+}
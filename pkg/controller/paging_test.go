@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+	"github.com/nexusriot/s3duck-tui/pkg/view"
+)
+
+// slicePager pages a fixed list n objects at a time.
+type slicePager struct {
+	objs  []*model.Object
+	n     int
+	calls int
+}
+
+func (p *slicePager) More() bool { return len(p.objs) > 0 }
+
+func (p *slicePager) Next(context.Context) ([]*model.Object, error) {
+	p.calls++
+	k := min(p.n, len(p.objs))
+	page := p.objs[:k]
+	p.objs = p.objs[k:]
+	return page, nil
+}
+
+func TestFetchPages(t *testing.T) {
+	all := []*model.Object{obj("a"), obj("b"), obj("c"), obj("d"), obj("e")}
+	cases := []struct {
+		min, want, calls int
+	}{
+		{0, 2, 1}, // a navigation: the first page only
+		{3, 4, 2}, // a refresh re-reads as far as the user had scrolled
+		{99, 5, 3},
+	}
+	for _, tc := range cases {
+		p := &slicePager{objs: all, n: 2}
+		got, err := fetchPages(context.Background(), p, tc.min)
+		if err != nil || len(got) != tc.want || p.calls != tc.calls {
+			t.Errorf("min %d: %d objects in %d pages (%v), want %d in %d", tc.min, len(got), p.calls, err, tc.want, tc.calls)
+		}
+	}
+}
+
+func TestListingNote(t *testing.T) {
+	cases := []struct {
+		name                  string
+		more, loading, cached bool
+		key                   sortKey
+		desc, filter          bool
+		want                  string
+	}{
+		{"complete", false, false, false, sortSize, false, true, ""},
+		{"name order is final", true, false, false, sortName, false, false, "1000 loaded, more ↓"},
+		{"fetching", true, true, false, sortName, false, false, "1000 loaded, loading…"},
+		{"other sort", true, false, false, sortSize, false, false, "1000 loaded, more ↓, sorted so far"},
+		{"reversed names", true, false, false, sortName, true, false, "1000 loaded, more ↓, sorted so far"},
+		{"filtered", true, false, false, sortName, false, true, "1000 loaded, more ↓, filtered so far"},
+		{"cached, re-listing", false, true, true, sortSize, false, false, "cached, refreshing…"},
+		{"cached, re-listing stopped", false, false, true, sortName, false, false, "cached, r refreshes"},
+		{"cached partial", true, true, true, sortSize, false, false, "cached, refreshing…, sorted so far"},
+	}
+	for _, tc := range cases {
+		if got := listingNote(1000, tc.more, tc.loading, tc.cached, tc.key, tc.desc, tc.filter); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestApplyPage(t *testing.T) {
+	c := &Controller{view: view.NewView()}
+	pl := &pagedListing{more: true, stop: func() {}}
+	c.listing[0] = pl
+	c.objs = map[string]*model.Object{"a": obj("a")}
+
+	stale := &pagedListing{more: true}
+	if c.applyPage(0, stale, []*model.Object{obj("x")}, false, nil) || len(c.objs) != 1 {
+		t.Fatalf("a page for a replaced listing was merged: %v", c.objs)
+	}
+
+	if !c.applyPage(0, pl, []*model.Object{obj("b")}, false, nil) {
+		t.Fatal("the active pane's page did not ask for a render")
+	}
+	if len(c.objs) != 2 || pl.more || pl.stop != nil {
+		t.Errorf("after page: %d objects, more=%v, fetching=%v", len(c.objs), pl.more, pl.stop != nil)
+	}
+
+	// A cancelled fetch leaves the listing resumable.
+	pl.more, pl.stop = true, func() {}
+	c.applyPage(0, pl, nil, false, context.Canceled)
+	if !pl.more || pl.stop != nil {
+		t.Errorf("after cancel: more=%v, fetching=%v", pl.more, pl.stop != nil)
+	}
+}
+
+// A big prefix lands with its first page, scrolling pulls in the next, Esc
+// stops a page on its way without losing the rest, and a refresh re-reads as
+// far as the user had got.
+func TestPagedListingInPane(t *testing.T) {
+	be := newScriptedNav("big")
+	be.pages = map[string]int{"big/": 3}
+	c := newNavController(t, be)
+
+	onUI(t, c, func() { c.jumpTo("big", "", "") })
+	waitFor(t, c, "first page", func() bool { return bucketName(c.currentBucket) == "big" && settled(c) })
+	onUI(t, c, func() {
+		if len(c.objs) != 1 || c.listing[0] == nil || !c.listing[0].more {
+			t.Fatalf("landed with %d objects, listing %+v; want the first page and more to come", len(c.objs), c.listing[0])
+		}
+	})
+
+	onUI(t, c, c.loadMore)
+	waitFor(t, c, "second page", func() bool { return len(c.objs) == 2 })
+
+	started, release := be.hold("big/#3")
+	defer release()
+	onUI(t, c, c.loadMore)
+	<-started
+	onUI(t, c, func() {
+		if !c.StopListing() {
+			t.Error("Esc found nothing to stop while a page was loading")
+		}
+	})
+	waitFor(t, c, "the fetch to stop", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.listing[0].stop == nil
+	})
+	onUI(t, c, func() {
+		if len(c.objs) != 2 || !c.listing[0].more {
+			t.Errorf("after Esc: %d objects, more=%v; want 2 and resumable", len(c.objs), c.listing[0].more)
+		}
+	})
+	release()
+
+	onUI(t, c, func() { go c.updateList() })
+	waitFor(t, c, "refresh", func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, ok := c.objs["big/big-2"]
+		return len(c.objs) == 2 && ok && c.listing[0] != nil
+	})
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	cfg "github.com/nexusriot/s3duck-tui/internal/config"
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// profileName is the active pane's profile name, or "" before one is open.
+func (c *Controller) profileName() string {
+	if c.activeConfig == nil {
+		return ""
+	}
+	return c.activeConfig.Name
+}
+
+// paneProfileTag is the list-title prefix naming a pane's profile. It is shown
+// only while the two panes are on different profiles — the one case where
+// "which endpoint is this?" is not obvious.
+func paneProfileTag(dual bool, mine, other *cfg.Config) string {
+	if !dual || mine == nil || other == nil || mine.Name == other.Name {
+		return ""
+	}
+	return fmt.Sprintf("[aqua]%s[-] ", tview.Escape(mine.Name))
+}
+
+// OpenProfileInPane points the active pane at another profile, leaving the
+// other pane where it is — MinIO staging on the left, AWS prod on the right.
+// The pane starts over at that profile's bucket list. Runs on the UI
+// goroutine; connecting does not.
+func (c *Controller) OpenProfileInPane() {
+	profiles := c.params.Config
+	if len(profiles) == 0 {
+		go c.error("Open profile in pane", fmt.Errorf("no profiles configured"))
+		return
+	}
+	names := make([]string, 0, len(profiles))
+	initial := 0
+	for i, p := range profiles {
+		names = append(names, p.Name)
+		if p.Name == c.profileName() {
+			initial = i
+		}
+	}
+
+	form := tview.NewForm()
+	form.SetTitle(fmt.Sprintf(" Open profile in pane %d ", c.active+1))
+	form.AddDropDown("Profile", names, initial, nil)
+	form.SetBorder(true)
+	form.AddButton("Open", func() {
+		i, _ := form.GetFormItemByLabel("Profile").(*tview.DropDown).GetCurrentOption()
+		c.view.Pages.RemovePage("modal")
+		if i < 0 || i >= len(profiles) {
+			return
+		}
+		c.connectPane(c.active, profiles[i])
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	form.SetInputCapture(escapeCloses(c, "modal"))
+
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 60, 9), true, true)
+}
+
+// connectPane builds a client for p off the UI goroutine and installs it in
+// pane. The pane index is fixed now, not when the connection completes, so
+// the profile lands in the pane it was chosen for.
+func (c *Controller) connectPane(pane int, p *cfg.Config) {
+	loading := tview.NewModal().SetText(fmt.Sprintf("Connecting to %s...", p.Name))
+	c.view.Pages.AddPage("progress", loading, true, true)
+
+	go func() {
+		mdl, err := modelForProfile(p)
+		if err != nil {
+			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress") })
+			c.error(fmt.Sprintf("Cannot connect to %s", p.Name), err)
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			c.view.Pages.RemovePage("progress")
+			c.setPaneProfile(pane, mdl, p)
+		})
+		c.logActivity("opened profile %s in pane %d", p.Name, pane+1)
+	}()
+}
+
+// setPaneProfile resets pane to the bucket list of a new profile. The
+// clipboard and undo are left alone: both remember the client they were made
+// through, so they stay valid whichever pane they are used from. UI goroutine.
+func (c *Controller) setPaneProfile(pane int, mdl *model.Model, p *cfg.Config) {
+	c.mu.Lock()
+	c.cancelNavLocked(pane) // a listing still coming for the old profile
+	c.listing[pane] = nil
+	if pane != c.active {
+		c.panes[pane] = paneState{model: mdl, config: p, selectedByScope: make(map[string]map[string]bool)}
+		c.mu.Unlock()
+		return
+	}
+	c.model = mdl
+	c.activeConfig = p
+	c.buckets = nil
+	c.objs = nil
+	c.currentBucket = nil
+	c.currentPath = ""
+	c.bucketPos = 0
+	c.restoreNext = ""
+	c.filter = ""
+	c.selectedByScope = make(map[string]map[string]bool)
+	c.mu.Unlock()
+	c.hist = histStack{}
+	c.filterSuppress = true
+	c.view.Filter.SetText("")
+	c.filterSuppress = false
+	go c.updateList()
+}
+
+// copyToOtherPane copies or moves items into the other pane's location when
+// that pane is on a different endpoint. The bucket dropdown lists the other
+// endpoint's buckets, defaulting to the one the pane shows; the transfer
+// streams through this process like `>` does, overwrite prompt included.
+func (c *Controller) copyToOtherPane(isMove bool, title string, items []copyMoveItem) {
+	other := c.panes[1-c.active]
+	src, dst := c.model, other.model
+	srcBucket := c.currentBucket
+	srcPrefix := model.NormalizePrefix(c.currentPath)
+	dstProfile := ""
+	if other.config != nil {
+		dstProfile = other.config.Name
+	}
+	initial := *other.currentBucket.Key
+	dstPrefix := model.NormalizePrefix(other.currentPath)
+
+	go func() {
+		bucketNames := []string{initial}
+		if list, err := dst.ListBuckets(context.Background()); err == nil {
+			bucketNames = bucketNames[:0]
+			for _, b := range list {
+				if b != nil && b.Key != nil {
+					bucketNames = append(bucketNames, *b.Key)
+				}
+			}
+			if len(bucketNames) == 0 {
+				bucketNames = []string{initial}
+			}
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			form := c.view.NewCopyMoveForm(fmt.Sprintf("%s to %s", title, dstProfile), bucketNames, initial, dstPrefix)
+			form.AddButton(title, func() {
+				_, bucketName := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+				prefix := model.NormalizePrefix(form.GetFormItem(1).(*tview.InputField).GetText())
+				c.view.Pages.RemovePage("modal")
+				if bucketName == "" {
+					return
+				}
+				c.runCrossCopy(items, src, srcBucket, srcPrefix, dst, dstProfile, bucketName, prefix, isMove, nil)
+			})
+			form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+			c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 75, 11), true, true)
+		})
+	}()
+}
+
+// pasteAcrossEndpoints pastes a clipboard yanked on another endpoint into the
+// current location by streaming. A cut is consumed only once something moved,
+// as with the same-endpoint paste.
+func (c *Controller) pasteAcrossEndpoints() {
+	clip := c.clip
+	isMove := clip.op == "cut"
+	var afterMove func()
+	if isMove {
+		afterMove = func() { c.clip = clipboard{} }
+	}
+	c.runCrossCopy(clip.items, clip.model, clip.bucket, model.NormalizePrefix(clip.prefix),
+		c.model, c.profileName(), *c.currentBucket.Key, model.NormalizePrefix(c.currentPath), isMove, afterMove)
+}
@@ -0,0 +1,324 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// pipeMaxOutput caps how much of a command's output the pager holds; the
+// rest is read and counted, so the command is never blocked on it.
+const pipeMaxOutput = 16 << 20 // 16 MiB
+
+// cappedBuffer keeps the first max bytes written to it and counts the rest.
+type cappedBuffer struct {
+	buf     bytes.Buffer
+	max     int
+	dropped int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	keep := min(len(p), max(0, b.max-b.buf.Len()))
+	b.buf.Write(p[:keep])
+	b.dropped += int64(len(p) - keep)
+	return len(p), nil
+}
+
+// pipeOutputKey is the default key a pipe's output is saved under: beside
+// the one object piped, or in the folder for several.
+func pipeOutputKey(currentPath string, keys []string) string {
+	if len(keys) == 1 {
+		return keys[0] + ".out"
+	}
+	return model.NormalizePrefix(currentPath) + "pipe.out"
+}
+
+// pipeKeys is what a pipe reads: the marked files, in name order, or else the
+// highlighted one. Folders are refused rather than expanded — a command
+// seeing a whole tree concatenated is rarely what was meant.
+func (c *Controller) pipeKeys() ([]string, error) {
+	var objs []*model.Object
+	if names := c.selectedNames(); len(names) > 0 {
+		for _, n := range names {
+			if o, ok := c.lookupObj(n); ok {
+				objs = append(objs, o)
+			}
+		}
+	} else if _, o, ok := c.currentObject(); ok {
+		objs = append(objs, o)
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("highlight or mark the objects to pipe")
+	}
+	keys := make([]string, 0, len(objs))
+	for _, o := range objs {
+		if o.Ot != model.File {
+			return nil, fmt.Errorf("%s is a folder; only objects can be piped", *o.Key)
+		}
+		keys = append(keys, *o.FullPath)
+	}
+	return keys, nil
+}
+
+// PipeObjects asks for a shell command and streams the highlighted object,
+// or the marked ones one after another, into its stdin — `zcat | jq`, `wc
+// -l` — with the TUI suspended as for EditObject, so the command's stderr
+// reaches the terminal. Its output then opens in a pager, from where it can
+// be saved to a new key. UI goroutine.
+func (c *Controller) PipeObjects() {
+	if c.currentBucket == nil {
+		return
+	}
+	keys, err := c.pipeKeys()
+	if err != nil {
+		go c.error("Pipe", err)
+		return
+	}
+	mdl, bucket, currentPath := c.model, c.currentBucket, c.currentPath
+
+	header := fmt.Sprintf("Pipe %s through", path.Base(keys[0]))
+	if len(keys) > 1 {
+		header = fmt.Sprintf("Pipe %d objects through", len(keys))
+	}
+	form := c.view.NewInputForm(header, "Command", c.lastPipe)
+	form.AddButton("Run", func() {
+		line := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		c.view.Pages.RemovePage("modal")
+		if line == "" {
+			return
+		}
+		c.lastPipe = line
+		go c.runPipe(mdl, bucket, currentPath, keys, line)
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 80, 7), true, true)
+}
+
+// pipeInput is the command's stdin; it remembers a failed write, which
+// means the command stopped reading — `head` does — and is not an error.
+type pipeInput struct {
+	w      io.Writer
+	closed bool
+}
+
+func (p *pipeInput) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if err != nil {
+		p.closed = true
+	}
+	return n, err
+}
+
+// runPipe runs line through sh with keys streamed into it. Any goroutine
+// but the UI one: it blocks while the command runs.
+func (c *Controller) runPipe(mdl *model.Model, bucket *model.Object, currentPath string, keys []string, line string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := exec.Command("sh", "-c", line)
+	out := &cappedBuffer{max: pipeMaxOutput}
+	cmd.Stdout, cmd.Stderr = out, os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		c.error("Pipe", err)
+		return
+	}
+
+	var runErr, feedErr error
+	suspended := c.view.App.Suspend(func() {
+		// The terminal is cooked while suspended, so Ctrl+C signals this
+		// process too: let it stop the command, not the browser.
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		defer signal.Stop(sig)
+
+		fmt.Fprintf(os.Stderr, "s3duck: %s | %s\n", strings.Join(keys, " "), line)
+		if runErr = cmd.Start(); runErr != nil {
+			return
+		}
+		fed := make(chan struct{})
+		in := &pipeInput{w: stdin}
+		go func() {
+			defer close(fed)
+			_, feedErr = mdl.CatObjects(ctx, bucket, keys, in)
+			stdin.Close()
+			if in.closed {
+				feedErr = nil
+			}
+		}()
+		runErr = cmd.Wait()
+		cancel() // the command is gone; stop reading objects for it
+		<-fed
+	})
+	c.view.App.QueueUpdateDraw(func() { c.graphics.gen++ })
+
+	if !suspended {
+		c.error("Pipe", fmt.Errorf("could not suspend the UI"))
+		return
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		c.error("Pipe: the command did not run", runErr)
+		return
+	}
+	if feedErr != nil && !errors.Is(feedErr, context.Canceled) {
+		c.error("Pipe: reading the objects failed", feedErr)
+		return
+	}
+	status := "exit status 0"
+	if exitErr != nil {
+		status = exitErr.String()
+	}
+	c.logActivity("Piped %d object(s) through %q: %s", len(keys), line, status)
+	c.view.App.QueueUpdateDraw(func() {
+		c.presentPipeOutput(mdl, bucket, currentPath, keys, line, status, out)
+	})
+}
+
+// presentPipeOutput pages through what the command wrote, as plain text —
+// its own colour codes and tview's tags alike are shown, not obeyed. w saves
+// it to a new key. UI goroutine.
+func (c *Controller) presentPipeOutput(mdl *model.Model, bucket *model.Object, currentPath string, keys []string, line, status string, out *cappedBuffer) {
+	data := out.buf.Bytes()
+	tv := tview.NewTextView().SetDynamicColors(false).SetScrollable(true).SetWrap(false)
+	tv.SetBorder(true)
+	title := fmt.Sprintf(" | %s — %s, %s ", tview.Escape(line), humanize.IBytes(uint64(len(data))+uint64(out.dropped)), status)
+	tv.SetTitle(title)
+	switch {
+	case len(data) == 0:
+		tv.SetText("(no output)")
+	case isProbablyBinary(data):
+		tv.SetText(fmt.Sprintf("(binary output, %s — w saves it to a key)", humanize.IBytes(uint64(len(data)))))
+	default:
+		text := string(data)
+		if out.dropped > 0 {
+			text += fmt.Sprintf("\n… %s more not kept", humanize.IBytes(uint64(out.dropped)))
+		}
+		tv.SetText(text)
+	}
+
+	help := tview.NewTextView().SetDynamicColors(true).SetText(
+		"  [::b]w[::-] save the output to a key   [::b]Esc[::-] close")
+	closePager := func() {
+		c.view.Pages.RemovePage("modal-pipe")
+		c.view.App.SetFocus(c.view.List)
+	}
+	tv.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc, event.Key() == tcell.KeyRune && event.Rune() == 'q':
+			closePager()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'w':
+			if out.dropped > 0 {
+				go c.error("Save output", fmt.Errorf("the output ran past %s and was cut; run the command with a redirect into a file and upload that instead",
+					humanize.IBytes(pipeMaxOutput)))
+				return nil
+			}
+			c.savePipeOutput(mdl, bucket, pipeOutputKey(currentPath, keys), data)
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tv, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	c.view.Pages.AddPage("modal-pipe", c.view.ModalClamped(flex, 160, 44), true, true)
+	c.view.App.SetFocus(tv)
+}
+
+// savePipeOutput asks for the key to write data to and hands it to
+// putPipeOutput. UI goroutine.
+func (c *Controller) savePipeOutput(mdl *model.Model, bucket *model.Object, key string, data []byte) {
+	form := c.view.NewInputForm("Save the output", "Key", key)
+	form.AddButton("Save", func() {
+		key := strings.TrimLeft(strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText()), "/")
+		c.view.Pages.RemovePage("modal")
+		if key == "" || strings.HasSuffix(key, "/") {
+			go c.error("Save output", fmt.Errorf("%q is not an object key", key))
+			return
+		}
+		c.putPipeOutput(mdl, bucket, key, data, false)
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 80, 7), true, true)
+}
+
+// putPipeOutput writes data to key behind a cancellable modal. Unless replace
+// is set it only creates: the key is looked for first and the PUT is
+// conditional on it being free, and an existing key, one that appeared
+// meanwhile or a lookup that failed other than with "not found" ask before
+// replacing anything. The pipe's pager stays open underneath, so Cancel
+// removes the modal alone. UI goroutine.
+func (c *Controller) putPipeOutput(mdl *model.Model, bucket *model.Object, key string, data []byte, replace bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.view.Pages.AddPage("progress", tview.NewModal().
+		SetText("Saving "+key+"...").
+		AddButtons([]string{"Cancel"}).
+		SetDoneFunc(func(_ int, _ string) {
+			cancel()
+			c.view.Pages.RemovePage("progress")
+		}), true, true)
+	go func() {
+		defer cancel()
+		var ask string
+		var err error
+		if replace {
+			err = mdl.PutBytes(ctx, bucket, key, data, model.ObjectContent{})
+		} else {
+			var exists bool
+			exists, err = mdl.ObjectExists(ctx, bucket, key)
+			switch {
+			case err != nil:
+				ask = fmt.Sprintf("Could not check whether %s exists:\n%v\n\nSave anyway, replacing it if it does?", key, err)
+			case exists:
+				ask = fmt.Sprintf("%s already exists.\n\nReplace it with the output?", key)
+			default:
+				err = mdl.CreateBytes(ctx, bucket, key, data, model.ObjectContent{})
+				if errors.Is(err, model.ErrObjectExists) {
+					ask = fmt.Sprintf("%s was created by someone else while saving.\n\nReplace it with the output?", key)
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			c.view.Pages.RemovePage("progress")
+			if ask == "" {
+				return
+			}
+			confirm := c.view.NewConfirm()
+			confirm.SetText(ask).
+				SetDoneFunc(func(_ int, label string) {
+					c.view.Pages.RemovePage("confirm")
+					if label == "OK" {
+						c.putPipeOutput(mdl, bucket, key, data, true)
+					}
+				})
+			c.view.Pages.AddPage("confirm", confirm, true, true)
+		})
+		if ask != "" {
+			return
+		}
+		if err != nil {
+			c.error("Save output failed", err)
+			return
+		}
+		c.success(fmt.Sprintf("Saved %s (%s)", key, humanize.IBytes(uint64(len(data)))))
+		c.updateList()
+	}()
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{max: 5}
+	for _, s := range []string{"abc", "defg", "hi"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v; a full buffer must still accept everything", s, n, err)
+		}
+	}
+	if got := b.buf.String(); got != "abcde" || b.dropped != 4 {
+		t.Errorf("kept %q, dropped %d; want %q, 4", got, b.dropped, "abcde")
+	}
+}
+
+func TestPipeOutputKey(t *testing.T) {
+	if got := pipeOutputKey("logs/", []string{"logs/app.log.gz"}); got != "logs/app.log.gz.out" {
+		t.Errorf("one object: %q", got)
+	}
+	if got := pipeOutputKey("logs", []string{"logs/a", "logs/b"}); got != "logs/pipe.out" {
+		t.Errorf("several: %q", got)
+	}
+	if got := pipeOutputKey("", []string{"a", "b"}); strings.HasPrefix(got, "/") || got != "pipe.out" {
+		t.Errorf("several at the top: %q", got)
+	}
+}
@@ -0,0 +1,654 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+const (
+	// previewChunk is what one ranged GET of the preview fetches. A multiple
+	// of hexWidth, so a hex dump's rows stay aligned across chunks.
+	previewChunk = 64 * 1024
+	// previewWindow caps how much of an object the preview holds. Scrolling on
+	// past it drops chunks from the far end; they are fetched again if the
+	// view comes back to them.
+	previewWindow = 16 * previewChunk
+	// previewDelay debounces cursor moves: nothing is fetched until the
+	// cursor has rested on an object this long.
+	previewDelay = 150 * time.Millisecond
+	// hexWidth is the number of bytes per hex dump row.
+	hexWidth = 16
+)
+
+// previewLoad says where a fetched range goes in the preview window.
+type previewLoad int
+
+const (
+	previewReset   previewLoad = iota // replace the window, stay at the top
+	previewEnd                        // replace the window, jump to the bottom
+	previewAppend                     // extend the window downwards
+	previewPrepend                    // extend the window upwards
+)
+
+// previewState is the preview panel's object and the part of it loaded: data
+// holds bytes [base, base+len(data)) of an object total bytes long. seq is
+// bumped whenever the object changes, so a fetch that lands late for an
+// object no longer previewed is dropped. kind is the viewer the object's type
+// calls for (see viewers.go); an image is kept decoded in img. UI goroutine
+// only.
+type previewState struct {
+	on      bool
+	seq     uint64
+	mdl     *model.Model
+	bucket  *model.Object
+	key     string
+	etag    string
+	base    int64
+	data    []byte
+	total   int64
+	binary  bool
+	kind    previewKind
+	raw     bool   // show the text as is, even where a viewer applies
+	plain   string // why the viewer fell back to the text as is
+	hint    string // what the viewer shows, when not the whole object
+	img     *image.RGBA
+	imgInfo string // its format and full size
+	imgFit  [2]int // the panel size, in cells, img was last drawn for
+	busy    bool
+	note    string // why the last fetch failed, "" if it didn't
+	cancel  context.CancelFunc
+	timer   *time.Timer
+}
+
+// hexDump renders data as `hexdump -C` does: the offset (counted from base),
+// sixteen bytes in hex split in two groups, and the printable ASCII of them.
+func hexDump(data []byte, base int64) string {
+	var b strings.Builder
+	for i := 0; i < len(data); i += hexWidth {
+		row := data[i:min(i+hexWidth, len(data))]
+		fmt.Fprintf(&b, "%08x  ", base+int64(i))
+		for j := 0; j < hexWidth; j++ {
+			switch {
+			case j < len(row):
+				fmt.Fprintf(&b, "%02x ", row[j])
+			default:
+				b.WriteString("   ")
+			}
+			if j == hexWidth/2-1 {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(" |")
+		for _, ch := range row {
+			if ch < 0x20 || ch > 0x7e {
+				ch = '.'
+			}
+			b.WriteByte(ch)
+		}
+		b.WriteString("|\n")
+	}
+	return b.String()
+}
+
+// textPreview renders data as text. A window that starts mid-object may start
+// mid-rune, so when mid is set the stray continuation bytes are dropped; any
+// other invalid UTF-8 — a rune cut off at the end of the window, say — shows
+// as U+FFFD.
+func textPreview(data []byte, mid bool) string {
+	for n := 0; mid && n < utf8.UTFMax && len(data) > 0 && !utf8.RuneStart(data[0]); n++ {
+		data = data[1:]
+	}
+	s := strings.ToValidUTF8(string(data), "�")
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// renderPreview renders a window of an object starting at base, as a hex dump
+// or as text, escaped for a dynamic-colour TextView.
+func renderPreview(data []byte, base int64, binary bool) string {
+	if binary {
+		return tview.Escape(hexDump(data, base))
+	}
+	return tview.Escape(textPreview(data, base > 0))
+}
+
+// TogglePreview shows or hides the preview panel under the details. Only the
+// single-pane layout has a details column; in dual-pane it says so. UI
+// goroutine.
+func (c *Controller) TogglePreview() {
+	if c.dual {
+		go c.error("Preview", fmt.Errorf("the preview shows in the single-pane layout (Ctrl+O)"))
+		return
+	}
+	c.preview.on = !c.preview.on
+	c.view.ShowPreview(c.preview.on)
+	if !c.preview.on {
+		c.stopPreview()
+		c.view.App.SetFocus(c.view.List)
+		return
+	}
+	c.previewHighlighted()
+}
+
+// closePreview turns the preview off, as on a fresh browser. UI goroutine.
+func (c *Controller) closePreview() {
+	c.preview.on = false
+	c.stopPreview()
+	c.view.ShowPreview(false)
+}
+
+// stopPreview forgets the previewed object: a pending or running fetch is
+// cancelled and the panel emptied. UI goroutine.
+func (c *Controller) stopPreview() {
+	p := &c.preview
+	p.seq++
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	*p = previewState{on: p.on, seq: p.seq}
+	c.view.Preview.Clear()
+	c.view.Preview.ScrollToBeginning()
+	c.view.PreviewTree.SetRoot(nil)
+	c.view.PreviewTable.Clear()
+	c.showPreviewPage("text")
+	c.previewTitle()
+}
+
+// previewHighlighted previews whatever the list cursor is on. UI goroutine.
+func (c *Controller) previewHighlighted() {
+	_, obj, _ := c.currentObject()
+	c.previewObject(obj)
+}
+
+// previewObject points the preview at obj (nil or a non-object clears it).
+// The first range is fetched once the cursor has rested for previewDelay, so
+// scrolling through a listing doesn't issue a GET per row. Re-pointing it at
+// the object already shown, unchanged, keeps the window and scroll position.
+// UI goroutine.
+func (c *Controller) previewObject(obj *model.Object) {
+	p := &c.preview
+	if !p.on || c.dual {
+		return
+	}
+	if obj == nil || obj.Ot != model.File || obj.FullPath == nil || c.currentBucket == nil {
+		if p.key != "" || p.timer != nil || p.busy {
+			c.stopPreview()
+		}
+		return
+	}
+	etag := ""
+	if obj.Etag != nil {
+		etag = *obj.Etag
+	}
+	if p.key == *obj.FullPath && p.bucket == c.currentBucket && p.mdl == c.model && p.etag == etag {
+		return
+	}
+	c.stopPreview()
+	p.mdl, p.bucket, p.key, p.etag = c.model, c.currentBucket, *obj.FullPath, etag
+	p.busy = true // until the first range lands
+	c.previewTitle()
+	seq := p.seq
+	p.timer = time.AfterFunc(previewDelay, func() {
+		c.view.App.QueueUpdateDraw(func() {
+			if c.preview.seq != seq {
+				return
+			}
+			c.preview.timer = nil
+			c.preview.busy = false
+			c.loadPreview(0, previewChunk, previewReset)
+		})
+	})
+}
+
+// loadPreview fetches n bytes at off and puts them where how says. One fetch
+// runs at a time; a scroll that wants more while one is running simply asks
+// again on its next key. UI goroutine; the GET is not.
+func (c *Controller) loadPreview(off, n int64, how previewLoad) {
+	p := &c.preview
+	if p.busy || p.key == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.busy, p.cancel, p.note = true, cancel, ""
+	c.previewTitle()
+	seq, mdl, bucket, key := p.seq, p.mdl, p.bucket, p.key
+
+	go func() {
+		defer cancel()
+		r, err := mdl.ReadRange(ctx, bucket, key, off, n)
+		if ctx.Err() != nil {
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			if c.preview.seq != seq {
+				return
+			}
+			c.preview.busy, c.preview.cancel = false, nil
+			if err != nil {
+				c.preview.note = err.Error()
+				if how == previewReset && len(c.preview.data) == 0 {
+					c.view.Preview.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+				}
+				c.previewTitle()
+				return
+			}
+			c.applyPreview(off, r, how)
+		})
+	}()
+}
+
+// applyPreview merges a fetched range into the window. Text and hex dumps are
+// trimmed back to previewWindow at the far end and re-rendered keeping the
+// rows in view where they were; a structured viewer takes over from
+// showStructured. The first range of an object decides which applies. UI
+// goroutine.
+func (c *Controller) applyPreview(off int64, r model.ObjectRange, how previewLoad) {
+	p := &c.preview
+	tv := c.view.Preview
+	row, _ := tv.GetScrollOffset()
+	data := r.Data
+	if p.total == 0 && p.data == nil && off == 0 {
+		p.binary = isProbablyBinary(data[:min(len(data), binarySniffLen)])
+		switch {
+		case isPreviewImage(data):
+			p.binary, p.kind = true, kindImage
+		case !p.binary:
+			p.kind = detectPreviewKind(p.key, r.ContentType)
+		}
+	}
+	p.total = r.Total
+	if p.structured() {
+		if how == previewAppend {
+			p.data = append(p.data, data...)
+		} else {
+			p.base, p.data = off, data
+		}
+		c.showStructured()
+		return
+	}
+	c.showPreviewPage("text")
+
+	// rows counts the rendered rows of a stretch of the window, so the view
+	// can be shifted by what was added above it or dropped from above it.
+	rows := func(part []byte, base int64) int {
+		tv.SetText(renderPreview(part, base, p.binary))
+		return tv.GetWrappedLineCount()
+	}
+	switch how {
+	case previewReset, previewEnd:
+		p.base, p.data = off, data
+		tv.SetText(renderPreview(p.data, p.base, p.binary))
+		if how == previewEnd {
+			tv.ScrollToEnd()
+		} else {
+			tv.ScrollToBeginning()
+		}
+	case previewAppend:
+		p.data = append(p.data, data...)
+		if over := len(p.data) - previewWindow; over > 0 {
+			drop := (over + previewChunk - 1) / previewChunk * previewChunk
+			row -= rows(p.data[:drop], p.base)
+			p.base += int64(drop)
+			p.data = append([]byte(nil), p.data[drop:]...)
+		}
+		tv.SetText(renderPreview(p.data, p.base, p.binary))
+		tv.ScrollTo(max(0, row), 0)
+	case previewPrepend:
+		row += rows(data, off)
+		p.base, p.data = off, append(append([]byte(nil), data...), p.data...)
+		if len(p.data) > previewWindow {
+			p.data = p.data[:previewWindow]
+		}
+		tv.SetText(renderPreview(p.data, p.base, p.binary))
+		tv.ScrollTo(row, 0)
+	}
+	c.previewTitle()
+}
+
+// structured reports whether the object is shown by a viewer rather than as
+// text or hex.
+func (p *previewState) structured() bool {
+	return (!p.binary || p.kind == kindImage) && !p.raw && p.kind != kindText && p.plain == ""
+}
+
+// showStructured renders the object with its kind's viewer: JSON and YAML as
+// folding trees, NDJSON as a tree of records, CSV and TSV as a table,
+// Markdown styled and images as pictures. Viewers need the object from its
+// start and, up to previewWindow, all of it, which is fetched first; JSON,
+// YAML, Markdown and images over that, or content the viewer can't make sense
+// of, fall back to the text (or hex) as is. UI goroutine.
+func (c *Controller) showStructured() {
+	p := &c.preview
+	if p.base != 0 {
+		c.loadPreview(0, previewChunk, previewReset)
+		return
+	}
+	if p.total > previewWindow && !p.kind.partial() {
+		c.previewFallBack(fmt.Sprintf("over %s, too big for the %s viewer", humanize.IBytes(previewWindow), p.kind))
+		return
+	}
+	want := min(p.total, previewWindow)
+	if have := int64(len(p.data)); have < want {
+		c.loadPreview(have, want-have, previewAppend)
+		return
+	}
+	data := p.data
+	p.hint = ""
+	if int64(len(data)) < p.total {
+		data = data[:bytes.LastIndexByte(data, '\n')+1] // whole lines only
+		p.hint = fmt.Sprintf("first %s", humanize.IBytes(uint64(len(data))))
+	}
+	switch p.kind {
+	case kindJSON:
+		f, err := jsonFold(data)
+		if err != nil {
+			c.previewFallBack(fmt.Sprintf("not valid JSON: %v", err))
+			return
+		}
+		c.showPreviewTree(f, 0, 2)
+	case kindNDJSON:
+		c.showPreviewTree(ndjsonFold(data), 1, 1)
+	case kindYAML:
+		f, err := yamlFold(data)
+		if err != nil {
+			c.previewFallBack(fmt.Sprintf("not YAML: %v", err))
+			return
+		}
+		c.showPreviewTree(f, 1, 2)
+	case kindCSV, kindTSV:
+		comma := ','
+		if p.kind == kindTSV {
+			comma = '\t'
+		}
+		rows, err := csvRows(data, comma)
+		if err != nil {
+			c.previewFallBack(fmt.Sprintf("not valid %s: %v", p.kind, err))
+			return
+		}
+		c.showPreviewTable(rows)
+	case kindMarkdown:
+		c.view.Preview.SetText(markdownStyled(textPreview(data, false)))
+		c.view.Preview.ScrollToBeginning()
+		c.showPreviewPage("text")
+	case kindImage:
+		c.showPreviewImage()
+		return
+	}
+	c.previewTitle()
+}
+
+// showPreviewImage decodes the image, off the UI goroutine as a large JPEG
+// takes a moment, and draws it. UI goroutine.
+func (c *Controller) showPreviewImage() {
+	p := &c.preview
+	if p.img != nil {
+		c.drawPreviewImage()
+		return
+	}
+	p.busy = true
+	c.previewTitle()
+	seq, data := p.seq, p.data
+	go func() {
+		img, format, w, h, err := decodePreviewImage(data)
+		c.view.App.QueueUpdateDraw(func() {
+			p := &c.preview
+			if p.seq != seq {
+				return
+			}
+			p.busy = false
+			if err != nil {
+				c.previewFallBack(fmt.Sprintf("not a readable image: %v", err))
+				return
+			}
+			p.img, p.imgInfo = img, fmt.Sprintf("%s %d×%d", strings.ToUpper(format), w, h)
+			c.drawPreviewImage()
+		})
+	}()
+}
+
+// drawPreviewImage fits the decoded image to the panel. Half blocks are text
+// in the text view; sixel and kitty images are written to the terminal after
+// each draw over the image box (see placeGraphics). UI goroutine.
+func (c *Controller) drawPreviewImage() {
+	p := &c.preview
+	_, _, w, h := c.view.PreviewPages.GetInnerRect()
+	p.imgFit, p.hint = [2]int{w, h}, p.imgInfo
+	c.graphics.gen++
+	if c.graphics.proto == graphicsBlocks {
+		c.view.Preview.SetText(halfBlocks(fitImage(p.img, max(w, 1), max(2*h, 2))))
+		c.view.Preview.ScrollToBeginning()
+		c.showPreviewPage("text")
+	} else {
+		c.showPreviewPage("image")
+	}
+	c.previewTitle()
+}
+
+// previewFallBack shows the window as text after all, saying why in the
+// title. UI goroutine.
+func (c *Controller) previewFallBack(why string) {
+	p := &c.preview
+	p.plain, p.hint = why, ""
+	c.view.Preview.SetText(renderPreview(p.data, p.base, p.binary))
+	c.view.Preview.ScrollToBeginning()
+	c.showPreviewPage("text")
+	c.previewTitle()
+}
+
+// showPreviewTree shows f as a folding tree, from level top (1 hides a root
+// that only holds the real top-level nodes), with open levels expanded.
+func (c *Controller) showPreviewTree(f *foldNode, top, open int) {
+	root := treeOf(f, 0, open)
+	tree := c.view.PreviewTree
+	tree.SetRoot(root).SetTopLevel(top)
+	tree.SetCurrentNode(root)
+	if top > 0 && len(root.GetChildren()) > 0 {
+		tree.SetCurrentNode(root.GetChildren()[0])
+	}
+	c.showPreviewPage("tree")
+}
+
+// showPreviewTable shows rows as a table under their first row as the header,
+// with numbers right-aligned.
+func (c *Controller) showPreviewTable(rows [][]string) {
+	t := c.view.PreviewTable
+	t.Clear()
+	for r, row := range rows {
+		for col, v := range row {
+			cell := tview.NewTableCell(tview.Escape(v)).SetMaxWidth(40)
+			switch {
+			case r == 0:
+				cell.SetTextColor(tcell.ColorYellow).SetAttributes(tcell.AttrBold)
+			case isNumeric(v):
+				cell.SetAlign(tview.AlignRight)
+			}
+			t.SetCell(r, col, cell)
+		}
+	}
+	t.ScrollToBeginning()
+	c.showPreviewPage("table")
+}
+
+// isNumeric reports whether a table cell holds a number.
+func isNumeric(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
+
+// showPreviewPage brings the text, tree or table view to the front of the
+// preview, keeping the keyboard in the preview if it was there. UI goroutine.
+func (c *Controller) showPreviewPage(name string) {
+	focused := c.previewFocused()
+	c.view.PreviewPages.SwitchToPage(name)
+	if focused {
+		_, front := c.view.PreviewPages.GetFrontPage()
+		c.view.App.SetFocus(front)
+	}
+}
+
+// previewFocused reports whether one of the preview's views has the keyboard.
+func (c *Controller) previewFocused() bool {
+	switch c.view.App.GetFocus() {
+	case c.view.Preview, c.view.PreviewTree, c.view.PreviewTable, c.view.PreviewImage:
+		return true
+	}
+	return false
+}
+
+// previewTitle shows what the preview holds: the object, the loaded span of
+// it and whether more is on the way.
+func (c *Controller) previewTitle() {
+	p := &c.preview
+	if p.key == "" {
+		c.view.PreviewPages.SetTitle(" Preview ")
+		return
+	}
+	title := " " + tview.Escape(path.Base(p.key))
+	if p.data != nil || p.total > 0 {
+		kind := "text"
+		switch {
+		case p.structured():
+			kind = p.kind.String()
+		case p.binary:
+			kind = "hex"
+		}
+		title += fmt.Sprintf(" — %s %s–%s of %s", kind,
+			humanize.IBytes(uint64(p.base)), humanize.IBytes(uint64(p.base)+uint64(len(p.data))), humanize.IBytes(uint64(p.total)))
+	}
+	switch {
+	case p.busy:
+		title += " — loading…"
+	case p.note != "":
+		title += " — [red]failed[-]"
+	case p.plain != "":
+		title += " — [gray]" + tview.Escape(p.plain) + "[-]"
+	case p.hint != "" && p.structured():
+		title += " — [gray]" + tview.Escape(p.hint) + "[-]"
+	}
+	c.view.PreviewPages.SetTitle(title + " ")
+}
+
+// focusPreview moves the keyboard to the preview so it can be scrolled. UI
+// goroutine.
+func (c *Controller) focusPreview() {
+	c.view.PreviewPages.SetBorderColor(tcell.ColorGreen)
+	_, front := c.view.PreviewPages.GetFrontPage()
+	c.view.App.SetFocus(front)
+}
+
+// togglePreviewRaw flips between a viewer and the text (or, for an image, the
+// hex dump) as is. UI goroutine.
+func (c *Controller) togglePreviewRaw() {
+	p := &c.preview
+	if p.kind == kindText || p.busy {
+		return
+	}
+	p.raw, p.plain = !p.raw, ""
+	if p.structured() {
+		c.showStructured()
+		return
+	}
+	c.view.Preview.SetText(renderPreview(p.data, p.base, p.binary))
+	c.view.Preview.ScrollToBeginning()
+	c.showPreviewPage("text")
+	c.previewTitle()
+}
+
+// previewInputCapture handles the keys of the focused preview. Esc and Tab
+// hand the keyboard back to the list and v flips between a viewer and the
+// text as is; a tree also folds with ←/→ (Enter toggles). Text and hex dumps
+// fetch the next (or previous) range once the view comes within a screen of
+// the window's edge, so an object of any size pages through a window of at
+// most previewWindow bytes; g / Home and G / End jump to the object's first
+// and last chunk.
+func (c *Controller) previewInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	p := &c.preview
+	switch {
+	case event.Key() == tcell.KeyEsc, event.Key() == tcell.KeyTab:
+		c.view.PreviewPages.SetBorderColor(tcell.ColorWhite)
+		c.view.App.SetFocus(c.view.List)
+		return nil
+	case event.Key() == tcell.KeyRune && event.Rune() == 'v':
+		c.togglePreviewRaw()
+		return nil
+	case c.view.App.GetFocus() == c.view.PreviewTree:
+		if n := c.view.PreviewTree.GetCurrentNode(); n != nil && len(n.GetChildren()) > 0 {
+			switch event.Key() {
+			case tcell.KeyRight:
+				n.Expand()
+				foldMark(n)
+				return nil
+			case tcell.KeyLeft:
+				n.Collapse()
+				foldMark(n)
+				return nil
+			}
+		}
+		return event
+	case p.structured():
+		return event
+	}
+
+	tv := c.view.Preview
+	end := p.base + int64(len(p.data))
+	_, _, _, height := tv.GetInnerRect()
+	row, _ := tv.GetScrollOffset()
+	down, up := false, false
+	switch event.Key() {
+	case tcell.KeyHome:
+		if p.base > 0 {
+			c.loadPreview(0, previewChunk, previewReset)
+			return nil
+		}
+	case tcell.KeyEnd:
+		if end < p.total {
+			c.loadPreview((p.total-1)/previewChunk*previewChunk, previewChunk, previewEnd)
+			return nil
+		}
+	case tcell.KeyDown, tcell.KeyPgDn, tcell.KeyCtrlF:
+		down = true
+	case tcell.KeyUp, tcell.KeyPgUp, tcell.KeyCtrlB:
+		up = true
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'g':
+			if p.base > 0 {
+				c.loadPreview(0, previewChunk, previewReset)
+				return nil
+			}
+		case 'G':
+			if end < p.total {
+				c.loadPreview((p.total-1)/previewChunk*previewChunk, previewChunk, previewEnd)
+				return nil
+			}
+		case 'j', ' ':
+			down = true
+		case 'k':
+			up = true
+		}
+	}
+	switch {
+	case down && end < p.total && row+2*height >= tv.GetWrappedLineCount():
+		c.loadPreview(end, previewChunk, previewAppend)
+	case up && p.base > 0 && row <= height:
+		c.loadPreview(max(0, p.base-previewChunk), previewChunk, previewPrepend)
+	}
+	return event
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHexDump(t *testing.T) {
+	data := []byte("Hello, [world]!\x00\x01\xffABC")
+	got := hexDump(data, 0x10020)
+	want := "00010020  48 65 6c 6c 6f 2c 20 5b  77 6f 72 6c 64 5d 21 00  |Hello, [world]!.|\n" +
+		"00010030  01 ff 41 42 43                                    |..ABC|\n"
+	if got != want {
+		t.Errorf("hexDump =\n%s\nwant\n%s", got, want)
+	}
+	if hexDump(nil, 0) != "" {
+		t.Error("an empty window should render nothing")
+	}
+}
+
+func TestTextPreview(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		mid  bool
+		want string
+	}{
+		{"line one\r\nline two\n", false, "line one\nline two\n"},
+		{"\xa9 caf\xc3\xa9", true, " café"},   // starts inside "©"
+		{"\xa9 caf\xc3\xa9", false, "� café"}, // at offset 0 it's just invalid
+		{"end of window caf\xc3", false, "end of window caf�"},
+	} {
+		if got := textPreview([]byte(tc.data), tc.mid); got != tc.want {
+			t.Errorf("textPreview(%q, %v) = %q, want %q", tc.data, tc.mid, got, tc.want)
+		}
+	}
+}
+
+func TestRenderPreviewEscapes(t *testing.T) {
+	// Bracketed text must not be taken for colour tags by the TextView.
+	text := renderPreview([]byte("[red]not red[-]"), 0, false)
+	if !strings.Contains(text, "[red[]") {
+		t.Errorf("text not escaped: %q", text)
+	}
+	hex := renderPreview([]byte("[red]"), 0, true)
+	if !strings.Contains(hex, "|[red[]|") {
+		t.Errorf("hex dump not escaped: %q", hex)
+	}
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	cfg "github.com/nexusriot/s3duck-tui/internal/config"
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+const putUsage = `s3duck-tui put [-profile name] [-content-type type] s3://bucket/key [file|-]
+
+The input is sent in parts that start at 16 MiB and double every thousand,
+two of them in memory at once: 32 MiB for the first 15.6 GiB, 1 GiB past
+484 GiB, and up to 8 GiB for a stream past 3.9 TiB.`
+
+// pickProfile is the profile a headless command runs as: the one named, or
+// else the only one there is.
+func pickProfile(profiles []*cfg.Config, name string) (*cfg.Config, error) {
+	if name != "" {
+		for _, p := range profiles {
+			if p.Name == name {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("no profile named %q", name)
+	}
+	switch len(profiles) {
+	case 0:
+		return nil, fmt.Errorf("no profiles yet; create one in the TUI first")
+	case 1:
+		return profiles[0], nil
+	}
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Name
+	}
+	return nil, fmt.Errorf("%d profiles (%s); choose one with -profile", len(profiles), strings.Join(names, ", "))
+}
+
+// Put is the headless `put` command: it streams a file, or stdin ("-", the
+// default), to an s3:// URI as one multipart upload, so a pipe of any length
+// goes up without being kept whole in memory or on disk; putUsage gives what
+// its two in-flight parts cost as the stream grows. The Content-Type is
+// guessed from the key's extension unless -content-type sets it. The bytes
+// written are reported to stderr; Ctrl+C aborts the upload, leaving no
+// object and no parts.
+func Put(args []string, stdin io.Reader, stderr io.Writer) error {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	profileName := fs.String("profile", "", "profile to run as (default: the only one)")
+	contentType := fs.String("content-type", "", "Content-Type (default: guessed from the key)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%v\nusage: %s", err, putUsage)
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return fmt.Errorf("usage: %s", putUsage)
+	}
+	bucket, key, err := model.ParseS3URI(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(key, "/") {
+		return fmt.Errorf("%q names a folder, not an object", fs.Arg(0))
+	}
+
+	src := stdin
+	if name := fs.Arg(1); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		src = f
+	}
+
+	params := cfg.NewParams()
+	if params.LoadErr != nil {
+		return params.LoadErr
+	}
+	profile, err := pickProfile(params.Config, *profileName)
+	if err != nil {
+		return err
+	}
+	mdl, err := modelForProfile(profile)
+	if err != nil {
+		return err
+	}
+	if mdl, err = mdl.ForBucket(bucket); err != nil {
+		fmt.Fprintf(stderr, "warning: %v; trying the profile's region\n", err)
+	}
+
+	if *contentType == "" {
+		*contentType = model.GuessContentType(key)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	n, err := mdl.PutStream(ctx, &model.Object{Key: &bucket}, key, src, *contentType)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "%s → s3://%s/%s\n", humanize.IBytes(uint64(n)), bucket, key)
+	return nil
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"testing"
+
+	cfg "github.com/nexusriot/s3duck-tui/internal/config"
+)
+
+func TestPickProfile(t *testing.T) {
+	a, b := &cfg.Config{Name: "a"}, &cfg.Config{Name: "b"}
+	for _, tc := range []struct {
+		profiles []*cfg.Config
+		name     string
+		want     *cfg.Config
+	}{
+		{[]*cfg.Config{a}, "", a},
+		{[]*cfg.Config{a, b}, "b", b},
+		{[]*cfg.Config{a, b}, "", nil}, // ambiguous
+		{[]*cfg.Config{a}, "c", nil},
+		{nil, "", nil},
+	} {
+		got, err := pickProfile(tc.profiles, tc.name)
+		if got != tc.want || (err != nil) != (tc.want == nil) {
+			t.Errorf("pickProfile(%d profile(s), %q) = %v, %v", len(tc.profiles), tc.name, got, err)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/dustin/go-humanize"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// searchQuery is a parsed search or filter query: a list of terms, all of
+// which must hold. The same query drives Ctrl+F (prefix, all-bucket and index
+// search), where a term sees the full key, and the listing filter `/`, where
+// it sees the entry's name.
+//
+// Terms, separated by spaces ("…" quotes a term that contains them):
+//
+//	word           case-insensitive substring
+//	*.gz  a?c  [ab]*  glob; against the whole key if it contains "/", else the last segment
+//	re:EXPR        case-insensitive regular expression, anywhere in the key
+//	ext:gz,tar.gz  extension (any of a comma list)
+//	class:GLACIER  storage class (any of a comma list; none reported = STANDARD)
+//	size>100M      size, with < <= > >= =, in bytes or with a unit; a bare K, M,
+//	               G, T is binary like KiB, MiB… as listings show sizes, and KB, MB…
+//	               are decimal
+//	modified<2025-01-01  last-modified, with the same operators; a date, a
+//	               date and time (2025-01-01T15:04), or an age (7d, 12h, 2w) back from now
+//
+// Folders carry no size, date or class, so any size, modified, class or ext
+// term leaves them out.
+type searchQuery struct {
+	terms []queryTerm
+	// attrs is set when a term tests something only objects have.
+	attrs bool
+}
+
+// queryTerm is one condition of a searchQuery.
+type queryTerm func(t queryTarget) bool
+
+// queryTarget is what a query is evaluated against.
+type queryTarget struct {
+	key    string // full key (search) or entry name (filter)
+	size   int64
+	mod    time.Time
+	class  string
+	folder bool
+}
+
+// targetOfObject is a listed object as a search sees it.
+func targetOfObject(o s3t.Object) queryTarget {
+	t := queryTarget{size: o.Size, class: string(o.StorageClass)}
+	if o.Key != nil {
+		t.key = *o.Key
+	}
+	if o.LastModified != nil {
+		t.mod = *o.LastModified
+	}
+	return t
+}
+
+// targetOfEntry is a browser entry as the listing filter sees it: by name.
+func targetOfEntry(o *model.Object) queryTarget {
+	t := queryTarget{folder: o.Ot != model.File}
+	if o.Key != nil {
+		t.key = *o.Key
+	}
+	if o.Size != nil {
+		t.size = *o.Size
+	}
+	if o.LastModified != nil {
+		t.mod = *o.LastModified
+	}
+	if o.StorageClass != nil {
+		t.class = *o.StorageClass
+	}
+	return t
+}
+
+// match reports whether t satisfies every term. An empty query matches all.
+func (q *searchQuery) match(t queryTarget) bool {
+	if q.attrs && t.folder {
+		return false
+	}
+	for _, term := range q.terms {
+		if !term(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitQuery splits s at spaces outside double quotes, dropping the quotes.
+func splitQuery(s string) ([]string, error) {
+	var out []string
+	var cur strings.Builder
+	quoted, inTok := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted, inTok = !quoted, true
+		case r == ' ' && !quoted:
+			if inTok {
+				out = append(out, cur.String())
+				cur.Reset()
+				inTok = false
+			}
+		default:
+			cur.WriteRune(r)
+			inTok = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unclosed quote in %q", s)
+	}
+	if inTok {
+		out = append(out, cur.String())
+	}
+	return out, nil
+}
+
+// parseQuery parses s (see searchQuery). Ages such as "7d" count back from
+// now, and dates are read in now's time zone.
+func parseQuery(s string, now time.Time) (*searchQuery, error) {
+	toks, err := splitQuery(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	q := &searchQuery{}
+	for _, tok := range toks {
+		term, attr, err := parseTerm(tok, now)
+		if err != nil {
+			return nil, err
+		}
+		q.terms = append(q.terms, term)
+		q.attrs = q.attrs || attr
+	}
+	return q, nil
+}
+
+// parseTerm parses one term; attr reports that it tests an object attribute.
+func parseTerm(tok string, now time.Time) (term queryTerm, attr bool, err error) {
+	if expr, ok := strings.CutPrefix(tok, "re:"); ok {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, false, fmt.Errorf("bad regular expression %q: %w", expr, err)
+		}
+		return func(t queryTarget) bool { return re.MatchString(t.key) }, false, nil
+	}
+	if list, ok := strings.CutPrefix(tok, "ext:"); ok {
+		var exts []string
+		for _, e := range strings.Split(list, ",") {
+			if e = strings.ToLower(strings.TrimPrefix(e, ".")); e != "" {
+				exts = append(exts, "."+e)
+			}
+		}
+		if len(exts) == 0 {
+			return nil, false, fmt.Errorf("ext: needs an extension")
+		}
+		return func(t queryTarget) bool {
+			k := strings.ToLower(t.key)
+			for _, e := range exts {
+				if strings.HasSuffix(k, e) {
+					return true
+				}
+			}
+			return false
+		}, true, nil
+	}
+	if list, ok := strings.CutPrefix(tok, "class:"); ok {
+		classes := strings.Split(strings.ToUpper(list), ",")
+		return func(t queryTarget) bool {
+			c := strings.ToUpper(t.class)
+			if c == "" {
+				c = "STANDARD"
+			}
+			for _, want := range classes {
+				if c == want {
+					return true
+				}
+			}
+			return false
+		}, true, nil
+	}
+	for _, field := range []string{"size", "modified"} {
+		rest, ok := strings.CutPrefix(tok, field)
+		if !ok || rest == "" || !strings.ContainsRune("<>=", rune(rest[0])) {
+			continue
+		}
+		op, val := splitOp(rest)
+		if val == "" {
+			return nil, false, fmt.Errorf("%s%s needs a value", field, op)
+		}
+		if field == "size" {
+			n, err := parseQuerySize(val)
+			if err != nil {
+				return nil, false, fmt.Errorf("bad size %q: %w", val, err)
+			}
+			lo, hi := int64(n), int64(n)+1
+			return func(t queryTarget) bool { return inRange(op, t.size, lo, hi) }, true, nil
+		}
+		from, to, err := parseWhen(val, now)
+		if err != nil {
+			return nil, false, err
+		}
+		lo, hi := from.UnixNano(), to.UnixNano()
+		return func(t queryTarget) bool { return !t.mod.IsZero() && inRange(op, t.mod.UnixNano(), lo, hi) }, true, nil
+	}
+	if strings.ContainsAny(tok, "*?[") {
+		glob := strings.ToLower(tok)
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, false, fmt.Errorf("bad glob %q: %w", tok, err)
+		}
+		whole := strings.Contains(glob, "/")
+		return func(t queryTarget) bool {
+			k := strings.ToLower(t.key)
+			if !whole {
+				k = k[strings.LastIndex(k, "/")+1:]
+			}
+			ok, _ := path.Match(glob, k)
+			return ok
+		}, false, nil
+	}
+	return func(t queryTarget) bool { return searchMatch(t.key, tok) }, false, nil
+}
+
+// parseQuerySize reads a size term's value. humanize.ParseBytes takes a bare
+// unit letter as decimal, but listings print sizes with humanize.IBytes, so
+// "100M" is read as the 100 MiB a listing would show and only a spelled-out
+// "MB" is a million bytes.
+func parseQuerySize(val string) (uint64, error) {
+	if n := len(val); n > 0 && strings.ContainsRune("kKmMgGtTpPeE", rune(val[n-1])) {
+		val += "iB"
+	}
+	return humanize.ParseBytes(val)
+}
+
+// splitOp splits a comparison ("<=5M") into its operator and value.
+func splitOp(s string) (op, val string) {
+	for _, o := range []string{"<=", ">=", "<", ">", "="} {
+		if v, ok := strings.CutPrefix(s, o); ok {
+			return o, v
+		}
+	}
+	return "", s
+}
+
+// inRange compares v against the value [lo, hi) — an exact size is the range
+// of one, a date the whole day — so "modified<=2025-01-01" includes that day
+// and "modified>2025-01-01" starts after it.
+func inRange(op string, v, lo, hi int64) bool {
+	switch op {
+	case "<":
+		return v < lo
+	case "<=":
+		return v < hi
+	case ">":
+		return v >= hi
+	case ">=":
+		return v >= lo
+	default:
+		return v >= lo && v < hi
+	}
+}
+
+// parseWhen reads a modified value as the span it names: a day, a minute, a
+// second, or for an age ("7d") the instant that long before now.
+func parseWhen(s string, now time.Time) (from, to time.Time, err error) {
+	if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+		unit := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+		if unit != 0 {
+			at := now.Add(-time.Duration(n) * unit)
+			return at, at, nil
+		}
+	}
+	for _, f := range []struct {
+		layout string
+		span   time.Duration
+	}{
+		{"2006-01-02", 24 * time.Hour},
+		{"2006-01-02T15:04", time.Minute},
+		{time.RFC3339, time.Second},
+	} {
+		if t, err := time.ParseInLocation(f.layout, s, now.Location()); err == nil {
+			if f.layout == "2006-01-02" {
+				return t, t.AddDate(0, 0, 1), nil // a calendar day, even across DST
+			}
+			return t, t.Add(f.span), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("bad date %q: want 2025-01-31, 2025-01-31T15:04 or an age like 7d", s)
+}
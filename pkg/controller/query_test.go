@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+func TestParseQueryKeyTerms(t *testing.T) {
+	cases := []struct {
+		query, key string
+		want       bool
+	}{
+		{"report", "a/Q1-Report.pdf", true}, // substring, as before
+		{"*.parquet", "warehouse/2025/part-0.PARQUET", true},
+		{"*.parquet", "warehouse/parquet/readme.md", false},
+		{"part-?.parquet", "x/part-7.parquet", true},
+		{"logs/*/*.gz", "logs/2025/app.gz", true},
+		{"logs/*/*.gz", "logs/2025/10/app.gz", false}, // "*" stays within a segment
+		{"re:^logs/.*\\.gz$", "logs/2025/10/app.gz", true},
+		{"re:^logs/", "archive/logs/a", false},
+		{"re:APP", "logs/app.gz", true},
+		{"logs app", "logs/app.gz", true}, // terms are ANDed
+		{"logs web", "logs/app.gz", false},
+		{`"q1 report"`, "a/Q1 Report.pdf", true},
+		{`"q1 report"`, "a/Q1-Report.pdf", false},
+		{"ext:GZ", "logs/app.gz", true},
+		{"ext:.tar.gz,zip", "b/x.tar.gz", true},
+		{"ext:gz", "logs/gz.txt", false},
+		{"", "anything", true},
+	}
+	for _, tc := range cases {
+		q, err := parseQuery(tc.query, time.Now())
+		if err != nil {
+			t.Fatalf("%q: %v", tc.query, err)
+		}
+		if got := q.match(queryTarget{key: tc.key}); got != tc.want {
+			t.Errorf("%q on %q = %v, want %v", tc.query, tc.key, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"re:(", "[a-", `"open`, "size>lots", "modified<someday", "size<", "ext:"} {
+		if _, err := parseQuery(bad, time.Now()); err == nil {
+			t.Errorf("%q parsed", bad)
+		}
+	}
+}
+
+func TestParseQueryAttributes(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 9, 30, 0, 0, time.UTC) }
+	obj := queryTarget{key: "logs/app.gz", size: 150_000_000, mod: day(1), class: "GLACIER"}
+	cases := []struct {
+		query string
+		want  bool
+	}{
+		{"size>143M", true}, // bare units are binary, as listings show sizes
+		{"size>=144m", false},
+		{"size<143M", false},
+		{"size>=150MB", true}, // decimal when spelled so
+		{"size>150MB", false},
+		{"size=150000000", true},
+		{"size<1GiB", true},
+		{"size<=100MiB", false},
+		{"modified<2026-05-01", false},
+		{"modified<=2026-05-01", true}, // the whole day
+		{"modified=2026-05-01", true},
+		{"modified>2026-05-01", false},
+		{"modified>=2026-05-01", true},
+		{"modified<2026-05-01T09:31", true},
+		{"modified>2026-05-01T09:30", false},
+		{"modified<7d", true}, // older than a week
+		{"modified>2w", true}, // newer than two weeks
+		{"class:glacier", true},
+		{"class:STANDARD,DEEP_ARCHIVE", false},
+		{"size>100M class:GLACIER ext:gz re:^logs/", true},
+		{"size>100M class:GLACIER ext:gz re:^web/", false},
+	}
+	for _, tc := range cases {
+		q, err := parseQuery(tc.query, now)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.query, err)
+		}
+		if got := q.match(obj); got != tc.want {
+			t.Errorf("%q = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+
+	// No class reported means STANDARD; no date matches no date term.
+	q, _ := parseQuery("class:standard", now)
+	if !q.match(queryTarget{key: "a"}) {
+		t.Error("an object without a class is not STANDARD")
+	}
+	q, _ = parseQuery("modified<2030-01-01", now)
+	if q.match(queryTarget{key: "a"}) {
+		t.Error("an undated object matched a date")
+	}
+	// Folders have only a name.
+	q, _ = parseQuery("size<1", now)
+	if q.match(queryTarget{key: "dir", folder: true}) {
+		t.Error("a folder matched a size")
+	}
+}
+
+func TestComputeHitsPredicates(t *testing.T) {
+	old, recent := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Now()
+	key := func(s string) *string { return &s }
+	objs := []s3t.Object{
+		{Key: key("data/a.parquet"), Size: 5 << 30, LastModified: &old, StorageClass: s3t.ObjectStorageClassGlacier},
+		{Key: key("data/b.parquet"), Size: 10, LastModified: &recent},
+		{Key: key("data/"), Size: 0}, // folder marker
+	}
+	hits, _ := computeHits(objs, "ext:parquet size>1G modified<2025-01-01 class:GLACIER", 0)
+	if len(hits) != 1 || hits[0].key != "data/a.parquet" {
+		t.Errorf("hits %+v", hits)
+	}
+	if hits, _ := computeHits(objs, "size<1", 0); len(hits) != 0 {
+		t.Errorf("the folder marker matched: %+v", hits)
+	}
+}
+
+func TestFilterSortObjectsQuery(t *testing.T) {
+	size := func(n int64) *int64 { return &n }
+	name := func(s string) *string { return &s }
+	objs := []*model.Object{
+		{Key: name("logs"), Ot: model.Folder},
+		{Key: name("big.csv"), Ot: model.File, Size: size(2 << 30)},
+		{Key: name("small.csv"), Ot: model.File, Size: size(10)},
+		{Key: name("notes.txt"), Ot: model.File, Size: size(3 << 30)},
+	}
+	keys := func(in []*model.Object) []string {
+		var out []string
+		for _, o := range in {
+			out = append(out, *o.Key)
+		}
+		return out
+	}
+	if got, want := keys(filterSortObjects(objs, "size>1G ext:csv", sortName, false)), []string{"big.csv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := keys(filterSortObjects(objs, "*.csv", sortName, false)), []string{"big.csv", "small.csv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("glob: got %q, want %q", got, want)
+	}
+	// A query that doesn't parse yet filters as the plain text typed so far.
+	if got, want := keys(filterSortObjects(objs, "re:(", sortName, false)), []string(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("unparsable: got %q, want %q", got, want)
+	}
+	if got, want := keys(filterSortObjects(objs, "LOG", sortName, false)), []string{"logs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("folders still match by name: got %q, want %q", got, want)
+	}
+}
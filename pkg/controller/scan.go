@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/dustin/go-humanize"
+	"github.com/rivo/tview"
+)
+
+// scanModal shows text on page behind a Cancel button and returns the modal
+// (for progress text) and the context Cancel cancels. It fronts the listing
+// phases that come before a confirm, a plan or a result — delete sizing,
+// download resolution, summary, search, duplicate and sync scans — which used
+// to page through the whole prefix however long ago the user gave up on them.
+//
+// Cancel closes the page itself. The goroutine doing the scan then returns as
+// soon as it sees ctx.Err(), without touching the pages: by then they may hold
+// something the user opened since. UI goroutine.
+func (c *Controller) scanModal(page, text string) (*tview.Modal, context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Cancel"}).
+		SetDoneFunc(func(_ int, _ string) {
+			cancel()
+			c.view.Pages.RemovePage(page).SwitchToPage("main")
+		})
+	c.view.Pages.AddPage(page, modal, true, true)
+	return modal, ctx, cancel
+}
+
+// scanCounter returns a progress callback for model.ListObjectsSharded that
+// shows the running object count under text on a scanModal. The sharded lister
+// calls it from several goroutines at once, a page at a time, so it keeps the
+// highest count seen and has at most one redraw queued. Any goroutine but the
+// UI one.
+func (c *Controller) scanCounter(modal *tview.Modal, text string) func(total int) {
+	var highest atomic.Int64
+	var queued atomic.Bool
+	return func(total int) {
+		for {
+			cur := highest.Load()
+			if int64(total) <= cur || highest.CompareAndSwap(cur, int64(total)) {
+				break
+			}
+		}
+		if !queued.CompareAndSwap(false, true) {
+			return
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			queued.Store(false)
+			modal.SetText(fmt.Sprintf("%s\n\n%s objects scanned", text, humanize.Comma(highest.Load())))
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+// selectMaxRows is how many result rows the console keeps; a query past it
+// is stopped and the title says so. The rows live in a tview.Table, which
+// holds every cell.
+const selectMaxRows = 100_000
+
+const selectPage = "select-results"
+
+// selectTable accumulates query results as columns and rows. Records may not
+// share a shape — JSON rows especially — so columns are the union of the names
+// seen, in first-seen order, and a row leaves the columns it lacks empty.
+type selectTable struct {
+	cols  []string
+	index map[string]int
+	rows  [][]string
+}
+
+// add appends rec and reports whether it introduced a new column.
+func (t *selectTable) add(rec model.SelectRecord) (newCols bool) {
+	if t.index == nil {
+		t.index = map[string]int{}
+	}
+	row := make([]string, len(t.cols))
+	for _, f := range rec {
+		i, ok := t.index[f.Name]
+		if !ok {
+			i = len(t.cols)
+			t.index[f.Name] = i
+			t.cols = append(t.cols, f.Name)
+			newCols = true
+		}
+		for len(row) <= i {
+			row = append(row, "")
+		}
+		row[i] = f.Value
+	}
+	t.rows = append(t.rows, row)
+	return newCols
+}
+
+// writeCSV writes the table as CSV: a header of the column names, then one
+// line per row, padded to the full width.
+func (t *selectTable) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.cols); err != nil {
+		return err
+	}
+	for _, r := range t.rows {
+		full := make([]string, len(t.cols))
+		copy(full, r)
+		if err := cw.Write(full); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// delimiterText shows a CSV delimiter in a form field, a tab as `\t`;
+// delimiterValue reads it back.
+func delimiterText(d string) string {
+	if d == "\t" {
+		return `\t`
+	}
+	return d
+}
+
+func delimiterValue(s string) string {
+	if s == `\t` {
+		return "\t"
+	}
+	return s
+}
+
+// SelectConsole opens the S3 Select query form for the highlighted object,
+// with the format and compression guessed from its name. UI goroutine.
+func (c *Controller) SelectConsole() {
+	_, obj, ok := c.currentObject()
+	if !ok || obj.Ot != model.File || obj.FullPath == nil {
+		go c.error("S3 Select", fmt.Errorf("highlight a CSV, JSON or Parquet object first"))
+		return
+	}
+	format, compression, delim := model.SelectFormatFor(*obj.FullPath)
+	c.selectForm(c.model, c.currentBucket, *obj.FullPath, model.SelectInput{
+		Expression:  "SELECT * FROM S3Object s LIMIT 100",
+		Format:      format,
+		Header:      true,
+		Delimiter:   delim,
+		Compression: compression,
+	})
+}
+
+// selectForm asks for the query and input options, starting from in. UI
+// goroutine.
+func (c *Controller) selectForm(mdl *model.Model, bucket *model.Object, key string, in model.SelectInput) {
+	indexOf := func(list []string, s string) int {
+		for i, v := range list {
+			if v == s {
+				return i
+			}
+		}
+		return 0
+	}
+	form := tview.NewForm()
+	form.SetTitle(fmt.Sprintf("S3 Select — %s", path.Base(key)))
+	form.AddInputField("SQL", in.Expression, 70, nil, nil)
+	form.AddDropDown("Format", model.SelectFormats, indexOf(model.SelectFormats, in.Format), nil)
+	form.AddDropDown("Compression", model.SelectCompressions, indexOf(model.SelectCompressions, in.Compression), nil)
+	form.AddCheckbox("CSV header row", in.Header, nil)
+	form.AddInputField("CSV delimiter", delimiterText(in.Delimiter), 4, nil, nil)
+	form.SetBorder(true)
+	form.AddButton("Run", func() {
+		_, in.Format = form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
+		_, in.Compression = form.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
+		in.Expression = strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		in.Header = form.GetFormItem(3).(*tview.Checkbox).IsChecked()
+		in.Delimiter = delimiterValue(form.GetFormItem(4).(*tview.InputField).GetText())
+		c.view.Pages.RemovePage("modal")
+		if in.Expression == "" {
+			return
+		}
+		c.runSelect(mdl, bucket, key, in)
+	})
+	form.AddButton("Cancel", func() { c.view.Pages.RemovePage("modal") })
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			c.view.Pages.RemovePage("modal")
+		}
+		return event
+	})
+	c.view.Pages.AddPage("modal", c.view.ModalEdit(form, 90, 15), true, true)
+}
+
+// runSelect runs the query and streams its rows into a results table as they
+// arrive: the worker queues records, and at most one redraw that moves them
+// into the table is pending at a time. Esc stops a running query and closes
+// the table; e edits the query; s saves the rows as CSV next to downloads. UI
+// goroutine; the query is not.
+func (c *Controller) runSelect(mdl *model.Model, bucket *model.Object, key string, in model.SelectInput) {
+	ctx, cancel := context.WithCancel(context.Background())
+	table := tview.NewTable().SetFixed(1, 0).SetSelectable(true, false)
+	table.SetBorder(true)
+	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorBlue).Foreground(tcell.ColorWhite))
+	status := tview.NewTextView().SetDynamicColors(true)
+
+	var (
+		mu      sync.Mutex
+		pending []model.SelectRecord
+		queued  atomic.Bool
+		res     selectTable // UI goroutine only
+		state   = "running…"
+	)
+	title := func() {
+		table.SetTitle(fmt.Sprintf(" %s — %d row(s), %s ", path.Base(key), len(res.rows), state))
+	}
+	flush := func() {
+		queued.Store(false)
+		mu.Lock()
+		batch := pending
+		pending = nil
+		mu.Unlock()
+		for _, rec := range batch {
+			if res.add(rec) {
+				for i, name := range res.cols {
+					table.SetCell(0, i, tview.NewTableCell(tview.Escape(name)).
+						SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAttributes(tcell.AttrBold))
+				}
+			}
+			r := len(res.rows)
+			for i, v := range res.rows[r-1] {
+				table.SetCell(r, i, tview.NewTableCell(tview.Escape(v)).SetMaxWidth(60))
+			}
+		}
+		title()
+	}
+	title()
+	status.SetText("  [::b]Esc[::-] stop / close   [::b]e[::-] edit query   [::b]s[::-] save CSV   [::b]↑↓←→[::-] scroll")
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			cancel()
+			c.view.Pages.RemovePage(selectPage)
+			c.view.App.SetFocus(c.view.List)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'e':
+			cancel()
+			c.view.Pages.RemovePage(selectPage)
+			c.selectForm(mdl, bucket, key, in)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 's':
+			c.saveSelect(key, &res)
+			return nil
+		}
+		return event
+	})
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(status, 1, 0, false)
+	c.view.Pages.RemovePage(selectPage)
+	c.view.Pages.AddPage(selectPage, c.view.ModalClamped(flex, 160, 40), true, true)
+	c.view.App.SetFocus(table)
+
+	go func() {
+		defer cancel()
+		rows := 0
+		local, err := mdl.SelectObject(ctx, bucket, key, in, func(rec model.SelectRecord) bool {
+			if rows >= selectMaxRows {
+				return false
+			}
+			rows++
+			mu.Lock()
+			pending = append(pending, rec)
+			mu.Unlock()
+			if queued.CompareAndSwap(false, true) {
+				c.view.App.QueueUpdateDraw(flush)
+			}
+			return true
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		done := "S3 Select"
+		if local {
+			done = "evaluated locally (the endpoint has no S3 Select)"
+		}
+		if rows >= selectMaxRows {
+			done = fmt.Sprintf("stopped at %d rows, %s", selectMaxRows, done)
+		}
+		c.view.App.QueueUpdateDraw(func() {
+			flush()
+			if err != nil {
+				state = "failed"
+				status.SetText(fmt.Sprintf("  [red]%s[-]   [::b]e[::-] edit query   [::b]Esc[::-] close", tview.Escape(err.Error())))
+			} else {
+				state = done
+			}
+			title()
+		})
+		if err == nil {
+			c.logActivity("S3 Select on %s/%s: %d row(s), %s", *bucket.Key, key, rows, done)
+		}
+	}()
+}
+
+// saveSelect writes the rows shown so far as CSV next to downloads. UI
+// goroutine; the write is not.
+func (c *Controller) saveSelect(key string, res *selectTable) {
+	if len(res.rows) == 0 {
+		go c.error("Save results", fmt.Errorf("no rows to save"))
+		return
+	}
+	snapshot := selectTable{cols: append([]string(nil), res.cols...), rows: append([][]string(nil), res.rows...)}
+	dir := c.resolveDownloadDir()
+	dst := filepath.Join(dir, fmt.Sprintf("%s.select-%s.csv", path.Base(key), time.Now().Format("20060102-150405")))
+	go func() {
+		err := os.MkdirAll(dir, 0o755)
+		if err == nil {
+			var f *os.File
+			if f, err = os.Create(dst); err == nil {
+				err = snapshot.writeCSV(f)
+				if cerr := f.Close(); err == nil {
+					err = cerr
+				}
+			}
+		}
+		if err != nil {
+			c.error("Save results", err)
+			return
+		}
+		c.logActivity("saved %d S3 Select row(s) of %s to %s", len(snapshot.rows), key, dst)
+		c.success(fmt.Sprintf("%d row(s) saved to %s", len(snapshot.rows), dst))
+	}()
+}
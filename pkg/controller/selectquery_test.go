@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/nexusriot/s3duck-tui/pkg/model"
+)
+
+func TestSelectTable(t *testing.T) {
+	var tab selectTable
+	if !tab.add(model.SelectRecord{{Name: "id", Value: "1"}, {Name: "name", Value: "ann"}}) {
+		t.Error("the first record added no columns")
+	}
+	if tab.add(model.SelectRecord{{Name: "name", Value: "bob"}, {Name: "id", Value: "2"}}) {
+		t.Error("known columns were reported as new")
+	}
+	if !tab.add(model.SelectRecord{{Name: "id", Value: "3"}, {Name: "tags", Value: `["x"]`}}) {
+		t.Error("a new column went unreported")
+	}
+	if want := []string{"id", "name", "tags"}; !reflect.DeepEqual(tab.cols, want) {
+		t.Errorf("cols %q, want %q", tab.cols, want)
+	}
+	want := [][]string{{"1", "ann"}, {"2", "bob"}, {"3", "", `["x"]`}}
+	if !reflect.DeepEqual(tab.rows, want) {
+		t.Errorf("rows %q, want %q", tab.rows, want)
+	}
+
+	var buf bytes.Buffer
+	if err := tab.writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "id,name,tags\n1,ann,\n2,bob,\n3,,\"[\"\"x\"\"]\"\n"; got != want {
+		t.Errorf("csv:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDelimiterText(t *testing.T) {
+	for _, d := range []string{",", "\t", ";", "|"} {
+		if got := delimiterValue(delimiterText(d)); got != d {
+			t.Errorf("%q round-tripped to %q", d, got)
+		}
+	}
+	if delimiterText("\t") != `\t` {
+		t.Error("a tab is not shown as \\t")
+	}
+}
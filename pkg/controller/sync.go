@@ -27,6 +27,10 @@ const (
 	syncUpload   syncDirection = iota // local dir → S3 prefix
 	syncDownload                      // S3 prefix → local dir
 	syncRemote                        // S3 prefix → S3 prefix (server-side copy)
+	// syncCrossProfile is S3 prefix → a prefix on another profile, streamed
+	// with CrossCopy. It is not in the sync dialog's dropdown: it starts from
+	// the cross-profile transfer (">"), where the destination profile is chosen.
+	syncCrossProfile
 )
 
 func (d syncDirection) String() string {
@@ -35,6 +39,8 @@ func (d syncDirection) String() string {
 		return "remote → local"
 	case syncRemote:
 		return "remote → remote"
+	case syncCrossProfile:
+		return "remote → other profile"
 	default:
 		return "local → remote"
 	}
@@ -243,9 +249,10 @@ func splitSyncPhases(ops []syncOp) (writes, deletes []indexedOp) {
 //
 // Only the fields the direction uses are populated:
 //
-//	syncUpload    localDir            → dstBucket/dstPrefix
-//	syncDownload  srcBucket/srcPrefix → localDir
-//	syncRemote    srcBucket/srcPrefix → dstBucket/dstPrefix
+//	syncUpload        localDir            → dstBucket/dstPrefix
+//	syncDownload      srcBucket/srcPrefix → localDir
+//	syncRemote        srcBucket/srcPrefix → dstBucket/dstPrefix
+//	syncCrossProfile  srcBucket/srcPrefix → dst: dstBucket/dstPrefix
 type syncSpec struct {
 	dir       syncDirection
 	localDir  string
@@ -254,6 +261,12 @@ type syncSpec struct {
 	dstBucket *model.Object
 	dstPrefix string
 	del       bool
+
+	// dst and dstProfile are the destination profile's client and name for
+	// syncCrossProfile; every other direction writes through the browsing
+	// client.
+	dst        *model.Model
+	dstProfile string
 }
 
 // prefixesOverlap reports whether one normalized prefix contains the other
@@ -283,8 +296,11 @@ func (s syncSpec) srcLabel() string {
 }
 
 func (s syncSpec) dstLabel() string {
-	if s.dir == syncDownload {
+	switch s.dir {
+	case syncDownload:
 		return s.localDir
+	case syncCrossProfile:
+		return s.dstProfile + ":" + remoteLabel(s.dstBucket, s.dstPrefix)
 	}
 	return remoteLabel(s.dstBucket, s.dstPrefix)
 }
@@ -305,9 +321,11 @@ func (c *Controller) Sync() {
 	// In dual-pane mode the other pane is the obvious remote destination. Read
 	// the pane state here, on the UI goroutine — the goroutine below must not
 	// touch c.dual/c.panes/c.active, which the pane-switch handlers write.
+	// A pane on another endpoint is no remote → remote destination (that is
+	// `>`'s cross-profile sync), so it is only offered on the same one.
 	dstName, dstPrefix := *bucket.Key, prefix
 	if c.dual {
-		if other := c.panes[1-c.active]; other.currentBucket != nil {
+		if other := c.panes[1-c.active]; other.currentBucket != nil && model.SameEndpoint(c.model, other.model) {
 			dstName = *other.currentBucket.Key
 			dstPrefix = model.NormalizePrefix(other.currentPath)
 		}
@@ -318,7 +336,7 @@ func (c *Controller) Sync() {
 	mdl := c.model
 	go func() {
 		bucketNames := []string{*bucket.Key}
-		if list, err := mdl.ListBuckets(); err == nil {
+		if list, err := mdl.ListBuckets(context.Background()); err == nil {
 			bucketNames = bucketNames[:0]
 			for _, b := range list {
 				if b != nil && b.Key != nil {
@@ -415,7 +433,7 @@ func syncDirectionLabels() []string {
 // collectSides gathers the entry lists for both sides of a spec. A missing
 // local directory is fatal for an upload (nothing to send) but normal for a
 // download's first run, where the transfer will create it.
-func (c *Controller) collectSides(mdl *model.Model, spec syncSpec) (src, dst []model.SyncEntry, err error) {
+func (c *Controller) collectSides(ctx context.Context, mdl *model.Model, spec syncSpec) (src, dst []model.SyncEntry, err error) {
 	local := func() ([]model.SyncEntry, error) {
 		entries, err := model.WalkLocal(spec.localDir)
 		if err != nil && spec.dir == syncDownload && os.IsNotExist(err) {
@@ -429,17 +447,22 @@ func (c *Controller) collectSides(mdl *model.Model, spec syncSpec) (src, dst []m
 		if src, err = local(); err != nil {
 			return nil, nil, err
 		}
-		dst, err = mdl.ListRemoteEntries(spec.dstPrefix, spec.dstBucket)
+		dst, err = mdl.ListRemoteEntries(ctx, spec.dstPrefix, spec.dstBucket)
 	case syncDownload:
-		if src, err = mdl.ListRemoteEntries(spec.srcPrefix, spec.srcBucket); err != nil {
+		if src, err = mdl.ListRemoteEntries(ctx, spec.srcPrefix, spec.srcBucket); err != nil {
 			return nil, nil, err
 		}
 		dst, err = local()
+	case syncCrossProfile:
+		if src, err = mdl.ListRemoteEntries(ctx, spec.srcPrefix, spec.srcBucket); err != nil {
+			return nil, nil, err
+		}
+		dst, err = spec.dst.ListRemoteEntries(ctx, spec.dstPrefix, spec.dstBucket)
 	default: // syncRemote
-		if src, err = mdl.ListRemoteEntries(spec.srcPrefix, spec.srcBucket); err != nil {
+		if src, err = mdl.ListRemoteEntries(ctx, spec.srcPrefix, spec.srcBucket); err != nil {
 			return nil, nil, err
 		}
-		dst, err = mdl.ListRemoteEntries(spec.dstPrefix, spec.dstBucket)
+		dst, err = mdl.ListRemoteEntries(ctx, spec.dstPrefix, spec.dstBucket)
 	}
 	if err != nil {
 		return nil, nil, err
@@ -447,16 +470,36 @@ func (c *Controller) collectSides(mdl *model.Model, spec syncSpec) (src, dst []m
 	return src, dst, nil
 }
 
+// pinSyncDst returns spec with a cross-profile destination switched to the
+// profile's client for the destination bucket's region; the plan is scanned
+// and applied through it. Other directions are returned unchanged. A failed
+// lookup is reported and the spec's client kept. Off the UI goroutine.
+func (c *Controller) pinSyncDst(spec syncSpec) syncSpec {
+	if spec.dir != syncCrossProfile || spec.dst == nil || spec.dstBucket == nil || spec.dstBucket.Key == nil {
+		return spec
+	}
+	dst, err := spec.dst.ForBucket(*spec.dstBucket.Key)
+	if err != nil {
+		c.error("Failed to resolve destination region", err)
+	}
+	spec.dst = dst
+	return spec
+}
+
 // previewSync scans both sides off the UI goroutine and shows the plan with an
 // Apply button. Scanning is a paginated listing (or a filesystem walk), so it
-// runs behind a "Scanning…" modal.
+// runs behind a cancellable "Scanning…" modal.
 func (c *Controller) previewSync(spec syncSpec) {
 	mdl := c.model
-	scanning := tview.NewModal().SetText("Scanning both sides...")
-	c.view.Pages.AddPage("progress", scanning, true, true)
+	_, ctx, cancel := c.scanModal("progress", "Scanning both sides...")
 
 	go func() {
-		src, dst, err := c.collectSides(mdl, spec)
+		defer cancel()
+		spec := c.pinSyncDst(spec)
+		src, dst, err := c.collectSides(ctx, mdl, spec)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress").SwitchToPage("main") })
 			c.error("Sync scan failed", err)
@@ -502,6 +545,23 @@ func (c *Controller) showPlan(title, text string, onApply func(), applicable boo
 	c.view.Pages.AddPage("modal", c.view.ModalEdit(flex, 86, 28), true, true)
 }
 
+// retrySyncOps picks the plan operations for the listed relative paths,
+// keeping plan order. A rel appears at most once in a plan, so it identifies
+// its operation.
+func retrySyncOps(ops []syncOp, rels []string) []syncOp {
+	want := make(map[string]bool, len(rels))
+	for _, r := range rels {
+		want[r] = true
+	}
+	var out []syncOp
+	for _, op := range ops {
+		if want[op.Rel] {
+			out = append(out, op)
+		}
+	}
+	return out
+}
+
 // runSync applies a reviewed plan as a background-able transfer job.
 // Failures are collected rather than aborting the run, so one unreadable file
 // doesn't strand the rest of the sync.
@@ -513,6 +573,11 @@ func (c *Controller) runSync(spec syncSpec, ops []syncOp) {
 	st := summarizeSync(ops)
 
 	job := c.addJob("sync", fmt.Sprintf("%s  %s → %s", spec.dir, spec.srcLabel(), spec.dstLabel()), st.Bytes, len(ops), cancel)
+	job.setRetry(func(rels []string) {
+		if again := retrySyncOps(ops, rels); len(again) > 0 {
+			c.runSync(spec, again)
+		}
+	})
 
 	progress := tview.NewModal().
 		SetText("Starting sync...\n").
@@ -623,6 +688,11 @@ func (c *Controller) runSync(spec syncSpec, ops []syncOp) {
 						draw(it.index, it.op, written)
 					})
 
+					if err != nil {
+						job.recordItem(it.op.Rel, itemFailed, fmt.Sprintf("%s: %v", it.op.Kind, err))
+					} else {
+						job.recordItem(it.op.Rel, itemOK, it.op.Kind.String())
+					}
 					mu.Lock()
 					delete(inFlight, it.index)
 					doneCount++
@@ -654,6 +724,9 @@ func (c *Controller) runSync(spec syncSpec, ops []syncOp) {
 			mu.Lock()
 			failed = append(failed, fmt.Sprintf("%d delete(s) skipped: %d write(s) failed", len(deletes), writesFailed))
 			mu.Unlock()
+			for _, d := range deletes {
+				job.recordItem(d.op.Rel, itemSkipped, "delete held back: a write failed")
+			}
 		}
 		canceled := ctx.Err() != nil
 
@@ -702,17 +775,18 @@ func (c *Controller) runSync(spec syncSpec, ops []syncOp) {
 }
 
 // refreshAfterSync re-lists the browser only when the run could have changed
-// what it is showing — that is, whenever the destination was remote.
+// what it is showing — that is, whenever the destination was a remote on the
+// browsing profile.
 func (c *Controller) refreshAfterSync(spec syncSpec) {
-	if spec.dir != syncDownload {
+	if spec.dir != syncDownload && spec.dir != syncCrossProfile {
 		c.updateList()
 	}
 }
 
 // applySyncOp performs one planned operation. Which side is written follows
 // from the direction: an upload writes to (and deletes from) S3, a download
-// writes to the local tree, and a remote→remote run copies server-side between
-// the two prefixes.
+// writes to the local tree, a remote→remote run copies server-side between
+// the two prefixes, and a cross-profile run streams into the other profile.
 func (c *Controller) applySyncOp(ctx context.Context, mdl *model.Model, spec syncSpec, op syncOp, onProgress func(written int64)) error {
 	switch spec.dir {
 	case syncUpload:
@@ -743,6 +817,14 @@ func (c *Controller) applySyncOp(ctx context.Context, mdl *model.Model, spec syn
 			})
 		return err
 
+	case syncCrossProfile:
+		dstKey := spec.dstPrefix + op.Rel
+		if op.Kind == syncDelete {
+			return spec.dst.DeleteKey(ctx, dstKey, spec.dstBucket)
+		}
+		return model.CrossCopy(ctx, mdl, spec.srcBucket, spec.srcPrefix+op.Rel, spec.dst, spec.dstBucket, dstKey,
+			func(written, _ int64) { onProgress(written) })
+
 	default: // syncRemote
 		dstKey := spec.dstPrefix + op.Rel
 		if op.Kind == syncDelete {
@@ -804,7 +886,8 @@ func compareLabel(k syncOpKind) string {
 // can never disagree about what counts as a difference.
 func (c *Controller) ComparePanes() {
 	if !c.dual {
-		go c.error("Compare", fmt.Errorf("compare needs dual-pane mode (Ctrl+O)"))
+		// One pane has nothing to compare with but the highlighted file.
+		c.DiffWith()
 		return
 	}
 	other := c.panes[1-c.active]
@@ -816,13 +899,26 @@ func (c *Controller) ComparePanes() {
 	left := syncSpec{dir: syncRemote,
 		srcBucket: c.currentBucket, srcPrefix: model.NormalizePrefix(c.currentPath),
 		dstBucket: other.currentBucket, dstPrefix: model.NormalizePrefix(other.currentPath)}
+	if !model.SameEndpoint(c.model, other.model) {
+		// Panes on different endpoints: list the right side through its own
+		// client, exactly as a sync to another profile would.
+		left.dir = syncCrossProfile
+		left.dst = other.model
+		if other.config != nil {
+			left.dstProfile = other.config.Name
+		}
+	}
 
-	scanning := tview.NewModal().SetText("Comparing both panes...")
-	c.view.Pages.AddPage("progress", scanning, true, true)
+	_, ctx, cancel := c.scanModal("progress", "Comparing both panes...")
 
 	mdl := c.model
 	go func() {
-		src, dst, err := c.collectSides(mdl, left)
+		defer cancel()
+		left := c.pinSyncDst(left)
+		src, dst, err := c.collectSides(ctx, mdl, left)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			c.view.App.QueueUpdateDraw(func() { c.view.Pages.RemovePage("progress").SwitchToPage("main") })
 			c.error("Compare failed", err)
@@ -832,10 +928,24 @@ func (c *Controller) ComparePanes() {
 		// comparison must be symmetric even though the planner is directional.
 		ops := planSync(src, dst, true)
 		text := comparePlanText(left.srcLabel(), left.dstLabel(), ops, syncPlanRows)
+		var differing []string
+		for _, op := range ops {
+			if op.Kind == syncUpdate {
+				differing = append(differing, op.Rel)
+			}
+		}
+		dstMdl := mdl
+		if left.dst != nil {
+			dstMdl = left.dst
+		}
+		diff := func(rel string) {
+			c.ShowDiff(objectDiffSide(mdl, left.srcBucket, left.srcPrefix+rel),
+				objectDiffSide(dstMdl, left.dstBucket, left.dstPrefix+rel))
+		}
 
 		c.view.App.QueueUpdateDraw(func() {
 			c.view.Pages.RemovePage("progress").SwitchToPage("main")
-			c.showPlan(" Pane comparison ", text, nil, false)
+			c.showComparison(text, differing, diff)
 		})
 	}()
 }
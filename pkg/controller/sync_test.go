@@ -302,6 +302,10 @@ func TestSyncDirectionLabelsMatchConstants(t *testing.T) {
 	if syncRemote.String() != "remote → remote" {
 		t.Errorf("syncRemote.String() = %q", syncRemote)
 	}
+	// The cross-profile direction is reached from `>`, never the dropdown.
+	if int(syncCrossProfile) < len(labels) {
+		t.Errorf("syncCrossProfile (%d) collides with a dropdown entry", int(syncCrossProfile))
+	}
 }
 
 func TestSyncSpecLabels(t *testing.T) {
@@ -336,6 +340,17 @@ func TestSyncSpecLabels(t *testing.T) {
 		}
 	})
 
+	t.Run("cross-profile names the destination profile", func(t *testing.T) {
+		s := syncSpec{dir: syncCrossProfile, localDir: "/ignored", dstProfile: "aws-prod",
+			srcBucket: obj("src"), srcPrefix: "a/", dstBucket: obj("dst"), dstPrefix: "b/"}
+		if got := s.srcLabel(); got != "src/a/" {
+			t.Errorf("src = %q", got)
+		}
+		if got := s.dstLabel(); got != "aws-prod:dst/b/" {
+			t.Errorf("dst = %q", got)
+		}
+	})
+
 	t.Run("a nil bucket degrades instead of panicking", func(t *testing.T) {
 		s := syncSpec{dir: syncRemote}
 		if got := s.srcLabel(); got != "(no bucket)" {
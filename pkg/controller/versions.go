@@ -94,6 +94,34 @@ func (c *Controller) ShowVersions() {
 	}()
 }
 
+// versionDiffPair picks the two versions "=" diffs from the history list,
+// which runs newest first: the highlighted one, i, against the one marked
+// with Space, or when none is against the version before it — what that
+// version changed. Delete markers have no content and are passed over.
+func versionDiffPair(vs []model.ObjectVersion, i, marked int) (older, newer int, ok bool) {
+	if i < 0 || i >= len(vs) || vs[i].IsDeleteMark {
+		return 0, 0, false
+	}
+	other := -1
+	if marked >= 0 && marked < len(vs) && marked != i {
+		if vs[marked].IsDeleteMark {
+			return 0, 0, false
+		}
+		other = marked
+	} else {
+		for j := i + 1; j < len(vs); j++ {
+			if !vs[j].IsDeleteMark {
+				other = j
+				break
+			}
+		}
+	}
+	if other < 0 {
+		return 0, 0, false
+	}
+	return max(i, other), min(i, other), true
+}
+
 // presentVersions builds the history list and its key bindings. Runs on the UI
 // goroutine.
 func (c *Controller) presentVersions(bucket *model.Object, key, shortName string, versions []model.ObjectVersion) {
@@ -108,8 +136,23 @@ func (c *Controller) presentVersions(bucket *model.Object, key, shortName string
 	}
 
 	help := tview.NewTextView().SetDynamicColors(true).SetText(
-		"  [::b]Enter[::-] restore as latest   [::b]w[::-] download this version   " +
-			"[::b]d[::-] delete permanently   [::b]Esc[::-] close")
+		"  [::b]Enter[::-] restore   [::b]w[::-] download   [::b]d[::-] delete forever   " +
+			"[::b]Space[::-] mark   [::b]=[::-] diff   [::b]Esc[::-] close")
+
+	marked := -1
+	mark := func(i int) {
+		if marked >= 0 {
+			primary, secondary := versionRow(versions[marked])
+			list.SetItemText(marked, primary, secondary)
+		}
+		if i == marked {
+			marked = -1
+			return
+		}
+		marked = i
+		primary, secondary := versionRow(versions[i])
+		list.SetItemText(i, "[yellow]*[-]"+primary, secondary)
+	}
 
 	selected := func() (model.ObjectVersion, bool) {
 		i := list.GetCurrentItem()
@@ -141,6 +184,20 @@ func (c *Controller) presentVersions(bucket *model.Object, key, shortName string
 					c.confirmDeleteVersion(bucket, key, shortName, v)
 				}
 				return nil
+			case ' ':
+				if _, ok := selected(); ok {
+					mark(list.GetCurrentItem())
+				}
+				return nil
+			case '=':
+				older, newer, ok := versionDiffPair(versions, list.GetCurrentItem(), marked)
+				if !ok {
+					go c.error("Diff", fmt.Errorf("no other version of %s with content to diff against", shortName))
+					return nil
+				}
+				c.ShowDiff(versionDiffSide(c.model, bucket, key, versions[older]),
+					versionDiffSide(c.model, bucket, key, versions[newer]))
+				return nil
 			}
 		}
 		return event
@@ -82,3 +82,35 @@ func TestVersionsTitle(t *testing.T) {
 		t.Errorf("no markers should mean no marker note: %s", clean)
 	}
 }
+
+func TestVersionDiffPair(t *testing.T) {
+	vs := []model.ObjectVersion{
+		{VersionID: "v4", IsLatest: true},
+		{VersionID: "dm", IsDeleteMark: true},
+		{VersionID: "v2"},
+		{VersionID: "v1"},
+	}
+	cases := []struct {
+		name         string
+		i, marked    int
+		older, newer int
+		ok           bool
+	}{
+		{"the latest against the version before it, past a delete marker", 0, -1, 2, 0, true},
+		{"an older version against its predecessor", 2, -1, 3, 2, true},
+		{"the oldest has nothing before it", 3, -1, 0, 0, false},
+		{"against the marked version, whichever is newer", 3, 0, 3, 0, true},
+		{"marking the highlighted version itself is ignored", 2, 2, 3, 2, true},
+		{"a delete marker has no content", 1, -1, 0, 0, false},
+		{"nor does a marked delete marker", 0, 1, 0, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			older, newer, ok := versionDiffPair(vs, tc.i, tc.marked)
+			if ok != tc.ok || ok && (older != tc.older || newer != tc.newer) {
+				t.Errorf("versionDiffPair(%d, %d) = %d, %d, %v; want %d, %d, %v",
+					tc.i, tc.marked, older, newer, ok, tc.older, tc.newer, tc.ok)
+			}
+		})
+	}
+}
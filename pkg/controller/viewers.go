@@ -0,0 +1,531 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// previewKind is how the preview renders an object: text kinds by type, and
+// images (binary, but recognised by their header; see imagepreview.go).
+type previewKind int
+
+const (
+	kindText previewKind = iota
+	kindJSON
+	kindNDJSON
+	kindYAML
+	kindCSV
+	kindTSV
+	kindMarkdown
+	kindImage
+)
+
+var previewKindNames = [...]string{"text", "JSON", "NDJSON", "YAML", "CSV", "TSV", "Markdown", "image"}
+
+func (k previewKind) String() string { return previewKindNames[k] }
+
+// partial reports whether the kind can be shown from the first part of an
+// object: line-based formats can, a JSON or YAML document cut off can't.
+func (k previewKind) partial() bool {
+	return k == kindNDJSON || k == kindCSV || k == kindTSV
+}
+
+// detectPreviewKind picks the viewer for key from its Content-Type, or from
+// its extension when the type says nothing specific — objects uploaded by
+// tools that don't set one come back as binary/octet-stream or text/plain.
+func detectPreviewKind(key, contentType string) previewKind {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch mt {
+	case "application/json", "text/json":
+		return kindJSON
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines", "application/jsonlines":
+		return kindNDJSON
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return kindYAML
+	case "text/csv":
+		return kindCSV
+	case "text/tab-separated-values":
+		return kindTSV
+	case "text/markdown", "text/x-markdown":
+		return kindMarkdown
+	}
+	if strings.HasSuffix(mt, "+json") {
+		return kindJSON
+	}
+	switch strings.ToLower(path.Ext(key)) {
+	case ".json", ".geojson":
+		return kindJSON
+	case ".ndjson", ".jsonl":
+		return kindNDJSON
+	case ".yaml", ".yml":
+		return kindYAML
+	case ".csv":
+		return kindCSV
+	case ".tsv", ".tab":
+		return kindTSV
+	case ".md", ".markdown":
+		return kindMarkdown
+	}
+	return kindText
+}
+
+// foldNode is one line of a foldable tree view: its styled text (already
+// escaped for tview) and the lines folded under it.
+type foldNode struct {
+	text     string
+	children []*foldNode
+}
+
+// jsonFold parses a JSON document into a fold tree, keeping object keys in
+// the order they appear. Anything but exactly one value is an error.
+func jsonFold(data []byte) (*foldNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := jsonValue(dec, "")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("more data after the top-level value")
+	}
+	return root, nil
+}
+
+// jsonValue reads the next value off dec as a node labelled with label (the
+// styled key or index leading to it).
+func jsonValue(dec *json.Decoder, label string) (*foldNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return &foldNode{text: label + jsonScalar(tok)}, nil
+	}
+	n := &foldNode{}
+	for i := 0; dec.More(); i++ {
+		child := fmt.Sprintf("[gray]%d:[-] ", i)
+		if d == '{' {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			name, _ := k.(string)
+			child = "[yellow]" + tview.Escape(quoteJSON(name)) + "[-]: "
+		}
+		c, err := jsonValue(dec, child)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, c)
+	}
+	if _, err := dec.Token(); err != nil { // the closing delimiter
+		return nil, err
+	}
+	switch {
+	case d == '{' && len(n.children) == 0:
+		n.text = label + tview.Escape("{}")
+	case d == '{':
+		n.text = label + fmt.Sprintf("{…} [gray]%d key(s)[-]", len(n.children))
+	case len(n.children) == 0:
+		n.text = label + tview.Escape("[]")
+	default:
+		n.text = label + fmt.Sprintf("[…] [gray]%d item(s)[-]", len(n.children))
+	}
+	return n, nil
+}
+
+// jsonScalar styles a JSON token that isn't a delimiter.
+func jsonScalar(tok json.Token) string {
+	switch v := tok.(type) {
+	case string:
+		return "[green]" + tview.Escape(quoteJSON(v)) + "[-]"
+	case json.Number:
+		return "[aqua]" + v.String() + "[-]"
+	case bool:
+		return "[fuchsia]" + strconv.FormatBool(v) + "[-]"
+	case nil:
+		return "[fuchsia]null[-]"
+	}
+	return tview.Escape(fmt.Sprint(tok))
+}
+
+// quoteJSON writes s as a JSON string literal.
+func quoteJSON(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// ndjsonFold makes one node per line of newline-delimited JSON, numbered and
+// showing the line itself (cut to previewLineLen), with the parsed value
+// folded under it. A line that doesn't parse stays, in red, as plain text.
+func ndjsonFold(data []byte) *foldNode {
+	root := &foldNode{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		label := fmt.Sprintf("[gray]%d[-] ", i+1)
+		v, err := jsonFold([]byte(line))
+		if err != nil {
+			root.children = append(root.children, &foldNode{text: label + "[red]" + tview.Escape(clip(line, previewLineLen)) + "[-]"})
+			continue
+		}
+		if len(v.children) > 0 {
+			v.text = label + tview.Escape(clip(line, previewLineLen))
+		} else {
+			v.text = label + v.text
+		}
+		root.children = append(root.children, v)
+	}
+	return root
+}
+
+// previewLineLen is how much of a long line a fold node shows.
+const previewLineLen = 160
+
+// clip cuts s to n bytes on a rune boundary, marking the cut.
+func clip(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+// yamlFold folds YAML by indentation: each line sits under the nearest line
+// above it that is indented less, and a "- " item under the key at its own
+// indentation, as YAML allows. It doesn't parse YAML; it highlights it, and
+// refuses only what is certainly malformed — a tab in the indentation.
+func yamlFold(data []byte) (*foldNode, error) {
+	type open struct {
+		indent int
+		node   *foldNode
+	}
+	root := &foldNode{}
+	stack := []open{{-1, root}}
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		body := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(body, "\t") {
+			return nil, fmt.Errorf("line %d: tab in the indentation", i+1)
+		}
+		indent := len(line) - len(body)
+		if body == "-" || strings.HasPrefix(body, "- ") {
+			indent++ // a sequence item may share its key's indentation
+		}
+		for stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		n := &foldNode{text: yamlLine(body)}
+		parent := stack[len(stack)-1].node
+		parent.children = append(parent.children, n)
+		stack = append(stack, open{indent, n})
+	}
+	return root, nil
+}
+
+// yamlLine styles one YAML line (without its indentation): comments and
+// document markers grey, keys yellow, and the value by its look.
+func yamlLine(s string) string {
+	switch {
+	case strings.HasPrefix(s, "#"), s == "---", s == "...", strings.HasPrefix(s, "--- "):
+		return "[gray]" + tview.Escape(s) + "[-]"
+	case s == "-":
+		return "[gray]-[-]"
+	case strings.HasPrefix(s, "- "):
+		return "[gray]-[-] " + yamlLine(strings.TrimLeft(s[2:], " "))
+	}
+	if k := yamlKeyEnd(s); k >= 0 {
+		return "[yellow]" + tview.Escape(s[:k]) + "[-]:" + yamlValue(s[k+1:])
+	}
+	return yamlValue(s)
+}
+
+// yamlKeyEnd finds the colon ending a mapping key in s: the first one outside
+// quotes followed by a blank or the end of the line, before any comment. -1
+// if s is no key.
+func yamlKeyEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case (ch == '"' || ch == '\'') && i == 0:
+			quote = ch
+		case ch == '#' && i > 0 && s[i-1] == ' ':
+			return -1
+		case ch == '{' || ch == '[':
+			return -1 // a flow collection, not a key
+		case ch == ':' && (i+1 == len(s) || s[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlValue styles a scalar with a possible trailing comment.
+func yamlValue(s string) string {
+	lead := s[:len(s)-len(strings.TrimLeft(s, " "))]
+	v := strings.TrimLeft(s, " ")
+	comment := ""
+	from := 0 // a comment can only follow a quoted scalar's closing quote
+	if v != "" && (v[0] == '"' || v[0] == '\'') {
+		if j := strings.IndexByte(v[1:], v[0]); j >= 0 {
+			from = j + 2
+		}
+	}
+	if i := strings.Index(v[from:], " #"); i >= 0 {
+		body := strings.TrimRight(v[:from+i], " ")
+		rest := v[len(body):] // the blanks, then the comment
+		text := strings.TrimLeft(rest, " ")
+		v, comment = body, rest[:len(rest)-len(text)]+"[gray]"+tview.Escape(text)+"[-]"
+	}
+	color := ""
+	switch {
+	case v == "":
+	case strings.HasPrefix(v, `"`), strings.HasPrefix(v, "'"):
+		color = "green"
+	case v == "|" || v == ">" || strings.HasPrefix(v, "|") && len(v) <= 3 || strings.HasPrefix(v, ">") && len(v) <= 3:
+		color = "gray"
+	case strings.HasPrefix(v, "&"), strings.HasPrefix(v, "*"), strings.HasPrefix(v, "!"):
+		color = "blue"
+	default:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "null", "~":
+			color = "fuchsia"
+		default:
+			if _, err := strconv.ParseFloat(strings.ReplaceAll(v, "_", ""), 64); err == nil {
+				color = "aqua"
+			}
+		}
+	}
+	if color == "" {
+		return lead + tview.Escape(v) + comment
+	}
+	return lead + "[" + color + "]" + tview.Escape(v) + "[-]" + comment
+}
+
+// csvRows parses CSV (or TSV, with comma '\t') for the table viewer. Rows may
+// be ragged; quotes are taken leniently.
+func csvRows(data []byte, comma rune) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.ReadAll()
+}
+
+// markdownStyled renders Markdown with tview style tags: headings bold (the
+// top two in yellow), emphasis, inline code and fenced blocks in aqua, list
+// bullets, quotes and rules in grey, and links as underlined text followed by
+// the URL. It styles line by line and never fails; what it doesn't recognise
+// is shown as written.
+func markdownStyled(src string) string {
+	var b strings.Builder
+	fence := ""
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trim := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trim, fence) {
+				fence = ""
+				b.WriteString("[gray]" + tview.Escape(line) + "[-]")
+			} else {
+				b.WriteString("[aqua]" + tview.Escape(line) + "[-]")
+			}
+		case strings.HasPrefix(trim, "```"), strings.HasPrefix(trim, "~~~"):
+			fence = trim[:3]
+			b.WriteString("[gray]" + tview.Escape(line) + "[-]")
+		case markdownRule(trim):
+			b.WriteString("[gray]" + strings.Repeat("─", 40) + "[-]")
+		case strings.HasPrefix(trim, "#"):
+			level := len(trim) - len(strings.TrimLeft(trim, "#"))
+			text := strings.TrimSpace(trim[level:])
+			if level > 6 || text == trim[level:] && text != "" {
+				b.WriteString(markdownInline(line)) // "#tag", not a heading
+				break
+			}
+			switch level {
+			case 1:
+				b.WriteString("[yellow::bu]" + markdownInline(text) + "[-::-]")
+			case 2:
+				b.WriteString("[yellow::b]" + markdownInline(text) + "[-::-]")
+			default:
+				b.WriteString("[::b]" + markdownInline(text) + "[::-]")
+			}
+		case strings.HasPrefix(trim, ">"):
+			b.WriteString("[gray]│[-] " + markdownInline(strings.TrimSpace(strings.TrimPrefix(trim, ">"))))
+		default:
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			if marker, rest, ok := markdownListItem(trim); ok {
+				b.WriteString(indent + "[yellow]" + marker + "[-] " + markdownInline(rest))
+			} else {
+				b.WriteString(markdownInline(line))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// markdownRule reports whether a trimmed line is a thematic break: three or
+// more of the same -, * or _ with nothing else but blanks.
+func markdownRule(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 3 {
+		return false
+	}
+	return strings.Count(s, s[:1]) == len(s) && strings.Contains("-*_", s[:1])
+}
+
+// markdownListItem splits a list item into its marker as shown (a bullet, a
+// checkbox, or the item number) and its text.
+func markdownListItem(s string) (marker, rest string, ok bool) {
+	switch {
+	case len(s) > 1 && strings.Contains("-*+", s[:1]) && s[1] == ' ':
+		marker, rest = "•", strings.TrimLeft(s[2:], " ")
+		switch {
+		case strings.HasPrefix(rest, "[ ] "):
+			marker, rest = "☐", rest[4:]
+		case strings.HasPrefix(rest, "[x] "), strings.HasPrefix(rest, "[X] "):
+			marker, rest = "☑", rest[4:]
+		}
+		return marker, rest, true
+	}
+	i := 0
+	for i < len(s) && i < 9 && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i > 0 && i+1 < len(s) && (s[i] == '.' || s[i] == ')') && s[i+1] == ' ' {
+		return s[:i+1], strings.TrimLeft(s[i+2:], " "), true
+	}
+	return "", "", false
+}
+
+// markdownInline styles the spans within one line: `code`, **strong**,
+// *emphasis* / _emphasis_, ~~strike~~, [links](url) and ![images](url). Plain
+// text between them is escaped in runs, so a literal "[red]" stays literal.
+func markdownInline(s string) string {
+	var out, plain strings.Builder
+	flush := func() {
+		out.WriteString(tview.Escape(plain.String()))
+		plain.Reset()
+	}
+	// span finds the closing delim of a span opening at i, requiring the
+	// content to be non-empty and not to start with a blank.
+	span := func(i int, delim string) (string, int, bool) {
+		start := i + len(delim)
+		if start >= len(s) || s[start] == ' ' {
+			return "", 0, false
+		}
+		j := strings.Index(s[start:], delim)
+		if j <= 0 {
+			return "", 0, false
+		}
+		return s[start : start+j], start + j + len(delim), true
+	}
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '`':
+			if j := strings.IndexByte(s[i+1:], '`'); j >= 0 {
+				flush()
+				out.WriteString("[aqua]" + tview.Escape(s[i+1:i+1+j]) + "[-]")
+				i += j + 2
+				continue
+			}
+		case strings.HasPrefix(s[i:], "**"), strings.HasPrefix(s[i:], "__"):
+			if text, next, ok := span(i, s[i:i+2]); ok {
+				flush()
+				out.WriteString("[::b]" + markdownInline(text) + "[::B]")
+				i = next
+				continue
+			}
+		case strings.HasPrefix(s[i:], "~~"):
+			if text, next, ok := span(i, "~~"); ok {
+				flush()
+				out.WriteString("[::s]" + markdownInline(text) + "[::S]")
+				i = next
+				continue
+			}
+		case s[i] == '*' || s[i] == '_' && (i == 0 || !isWordByte(s[i-1])):
+			if text, next, ok := span(i, s[i:i+1]); ok && (s[i] == '*' || next == len(s) || !isWordByte(s[next])) {
+				flush()
+				out.WriteString("[::i]" + markdownInline(text) + "[::I]")
+				i = next
+				continue
+			}
+		case s[i] == '[' || strings.HasPrefix(s[i:], "!["):
+			img := s[i] == '!'
+			open := i
+			if img {
+				open++
+			}
+			if end := strings.Index(s[open:], "]("); end > 0 {
+				if cl := strings.IndexByte(s[open+end+2:], ')'); cl >= 0 {
+					text := s[open+1 : open+end]
+					url := s[open+end+2 : open+end+2+cl]
+					flush()
+					if img {
+						out.WriteString("[gray]🖼 " + tview.Escape(text) + "[-]")
+					} else {
+						out.WriteString("[::u]" + markdownInline(text) + "[::U]")
+					}
+					out.WriteString("[gray] (" + tview.Escape(url) + ")[-]")
+					i = open + end + 3 + cl
+					continue
+				}
+			}
+		}
+		plain.WriteByte(s[i])
+		i++
+	}
+	flush()
+	return out.String()
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+// treeOf builds a tview tree from a fold tree, with the nodes above depth open
+// expanded and the rest folded.
+func treeOf(f *foldNode, depth, open int) *tview.TreeNode {
+	n := tview.NewTreeNode("").SetReference(f).SetExpanded(depth < open)
+	for _, c := range f.children {
+		n.AddChild(treeOf(c, depth+1, open))
+	}
+	foldMark(n)
+	return n
+}
+
+// foldMark sets a tree node's text: a node with children is marked ▾ when
+// expanded and ▸ when folded.
+func foldMark(n *tview.TreeNode) {
+	f := n.GetReference().(*foldNode)
+	switch {
+	case len(f.children) == 0:
+		n.SetText(f.text)
+	case n.IsExpanded():
+		n.SetText("[gray]▾[-] " + f.text)
+	default:
+		n.SetText("[gray]▸[-] " + f.text)
+	}
+}
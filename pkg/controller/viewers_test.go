@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectPreviewKind(t *testing.T) {
+	for _, tc := range []struct {
+		key, ctype string
+		want       previewKind
+	}{
+		{"a/b.json", "", kindJSON},
+		{"a/b.JSONL", "binary/octet-stream", kindNDJSON},
+		{"events", "application/x-ndjson", kindNDJSON},
+		{"conf", "application/yaml; charset=utf-8", kindYAML},
+		{"deploy.yml", "text/plain", kindYAML},
+		{"report", "text/csv", kindCSV},
+		{"report.tsv", "", kindTSV},
+		{"README.md", "", kindMarkdown},
+		{"doc", "application/vnd.api+json", kindJSON},
+		{"data.csv", "application/json", kindJSON}, // the type wins
+		{"notes.txt", "", kindText},
+		{"archive.json.gz", "", kindText},
+	} {
+		if got := detectPreviewKind(tc.key, tc.ctype); got != tc.want {
+			t.Errorf("detectPreviewKind(%q, %q) = %v, want %v", tc.key, tc.ctype, got, tc.want)
+		}
+	}
+}
+
+// foldLines flattens a fold tree into indented lines, for comparing.
+func foldLines(f *foldNode, depth int, out *[]string) {
+	for _, c := range f.children {
+		*out = append(*out, strings.Repeat("  ", depth)+c.text)
+		foldLines(c, depth+1, out)
+	}
+}
+
+func TestJSONFold(t *testing.T) {
+	root, err := jsonFold([]byte(`{"z": 1.50, "a": ["x", true, null], "o": {}, "[red]": "[b]"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.text != "{…} [gray]4 key(s)[-]" {
+		t.Errorf("root = %q", root.text)
+	}
+	var got []string
+	foldLines(root, 0, &got)
+	want := []string{
+		`[yellow]"z"[-]: [aqua]1.50[-]`, // key order and number text kept
+		`[yellow]"a"[-]: […] [gray]3 item(s)[-]`,
+		`  [gray]0:[-] [green]"x"[-]`,
+		`  [gray]1:[-] [fuchsia]true[-]`,
+		`  [gray]2:[-] [fuchsia]null[-]`,
+		`[yellow]"o"[-]: {}`,
+		`[yellow]"[red[]"[-]: [green]"[b[]"[-]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("jsonFold =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, bad := range []string{`{"a": 1`, `{"a": 1} {"b": 2}`, `nope`, ``} {
+		if _, err := jsonFold([]byte(bad)); err == nil {
+			t.Errorf("jsonFold(%q) should fail", bad)
+		}
+	}
+}
+
+func TestNDJSONFold(t *testing.T) {
+	root := ndjsonFold([]byte("{\"id\":1}\r\n\n42\n{broken\n"))
+	var got []string
+	foldLines(root, 0, &got)
+	want := []string{
+		`[gray]1[-] {"id":1}`,
+		`  [yellow]"id"[-]: [aqua]1[-]`,
+		`[gray]3[-] [aqua]42[-]`,
+		`[gray]4[-] [red]{broken[-]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ndjsonFold =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestYAMLFold(t *testing.T) {
+	src := `# config
+server:
+  port: 8080
+  hosts:
+  - a.example
+  - name: b
+    tls: true
+debug: "no"   # quoted
+`
+	root, err := yamlFold([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	foldLines(root, 0, &got)
+	want := []string{
+		`[gray]# config[-]`,
+		`[yellow]server[-]:`,
+		`  [yellow]port[-]: [aqua]8080[-]`,
+		`  [yellow]hosts[-]:`,
+		`    [gray]-[-] a.example`,
+		`    [gray]-[-] [yellow]name[-]: b`,
+		`      [yellow]tls[-]: [fuchsia]true[-]`,
+		`[yellow]debug[-]: [green]"no"[-]   [gray]# quoted[-]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("yamlFold =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if _, err := yamlFold([]byte("a:\n\tb: 1\n")); err == nil {
+		t.Error("tab indentation should be refused")
+	}
+}
+
+func TestMarkdownStyled(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"# Title", "[yellow::bu]Title[-::-]"},
+		{"### Small", "[::b]Small[::-]"},
+		{"#hashtag", "#hashtag"},
+		{"Some **bold** and *it* and `co[de]`", "Some [::b]bold[::B] and [::i]it[::I] and [aqua]co[de[][-]"},
+		{"snake_case_name stays", "snake_case_name stays"},
+		{"see [docs](https://x.io/a)", "see [::u]docs[::U][gray] (https://x.io/a)[-]"},
+		{"- item\n  1. first\n- [x] done", "[yellow]•[-] item\n  [yellow]1.[-] first\n[yellow]☑[-] done"},
+		{"> quoted", "[gray]│[-] quoted"},
+		{"---", "[gray]" + strings.Repeat("─", 40) + "[-]"},
+		{"```go\n**x** [red]\n```", "[gray]```go[-]\n[aqua]**x** [red[][-]\n[gray]```[-]"},
+		{"a [red] tag", "a [red[] tag"},
+	} {
+		if got := markdownStyled(tc.in); got != tc.want {
+			t.Errorf("markdownStyled(%q) =\n%q\nwant\n%q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCSVRows(t *testing.T) {
+	rows, err := csvRows([]byte("name\tsize\n\"a b\"\t10\nragged\n"), '\t')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[1][0] != "a b" || len(rows[2]) != 1 {
+		t.Errorf("csvRows = %q", rows)
+	}
+}
@@ -0,0 +1,510 @@
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// archiveBlock is the least a zip read fetches in one ranged GET; the
+	// blocks are kept, so neighbouring reads cost nothing.
+	archiveBlock = 64 << 10
+	// archiveMaxDirectory caps a zip's central directory, which is held in
+	// memory whole.
+	archiveMaxDirectory = 64 << 20
+	// archiveMaxMembers caps the members listed, so a hostile archive of
+	// empty entries can't exhaust memory.
+	archiveMaxMembers = 500_000
+)
+
+// Archive formats OpenArchive reads.
+const (
+	ArchiveZip = "zip"
+	ArchiveTar = "tar" // plain, gzip'd, bzip2'd or zstd'd
+)
+
+// ArchiveKind tells from key's extension whether it names an archive
+// OpenArchive can browse: ArchiveZip, ArchiveTar, or "" for neither.
+func ArchiveKind(key string) string {
+	k := strings.ToLower(key)
+	switch {
+	case strings.HasSuffix(k, ".zip"), strings.HasSuffix(k, ".jar"):
+		return ArchiveZip
+	case strings.HasSuffix(k, ".tar"), strings.HasSuffix(k, ".tgz"), strings.HasSuffix(k, ".tar.gz"),
+		strings.HasSuffix(k, ".tbz2"), strings.HasSuffix(k, ".tar.bz2"),
+		strings.HasSuffix(k, ".tzst"), strings.HasSuffix(k, ".tar.zst"), strings.HasSuffix(k, ".tar.zstd"):
+		return ArchiveTar
+	}
+	return ""
+}
+
+// ArchiveMember is one entry of an archive.
+type ArchiveMember struct {
+	Name     string // path inside the archive, "/"-separated; a directory's ends in "/"
+	Size     int64
+	Modified time.Time
+	Mode     fs.FileMode
+	Link     string // a link's target
+
+	zf     *zip.File
+	index  int   // tar: the member's position in the stream
+	offset int64 // plain tar: where its data starts; -1 if it must be streamed to
+}
+
+// IsDir reports whether the member is a directory entry.
+func (a ArchiveMember) IsDir() bool { return strings.HasSuffix(a.Name, "/") }
+
+// Archive is the listing of an archive object, as OpenArchive read it.
+type Archive struct {
+	Bucket *Object
+	Key    string
+	Kind   string
+	Size   int64
+	// Compression is what the tar stream was compressed with ("gzip",
+	// "bzip2", "zstd" or ""); always "" for zip.
+	Compression string
+	Members     []ArchiveMember
+}
+
+// Under returns the members whose names start with prefix ("" for all), in
+// archive order.
+func (a *Archive) Under(prefix string) []ArchiveMember {
+	var out []ArchiveMember
+	for _, m := range a.Members {
+		if strings.HasPrefix(m.Name, prefix) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// archiveName turns a member's stored name into the "/"-separated relative
+// path it is listed and extracted under: Windows separators become slashes
+// and leading "/" and "./" go. Names that still point outside the archive
+// ("../x") are kept; SafeLocalPath refuses them at extraction.
+func archiveName(name string, dir bool) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for {
+		switch {
+		case strings.HasPrefix(name, "/"):
+			name = name[1:]
+		case strings.HasPrefix(name, "./"):
+			name = name[2:]
+		default:
+			if dir && name != "" && !strings.HasSuffix(name, "/") {
+				name += "/"
+			}
+			return name
+		}
+	}
+}
+
+// OpenArchive lists the members of the archive key in bucket, size bytes
+// long. A zip is read from its end: the central directory is fetched with
+// ranged GETs, whatever the archive's size. A tar has no index, so the whole
+// object is streamed through, decompressed as its first bytes say (gzip,
+// bzip2 or zstd); progress, if set, gets the running number of bytes read.
+func (m *Model) OpenArchive(ctx context.Context, bucket *Object, key string, size int64, progress func(read int64)) (*Archive, error) {
+	if bucket == nil || bucket.Key == nil {
+		return nil, fmt.Errorf("bucket is nil")
+	}
+	a := &Archive{Bucket: bucket, Key: key, Kind: ArchiveKind(key), Size: size}
+	var err error
+	switch a.Kind {
+	case ArchiveZip:
+		err = m.openZip(ctx, a)
+	case ArchiveTar:
+		err = m.openTar(ctx, a, progress)
+	default:
+		err = fmt.Errorf("%s is not a zip or tar archive", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (m *Model) openZip(ctx context.Context, a *Archive) error {
+	ra := &objectReaderAt{m: m, ctx: ctx, bucket: a.Bucket, key: a.Key, size: a.Size}
+	// Fetch the end of the archive and, once its end record says where, the
+	// whole central directory in one GET each, rather than in the small reads
+	// archive/zip makes.
+	tail := min(a.Size, 128<<10)
+	if tail < 22 {
+		return fmt.Errorf("%s is too short to be a zip", a.Key)
+	}
+	if err := ra.fetch(a.Size-tail, tail); err != nil {
+		return err
+	}
+	if off, n, ok := zipDirectory(ra.spans[0].data, a.Size-tail); ok {
+		if n > archiveMaxDirectory {
+			return fmt.Errorf("the zip's central directory is %d bytes, over the %d limit", n, archiveMaxDirectory)
+		}
+		if off+n > a.Size-tail {
+			if err := ra.fetch(off, min(n, a.Size-tail-off)); err != nil {
+				return err
+			}
+		}
+	}
+	zr, err := zip.NewReader(ra, a.Size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return err
+	}
+	if len(zr.File) > archiveMaxMembers {
+		return fmt.Errorf("the zip has %d members, over the %d limit", len(zr.File), archiveMaxMembers)
+	}
+	for _, f := range zr.File {
+		name := archiveName(f.Name, f.FileInfo().IsDir())
+		if name == "" {
+			continue
+		}
+		a.Members = append(a.Members, ArchiveMember{
+			Name: name, Size: int64(f.UncompressedSize64), Modified: f.Modified,
+			Mode: f.Mode(), zf: f, offset: -1,
+		})
+	}
+	// Later reads (local headers, at extraction) may outlive ctx.
+	ra.mu.Lock()
+	ra.ctx = context.Background()
+	ra.mu.Unlock()
+	return nil
+}
+
+// zipDirectory finds the end of central directory record in tail, the last
+// bytes of a zip starting at base, and returns where the central directory
+// is and how long it is, following a zip64 locator if there is one.
+func zipDirectory(tail []byte, base int64) (off, n int64, ok bool) {
+	i := bytes.LastIndex(tail, []byte("PK\x05\x06"))
+	if i < 0 || len(tail)-i < 22 {
+		return 0, 0, false
+	}
+	end := tail[i:]
+	n, off = int64(binary.LittleEndian.Uint32(end[12:])), int64(binary.LittleEndian.Uint32(end[16:]))
+	if (n == 0xFFFFFFFF || off == 0xFFFFFFFF) && i >= 20 && string(tail[i-20:i-16]) == "PK\x06\x07" {
+		rec := int64(binary.LittleEndian.Uint64(tail[i-12:]))
+		if j := rec - base; j >= 0 && j+56 <= int64(len(tail)) && string(tail[j:j+4]) == "PK\x06\x06" {
+			n, off = int64(binary.LittleEndian.Uint64(tail[j+40:])), int64(binary.LittleEndian.Uint64(tail[j+48:]))
+		}
+	}
+	return off, n, off >= 0 && n >= 0
+}
+
+// decompressedTar sniffs a tar stream's compression from its first bytes
+// and returns it decompressed, with the compression's name.
+func decompressedTar(r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		zr, err := gzip.NewReader(br)
+		return zr, "gzip", err
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return bzip2.NewReader(br), "bzip2", nil
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := newZstdReader(br)
+		return zr, "zstd", err
+	}
+	return br, "", nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (m *Model) openTar(ctx context.Context, a *Archive, progress func(int64)) error {
+	body, err := m.archiveStream(ctx, a, progress)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	r, comp, err := decompressedTar(body)
+	if err != nil {
+		return err
+	}
+	a.Compression = comp
+	// Uncompressed, the offset the tar reader has reached after a header is
+	// where that member's data starts: it reads whole 512-byte blocks and
+	// nothing ahead. Extraction then fetches just that range.
+	cr := &countingReader{r: r}
+	tr := tar.NewReader(cr)
+	seen := make(map[string]int)
+	for i := 0; ; i++ {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading the tar: %w", err)
+		}
+		if len(a.Members) >= archiveMaxMembers {
+			return fmt.Errorf("the tar has over %d members", archiveMaxMembers)
+		}
+		off := int64(-1)
+		if comp == "" && h.Typeflag != tar.TypeGNUSparse && len(h.PAXRecords["GNU.sparse.map"]) == 0 && h.PAXRecords["GNU.sparse.major"] == "" {
+			off = cr.n
+		}
+		name := archiveName(h.Name, h.Typeflag == tar.TypeDir)
+		if name == "" {
+			continue
+		}
+		mb := ArchiveMember{
+			Name: name, Size: h.Size, Modified: h.ModTime, Mode: h.FileInfo().Mode(),
+			Link: h.Linkname, index: i, offset: off,
+		}
+		// A name seen again is an appended update (tar -r): the later one is
+		// what tar would extract.
+		if j, ok := seen[name]; ok {
+			a.Members[j] = mb
+			continue
+		}
+		seen[name] = len(a.Members)
+		a.Members = append(a.Members, mb)
+	}
+}
+
+// archiveStream GETs the whole archive, throttled by the bandwidth limit.
+func (m *Model) archiveStream(ctx context.Context, a *Archive, progress func(int64)) (io.ReadCloser, error) {
+	out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(*a.Bucket.Key), Key: aws.String(a.Key)})
+	if err != nil {
+		return nil, err
+	}
+	update := func(int64, int64) {}
+	if progress != nil {
+		update = func(n, _ int64) { progress(n) }
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{&progressReader{r: out.Body, update: update, limiter: m.Limiter}, out.Body}, nil
+}
+
+// ExtractMember writes the content of member mb of archive a to w and
+// returns the number of bytes written. A zip member and a member of a plain
+// tar cost one ranged GET of their own bytes; in a compressed tar the stream
+// has to be read from the start up to the member.
+func (m *Model) ExtractMember(ctx context.Context, a *Archive, mb ArchiveMember, w io.Writer) (int64, error) {
+	if mb.IsDir() {
+		return 0, nil
+	}
+	if !mb.Mode.IsRegular() {
+		return 0, fmt.Errorf("%s is not a regular file", mb.Name)
+	}
+	if mb.zf != nil {
+		return m.extractZip(ctx, a, mb.zf, w)
+	}
+	if mb.offset >= 0 {
+		if mb.Size == 0 {
+			return 0, nil
+		}
+		body, err := m.rangeBody(ctx, a, mb.offset, mb.Size)
+		if err != nil {
+			return 0, err
+		}
+		defer body.Close()
+		return copyExact(w, body, mb.Size)
+	}
+
+	body, err := m.archiveStream(ctx, a, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	r, _, err := decompressedTar(body)
+	if err != nil {
+		return 0, err
+	}
+	tr := tar.NewReader(r)
+	for i := 0; ; i++ {
+		if _, err := tr.Next(); err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("%s is no longer in the archive", mb.Name)
+			}
+			return 0, err
+		}
+		if i == mb.index {
+			return copyExact(w, tr, mb.Size)
+		}
+	}
+}
+
+// copyExact copies r to w and fails unless it was exactly n bytes.
+func copyExact(w io.Writer, r io.Reader, n int64) (int64, error) {
+	got, err := io.Copy(w, io.LimitReader(r, n+1))
+	if err == nil && got != n {
+		err = fmt.Errorf("expected %d bytes, got %d", n, got)
+	}
+	return got, err
+}
+
+// rangeBody GETs n bytes of a's object at off, throttled by the bandwidth
+// limit.
+func (m *Model) rangeBody(ctx context.Context, a *Archive, off, n int64) (io.ReadCloser, error) {
+	out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(*a.Bucket.Key),
+		Key:    aws.String(a.Key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+n-1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := io.Reader(&progressReader{r: out.Body, update: func(int64, int64) {}, limiter: m.Limiter})
+	if out.ContentRange == nil { // the whole object came back
+		if _, err := io.CopyN(io.Discard, r, off); err != nil {
+			out.Body.Close()
+			return nil, err
+		}
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(r, n), out.Body}, nil
+}
+
+func (m *Model) extractZip(ctx context.Context, a *Archive, f *zip.File, w io.Writer) (int64, error) {
+	if f.Flags&1 != 0 {
+		return 0, fmt.Errorf("%s is encrypted", f.Name)
+	}
+	off, err := f.DataOffset()
+	if err != nil {
+		return 0, err
+	}
+	var raw io.Reader = bytes.NewReader(nil)
+	if f.CompressedSize64 > 0 {
+		body, err := m.rangeBody(ctx, a, off, int64(f.CompressedSize64))
+		if err != nil {
+			return 0, err
+		}
+		defer body.Close()
+		raw = body
+	}
+	var r io.Reader
+	switch f.Method {
+	case zip.Store:
+		r = raw
+	case zip.Deflate:
+		fr := flate.NewReader(raw)
+		defer fr.Close()
+		r = fr
+	case 12:
+		r = bzip2.NewReader(raw)
+	case 93:
+		zr, err := newZstdReader(raw)
+		if err != nil {
+			return 0, err
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return 0, fmt.Errorf("%s: compression method %d is not supported", f.Name, f.Method)
+	}
+	sum := crc32.NewIEEE()
+	n, err := copyExact(io.MultiWriter(w, sum), r, int64(f.UncompressedSize64))
+	if err != nil {
+		return n, err
+	}
+	if f.CRC32 != 0 && sum.Sum32() != f.CRC32 {
+		return n, fmt.Errorf("%s: checksum mismatch", f.Name)
+	}
+	return n, nil
+}
+
+// objectReaderAt reads an object with ranged GETs, at least archiveBlock at
+// a time, and keeps what it has read: a zip's directory is read in many
+// small pieces. Safe for concurrent use.
+type objectReaderAt struct {
+	m      *Model
+	ctx    context.Context
+	bucket *Object
+	key    string
+	size   int64
+
+	mu    sync.Mutex
+	spans []span // sorted by off
+}
+
+type span struct {
+	off  int64
+	data []byte
+}
+
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	want := min(int64(len(p)), r.size-off)
+	if !r.cached(p[:want], off) {
+		if err := r.fetch(off, max(want, archiveBlock)); err != nil {
+			return 0, err
+		}
+		if !r.cached(p[:want], off) {
+			return 0, io.ErrUnexpectedEOF
+		}
+	}
+	if want < int64(len(p)) {
+		return int(want), io.EOF
+	}
+	return len(p), nil
+}
+
+// cached fills p from a kept span holding all of it.
+func (r *objectReaderAt) cached(p []byte, off int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.spans {
+		if off >= s.off && off+int64(len(p)) <= s.off+int64(len(s.data)) {
+			copy(p, s.data[off-s.off:])
+			return true
+		}
+	}
+	return false
+}
+
+// fetch reads n bytes at off (fewer at the end of the object) into a span.
+func (r *objectReaderAt) fetch(off, n int64) error {
+	n = min(n, r.size-off)
+	if n <= 0 {
+		return nil
+	}
+	r.mu.Lock()
+	ctx := r.ctx
+	r.mu.Unlock()
+	got, err := r.m.ReadRange(ctx, r.bucket, r.key, off, n)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span{off, got.Data})
+	sort.Slice(r.spans, func(i, j int) bool { return r.spans[i].off < r.spans[j].off })
+	return nil
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tarZstdFixture is `tar --format=ustar -cf - docs | zstd -19` of docs/,
+// docs/b.txt (zstdFixtureText) and docs/a.txt ("hello zstd\n").
+const tarZstdFixture = "KLUv/WQAJ60LAAbVNRmAS9pgTKXYrMKnDsAMHER2b65cxgqY5YAkNAAtACkAjGSQHJjEiaJYEk5OXlb5Rj82kCZAOIzTMAzDPoxB03CcS85lEvnYZf+/07HPXfoIGgXGELci42ri3Yp8q4l3K/JXE+9WZFtNvFuRazXxbkW0mni3IoCSWA6J8liapVEWSquJdyuyrSberci1mni3ItNq4t2KbNNq4t2KvKuJdyuyriberci5mngBB2BoGpGESSQUhI08OKuJdyvyriberci6mni3Iudq4t2KjKuJdyvyrSbercgPgN6oIcymIKmRnTGQpSCFtAYiCEAIXkGAIAQKQuCCpuc/SWvx0RqrWpaMNlnhGdCEFfPQ33tt/s8+ooz0vp4cpox+pkU8Qk4120cTwUwOUzgWhUE2iHABgbRPVqA9OYhwAYG0T1agPTmIcAGBtE9WCjRwLL1qUBsylfCmFZg2ZU4w6KpwGiBNAAk6gMnxI8UfUJXmM08TYN2w8wPjzuDY"
+
+func TestArchiveKind(t *testing.T) {
+	for key, want := range map[string]string{
+		"a/b.zip": ArchiveZip, "x.JAR": ArchiveZip, "x.tar": ArchiveTar, "x.tgz": ArchiveTar,
+		"x.tar.gz": ArchiveTar, "x.tar.bz2": ArchiveTar, "x.tar.zst": ArchiveTar,
+		"x.gz": "", "x.zst": "", "zip": "", "x.tar/": "",
+	} {
+		if got := ArchiveKind(key); got != want {
+			t.Errorf("ArchiveKind(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		dir  bool
+		want string
+	}{
+		{"a/b.txt", false, "a/b.txt"},
+		{"./a/", true, "a/"},
+		{"/abs/x", false, "abs/x"},
+		{`win\dir\f.txt`, false, "win/dir/f.txt"},
+		{"d", true, "d/"},
+		{"./", true, ""},
+		{"../up", false, "../up"},
+	} {
+		if got := archiveName(tc.in, tc.dir); got != tc.want {
+			t.Errorf("archiveName(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestZipArchive(t *testing.T) {
+	// Enough incompressible data ahead of the directory that it isn't read
+	// when listing.
+	big := make([]byte, 300<<10)
+	x := uint32(7)
+	for i := range big {
+		x = x*1664525 + 1013904223
+		big[i] = byte(x >> 24)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.CreateHeader(&zip.FileHeader{Name: "big.bin", Method: zip.Store})
+	w.Write(big)
+	zw.Create("docs/")
+	w, _ = zw.Create("docs/notes.txt")
+	w.Write([]byte(strings.Repeat("deflated text\n", 100)))
+	w, _ = zw.Create("../escape.txt")
+	w.Write([]byte("nope"))
+	zw.Close()
+
+	fs := newFakeS3(map[string][]byte{"b/a.zip": buf.Bytes()})
+	m := newFakeModel(t, fs)
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+	a, err := m.OpenArchive(ctx, bucket, "a.zip", int64(buf.Len()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := fs.stats().requests; n != 1 {
+		t.Errorf("listing took %d requests, want 1", n)
+	}
+	var names []string
+	for _, mb := range a.Members {
+		names = append(names, mb.Name)
+	}
+	if got := strings.Join(names, " "); got != "big.bin docs/ docs/notes.txt ../escape.txt" {
+		t.Fatalf("members = %s", got)
+	}
+	if u := a.Under("docs/"); len(u) != 2 {
+		t.Errorf("Under(docs/) = %d members", len(u))
+	}
+
+	var out bytes.Buffer
+	if n, err := m.ExtractMember(ctx, a, a.Members[2], &out); err != nil || n != 1400 || out.String() != strings.Repeat("deflated text\n", 100) {
+		t.Errorf("notes.txt: %d bytes, %v", n, err)
+	}
+	out.Reset()
+	if _, err := m.ExtractMember(ctx, a, a.Members[0], &out); err != nil || !bytes.Equal(out.Bytes(), big) {
+		t.Errorf("big.bin: %v", err)
+	}
+
+	// Through the download machinery: into place, and never out of it.
+	dst := t.TempDir()
+	mb := a.Members[2]
+	if _, err := m.DownloadTarget(ctx, DownloadTarget{Key: mb.Name, Size: mb.Size, Archive: a, Member: &mb}, "docs/", dst, bucket.Key, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "notes.txt")); len(got) != 1400 {
+		t.Errorf("extracted %d bytes", len(got))
+	}
+	mb = a.Members[3]
+	if _, err := m.DownloadTarget(ctx, DownloadTarget{Key: mb.Name, Size: mb.Size, Archive: a, Member: &mb}, "", dst, bucket.Key, false, nil); err == nil {
+		t.Error("../escape.txt was extracted")
+	}
+}
+
+func TestTarArchive(t *testing.T) {
+	var plain bytes.Buffer
+	tw := tar.NewWriter(&plain)
+	tw.WriteHeader(&tar.Header{Name: "./src/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, f := range []struct{ name, body string }{{"./src/main.go", "package main\n"}, {"./src/empty", ""}, {"./README", "read me\n"}} {
+		tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.body))})
+		tw.Write([]byte(f.body))
+	}
+	tw.WriteHeader(&tar.Header{Name: "./link", Typeflag: tar.TypeSymlink, Linkname: "README"})
+	tw.Close()
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(plain.Bytes())
+	zw.Close()
+	zst, _ := base64.StdEncoding.DecodeString(tarZstdFixture)
+
+	fs := newFakeS3(map[string][]byte{"b/x.tar": plain.Bytes(), "b/x.tgz": gz.Bytes(), "b/x.tar.zst": zst})
+	m := newFakeModel(t, fs)
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+	for _, tc := range []struct {
+		key, comp, members, member, want string
+	}{
+		{"x.tar", "", "src/ src/main.go src/empty README link", "src/main.go", "package main\n"},
+		{"x.tgz", "gzip", "src/ src/main.go src/empty README link", "README", "read me\n"},
+		{"x.tar.zst", "zstd", "docs/ docs/b.txt docs/a.txt", "docs/a.txt", "hello zstd\n"},
+	} {
+		var read int64
+		body := map[string][]byte{"x.tar": plain.Bytes(), "x.tgz": gz.Bytes(), "x.tar.zst": zst}[tc.key]
+		a, err := m.OpenArchive(ctx, bucket, tc.key, int64(len(body)), func(n int64) { read = n })
+		if err != nil {
+			t.Errorf("%s: %v", tc.key, err)
+			continue
+		}
+		if read != int64(len(body)) || a.Compression != tc.comp {
+			t.Errorf("%s: progress %d of %d, compression %q", tc.key, read, len(body), a.Compression)
+		}
+		var names []string
+		var mb ArchiveMember
+		for _, x := range a.Members {
+			names = append(names, x.Name)
+			if x.Name == tc.member {
+				mb = x
+			}
+		}
+		if got := strings.Join(names, " "); got != tc.members {
+			t.Errorf("%s: members = %s", tc.key, got)
+		}
+		before := fs.stats().requests
+		var out bytes.Buffer
+		if _, err := m.ExtractMember(ctx, a, mb, &out); err != nil || out.String() != tc.want {
+			t.Errorf("%s: %s = %q, %v", tc.key, tc.member, out.String(), err)
+		}
+		if n := fs.stats().requests - before; n != 1 {
+			t.Errorf("%s: extraction took %d requests", tc.key, n)
+		}
+		if tc.key == "x.tar" {
+			if mb.offset < 0 {
+				t.Error("plain tar member has no offset")
+			}
+			if _, err := m.ExtractMember(ctx, a, a.Members[4], &out); err == nil {
+				t.Error("a symlink was extracted")
+			}
+		}
+	}
+
+	out := new(bytes.Buffer)
+	a, _ := m.OpenArchive(ctx, bucket, "x.tar.zst", int64(len(zst)), nil)
+	if _, err := m.ExtractMember(ctx, a, a.Members[1], out); err != nil || out.String() != zstdFixtureText() {
+		t.Errorf("docs/b.txt: %d bytes, %v", out.Len(), err)
+	}
+}
@@ -0,0 +1,236 @@
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3m "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ArchiveMemberKey is the key member name of a local archive uploads to
+// under s3Prefix. Like SafeLocalPath for downloads it refuses a name that
+// would land outside the prefix ("../x"); leading slashes and "./" are
+// dropped, as tar and unzip would.
+func ArchiveMemberKey(s3Prefix, name string, dir bool) (string, error) {
+	rel, err := ArchiveEntryName("", archiveName(name, dir))
+	if err != nil {
+		return "", fmt.Errorf("archive member %q escapes the destination prefix", name)
+	}
+	if rel == "" {
+		return "", nil
+	}
+	key := path.Join(s3Prefix, rel)
+	if dir {
+		key += "/"
+	}
+	return key, nil
+}
+
+// eachLocalMember calls fn for every directory and regular file in the zip
+// or tar (plain, gzip'd, bzip2'd or zstd'd) at localPath, in archive order.
+// open returns the member's content; for a tar it is only readable until fn
+// returns.
+func eachLocalMember(localPath string, fn func(name string, dir bool, size int64, open func() (io.ReadCloser, error)) error) error {
+	switch ArchiveKind(localPath) {
+	case ArchiveZip:
+		zr, err := zip.OpenReader(localPath)
+		if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+			return err
+		}
+		defer zr.Close()
+		zr.RegisterDecompressor(12, func(r io.Reader) io.ReadCloser { return io.NopCloser(bzip2.NewReader(r)) })
+		zr.RegisterDecompressor(93, func(r io.Reader) io.ReadCloser {
+			d, err := newZstdReader(r)
+			if err != nil {
+				return nil // archive/zip reports ErrAlgorithm
+			}
+			return d
+		})
+		for _, f := range zr.File {
+			dir := f.FileInfo().IsDir()
+			if !dir && !f.Mode().IsRegular() {
+				continue
+			}
+			if f.Flags&1 != 0 {
+				return fmt.Errorf("%s is encrypted", f.Name)
+			}
+			if err := fn(f.Name, dir, int64(f.UncompressedSize64), f.Open); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ArchiveTar:
+		fp, err := os.Open(localPath)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		r, _, err := decompressedTar(fp)
+		if err != nil {
+			return err
+		}
+		tr := tar.NewReader(r)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", path.Base(localPath), err)
+			}
+			dir := h.Typeflag == tar.TypeDir
+			if !dir && !h.FileInfo().Mode().IsRegular() {
+				continue
+			}
+			open := func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+			if err := fn(h.Name, dir, h.Size, open); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("%s is not a zip or tar archive", path.Base(localPath))
+}
+
+// PrepareArchiveUpload lists the files of the local archive at localPath
+// with the keys UploadArchive would give them under s3Prefix, and their
+// total size. LocalPath is the member's name in the archive. A member whose
+// name escapes the prefix fails the whole archive here, before anything is
+// written.
+func (m *Model) PrepareArchiveUpload(localPath, s3Prefix string) ([]UploadTarget, int64, error) {
+	var out []UploadTarget
+	var total int64
+	err := eachLocalMember(localPath, func(name string, dir bool, size int64, _ func() (io.ReadCloser, error)) error {
+		key, err := ArchiveMemberKey(s3Prefix, name, dir)
+		if err != nil || dir || key == "" {
+			return err
+		}
+		out = append(out, UploadTarget{LocalPath: name, RemotePath: key, Size: size})
+		total += size
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+// UploadArchive uploads the members of the local archive at localPath to
+// keys under s3Prefix, streaming each straight from the archive into the
+// uploader: nothing is unpacked to disk. Directories with no files under
+// them become folder markers, as in Upload; skip, progressCb and fileDone
+// are Upload's too, with the member's name in place of the local path.
+func (m *Model) UploadArchive(
+	ctx context.Context,
+	localPath, s3Prefix string,
+	bucket *Object,
+	skip map[string]bool,
+	progressCb func(current, total int64, i, count int, local, remote string),
+	fileDone func(remote string, err error),
+) error {
+	if bucket == nil || bucket.Key == nil {
+		return errors.New("bucket is nil")
+	}
+	files, totalSize, err := m.PrepareArchiveUpload(localPath, s3Prefix)
+	if err != nil {
+		return err
+	}
+	defer m.invalidateLists(*bucket.Key, s3Prefix)
+
+	count := 0
+	for _, f := range files {
+		if skip[f.RemotePath] {
+			totalSize -= f.Size
+			continue
+		}
+		count++
+	}
+	// A directory is non-empty when some file's key lies below it.
+	nonEmpty := make(map[string]bool)
+	for _, f := range files {
+		for d := path.Dir(f.RemotePath); d != "." && d != "/" && !nonEmpty[d+"/"]; d = path.Dir(d) {
+			nonEmpty[d+"/"] = true
+		}
+	}
+
+	uploader := newUploader(m.Client)
+	var uploaded int64
+	i := 0
+	return eachLocalMember(localPath, func(name string, dir bool, size int64, open func() (io.ReadCloser, error)) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key, err := ArchiveMemberKey(s3Prefix, name, dir)
+		if err != nil || key == "" {
+			return err
+		}
+		if dir {
+			if nonEmpty[key] {
+				return nil
+			}
+			nonEmpty[key] = true // a tar may list a directory twice
+			_, err := m.Client.PutObject(ctx, &s3.PutObjectInput{
+				Bucket: aws.String(*bucket.Key),
+				Key:    aws.String(key),
+				Body:   strings.NewReader(""),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create folder marker %s: %w", key, err)
+			}
+			return nil
+		}
+		if skip[key] {
+			return nil
+		}
+		i++
+		n := i
+		err = m.uploadMember(ctx, uploader, bucket, name, key, size, open, func(written int64) {
+			if progressCb != nil {
+				progressCb(uploaded+written, totalSize, n, count, name, key)
+			}
+		})
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if fileDone == nil {
+			if err != nil {
+				return err
+			}
+		} else {
+			fileDone(key, err)
+		}
+		if err == nil {
+			uploaded += size
+		}
+		return nil
+	})
+}
+
+// uploadMember streams one archive member, size bytes from open, to key.
+func (m *Model) uploadMember(ctx context.Context, uploader *s3m.Uploader, bucket *Object, name, key string, size int64, open func() (io.ReadCloser, error), progress func(written int64)) error {
+	rc, err := open()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer rc.Close()
+	body := &progressReader{r: io.LimitReader(rc, size), total: size, update: func(written, _ int64) {
+		progress(written)
+	}, limiter: m.Limiter}
+	if _, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(*bucket.Key),
+		Key:    aws.String(key),
+		Body:   body,
+	}); err != nil {
+		return fmt.Errorf("upload failed for %s: %w", name, err)
+	}
+	return nil
+}
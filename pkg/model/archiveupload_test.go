@@ -0,0 +1,110 @@
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestArchiveMemberKey(t *testing.T) {
+	for _, tc := range []struct {
+		prefix, name string
+		dir          bool
+		want         string
+		bad          bool
+	}{
+		{"in/", "a.txt", false, "in/a.txt", false},
+		{"in", "./d", true, "in/d/", false},
+		{"", "/abs/x", false, "abs/x", false},
+		{"in/", `win\f.txt`, false, "in/win/f.txt", false},
+		{"in/", "a/../../up", false, "", true},
+		{"in/", "./", true, "", false},
+	} {
+		got, err := ArchiveMemberKey(tc.prefix, tc.name, tc.dir)
+		if got != tc.want || (err != nil) != tc.bad {
+			t.Errorf("ArchiveMemberKey(%q, %q) = %q, %v", tc.prefix, tc.name, got, err)
+		}
+	}
+}
+
+func TestUploadArchive(t *testing.T) {
+	dir := t.TempDir()
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	zw.Create("docs/")
+	w, _ := zw.Create("docs/a.txt")
+	w.Write([]byte("alpha"))
+	zw.Create("empty/")
+	w, _ = zw.Create("b.txt")
+	w.Write([]byte(strings.Repeat("b", 1000)))
+	zw.Close()
+	zipPath := filepath.Join(dir, "in.zip")
+	os.WriteFile(zipPath, zbuf.Bytes(), 0644)
+
+	var tbuf bytes.Buffer
+	gz := gzip.NewWriter(&tbuf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "./x/y.txt", Size: 3, Mode: 0644})
+	tw.Write([]byte("yyy"))
+	tw.WriteHeader(&tar.Header{Name: "./x/ln", Typeflag: tar.TypeSymlink, Linkname: "y.txt"})
+	tw.Close()
+	gz.Close()
+	tgzPath := filepath.Join(dir, "in.tgz")
+	os.WriteFile(tgzPath, tbuf.Bytes(), 0644)
+
+	for _, tc := range []struct {
+		path string
+		skip map[string]bool
+		want string
+	}{
+		{zipPath, nil, "b/dst/b.txt=1000 b/dst/docs/a.txt=alpha b/dst/empty/="},
+		{zipPath, map[string]bool{"dst/b.txt": true}, "b/dst/docs/a.txt=alpha b/dst/empty/="},
+		{tgzPath, nil, "b/dst/x/y.txt=yyy"},
+	} {
+		fs := newFakeS3(nil)
+		m := newFakeModel(t, fs)
+		var last int64
+		err := m.UploadArchive(context.Background(), tc.path, "dst/", &Object{Key: strPtr("b")}, tc.skip,
+			func(cur, _ int64, _, _ int, _, _ string) { last = cur }, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tc.path, err)
+			continue
+		}
+		var got []string
+		for k, v := range fs.contents() {
+			if len(v) > 10 {
+				v = "1000"
+			}
+			got = append(got, k+"="+v)
+		}
+		sort.Strings(got)
+		if s := strings.Join(got, " "); s != tc.want {
+			t.Errorf("%s: puts = %s, want %s", tc.path, s, tc.want)
+		}
+		if last == 0 {
+			t.Errorf("%s: no progress", tc.path)
+		}
+	}
+
+	// One member escaping the prefix refuses the archive before any write.
+	var ebuf bytes.Buffer
+	zw = zip.NewWriter(&ebuf)
+	w, _ = zw.Create("ok.txt")
+	w.Write([]byte("fine"))
+	w, _ = zw.Create("../../evil.txt")
+	w.Write([]byte("nope"))
+	zw.Close()
+	evil := filepath.Join(dir, "evil.zip")
+	os.WriteFile(evil, ebuf.Bytes(), 0644)
+	m := newTestModel(t, NewConfig("http://s3.test", strPtr("us-east-1"), "ak", "sk", "", false, 0))
+	if _, _, err := m.PrepareArchiveUpload(evil, "dst/"); err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Errorf("PrepareArchiveUpload(evil) = %v", err)
+	}
+}
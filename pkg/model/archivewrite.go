@@ -0,0 +1,182 @@
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ArchiveTarGz is the gzip'd tar WriteArchive writes; its other format is
+// ArchiveZip.
+const ArchiveTarGz = "tar.gz"
+
+// ArchiveEntryName is the name key is stored under in an archive of the
+// objects below currentPath: the key relative to it, without leading slashes.
+// A key that would climb out of the archive once extracted ("a/../../x") is
+// refused rather than passed on to whoever unpacks it; "" means key is
+// currentPath's own folder marker.
+func ArchiveEntryName(currentPath, key string) (string, error) {
+	rel := strings.TrimPrefix(key, NormalizePrefix(currentPath))
+	dir := strings.HasSuffix(rel, "/")
+	rel = strings.TrimLeft(rel, "/")
+	if rel == "" {
+		return "", nil
+	}
+	clean := path.Clean(rel)
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("object key %q escapes the archive", key)
+	}
+	if clean == "." {
+		return "", nil
+	}
+	if dir {
+		clean += "/"
+	}
+	return clean, nil
+}
+
+// WriteArchive streams targets, keys of bucket, into a new zip or tar.gz
+// (format) at dest, named relative to currentPath and dated by their
+// Modified. Objects are fetched one after another, straight into the
+// archive, throttled by the bandwidth limit; progress, if set, gets the
+// object bytes written so far and the key being read. Like DownloadTarget it
+// writes a sibling temp file and renames it onto dest only when the archive
+// is complete, so a failed or canceled run leaves nothing behind — one
+// object failing fails the archive, as an archive silently missing files is
+// worse than none. It returns the object bytes written.
+func (m *Model) WriteArchive(ctx context.Context, bucket *string, targets []DownloadTarget, currentPath, dest, format string, progress func(written int64, key string)) (int64, error) {
+	if format != ArchiveZip && format != ArchiveTarGz {
+		return 0, fmt.Errorf("unknown archive format %q", format)
+	}
+	tmp := dest + ".s3duck-part"
+	fp, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	n, err := m.writeArchive(ctx, bucket, targets, currentPath, fp, format, progress)
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		err = os.Rename(tmp, dest)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return n, err
+	}
+	return n, nil
+}
+
+// archiveWriter is what WriteArchive needs of a zip or tar writer.
+type archiveWriter interface {
+	add(name string, size int64, modified time.Time) (io.Writer, error)
+	Close() error
+}
+
+type zipPacker struct{ *zip.Writer }
+
+func (z zipPacker) add(name string, _ int64, modified time.Time) (io.Writer, error) {
+	h := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified}
+	if strings.HasSuffix(name, "/") {
+		h.Method = zip.Store
+	}
+	return z.CreateHeader(h)
+}
+
+type tarPacker struct {
+	*tar.Writer
+	gz *gzip.Writer
+}
+
+func (t tarPacker) add(name string, size int64, modified time.Time) (io.Writer, error) {
+	h := &tar.Header{Name: name, Size: size, Mode: 0644, ModTime: modified.Truncate(time.Second), Typeflag: tar.TypeReg}
+	if strings.HasSuffix(name, "/") {
+		h.Size, h.Mode, h.Typeflag = 0, 0755, tar.TypeDir
+	}
+	return t.Writer, t.WriteHeader(h)
+}
+
+func (t tarPacker) Close() error {
+	if err := t.Writer.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
+
+func (m *Model) writeArchive(ctx context.Context, bucket *string, targets []DownloadTarget, currentPath string, w io.Writer, format string, progress func(int64, string)) (int64, error) {
+	var aw archiveWriter
+	if format == ArchiveZip {
+		aw = zipPacker{zip.NewWriter(w)}
+	} else {
+		gz := gzip.NewWriter(w)
+		aw = tarPacker{tar.NewWriter(gz), gz}
+	}
+	if progress == nil {
+		progress = func(int64, string) {}
+	}
+
+	var done int64
+	for _, t := range targets {
+		if err := ctx.Err(); err != nil {
+			return done, err
+		}
+		name, err := ArchiveEntryName(currentPath, t.Key)
+		if err != nil {
+			return done, err
+		}
+		if name == "" {
+			continue
+		}
+		modified := t.Modified
+		if modified.IsZero() {
+			modified = time.Now()
+		}
+		if strings.HasSuffix(name, "/") {
+			if _, err := aw.add(name, 0, modified); err != nil {
+				return done, err
+			}
+			continue
+		}
+
+		progress(done, t.Key)
+		out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: bucket, Key: aws.String(t.Key)})
+		if err != nil {
+			return done, fmt.Errorf("%s: %w", t.Key, err)
+		}
+		// A tar header states the size before the body: take it from the
+		// response, which describes the body on its way, over the listing,
+		// which may be stale.
+		size := t.Size
+		if out.ContentLength > 0 || size == 0 {
+			size = out.ContentLength
+		}
+		base := done
+		body := &progressReader{r: out.Body, update: func(written, _ int64) {
+			progress(base+written, t.Key)
+		}, limiter: m.Limiter}
+		ew, err := aw.add(name, size, modified)
+		if err == nil {
+			_, err = copyExact(ew, body, size)
+		}
+		out.Body.Close()
+		if err != nil {
+			return done, fmt.Errorf("%s: %w", t.Key, err)
+		}
+		done += size
+		progress(done, t.Key)
+	}
+	return done, aw.Close()
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveEntryName(t *testing.T) {
+	for _, tc := range []struct {
+		cur, key, want string
+		bad            bool
+	}{
+		{"p/", "p/a.txt", "a.txt", false},
+		{"p", "p/sub/", "sub/", false},
+		{"", "x//y", "x/y", false},
+		{"p/", "p/", "", false},
+		{"p/", "p//lead", "lead", false},
+		{"p/", "p/a/../../../x", "", true},
+		{"", "./x", "x", false},
+	} {
+		got, err := ArchiveEntryName(tc.cur, tc.key)
+		if got != tc.want || (err != nil) != tc.bad {
+			t.Errorf("ArchiveEntryName(%q, %q) = %q, %v", tc.cur, tc.key, got, err)
+		}
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	objs := map[string][]byte{
+		"b/p/a.txt":     []byte("alpha\n"),
+		"b/p/sub/":      {},
+		"b/p/sub/b.bin": bytes.Repeat([]byte{0, 1, 2}, 1000),
+		"b/p/../evil":   []byte("x"),
+	}
+	m := newFakeModel(t, newFakeS3(objs))
+	when := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	targets := []DownloadTarget{
+		{Key: "p/a.txt", Size: 6, Modified: when},
+		{Key: "p/sub/", Modified: when},
+		{Key: "p/sub/b.bin", Size: 3000, Modified: when},
+	}
+	dir := t.TempDir()
+	ctx := context.Background()
+	bucket := strPtr("b")
+
+	zipPath := filepath.Join(dir, "out.zip")
+	var last int64
+	n, err := m.WriteArchive(ctx, bucket, targets, "p/", zipPath, ArchiveZip, func(w int64, _ string) { last = w })
+	if err != nil || n != 3006 || last != 3006 {
+		t.Fatalf("zip: %d bytes (progress %d), %v", n, last, err)
+	}
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if !f.Modified.Equal(when) {
+			t.Errorf("%s dated %v", f.Name, f.Modified)
+		}
+		if f.Name == "sub/b.bin" {
+			rc, _ := f.Open()
+			got, _ := io.ReadAll(rc)
+			rc.Close()
+			if !bytes.Equal(got, objs["b/p/sub/b.bin"]) {
+				t.Error("sub/b.bin content differs")
+			}
+		}
+	}
+	if got := strings.Join(names, " "); got != "a.txt sub/ sub/b.bin" {
+		t.Errorf("zip names = %s", got)
+	}
+
+	tgzPath := filepath.Join(dir, "out.tar.gz")
+	if _, err := m.WriteArchive(ctx, bucket, targets, "p/", tgzPath, ArchiveTarGz, nil); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(tgzPath)
+	gz, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	names = nil
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, h.Name)
+		if !h.ModTime.Equal(when) {
+			t.Errorf("%s dated %v", h.Name, h.ModTime)
+		}
+		if h.Name == "a.txt" {
+			if got, _ := io.ReadAll(tr); string(got) != "alpha\n" {
+				t.Errorf("a.txt = %q", got)
+			}
+		}
+	}
+	if got := strings.Join(names, " "); got != "a.txt sub/ sub/b.bin" {
+		t.Errorf("tar names = %s", got)
+	}
+
+	// A key escaping the archive, a missing object or a stale size fails the
+	// whole archive and leaves nothing behind.
+	for _, bad := range [][]DownloadTarget{
+		{{Key: "p/a.txt", Size: 6}, {Key: "p/../evil", Size: 1}},
+		{{Key: "p/gone", Size: 3}},
+	} {
+		out := filepath.Join(dir, "bad.zip")
+		if _, err := m.WriteArchive(ctx, bucket, bad, "p/", out, ArchiveZip, nil); err == nil {
+			t.Errorf("%v: no error", bad)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+			t.Errorf("%v: left %d files behind", bad, len(entries)-2)
+		}
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := m.WriteArchive(canceled, bucket, targets, "p/", filepath.Join(dir, "c.zip"), ArchiveZip, nil); err == nil {
+		t.Error("a canceled archive was written")
+	}
+}
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// listCacheMaxEntries bounds how many folder listings a profile keeps. The
+// oldest is evicted first; a listing is only ever as large as the pages the
+// user actually loaded.
+const listCacheMaxEntries = 64
+
+// CachedListing is a folder listing as it was last fetched: the bucket it
+// belongs to, the objects loaded, and whether more pages were left.
+type CachedListing struct {
+	Bucket  *Object
+	Objects []*Object
+	More    bool
+	Fetched time.Time
+}
+
+type listCacheKey struct {
+	bucket, path string
+}
+
+// listCache remembers recent folder listings of one profile, so revisiting a
+// location (Backspace, Enter, history) can show it at once while it is
+// re-listed in the background. Entries expire after ttl, and every write the
+// model makes drops the listings it could have changed. epoch counts those
+// invalidations: a listing fetched across one may predate the write, so it is
+// not stored.
+type listCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[listCacheKey]CachedListing
+	epoch   uint64
+}
+
+// newListCache returns a cache keeping listings for ttl, or nil (no caching)
+// when ttl is not positive.
+func newListCache(ttl time.Duration) *listCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &listCache{ttl: ttl, now: time.Now, entries: map[listCacheKey]CachedListing{}}
+}
+
+func (m *Model) lists() *listCache {
+	if m == nil || m.pool == nil {
+		return nil
+	}
+	return m.pool.lists
+}
+
+// cachedLists is the cache reads and stores go through: none for a model
+// browsing an inventory report, whose listings are not the bucket's. Its
+// writes still invalidate through lists — they reach the live bucket.
+func (m *Model) cachedLists() *listCache {
+	if m.Inventory() != nil {
+		return nil
+	}
+	return m.lists()
+}
+
+// ListingEpoch is taken before fetching a listing and handed back to
+// CacheList, which discards the listing if a write invalidated anything in
+// between.
+func (m *Model) ListingEpoch() uint64 {
+	lc := m.cachedLists()
+	if lc == nil {
+		return 0
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.epoch
+}
+
+// CacheList stores the listing of path in bucket, fetched since epoch.
+func (m *Model) CacheList(epoch uint64, bucket *Object, path string, objs []*Object, more bool) {
+	lc := m.cachedLists()
+	if lc == nil || bucket == nil || bucket.Key == nil {
+		return
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if epoch != lc.epoch {
+		return
+	}
+	k := listCacheKey{*bucket.Key, path}
+	if _, ok := lc.entries[k]; !ok && len(lc.entries) >= listCacheMaxEntries {
+		lc.evictOldestLocked()
+	}
+	lc.entries[k] = CachedListing{Bucket: bucket, Objects: objs, More: more, Fetched: lc.now()}
+}
+
+func (lc *listCache) evictOldestLocked() {
+	var oldest listCacheKey
+	var at time.Time
+	first := true
+	for k, e := range lc.entries {
+		if first || e.Fetched.Before(at) {
+			oldest, at, first = k, e.Fetched, false
+		}
+	}
+	delete(lc.entries, oldest)
+}
+
+// CachedList returns the cached listing of path in bucket, if there is one
+// younger than the profile's TTL.
+func (m *Model) CachedList(bucket, path string) (CachedListing, bool) {
+	lc := m.cachedLists()
+	if lc == nil {
+		return CachedListing{}, false
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	k := listCacheKey{bucket, path}
+	e, ok := lc.entries[k]
+	if !ok {
+		return CachedListing{}, false
+	}
+	if lc.now().Sub(e.Fetched) >= lc.ttl {
+		delete(lc.entries, k)
+		return CachedListing{}, false
+	}
+	return e, true
+}
+
+// invalidateLists drops every cached listing in bucket that a write to key
+// may have changed: the folders above it (its own folder, and any parent that
+// gains or loses a subfolder) and, for a folder key, everything beneath it.
+// An empty key drops the whole bucket.
+func (m *Model) invalidateLists(bucket, key string) {
+	lc := m.lists()
+	if lc == nil {
+		return
+	}
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.epoch++
+	for k := range lc.entries {
+		if k.bucket == bucket && (strings.HasPrefix(key, k.path) || strings.HasPrefix(k.path, key)) {
+			delete(lc.entries, k)
+		}
+	}
+}
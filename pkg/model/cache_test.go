@@ -0,0 +1,135 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newCachingModel is a model with a listing cache of ttl whose clock the test
+// moves by hand.
+func newCachingModel(t *testing.T, ttl time.Duration) (*Model, *time.Time) {
+	t.Helper()
+	cf := NewConfig("http://s3.test", strPtr("us-east-1"), "ak", "sk", "", false, 0)
+	cf.ListCacheTTL = ttl
+	m := newTestModel(t, cf)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.pool.lists.now = func() time.Time { return now }
+	return m, &now
+}
+
+func cacheAt(m *Model, bucket string, paths ...string) {
+	for _, p := range paths {
+		m.CacheList(m.ListingEpoch(), &Object{Key: strPtr(bucket)}, p, []*Object{{Key: strPtr("x")}}, false)
+	}
+}
+
+func cached(m *Model, bucket, path string) bool {
+	_, ok := m.CachedList(bucket, path)
+	return ok
+}
+
+func TestListCacheExpires(t *testing.T) {
+	m, now := newCachingModel(t, time.Minute)
+	cacheAt(m, "b", "p/")
+	*now = now.Add(59 * time.Second)
+	if !cached(m, "b", "p/") {
+		t.Fatal("listing gone before its TTL")
+	}
+	*now = now.Add(time.Second)
+	if cached(m, "b", "p/") {
+		t.Error("listing served past its TTL")
+	}
+}
+
+func TestListCacheDisabled(t *testing.T) {
+	m := newTestModel(t, NewConfig("http://s3.test", strPtr("us-east-1"), "ak", "sk", "", false, 0))
+	cacheAt(m, "b", "p/")
+	if cached(m, "b", "p/") {
+		t.Error("a profile without a TTL cached a listing")
+	}
+	cacheAt(&Model{}, "b", "p/") // no pool: a no-op, not a panic
+}
+
+func TestInvalidateListsDropsAffectedFolders(t *testing.T) {
+	m, _ := newCachingModel(t, time.Hour)
+	cacheAt(m, "b", "", "a/", "a/b/", "a/b/deep/", "a/c/", "x/")
+	cacheAt(m, "other", "a/b/")
+
+	m.invalidateLists("b", "a/b/f.txt")
+	for path, want := range map[string]bool{"": false, "a/": false, "a/b/": false, "a/b/deep/": true, "a/c/": true, "x/": true} {
+		if got := cached(m, "b", path); got != want {
+			t.Errorf("after a write to a/b/f.txt, %q cached = %v, want %v", path, got, want)
+		}
+	}
+	if !cached(m, "other", "a/b/") {
+		t.Error("a write dropped another bucket's listing")
+	}
+
+	m.invalidateLists("b", "a/") // a folder: everything beneath it goes too
+	if cached(m, "b", "a/b/deep/") || cached(m, "b", "a/c/") || !cached(m, "b", "x/") {
+		t.Error("a folder write must drop its subtree and nothing else")
+	}
+	m.invalidateLists("other", "")
+	if cached(m, "other", "a/b/") {
+		t.Error("a bucket-wide invalidation left a listing behind")
+	}
+}
+
+// A listing fetched while a write went through may predate it, so it must not
+// be stored.
+func TestCacheListSkipsListingRacingAWrite(t *testing.T) {
+	m, _ := newCachingModel(t, time.Hour)
+	epoch := m.ListingEpoch()
+	m.invalidateLists("b", "elsewhere/k")
+	m.CacheList(epoch, &Object{Key: strPtr("b")}, "p/", nil, false)
+	if cached(m, "b", "p/") {
+		t.Error("a listing that raced a write was cached")
+	}
+}
+
+func TestListCacheEvictsOldest(t *testing.T) {
+	m, now := newCachingModel(t, time.Hour)
+	for i := 0; i < listCacheMaxEntries; i++ {
+		cacheAt(m, "b", string(rune('A'+i%26))+string(rune('a'+i/26))+"/")
+		*now = now.Add(time.Second)
+	}
+	cacheAt(m, "b", "new/")
+	if cached(m, "b", "Aa/") || !cached(m, "b", "new/") || !cached(m, "b", "Ba/") {
+		t.Error("a full cache must evict exactly its oldest listing")
+	}
+}
+
+// The model's own writes invalidate on their way through, so the controller
+// never has to remember to.
+func TestWritesInvalidateListings(t *testing.T) {
+	m, _ := newCachingModel(t, time.Hour)
+	fs := newFakeS3(nil)
+	fs.fill("b", "p/k", "q/a", "q/b")
+	useTransport(m, fs)
+	bucket := &Object{Key: strPtr("b")}
+	ctx := context.Background()
+
+	cacheAt(m, "b", "p/", "q/")
+	if err := m.DeleteKey(ctx, "p/k", bucket); err != nil {
+		t.Fatalf("DeleteKey: %v", err)
+	}
+	if cached(m, "b", "p/") || !cached(m, "b", "q/") {
+		t.Error("DeleteKey must drop exactly the folders it touched")
+	}
+
+	cacheAt(m, "b", "p/")
+	if err := m.Delete(ctx, strPtr("q/"), bucket); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if cached(m, "b", "q/") || !cached(m, "b", "p/") {
+		t.Error("a folder delete must drop that folder's listing")
+	}
+
+	if err := m.PutBytes(ctx, bucket, "p/new.txt", []byte("x"), ObjectContent{}); err != nil {
+		t.Fatalf("PutBytes: %v", err)
+	}
+	if cached(m, "b", "p/") {
+		t.Error("PutBytes left the folder it wrote into cached")
+	}
+}
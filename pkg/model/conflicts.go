@@ -57,7 +57,7 @@ func CommonPrefix(keys []string) string {
 // expanding a folder into the concrete objects underneath it. It runs the
 // same planFolderCopy/remapKey mapping CopyKeys does, so what the overwrite
 // confirmation lists is exactly what the transfer would write.
-func (m *Model) PlannedCopyKeys(srcBucket, dstBucket *Object, srcKey, dstKey string, isFolder bool) ([]string, error) {
+func (m *Model) PlannedCopyKeys(ctx context.Context, srcBucket, dstBucket *Object, srcKey, dstKey string, isFolder bool) ([]string, error) {
 	if srcBucket == nil || srcBucket.Key == nil || dstBucket == nil || dstBucket.Key == nil {
 		return nil, errors.New("bucket is nil")
 	}
@@ -69,7 +69,7 @@ func (m *Model) PlannedCopyKeys(srcBucket, dstBucket *Object, srcKey, dstKey str
 	if err != nil {
 		return nil, err
 	}
-	objs, err := m.ListObjects(src, srcBucket)
+	objs, err := m.ListObjects(ctx, src, srcBucket)
 	if err != nil {
 		return nil, err
 	}
@@ -97,6 +97,12 @@ func (m *Model) Conflicts(ctx context.Context, bucket *Object, keys []string) ([
 	if len(keys) == 0 {
 		return nil, nil
 	}
+	// The probes go to the destination bucket, which may be in another
+	// region than the one this client is pinned to.
+	w, err := m.forBucket(bucket)
+	if err != nil {
+		return nil, err
+	}
 
 	var files, folders []string
 	for _, k := range keys {
@@ -109,7 +115,7 @@ func (m *Model) Conflicts(ctx context.Context, bucket *Object, keys []string) ([
 		}
 	}
 
-	found, err := m.conflictingFolders(ctx, bucket, folders)
+	found, err := w.conflictingFolders(ctx, bucket, folders)
 	if err != nil {
 		return nil, err
 	}
@@ -123,9 +129,9 @@ func (m *Model) Conflicts(ctx context.Context, bucket *Object, keys []string) ([
 	switch {
 	case len(files) == 0:
 	case len(files) <= conflictHeadLimit:
-		fileHits, err = m.conflictsByHead(ctx, bucket, files)
+		fileHits, err = w.conflictsByHead(ctx, bucket, files)
 	default:
-		fileHits, err = m.conflictsByListing(ctx, bucket, files)
+		fileHits, err = w.conflictsByListing(ctx, bucket, files)
 	}
 	if err != nil {
 		return nil, err
@@ -217,7 +223,7 @@ func (m *Model) conflictsByHead(ctx context.Context, bucket *Object, keys []stri
 // It is given plain object keys only; folder candidates are answered by
 // conflictingFolders.
 func (m *Model) conflictsByListing(ctx context.Context, bucket *Object, keys []string) ([]string, error) {
-	objs, err := m.ListObjects(CommonPrefix(keys), bucket)
+	objs, err := m.ListObjects(ctx, CommonPrefix(keys), bucket)
 	if err != nil {
 		return nil, err
 	}
@@ -239,6 +245,23 @@ func (m *Model) conflictsByListing(ctx context.Context, bucket *Object, keys []s
 	return found, nil
 }
 
+// isPreconditionFailed reports whether a conditional write was refused: 412,
+// or the 409 S3 answers while a competing conditional write is in flight.
+func isPreconditionFailed(err error) bool {
+	var re *awshttp.ResponseError
+	if errors.As(err, &re) && re.HTTPStatusCode() == http.StatusPreconditionFailed {
+		return true
+	}
+	var api smithy.APIError
+	if errors.As(err, &api) {
+		switch api.ErrorCode() {
+		case "PreconditionFailed", "ConditionalRequestConflict":
+			return true
+		}
+	}
+	return false
+}
+
 // isNotFound reports whether an error is S3's "this key does not exist".
 //
 // It has to be generous. A HEAD response carries no body, so there is nothing
@@ -5,12 +5,15 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/url"
+	"path"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 // ObjectContent is a whole small object in memory, together with every
@@ -163,6 +166,53 @@ func (m *Model) GetObjectContent(ctx context.Context, bucket *Object, key string
 	return content, nil
 }
 
+// CatObjects writes keys of bucket to w one after another, as cat would,
+// throttled by the bandwidth limit: the bodies as stored, gzip'd or not. It
+// stops at the first object that can't be read, naming it, or at the first
+// failed write, whose error it returns as is. It returns the bytes written.
+func (m *Model) CatObjects(ctx context.Context, bucket *Object, keys []string, w io.Writer) (int64, error) {
+	if bucket == nil || bucket.Key == nil {
+		return 0, fmt.Errorf("bucket is nil")
+	}
+	var total int64
+	for _, key := range keys {
+		out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(*bucket.Key),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", key, err)
+		}
+		body := &progressReader{r: out.Body, update: func(int64, int64) {}, limiter: m.Limiter}
+		cw := &catWriter{w: w}
+		n, err := io.Copy(cw, body)
+		out.Body.Close()
+		total += n
+		if err != nil {
+			if cw.err == nil {
+				err = fmt.Errorf("%s: %w", key, err)
+			}
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// catWriter remembers whether a write failed, which tells CatObjects a
+// failed copy's side.
+type catWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (c *catWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	if err != nil {
+		c.err = err
+	}
+	return n, err
+}
+
 // CurrentETag returns the object's ETag right now (quotes trimmed), via a
 // HEAD. The editor flow compares it against the ETag captured at download
 // time so a save can refuse to overwrite a concurrent modification.
@@ -184,6 +234,23 @@ func (m *Model) CurrentETag(ctx context.Context, bucket *Object, key string) (st
 // attributes (Content-Type and friends, storage class, metadata, tags) along.
 // attrs.Data and attrs.ETag are ignored — data is the body being written.
 func (m *Model) PutBytes(ctx context.Context, bucket *Object, key string, data []byte, attrs ObjectContent) error {
+	return m.putBytes(ctx, bucket, key, data, attrs)
+}
+
+// CreateBytes is PutBytes for a key that must not exist yet. The PUT carries
+// If-None-Match: *, so a key someone else wrote since the caller last looked
+// is not replaced: the write fails with ErrObjectExists instead. A backend
+// without conditional writes ignores the header, so callers still check for
+// the key first.
+func (m *Model) CreateBytes(ctx context.Context, bucket *Object, key string, data []byte, attrs ObjectContent) error {
+	err := m.putBytes(ctx, bucket, key, data, attrs, s3.WithAPIOptions(smithyhttp.SetHeaderValue("If-None-Match", "*")))
+	if isPreconditionFailed(err) {
+		return fmt.Errorf("%w: %s", ErrObjectExists, key)
+	}
+	return err
+}
+
+func (m *Model) putBytes(ctx context.Context, bucket *Object, key string, data []byte, attrs ObjectContent, optFns ...func(*s3.Options)) error {
 	if bucket == nil || bucket.Key == nil {
 		return fmt.Errorf("bucket is nil")
 	}
@@ -197,10 +264,17 @@ func (m *Model) PutBytes(ctx context.Context, bucket *Object, key string, data [
 		Body:   bytes.NewReader(data),
 	}
 	applyAttrs(in, attrs, true)
-	_, err := m.Client.PutObject(ctx, in)
+	_, err := m.Client.PutObject(ctx, in, optFns...)
+	m.invalidateLists(*bucket.Key, key)
 	return err
 }
 
+// GuessContentType is the Content-Type for a new object named key, from its
+// extension; "" when the extension says nothing, leaving it to the backend.
+func GuessContentType(key string) string {
+	return mime.TypeByExtension(path.Ext(key))
+}
+
 // CrossCopy streams one object between two independent clients: a GET from the
 // source feeds a multipart PUT at the destination, so the two sides may be
 // different endpoints entirely (MinIO → AWS) — the case server-side CopyObject
@@ -210,22 +284,30 @@ func (m *Model) PutBytes(ctx context.Context, bucket *Object, key string, data [
 // doesn't know it — so the destination's default applies). The source model's
 // bandwidth limiter throttles the read side, which bounds the whole pipe.
 func CrossCopy(ctx context.Context, src *Model, srcBucket *Object, srcKey string, dst *Model, dstBucket *Object, dstKey string, progress func(written, total int64)) error {
+	_, err := crossCopy(ctx, src, srcBucket, srcKey, dst, dstBucket, dstKey, progress)
+	return err
+}
+
+// crossCopy is CrossCopy reporting what it saw of the bytes it streamed, which
+// is what CrossMove verifies the destination against.
+func crossCopy(ctx context.Context, src *Model, srcBucket *Object, srcKey string, dst *Model, dstBucket *Object, dstKey string, progress func(written, total int64)) (streamDigest, error) {
 	if src == nil || dst == nil {
-		return fmt.Errorf("source and destination models are required")
+		return streamDigest{}, fmt.Errorf("source and destination models are required")
 	}
 	if srcBucket == nil || srcBucket.Key == nil || dstBucket == nil || dstBucket.Key == nil {
-		return fmt.Errorf("source and destination buckets are required")
+		return streamDigest{}, fmt.Errorf("source and destination buckets are required")
 	}
 	if srcKey == "" || dstKey == "" || strings.HasSuffix(dstKey, "/") {
-		return fmt.Errorf("bad keys: %q → %q", srcKey, dstKey)
+		return streamDigest{}, fmt.Errorf("bad keys: %q → %q", srcKey, dstKey)
 	}
+	defer dst.invalidateLists(*dstBucket.Key, dstKey)
 
 	out, err := src.Client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(*srcBucket.Key),
 		Key:    aws.String(srcKey),
 	})
 	if err != nil {
-		return fmt.Errorf("reading %s: %w", srcKey, err)
+		return streamDigest{}, fmt.Errorf("reading %s: %w", srcKey, err)
 	}
 	defer out.Body.Close()
 
@@ -233,7 +315,7 @@ func CrossCopy(ctx context.Context, src *Model, srcBucket *Object, srcKey string
 	if out.TagCount > 0 {
 		tagging, err := getObjectTagging(ctx, src.Client, *srcBucket.Key, srcKey, "")
 		if err != nil {
-			return fmt.Errorf("reading tags of %s: %w", srcKey, err)
+			return streamDigest{}, fmt.Errorf("reading tags of %s: %w", srcKey, err)
 		}
 		attrs.Tagging = tagging
 	}
@@ -243,8 +325,9 @@ func CrossCopy(ctx context.Context, src *Model, srcBucket *Object, srcKey string
 	if progress != nil {
 		update = progress
 	}
+	hasher := newPartHasher(uploadPartSize)
 	reader := &progressReader{
-		r:       out.Body,
+		r:       io.TeeReader(out.Body, hasher),
 		total:   out.ContentLength,
 		update:  update,
 		limiter: src.Limiter,
@@ -258,7 +341,10 @@ func CrossCopy(ctx context.Context, src *Model, srcBucket *Object, srcKey string
 	applyAttrs(in, attrs, false)
 
 	if _, err := newUploader(dst.Client).Upload(ctx, in); err != nil {
-		return fmt.Errorf("writing %s: %w", dstKey, err)
+		return streamDigest{}, fmt.Errorf("writing %s: %w", dstKey, err)
 	}
-	return nil
+	d := hasher.digest()
+	d.srcSize = out.ContentLength
+	d.srcETag = trimETag(out.ETag)
+	return d, nil
 }
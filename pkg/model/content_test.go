@@ -0,0 +1,95 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{ err error }
+
+func (f failingWriter) Write([]byte) (int, error) { return 0, f.err }
+
+func TestCatObjects(t *testing.T) {
+	m := newFakeModel(t, newFakeS3(map[string][]byte{"b/a": []byte("one\n"), "b/b": []byte("two\n")}))
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+
+	t.Run("in order, one after another", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := m.CatObjects(ctx, bucket, []string{"b", "a"}, &buf)
+		if err != nil || n != 8 || buf.String() != "two\none\n" {
+			t.Errorf("CatObjects = %d, %v, %q", n, err, buf.String())
+		}
+	})
+
+	t.Run("a missing object stops it, named", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := m.CatObjects(ctx, bucket, []string{"a", "gone", "b"}, &buf)
+		if err == nil || !strings.HasPrefix(err.Error(), "gone: ") || buf.String() != "one\n" {
+			t.Errorf("err = %v, wrote %q", err, buf.String())
+		}
+	})
+
+	t.Run("a failed write comes back as it is", func(t *testing.T) {
+		closed := errors.New("pipe closed")
+		_, err := m.CatObjects(ctx, bucket, []string{"a"}, failingWriter{closed})
+		if err != closed {
+			t.Errorf("err = %v, want the writer's own", err)
+		}
+	})
+}
+
+func TestGuessContentType(t *testing.T) {
+	for key, want := range map[string]string{
+		"a/index.html": "text/html; charset=utf-8",
+		"data.JSON":    "application/json",
+		"notes":        "",
+		"dir.d/notes":  "",
+	} {
+		if got := GuessContentType(key); got != want {
+			t.Errorf("GuessContentType(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCreateBytes(t *testing.T) {
+	fs := newFakeS3(nil)
+	m := newFakeModel(t, fs)
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+
+	if err := m.CreateBytes(ctx, bucket, "new.txt", []byte("mine"), ObjectContent{ContentType: "text/plain"}); err != nil {
+		t.Fatal(err)
+	}
+	err := m.CreateBytes(ctx, bucket, "new.txt", []byte("theirs"), ObjectContent{})
+	if !errors.Is(err, ErrObjectExists) {
+		t.Errorf("second create: %v, want ErrObjectExists", err)
+	}
+	if o, _ := fs.object("b/new.txt"); string(o.data) != "mine" || o.contentType != "text/plain" {
+		t.Errorf("stored %q as %q", o.data, o.contentType)
+	}
+}
+
+func TestObjectExists(t *testing.T) {
+	fs := newFakeS3(map[string][]byte{"b/here": []byte("x")})
+	m := newFakeModel(t, fs)
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+
+	if ok, err := m.ObjectExists(ctx, bucket, "here"); !ok || err != nil {
+		t.Errorf("here: %v, %v", ok, err)
+	}
+	if ok, err := m.ObjectExists(ctx, bucket, "gone"); ok || err != nil {
+		t.Errorf("gone: %v, %v", ok, err)
+	}
+	// Without ListBucket, S3 answers a HEAD 403 whether or not the key is
+	// there: that is no answer.
+	fs.fail = func(*http.Request) (int, string) { return http.StatusForbidden, "AccessDenied" }
+	if ok, err := m.ObjectExists(ctx, bucket, "gone"); ok || err == nil {
+		t.Errorf("forbidden: %v, %v; want an error", ok, err)
+	}
+}
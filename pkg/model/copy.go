@@ -112,6 +112,18 @@ type copySpec struct {
 	// to the copy — which means STANDARD, since that is what a CopyObject
 	// without an explicit class produces.
 	storageClass string
+
+	// srcClient reads the source's attributes and tags when the source is in
+	// another region than the copying model's client; nil means that client.
+	srcClient *s3.Client
+}
+
+// sourceClient is the client that can read the source object's attributes.
+func (sp copySpec) sourceClient(m *Model) *s3.Client {
+	if sp.srcClient != nil {
+		return sp.srcClient
+	}
+	return m.Client
 }
 
 // copySourceValue is the x-amz-copy-source header for this spec.
@@ -125,6 +137,7 @@ func (sp copySpec) copySourceValue() string {
 // runCopy executes a copy, choosing the single-request or multipart path by
 // the source's size.
 func (m *Model) runCopy(ctx context.Context, sp copySpec) error {
+	defer m.invalidateLists(sp.dstBucket, sp.dstKey)
 	size := sp.srcSize
 	var head *ObjectMeta
 
@@ -165,7 +178,7 @@ func (m *Model) headForCopy(ctx context.Context, sp copySpec) (ObjectMeta, error
 	if sp.srcVersion != "" {
 		in.VersionId = aws.String(sp.srcVersion)
 	}
-	out, err := m.Client.HeadObject(ctx, in)
+	out, err := sp.sourceClient(m).HeadObject(ctx, in)
 	if err != nil {
 		return ObjectMeta{}, err
 	}
@@ -252,7 +265,7 @@ func (m *Model) copyMultipart(ctx context.Context, sp copySpec, size int64, attr
 	// Tagging is optional on S3-compatible backends, so a failure degrades to
 	// "no tags" rather than failing a multi-gigabyte copy outright — the same
 	// trade the metadata editor makes.
-	if tagging, err := getObjectTagging(ctx, m.Client, sp.srcBucket, sp.srcKey, sp.srcVersion); err == nil && tagging != "" {
+	if tagging, err := getObjectTagging(ctx, sp.sourceClient(m), sp.srcBucket, sp.srcKey, sp.srcVersion); err == nil && tagging != "" {
 		create.Tagging = aws.String(tagging)
 	}
 
@@ -264,7 +277,7 @@ func (m *Model) copyMultipart(ctx context.Context, sp copySpec, size int64, attr
 
 	done, err := m.copyParts(ctx, sp, parts, uploadID)
 	if err != nil {
-		m.abortMultipart(ctx, sp, uploadID)
+		m.abortMultipart(ctx, sp.dstBucket, sp.dstKey, uploadID)
 		return err
 	}
 
@@ -277,7 +290,7 @@ func (m *Model) copyMultipart(ctx context.Context, sp copySpec, size int64, attr
 		// Completion can fail on its own (a rejected part list, a dead
 		// connection) and leaves the upload just as open as a failed part
 		// does, so it gets the same cleanup.
-		m.abortMultipart(ctx, sp, uploadID)
+		m.abortMultipart(ctx, sp.dstBucket, sp.dstKey, uploadID)
 		return fmt.Errorf("completing multipart copy of %s: %w", sp.dstKey, err)
 	}
 	return nil
@@ -287,12 +300,12 @@ func (m *Model) copyMultipart(ctx context.Context, sp copySpec, size int64, attr
 // they stop accruing storage charges. It runs on a context detached from the
 // caller's: a cancelled copy leaves ctx already dead, and the cleanup request
 // would be dropped before it was ever sent.
-func (m *Model) abortMultipart(ctx context.Context, sp copySpec, uploadID string) {
+func (m *Model) abortMultipart(ctx context.Context, bucket, key, uploadID string) {
 	abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
 	defer cancel()
 	_, _ = m.Client.AbortMultipartUpload(abortCtx, &s3.AbortMultipartUploadInput{
-		Bucket:   aws.String(sp.dstBucket),
-		Key:      aws.String(sp.dstKey),
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
 		UploadId: aws.String(uploadID),
 	})
 }
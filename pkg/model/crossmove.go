@@ -0,0 +1,142 @@
+package model
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// streamDigest is what crossCopy saw of the bytes it streamed: their count, the
+// MD5 of the whole stream, and the MD5 of every uploadPartSize chunk — enough
+// to predict the destination's ETag whether the uploader wrote it in one
+// request or as a multipart upload. srcSize and srcETag are what the source's
+// GET reported.
+type streamDigest struct {
+	size     int64
+	md5      string
+	partMD5s [][]byte
+
+	srcSize int64
+	srcETag string
+}
+
+// multipartETag is the ETag S3 assigns to a multipart upload of these parts:
+// the MD5 of the concatenated part MD5s, suffixed with the part count.
+func (d streamDigest) multipartETag() string {
+	h := md5.New()
+	for _, p := range d.partMD5s {
+		h.Write(p)
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(d.partMD5s))
+}
+
+// partHasher is an io.Writer that hashes a stream both whole and in fixed-size
+// parts. It sits on a TeeReader in front of the uploader, so the digest covers
+// exactly the bytes that were sent.
+type partHasher struct {
+	partSize int64
+	whole    hash.Hash
+	part     hash.Hash
+	inPart   int64
+	n        int64
+	parts    [][]byte
+}
+
+func newPartHasher(partSize int64) *partHasher {
+	return &partHasher{partSize: partSize, whole: md5.New(), part: md5.New()}
+}
+
+func (h *partHasher) Write(p []byte) (int, error) {
+	written := len(p)
+	h.whole.Write(p)
+	h.n += int64(len(p))
+	for len(p) > 0 {
+		take := h.partSize - h.inPart
+		if int64(len(p)) < take {
+			take = int64(len(p))
+		}
+		h.part.Write(p[:take])
+		h.inPart += take
+		p = p[take:]
+		if h.inPart == h.partSize {
+			h.parts = append(h.parts, h.part.Sum(nil))
+			h.part.Reset()
+			h.inPart = 0
+		}
+	}
+	return written, nil
+}
+
+// digest closes the trailing partial part and returns the result.
+func (h *partHasher) digest() streamDigest {
+	parts := h.parts
+	if h.inPart > 0 || len(parts) == 0 {
+		parts = append(parts, h.part.Sum(nil))
+	}
+	return streamDigest{size: h.n, md5: hex.EncodeToString(h.whole.Sum(nil)), partMD5s: parts}
+}
+
+// isPlainETag reports whether an ETag is a bare MD5 of the content, as S3
+// gives single-request uploads without SSE-KMS. Multipart ETags carry a "-N"
+// suffix and KMS-encrypted ones are opaque; neither can be checked against a
+// content hash directly.
+func isPlainETag(etag string) bool {
+	if len(etag) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(etag)
+	return err == nil
+}
+
+// verifyCrossCopy decides whether a streamed copy can be trusted enough to
+// delete its source. Sizes must agree three ways (the source's GET, the bytes
+// streamed, the destination's HEAD); a plain source ETag must match what was
+// read, so a corrupted read can't be laundered into a "verified" copy; and the
+// destination's ETag must match the stream, either as one MD5 or as the
+// multipart ETag of its parts. A destination ETag that matches neither — a
+// backend that computes ETags differently, KMS encryption — fails closed: the
+// copy stays, and so does the source.
+func verifyCrossCopy(d streamDigest, dstSize int64, dstETag string) error {
+	if d.size != d.srcSize {
+		return fmt.Errorf("read %d of %d source bytes", d.size, d.srcSize)
+	}
+	if dstSize != d.srcSize {
+		return fmt.Errorf("destination is %d bytes, source %d", dstSize, d.srcSize)
+	}
+	if isPlainETag(d.srcETag) && d.srcETag != d.md5 {
+		return fmt.Errorf("bytes read don't match the source ETag %s", d.srcETag)
+	}
+	if dstETag != d.md5 && dstETag != d.multipartETag() {
+		return fmt.Errorf("destination ETag %s doesn't match the bytes sent", dstETag)
+	}
+	return nil
+}
+
+// CrossMove streams one object to another model (CrossCopy), verifies the
+// written copy against what was streamed, and only then deletes the source.
+// Any verification failure leaves both objects in place and says so.
+func CrossMove(ctx context.Context, src *Model, srcBucket *Object, srcKey string, dst *Model, dstBucket *Object, dstKey string, progress func(written, total int64)) error {
+	d, err := crossCopy(ctx, src, srcBucket, srcKey, dst, dstBucket, dstKey, progress)
+	if err != nil {
+		return err
+	}
+	head, err := dst.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(*dstBucket.Key),
+		Key:    aws.String(dstKey),
+	})
+	if err != nil {
+		return fmt.Errorf("copied, but checking %s failed (source kept): %w", dstKey, err)
+	}
+	if err := verifyCrossCopy(d, head.ContentLength, trimETag(head.ETag)); err != nil {
+		return fmt.Errorf("copied, but not verified (source kept): %w", err)
+	}
+	if err := src.DeleteKey(ctx, srcKey, srcBucket); err != nil {
+		return fmt.Errorf("copied and verified, but deleting the source failed: %w", err)
+	}
+	return nil
+}
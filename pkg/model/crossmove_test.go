@@ -0,0 +1,115 @@
+package model
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func md5hex(b []byte) string {
+	s := md5.Sum(b)
+	return hex.EncodeToString(s[:])
+}
+
+// digestOf runs data through a partHasher in uneven writes, as a TeeReader in
+// front of a network body would.
+func digestOf(data []byte, partSize int64) streamDigest {
+	h := newPartHasher(partSize)
+	for i := 0; i < len(data); i += 7 {
+		end := i + 7
+		if end > len(data) {
+			end = len(data)
+		}
+		h.Write(data[i:end])
+	}
+	d := h.digest()
+	d.srcSize = int64(len(data))
+	return d
+}
+
+func TestPartHasher(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 5) // 50 bytes
+	d := digestOf(data, 16)
+
+	if d.size != 50 || d.md5 != md5hex(data) {
+		t.Fatalf("whole digest = %d bytes / %s, want 50 / %s", d.size, d.md5, md5hex(data))
+	}
+	// 16+16+16+2: the trailing partial part is closed by digest().
+	if len(d.partMD5s) != 4 {
+		t.Fatalf("got %d parts, want 4", len(d.partMD5s))
+	}
+	for i, want := range [][]byte{data[:16], data[16:32], data[32:48], data[48:]} {
+		if got := hex.EncodeToString(d.partMD5s[i]); got != md5hex(want) {
+			t.Errorf("part %d md5 = %s, want %s", i+1, got, md5hex(want))
+		}
+	}
+
+	var concat []byte
+	for _, p := range d.partMD5s {
+		concat = append(concat, p...)
+	}
+	if got, want := d.multipartETag(), fmt.Sprintf("%s-4", md5hex(concat)); got != want {
+		t.Errorf("multipartETag = %s, want %s", got, want)
+	}
+
+	// An exact multiple leaves no empty trailing part.
+	if d := digestOf(data[:32], 16); len(d.partMD5s) != 2 {
+		t.Errorf("32 bytes in 16-byte parts = %d parts, want 2", len(d.partMD5s))
+	}
+	// An empty object is one (empty) part — what a zero-byte upload is.
+	if d := digestOf(nil, 16); len(d.partMD5s) != 1 || d.md5 != md5hex(nil) {
+		t.Errorf("empty digest = %d parts / %s", len(d.partMD5s), d.md5)
+	}
+}
+
+func TestIsPlainETag(t *testing.T) {
+	cases := map[string]bool{
+		md5hex([]byte("x")):                  true,
+		"d41d8cd98f00b204e9800998ecf8427e-3": false, // multipart
+		"":                                   false,
+		strings.Repeat("z", 32):              false, // not hex
+		"d41d8cd98f00b204e9800998ecf8427e0000000": false,
+	}
+	for etag, want := range cases {
+		if got := isPlainETag(etag); got != want {
+			t.Errorf("isPlainETag(%q) = %v, want %v", etag, got, want)
+		}
+	}
+}
+
+func TestVerifyCrossCopy(t *testing.T) {
+	data := bytes.Repeat([]byte("abc"), 20) // 60 bytes
+	single := digestOf(data, 1<<20)         // one part: uploader used PutObject
+	multi := digestOf(data, 16)             // four parts: uploader went multipart
+	single.srcETag = md5hex(data)
+	multi.srcETag = "0123456789abcdef0123456789abcdef-2" // the source was multipart too
+
+	cases := []struct {
+		name    string
+		d       streamDigest
+		dstSize int64
+		dstETag string
+		wantErr string
+	}{
+		{"single-request copy", single, 60, md5hex(data), ""},
+		{"multipart copy, multipart source", multi, 60, multi.multipartETag(), ""},
+		{"short read", func() streamDigest { d := single; d.srcSize = 61; return d }(), 61, md5hex(data), "read 60 of 61"},
+		{"destination truncated", single, 59, md5hex(data), "destination is 59 bytes"},
+		{"corrupted read", func() streamDigest { d := single; d.srcETag = md5hex([]byte("other")); return d }(), 60, md5hex(data), "source ETag"},
+		{"unverifiable destination ETag (KMS)", single, 60, "kms-opaque-etag", "destination ETag"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyCrossCopy(tc.d, tc.dstSize, tc.dstETag)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("error = %v, want one mentioning %q", err, tc.wantErr)
+			}
+		})
+	}
+}
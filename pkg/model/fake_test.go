@@ -0,0 +1,409 @@
+package model
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeEpoch is when an object stored without a time was last modified.
+var fakeEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeS3 is an in-memory S3 endpoint for model tests, addressed path-style,
+// keeping objects by "bucket/key". It answers what the model sends the way S3
+// does: GET (a Range with 206, or 416 InvalidRange past the end), HEAD, PUT
+// (412 for If-None-Match: * on a taken key), multipart uploads, ListObjectsV2
+// (prefix, delimiter, start-after and continuation), DeleteObject and
+// DeleteObjects. Anything else — S3 Select included — gets 501
+// NotImplemented, as from an endpoint without it. The knobs, set before use,
+// bend it into the backend or the moment a test needs; stats says what
+// reached it.
+type fakeS3 struct {
+	pageSize    int           // keys or prefixes per listing page; 0 means 1000
+	ignoreRange bool          // send whole bodies whatever the Range, as some backends do
+	delay       time.Duration // before every answer
+	// fail, if set, picks requests to refuse: a non-zero status is answered
+	// with an error of that status and code instead.
+	fail     func(req *http.Request) (status int, code string)
+	onList   func(n int) // after the nth listing page went out
+	onDelete func(n int) // after the nth DeleteObjects batch went out
+
+	mu      sync.Mutex
+	objs    map[string]fakeObject
+	uploads map[string]*fakeUpload
+	nextID  int
+	stat    fakeStats
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	mod         time.Time
+}
+
+// fakeUpload is a multipart upload in progress.
+type fakeUpload struct {
+	path, contentType string
+	parts             map[int][]byte
+}
+
+// fakeStats counts what reached a fakeS3.
+type fakeStats struct {
+	requests int
+	gets     int   // object GETs
+	lists    int   // listing pages
+	deletes  int   // DeleteObjects batches
+	aborts   int   // multipart uploads aborted
+	parts    []int // the sizes of the parts of the last multipart upload completed
+	inflight int
+	peak     int // the most requests served at once
+}
+
+// newFakeS3 is a fakeS3 holding objs, by "bucket/key".
+func newFakeS3(objs map[string][]byte) *fakeS3 {
+	f := &fakeS3{objs: map[string]fakeObject{}, uploads: map[string]*fakeUpload{}}
+	for path, data := range objs {
+		f.objs[path] = fakeObject{data: data, mod: fakeEpoch}
+	}
+	return f
+}
+
+// put stores body at path.
+func (f *fakeS3) put(path, body string) { f.putAt(path, body, fakeEpoch) }
+
+// putAt stores body at path, last modified at mod.
+func (f *fakeS3) putAt(path, body string, mod time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objs[path] = fakeObject{data: []byte(body), mod: mod}
+}
+
+// fill stores an empty object at each of keys in bucket.
+func (f *fakeS3) fill(bucket string, keys ...string) {
+	for _, k := range keys {
+		f.put(bucket+"/"+k, "")
+	}
+}
+
+// remove deletes the objects at paths.
+func (f *fakeS3) remove(paths ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range paths {
+		delete(f.objs, p)
+	}
+}
+
+// setType sets the Content-Type the object at path is served with.
+func (f *fakeS3) setType(path, contentType string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o := f.objs[path]
+	o.contentType = contentType
+	f.objs[path] = o
+}
+
+// object is what is stored at path.
+func (f *fakeS3) object(path string) (fakeObject, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, ok := f.objs[path]
+	return o, ok
+}
+
+// contents is every stored body, by path.
+func (f *fakeS3) contents() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]string, len(f.objs))
+	for p, o := range f.objs {
+		out[p] = string(o.data)
+	}
+	return out
+}
+
+func (f *fakeS3) stats() fakeStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stat
+}
+
+func (f *fakeS3) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.stat.requests++
+	f.stat.inflight++
+	f.stat.peak = max(f.stat.peak, f.stat.inflight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.stat.inflight--
+		f.mu.Unlock()
+	}()
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	if f.fail != nil {
+		if status, code := f.fail(req); status != 0 {
+			return fakeError(req, status, code), nil
+		}
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	q := req.URL.Query()
+	switch {
+	case key == "" && req.Method == http.MethodGet && q.Get("list-type") == "2":
+		return f.list(req, bucket), nil
+	case key == "" && req.Method == http.MethodPost && q.Has("delete"):
+		return f.deleteObjects(req, bucket, body), nil
+	case key == "":
+	case req.Method == http.MethodPost && q.Has("uploads"):
+		return f.createUpload(req, bucket+"/"+key), nil
+	case req.Method == http.MethodPut && q.Has("uploadId"):
+		return f.uploadPart(req, q.Get("uploadId"), q.Get("partNumber"), body), nil
+	case req.Method == http.MethodPost && q.Has("uploadId"):
+		return f.completeUpload(req, q.Get("uploadId"), body), nil
+	case req.Method == http.MethodDelete && q.Has("uploadId"):
+		f.mu.Lock()
+		delete(f.uploads, q.Get("uploadId"))
+		f.stat.aborts++
+		f.mu.Unlock()
+		return fakeResponse(req, http.StatusNoContent, nil), nil
+	case len(q) > 0 && !q.Has("x-id"):
+		// A sub-resource (?select, ?tagging, ...) this fake knows nothing of.
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		return f.get(req, bucket+"/"+key), nil
+	case req.Method == http.MethodPut && req.Header.Get("X-Amz-Copy-Source") == "":
+		f.mu.Lock()
+		_, taken := f.objs[bucket+"/"+key]
+		if taken && req.Header.Get("If-None-Match") == "*" {
+			f.mu.Unlock()
+			return fakeError(req, http.StatusPreconditionFailed, "PreconditionFailed"), nil
+		}
+		f.objs[bucket+"/"+key] = fakeObject{data: body, contentType: req.Header.Get("Content-Type"), mod: time.Now()}
+		f.mu.Unlock()
+		resp := fakeResponse(req, http.StatusOK, nil)
+		resp.Header.Set("ETag", fakeETag(body))
+		return resp, nil
+	case req.Method == http.MethodDelete:
+		f.remove(bucket + "/" + key)
+		return fakeResponse(req, http.StatusNoContent, nil), nil
+	}
+	return fakeError(req, http.StatusNotImplemented, "NotImplemented"), nil
+}
+
+func (f *fakeS3) get(req *http.Request, path string) *http.Response {
+	f.mu.Lock()
+	o, ok := f.objs[path]
+	if ok && req.Method == http.MethodGet {
+		f.stat.gets++
+	}
+	f.mu.Unlock()
+	if !ok {
+		if req.Method == http.MethodHead {
+			return fakeResponse(req, http.StatusNotFound, nil)
+		}
+		return fakeError(req, http.StatusNotFound, "NoSuchKey")
+	}
+	data := o.data
+	resp := fakeResponse(req, http.StatusOK, nil)
+	resp.Header.Set("ETag", fakeETag(data))
+	resp.Header.Set("Last-Modified", o.mod.UTC().Format(http.TimeFormat))
+	if o.contentType != "" {
+		resp.Header.Set("Content-Type", o.contentType)
+	}
+	var a, z int
+	if _, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &a, &z); err == nil && !f.ignoreRange {
+		if a >= len(data) {
+			return fakeError(req, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+		}
+		z = min(z, len(data)-1)
+		resp.StatusCode = http.StatusPartialContent
+		resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", a, z, len(data)))
+		data = data[a : z+1]
+	}
+	resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	if req.Method == http.MethodGet {
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	return resp
+}
+
+// list answers one ListObjectsV2 page. A continuation token is the last key
+// or prefix served; a prefix is served as a key sorting after everything
+// under it, so the next page starts past it.
+func (f *fakeS3) list(req *http.Request, bucket string) *http.Response {
+	q := req.URL.Query()
+	prefix, delim, after := q.Get("prefix"), q.Get("delimiter"), q.Get("start-after")
+	if tok := q.Get("continuation-token"); tok != "" {
+		after = tok
+	}
+	pageSize := f.pageSize
+	if pageSize == 0 {
+		pageSize = 1000
+	}
+
+	f.mu.Lock()
+	var keys []string
+	for p := range f.objs {
+		if k, ok := strings.CutPrefix(p, bucket+"/"); ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>%s</Name><Prefix>%s</Prefix>`, bucket, prefix)
+	n, last, seen := 0, "", map[string]bool{}
+	truncated := false
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) || k <= after {
+			continue
+		}
+		if delim != "" {
+			if i := strings.Index(k[len(prefix):], delim); i >= 0 {
+				cp := k[:len(prefix)+i+1]
+				if seen[cp] || cp <= after {
+					continue
+				}
+				if n == pageSize {
+					truncated = true
+					break
+				}
+				seen[cp] = true
+				fmt.Fprintf(&b, "<CommonPrefixes><Prefix>%s</Prefix></CommonPrefixes>", cp)
+				n, last = n+1, cp+"\U0010FFFF"
+				continue
+			}
+		}
+		if n == pageSize {
+			truncated = true
+			break
+		}
+		o := f.objs[bucket+"/"+k]
+		fmt.Fprintf(&b, "<Contents><Key>%s</Key><Size>%d</Size><ETag>%s</ETag><LastModified>%s</LastModified></Contents>",
+			k, len(o.data), fakeETag(o.data), o.mod.UTC().Format(time.RFC3339))
+		n, last = n+1, k
+	}
+	if truncated {
+		fmt.Fprintf(&b, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", last)
+	} else {
+		b.WriteString("<IsTruncated>false</IsTruncated>")
+	}
+	b.WriteString("</ListBucketResult>")
+	f.stat.lists++
+	page, hook := f.stat.lists, f.onList
+	f.mu.Unlock()
+	if hook != nil {
+		hook(page)
+	}
+	return fakeResponse(req, http.StatusOK, []byte(b.String()))
+}
+
+func (f *fakeS3) deleteObjects(req *http.Request, bucket string, body []byte) *http.Response {
+	var in struct {
+		Objects []struct{ Key string } `xml:"Object"`
+	}
+	if err := xml.Unmarshal(body, &in); err != nil {
+		return fakeError(req, http.StatusBadRequest, "MalformedXML")
+	}
+	var b strings.Builder
+	b.WriteString(`<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	f.mu.Lock()
+	for _, o := range in.Objects {
+		delete(f.objs, bucket+"/"+o.Key)
+		fmt.Fprintf(&b, "<Deleted><Key>%s</Key></Deleted>", o.Key)
+	}
+	f.stat.deletes++
+	batch, hook := f.stat.deletes, f.onDelete
+	f.mu.Unlock()
+	b.WriteString("</DeleteResult>")
+	if hook != nil {
+		hook(batch)
+	}
+	return fakeResponse(req, http.StatusOK, []byte(b.String()))
+}
+
+func (f *fakeS3) createUpload(req *http.Request, path string) *http.Response {
+	f.mu.Lock()
+	f.nextID++
+	id := fmt.Sprintf("upload-%d", f.nextID)
+	f.uploads[id] = &fakeUpload{path: path, contentType: req.Header.Get("Content-Type"), parts: map[int][]byte{}}
+	f.mu.Unlock()
+	return fakeResponse(req, http.StatusOK, []byte(fmt.Sprintf(
+		`<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, id)))
+}
+
+func (f *fakeS3) uploadPart(req *http.Request, id, number string, body []byte) *http.Response {
+	n, err := strconv.Atoi(number)
+	f.mu.Lock()
+	up, ok := f.uploads[id]
+	if ok && err == nil {
+		up.parts[n] = body
+	}
+	f.mu.Unlock()
+	if !ok || err != nil {
+		return fakeError(req, http.StatusNotFound, "NoSuchUpload")
+	}
+	resp := fakeResponse(req, http.StatusOK, nil)
+	resp.Header.Set("ETag", fakeETag(body))
+	return resp
+}
+
+// completeUpload joins the parts the request lists, in its order, which S3
+// requires to be ascending.
+func (f *fakeS3) completeUpload(req *http.Request, id string, body []byte) *http.Response {
+	var in struct {
+		Parts []struct{ PartNumber int } `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &in); err != nil || len(in.Parts) == 0 {
+		return fakeError(req, http.StatusBadRequest, "MalformedXML")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	up, ok := f.uploads[id]
+	if !ok {
+		return fakeError(req, http.StatusNotFound, "NoSuchUpload")
+	}
+	var data []byte
+	var sizes []int
+	for i, p := range in.Parts {
+		part, ok := up.parts[p.PartNumber]
+		if !ok || i > 0 && p.PartNumber <= in.Parts[i-1].PartNumber {
+			return fakeError(req, http.StatusBadRequest, "InvalidPartOrder")
+		}
+		data = append(data, part...)
+		sizes = append(sizes, len(part))
+	}
+	delete(f.uploads, id)
+	f.objs[up.path] = fakeObject{data: data, contentType: up.contentType, mod: time.Now()}
+	f.stat.parts = sizes
+	return fakeResponse(req, http.StatusOK, []byte(fmt.Sprintf(
+		`<CompleteMultipartUploadResult><Key>%s</Key><ETag>"%x-%d"</ETag></CompleteMultipartUploadResult>`, up.path, md5.Sum(data), len(sizes))))
+}
+
+func fakeETag(data []byte) string { return fmt.Sprintf(`"%x"`, md5.Sum(data)) }
+
+func fakeResponse(req *http.Request, status int, body []byte) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: http.NoBody, Request: req}
+	if body != nil {
+		resp.Header.Set("Content-Type", "application/xml")
+		resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp
+}
+
+func fakeError(req *http.Request, status int, code string) *http.Response {
+	return fakeResponse(req, status, []byte(fmt.Sprintf(`<Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)))
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// followTail is how much of an object a follow shows when it starts or
+// starts over, and the most one poll fetches: the end of a big log, as tail
+// shows it.
+const followTail = 256 << 10
+
+// followOverlap is how many of the bytes already shown a poll reads again in
+// front of the new ones. S3 has no append — a grown object is a rewritten one
+// — so they must match for the new bytes to be a continuation; otherwise the
+// object was replaced and the follow starts over.
+const followOverlap = 64
+
+// FollowUpdate is what one Follower.Poll found.
+type FollowUpdate struct {
+	Key string // the object followed: in prefix mode the newest key, "" while there is none
+	// Data is what to show after what was shown already or, on Reset, in its
+	// place.
+	Data  []byte
+	Reset bool
+	// Skipped counts the bytes left out in front of Data: the start of an
+	// object bigger than followTail, or a growth bigger than that.
+	Skipped int64
+	Size    int64 // the object's size now
+	Changed bool  // anything happened since the last poll
+}
+
+// Follower follows one object, or the newest object under a prefix, as it
+// grows or is replaced — a tail -f over HEAD and ranged GETs.
+type Follower struct {
+	m      *Model
+	bucket *Object
+	target string
+	prefix bool
+
+	key  string
+	etag string
+	size int64
+	tail []byte // the last followOverlap bytes shown
+}
+
+// NewFollower returns a Follower of key target in bucket or, with prefix,
+// of the newest object under the prefix target.
+func (m *Model) NewFollower(bucket *Object, target string, prefix bool) *Follower {
+	if prefix {
+		target = NormalizePrefix(target)
+	}
+	return &Follower{m: m, bucket: bucket, target: target, prefix: prefix}
+}
+
+// Poll checks the followed object once. A HEAD that shows no change costs
+// nothing more; an object that grew has its new bytes fetched with one
+// ranged GET, and one that was replaced (its ETag changed without the bytes
+// shown still leading it), or a newer key under a followed prefix, is
+// reloaded from its last followTail bytes. Prefix mode lists the prefix on
+// every poll.
+func (f *Follower) Poll(ctx context.Context) (FollowUpdate, error) {
+	if f.bucket == nil || f.bucket.Key == nil {
+		return FollowUpdate{}, fmt.Errorf("bucket is nil")
+	}
+	key := f.target
+	if f.prefix {
+		newest, err := f.newest(ctx)
+		if err != nil || newest == "" {
+			return FollowUpdate{Key: f.key}, err
+		}
+		key = newest
+	}
+	head, err := f.m.HeadObject(ctx, f.bucket, key)
+	if err != nil {
+		return FollowUpdate{Key: key}, err
+	}
+	up := FollowUpdate{Key: key, Size: head.Size}
+	if key == f.key && head.ETag == f.etag && head.Size == f.size {
+		return up, nil
+	}
+	up.Changed = true
+
+	if key == f.key && head.Size > f.size {
+		from := f.size - int64(len(f.tail))
+		grown := head.Size - f.size
+		n := int64(len(f.tail)) + grown
+		if grown > followTail {
+			n = int64(len(f.tail)) // only check it's still the same object
+		}
+		// Nothing was shown of an object that started out empty, so there is
+		// nothing to check either.
+		var r ObjectRange
+		if n > 0 {
+			if r, err = f.m.ReadRange(ctx, f.bucket, key, from, n); err != nil {
+				return up, err
+			}
+		}
+		if bytes.HasPrefix(r.Data, f.tail) {
+			if grown <= followTail {
+				up.Data = r.Data[len(f.tail):]
+				f.remember(key, head.ETag, from+int64(len(r.Data)), up.Data, false)
+				return up, nil
+			}
+			if up.Data, err = f.readTail(ctx, key, head.Size); err != nil {
+				return up, err
+			}
+			up.Skipped = grown - int64(len(up.Data))
+			f.remember(key, head.ETag, head.Size, up.Data, true)
+			return up, nil
+		}
+	}
+
+	// Replaced, truncated, or a key not shown yet: start over.
+	up.Reset = true
+	if up.Data, err = f.readTail(ctx, key, head.Size); err != nil {
+		return up, err
+	}
+	up.Skipped = head.Size - int64(len(up.Data))
+	f.remember(key, head.ETag, head.Size, up.Data, true)
+	return up, nil
+}
+
+// readTail reads the last followTail bytes of an object of size bytes.
+func (f *Follower) readTail(ctx context.Context, key string, size int64) ([]byte, error) {
+	off := max(0, size-followTail)
+	if size == off {
+		return nil, nil
+	}
+	r, err := f.m.ReadRange(ctx, f.bucket, key, off, size-off)
+	return r.Data, err
+}
+
+// remember records what was shown of key up to end, after data; with fresh,
+// data is all of it there is to go on.
+func (f *Follower) remember(key, etag string, end int64, data []byte, fresh bool) {
+	tail := data
+	if !fresh {
+		tail = append(append([]byte(nil), f.tail...), data...)
+	}
+	if len(tail) > followOverlap {
+		tail = tail[len(tail)-followOverlap:]
+	}
+	f.key, f.etag, f.size = key, etag, end
+	f.tail = append([]byte(nil), tail...)
+}
+
+// newest is the most recently modified object under the followed prefix,
+// the greater key on a tie; folder markers don't count.
+func (f *Follower) newest(ctx context.Context) (string, error) {
+	objs, err := f.m.ListObjects(ctx, f.target, f.bucket)
+	if err != nil {
+		return "", err
+	}
+	best := ""
+	var bestTime int64
+	for _, o := range objs {
+		if o.Key == nil || strings.HasSuffix(*o.Key, "/") {
+			continue
+		}
+		var t int64
+		if o.LastModified != nil {
+			t = o.LastModified.UnixNano()
+		}
+		if best == "" || t > bestTime || t == bestTime && *o.Key > best {
+			best, bestTime = *o.Key, t
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFollower(t *testing.T) {
+	fs := newFakeS3(nil)
+	m := newFakeModel(t, fs)
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	poll := func(t *testing.T, f *Follower) FollowUpdate {
+		t.Helper()
+		up, err := f.Poll(ctx)
+		if err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+		return up
+	}
+
+	t.Run("one key", func(t *testing.T) {
+		fs.putAt("b/app.log", "one\ntwo\n", t0)
+		f := m.NewFollower(bucket, "app.log", false)
+
+		if up := poll(t, f); !up.Reset || string(up.Data) != "one\ntwo\n" || up.Skipped != 0 {
+			t.Fatalf("first poll = %+v", up)
+		}
+		gets := fs.stats().gets
+		if up := poll(t, f); up.Changed || up.Data != nil || fs.stats().gets != gets {
+			t.Fatalf("an unchanged object should cost a HEAD only: %+v, %d GET(s)", up, fs.stats().gets-gets)
+		}
+
+		fs.putAt("b/app.log", "one\ntwo\nthree\n", t0)
+		if up := poll(t, f); up.Reset || string(up.Data) != "three\n" {
+			t.Fatalf("growth = %+v", up)
+		}
+
+		fs.putAt("b/app.log", "fresh\n", t0)
+		if up := poll(t, f); !up.Reset || string(up.Data) != "fresh\n" {
+			t.Fatalf("a shorter rewrite = %+v", up)
+		}
+
+		fs.putAt("b/app.log", "FRESH\nmore\n", t0)
+		if up := poll(t, f); !up.Reset || string(up.Data) != "FRESH\nmore\n" {
+			t.Fatalf("a longer rewrite that doesn't continue what was shown = %+v", up)
+		}
+	})
+
+	t.Run("a big object shows its tail, and a big growth skips", func(t *testing.T) {
+		big := strings.Repeat("x", followTail+100)
+		fs.putAt("b/big.log", big, t0)
+		f := m.NewFollower(bucket, "big.log", false)
+		up := poll(t, f)
+		if !up.Reset || len(up.Data) != followTail || up.Skipped != 100 {
+			t.Fatalf("first poll: %d byte(s), skipped %d", len(up.Data), up.Skipped)
+		}
+		fs.putAt("b/big.log", big+strings.Repeat("y", followTail+5), t0)
+		up = poll(t, f)
+		if up.Reset || len(up.Data) != followTail || up.Skipped != 5 || up.Data[0] != 'y' {
+			t.Fatalf("growth: reset %v, %d byte(s), skipped %d", up.Reset, len(up.Data), up.Skipped)
+		}
+	})
+
+	t.Run("an empty object that grows big shows its tail", func(t *testing.T) {
+		fs.putAt("b/empty.log", "", t0)
+		f := m.NewFollower(bucket, "empty.log", false)
+		if up := poll(t, f); !up.Reset || len(up.Data) != 0 {
+			t.Fatalf("first poll = %+v", up)
+		}
+		fs.putAt("b/empty.log", strings.Repeat("z", followTail+7), t0)
+		up := poll(t, f)
+		if up.Reset || len(up.Data) != followTail || up.Skipped != 7 {
+			t.Fatalf("growth: reset %v, %d byte(s), skipped %d", up.Reset, len(up.Data), up.Skipped)
+		}
+		fs.putAt("b/empty.log", strings.Repeat("z", followTail+7)+"end\n", t0)
+		if up := poll(t, f); up.Reset || string(up.Data) != "end\n" {
+			t.Fatalf("then growth = %+v", up)
+		}
+	})
+
+	t.Run("a prefix follows its newest key", func(t *testing.T) {
+		fs.putAt("b/logs/", "", t0.Add(time.Hour)) // a folder marker never counts
+		fs.putAt("b/logs/a.log", "a1\n", t0)
+		f := m.NewFollower(bucket, "logs", true)
+		if up := poll(t, f); up.Key != "logs/a.log" || string(up.Data) != "a1\n" {
+			t.Fatalf("first poll = %+v", up)
+		}
+		fs.putAt("b/logs/a.log", "a1\na2\n", t0.Add(time.Minute))
+		if up := poll(t, f); up.Key != "logs/a.log" || up.Reset || string(up.Data) != "a2\n" {
+			t.Fatalf("growth = %+v", up)
+		}
+		fs.putAt("b/logs/b.log", "b1\n", t0.Add(2*time.Minute))
+		if up := poll(t, f); up.Key != "logs/b.log" || !up.Reset || string(up.Data) != "b1\n" {
+			t.Fatalf("rollover = %+v", up)
+		}
+	})
+
+	t.Run("an empty prefix waits", func(t *testing.T) {
+		up := poll(t, m.NewFollower(bucket, "none/", true))
+		if up.Key != "" || up.Changed {
+			t.Fatalf("poll = %+v", up)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// gunzipped returns r decompressed when it starts with the gzip magic and r
+// itself otherwise, so a reader need not trust a key's extension or a
+// Content-Encoding header a backend may or may not have recorded.
+func gunzipped(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return io.NopCloser(br), nil
+}
+
+// ReadObjects fetches each of keys in bucket on up to shardWorkers goroutines
+// and hands read the body, decompressed if it is gzip'd and throttled by the
+// bandwidth limit. A failed GET is passed to read as err rather than ending
+// the run — one unreadable object should not cost the rest; an error returned
+// by read stops everything and is returned. done, if set, gets the running
+// number of objects read. Bodies are closed when read returns.
+func (m *Model) ReadObjects(ctx context.Context, bucket *Object, keys []string, read func(key string, body io.Reader, err error) error, done func(n int)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var finished atomic.Int64
+	return eachShard(ctx, cancel, len(keys), func(i int) error {
+		err := m.readObject(ctx, *bucket.Key, keys[i], read)
+		if done != nil {
+			done(int(finished.Add(1)))
+		}
+		return err
+	})
+}
+
+func (m *Model) readObject(ctx context.Context, bucket, key string, read func(key string, body io.Reader, err error) error) error {
+	out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return read(key, nil, err)
+	}
+	defer out.Body.Close()
+	body, err := gunzipped(&progressReader{r: out.Body, update: func(int64, int64) {}, limiter: m.Limiter})
+	if err != nil {
+		return read(key, nil, err)
+	}
+	defer body.Close()
+	return read(key, body, nil)
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestReadObjects(t *testing.T) {
+	tr := newFakeS3(map[string][]byte{
+		"b/plain.txt": []byte("hello"),
+		"b/packed.gz": gzipped(t, "unpacked"),
+	})
+	m := newFakeModel(t, tr)
+
+	var mu sync.Mutex
+	got := map[string]string{}
+	var failed []string
+	last := 0
+	err := m.ReadObjects(context.Background(), &Object{Key: strPtr("b")}, []string{"plain.txt", "packed.gz", "missing"},
+		func(key string, body io.Reader, err error) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, key)
+				return nil
+			}
+			data, err := io.ReadAll(body)
+			got[key] = string(data)
+			return err
+		},
+		func(n int) {
+			mu.Lock()
+			last = max(last, n)
+			mu.Unlock()
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["plain.txt"] != "hello" || got["packed.gz"] != "unpacked" {
+		t.Errorf("read %q", got)
+	}
+	sort.Strings(failed)
+	if len(failed) != 1 || failed[0] != "missing" || last != 3 {
+		t.Errorf("failed %q, progress %d", failed, last)
+	}
+}
@@ -0,0 +1,251 @@
+package model
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// indexFormat is the version written into a local index's header. An index of
+// another version is refused rather than misread; rebuilding it is cheap next
+// to getting it wrong.
+const indexFormat = 1
+
+// LocalIndex is a snapshot of every object under Prefix in Bucket — key, size,
+// ETag, storage class and modification time — kept on disk so a search can
+// run without listing anything. It is refreshed a prefix at a time: each
+// refresh re-lists one prefix inside it and splices the result in, and the
+// index remembers when each prefix was last listed (Built).
+type LocalIndex struct {
+	Bucket string
+	Prefix string
+
+	parts   []indexPart  // prefixes listed and when, most general first
+	entries []indexEntry // sorted by key
+}
+
+// indexPart records that everything under Prefix was listed at At.
+type indexPart struct {
+	Prefix string    `json:"prefix"`
+	At     time.Time `json:"at"`
+}
+
+// indexHeader is the first line of an index file.
+type indexHeader struct {
+	Format int         `json:"format"`
+	Bucket string      `json:"bucket"`
+	Prefix string      `json:"prefix"`
+	Parts  []indexPart `json:"parts"`
+}
+
+// IndexDelta counts what a refresh changed.
+type IndexDelta struct {
+	Added, Changed, Removed int
+}
+
+// NewLocalIndex returns an empty index of prefix in bucket; Refresh of prefix
+// fills it.
+func NewLocalIndex(bucket, prefix string) *LocalIndex {
+	return &LocalIndex{Bucket: bucket, Prefix: prefix}
+}
+
+// Len returns the number of objects in the index.
+func (ix *LocalIndex) Len() int { return len(ix.entries) }
+
+// Covers reports whether prefix lies inside the index.
+func (ix *LocalIndex) Covers(prefix string) bool {
+	return strings.HasPrefix(prefix, ix.Prefix)
+}
+
+// Built returns when the index's view of prefix was listed: the time of the
+// most specific refresh covering it. Refreshes of folders beneath prefix are
+// newer and do not count. Zero when nothing covering prefix was ever listed.
+func (ix *LocalIndex) Built(prefix string) time.Time {
+	var at time.Time
+	best := -1
+	for _, p := range ix.parts {
+		if strings.HasPrefix(prefix, p.Prefix) && len(p.Prefix) > best {
+			at, best = p.At, len(p.Prefix)
+		}
+	}
+	return at
+}
+
+// Refresh re-lists prefix (which must lie inside the index) through m and
+// replaces that range of the index with what it finds, reporting what
+// changed. The rest of the index is untouched, so refreshing a busy folder of
+// a large index costs a listing of that folder only. scanned is passed on to
+// ListObjectsSharded.
+func (ix *LocalIndex) Refresh(ctx context.Context, m *Model, prefix string, scanned func(total int)) (IndexDelta, error) {
+	if !ix.Covers(prefix) {
+		return IndexDelta{}, fmt.Errorf("%q is outside the index of %q", prefix, ix.Prefix)
+	}
+	at := time.Now()
+	objs, err := m.ListObjectsSharded(ctx, prefix, &Object{Key: aws.String(ix.Bucket), Ot: Bucket}, scanned)
+	if err != nil {
+		return IndexDelta{}, err
+	}
+	fresh := make([]indexEntry, 0, len(objs))
+	for _, o := range objs {
+		e := indexEntry{key: aws.ToString(o.Key), etag: trimETag(o.ETag), class: string(o.StorageClass), size: o.Size}
+		if o.LastModified != nil {
+			e.mod = o.LastModified.UnixMilli()
+		}
+		fresh = append(fresh, e)
+	}
+
+	lo := sort.Search(len(ix.entries), func(i int) bool { return ix.entries[i].key >= prefix })
+	hi := lo
+	for hi < len(ix.entries) && strings.HasPrefix(ix.entries[hi].key, prefix) {
+		hi++
+	}
+	delta := diffEntries(ix.entries[lo:hi], fresh)
+	spliced := make([]indexEntry, 0, len(ix.entries)-(hi-lo)+len(fresh))
+	spliced = append(spliced, ix.entries[:lo]...)
+	spliced = append(spliced, fresh...)
+	ix.entries = append(spliced, ix.entries[hi:]...)
+
+	parts := ix.parts[:0:0]
+	for _, p := range ix.parts {
+		if !strings.HasPrefix(p.Prefix, prefix) {
+			parts = append(parts, p)
+		}
+	}
+	parts = append(parts, indexPart{Prefix: prefix, At: at})
+	sort.SliceStable(parts, func(i, j int) bool { return len(parts[i].Prefix) < len(parts[j].Prefix) })
+	ix.parts = parts
+	return delta, nil
+}
+
+// diffEntries compares two key-sorted runs of the same range.
+func diffEntries(old, fresh []indexEntry) IndexDelta {
+	var d IndexDelta
+	i, j := 0, 0
+	for i < len(old) || j < len(fresh) {
+		switch {
+		case j == len(fresh) || i < len(old) && old[i].key < fresh[j].key:
+			d.Removed++
+			i++
+		case i == len(old) || fresh[j].key < old[i].key:
+			d.Added++
+			j++
+		default:
+			if old[i] != fresh[j] {
+				d.Changed++
+			}
+			i++
+			j++
+		}
+	}
+	return d
+}
+
+// Walk calls fn with every object under prefix, in key order, until fn
+// returns false.
+func (ix *LocalIndex) Walk(prefix string, fn func(o s3t.Object) bool) {
+	for i := sort.Search(len(ix.entries), func(i int) bool { return ix.entries[i].key >= prefix }); i < len(ix.entries); i++ {
+		if !strings.HasPrefix(ix.entries[i].key, prefix) || !fn(ix.entries[i].object()) {
+			return
+		}
+	}
+}
+
+// Save writes the index to path: gzip'd, a JSON header line, then one CSV row
+// per object (key, size, modified in Unix milliseconds, ETag, class). It
+// writes a temporary file beside path and renames it over, so a crash leaves
+// the previous index intact.
+func (ix *LocalIndex) Save(path string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".index-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	zw := gzip.NewWriter(f)
+	head, err := json.Marshal(indexHeader{Format: indexFormat, Bucket: ix.Bucket, Prefix: ix.Prefix, Parts: ix.parts})
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(append(head, '\n')); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(zw)
+	for _, e := range ix.entries {
+		if err := cw.Write([]string{e.key, strconv.FormatInt(e.size, 10), strconv.FormatInt(e.mod, 10), e.etag, e.class}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadIndex reads an index written by Save.
+func LoadIndex(path string) (*LocalIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	br := bufio.NewReader(zr)
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var head indexHeader
+	if err := json.Unmarshal(line, &head); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if head.Format != indexFormat {
+		return nil, fmt.Errorf("%s: index format %d, want %d; rebuild it", path, head.Format, indexFormat)
+	}
+	ix := &LocalIndex{Bucket: head.Bucket, Prefix: head.Prefix, parts: head.Parts}
+	classes := newInterner()
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = 5
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return ix, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		e := indexEntry{key: rec[0], etag: rec[3], class: classes.intern(rec[4])}
+		e.size, _ = strconv.ParseInt(rec[1], 10, 64)
+		e.mod, _ = strconv.ParseInt(rec[2], 10, 64)
+		ix.entries = append(ix.entries, e)
+	}
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func indexKeys(ix *LocalIndex, prefix string) []string {
+	var out []string
+	ix.Walk(prefix, func(o s3t.Object) bool {
+		out = append(out, aws.ToString(o.Key))
+		return true
+	})
+	return out
+}
+
+// A refresh of a folder re-lists that folder only, splices it in and counts
+// the difference; the rest of the index and its age are left alone.
+func TestLocalIndexRefresh(t *testing.T) {
+	fs := newFakeS3(nil)
+	fs.fill("b", "a/1", "a/2", "b/1", "b/2", "c")
+	m := newFakeModel(t, fs)
+	ix := NewLocalIndex("b", "")
+	if d, err := ix.Refresh(context.Background(), m, "", nil); err != nil || d != (IndexDelta{Added: 5}) {
+		t.Fatalf("build: %+v, %v", d, err)
+	}
+	full := ix.Built("b/")
+
+	fs.remove("b/a/2", "b/b/2")
+	fs.fill("b", "b/3", "d")
+	time.Sleep(time.Millisecond)
+	d, err := ix.Refresh(context.Background(), m, "b/", nil)
+	if err != nil || d != (IndexDelta{Added: 1, Removed: 1}) {
+		t.Fatalf("refresh b/: %+v, %v", d, err)
+	}
+	if got, want := indexKeys(ix, ""), []string{"a/1", "a/2", "b/1", "b/3", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after refreshing b/: %q, want %q", got, want)
+	}
+	if !ix.Built("b/x/").After(full) || !ix.Built("a/").Equal(full) || !ix.Built("").Equal(full) {
+		t.Errorf("ages: b/ %v, a/ %v, root %v (built %v)", ix.Built("b/"), ix.Built("a/"), ix.Built(""), full)
+	}
+
+	// Refreshing the whole index supersedes the narrower refresh.
+	if d, err := ix.Refresh(context.Background(), m, "", nil); err != nil || d != (IndexDelta{Added: 1, Removed: 1}) {
+		t.Fatalf("refresh all: %+v, %v", d, err)
+	}
+	if len(ix.parts) != 1 {
+		t.Errorf("parts after a full refresh: %+v", ix.parts)
+	}
+	if _, err := NewLocalIndex("b", "a/").Refresh(context.Background(), m, "b/", nil); err == nil {
+		t.Error("refreshed a prefix outside the index")
+	}
+}
+
+func TestDiffEntries(t *testing.T) {
+	old := []indexEntry{{key: "a", size: 1}, {key: "b", size: 1}, {key: "c", size: 1}}
+	fresh := []indexEntry{{key: "b", size: 2}, {key: "c", size: 1}, {key: "d", size: 1}}
+	if got, want := diffEntries(old, fresh), (IndexDelta{Added: 1, Changed: 1, Removed: 1}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLocalIndexSaveLoad(t *testing.T) {
+	ix := NewLocalIndex("b", "logs/")
+	ix.entries = []indexEntry{
+		{key: "logs/a,\"quoted\"\nname", etag: "e1", class: "STANDARD", size: 3, mod: 1700000000000},
+		{key: "logs/b", etag: "e2", class: "GLACIER", size: 7},
+	}
+	ix.parts = []indexPart{{Prefix: "logs/", At: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}}
+	path := filepath.Join(t.TempDir(), "p", "x.idx.gz")
+	if err := ix.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Bucket != "b" || got.Prefix != "logs/" || !reflect.DeepEqual(got.entries, ix.entries) || !got.Built("logs/x").Equal(ix.parts[0].At) {
+		t.Errorf("round trip: %+v", got)
+	}
+	if o := got.entries[0].object(); aws.ToString(o.ETag) != `"e1"` || o.LastModified == nil {
+		t.Errorf("object view: %+v", o)
+	}
+
+	if _, err := LoadIndex(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("a missing index loaded")
+	}
+	ix.entries = nil
+	if err := ix.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadIndex(path); err != nil || got.Len() != 0 {
+		t.Errorf("empty index: %v, %v", got, err)
+	}
+}
+
+func TestLocalIndexWalkStops(t *testing.T) {
+	ix := NewLocalIndex("b", "")
+	for _, k := range []string{"a", "b/1", "b/2", "b/3", "c"} {
+		ix.entries = append(ix.entries, indexEntry{key: k})
+	}
+	var seen []string
+	ix.Walk("b/", func(o s3t.Object) bool {
+		seen = append(seen, *o.Key)
+		return !strings.HasSuffix(*o.Key, "2")
+	})
+	if want := []string{"b/1", "b/2"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("walked %q, want %q", seen, want)
+	}
+}
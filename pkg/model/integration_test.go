@@ -77,7 +77,7 @@ func freshBucket(t *testing.T, m *model.Model, name string) *model.Object {
 	_ = m.CreateBucket(&name, false)
 	bucket := &model.Object{Key: &name, Ot: model.Bucket}
 
-	objs, err := m.ListObjects("", bucket)
+	objs, err := m.ListObjects(ctx, "", bucket)
 	if err != nil {
 		t.Fatalf("listing %s: %v", name, err)
 	}
@@ -148,7 +148,7 @@ func TestIntegrationTransferRoundTrip(t *testing.T) {
 		}
 	}
 
-	remote, err := m.ListRemoteEntries("tree", bucket)
+	remote, err := m.ListRemoteEntries(ctx, "tree", bucket)
 	if err != nil {
 		t.Fatalf("ListRemoteEntries: %v", err)
 	}
@@ -212,7 +212,7 @@ func TestIntegrationTransferRoundTrip(t *testing.T) {
 		if err := m.DeleteKey(ctx, prefix+"a.txt", bucket); err != nil {
 			t.Fatalf("DeleteKey: %v", err)
 		}
-		after, _ := m.ListRemoteEntries("tree", bucket)
+		after, _ := m.ListRemoteEntries(ctx, "tree", bucket)
 		if len(after) != 2 {
 			t.Errorf("got %d objects, want 2", len(after))
 		}
@@ -220,7 +220,7 @@ func TestIntegrationTransferRoundTrip(t *testing.T) {
 		if err := m.DeleteKey(ctx, prefix, bucket); err == nil {
 			t.Error("a prefix-like key must be refused")
 		}
-		still, _ := m.ListRemoteEntries("tree", bucket)
+		still, _ := m.ListRemoteEntries(ctx, "tree", bucket)
 		if len(still) != 2 {
 			t.Errorf("the refused delete removed %d objects", 2-len(still))
 		}
@@ -236,7 +236,7 @@ func TestIntegrationSessionTokenReachesTheWire(t *testing.T) {
 	if err != nil {
 		t.Fatalf("NewModel: %v", err)
 	}
-	if _, err := good.ListBuckets(); err != nil {
+	if _, err := good.ListBuckets(context.Background()); err != nil {
 		t.Fatalf("baseline ListBuckets failed: %v", err)
 	}
 
@@ -244,7 +244,7 @@ func TestIntegrationSessionTokenReachesTheWire(t *testing.T) {
 	if err != nil {
 		t.Fatalf("NewModel: %v", err)
 	}
-	if _, err := bad.ListBuckets(); err == nil {
+	if _, err := bad.ListBuckets(context.Background()); err == nil {
 		t.Error("a garbage session token was accepted; the token is not being sent")
 	}
 }
@@ -376,6 +376,16 @@ func TestIntegrationVersioning(t *testing.T) {
 		}
 	})
 
+	t.Run("content reads that version, not the latest", func(t *testing.T) {
+		r, err := m.ReadVersionRange(ctx, bucket, key, oldest.VersionID, 0, 1<<20)
+		if err != nil {
+			t.Fatalf("ReadVersionRange: %v", err)
+		}
+		if string(r.Data) != "version one" || r.Total != 11 {
+			t.Errorf("content = %q of %d, want the oldest version", r.Data, r.Total)
+		}
+	})
+
 	t.Run("restore adds a version rather than rewinding", func(t *testing.T) {
 		if err := m.RestoreVersion(ctx, bucket, key, oldest.VersionID, oldest.StorageClass); err != nil {
 			t.Fatalf("RestoreVersion: %v", err)
@@ -439,11 +449,11 @@ func TestIntegrationRemoteToRemote(t *testing.T) {
 	putObject(t, m, dst, dstPrefix+"changed.txt", "old")
 	putObject(t, m, dst, dstPrefix+"extra.txt", "should go")
 
-	srcEntries, err := m.ListRemoteEntries(srcPrefix, src)
+	srcEntries, err := m.ListRemoteEntries(ctx, srcPrefix, src)
 	if err != nil {
 		t.Fatal(err)
 	}
-	dstEntries, err := m.ListRemoteEntries(dstPrefix, dst)
+	dstEntries, err := m.ListRemoteEntries(ctx, dstPrefix, dst)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -471,7 +481,7 @@ func TestIntegrationRemoteToRemote(t *testing.T) {
 		}
 	}
 
-	after, _ := m.ListRemoteEntries(dstPrefix, dst)
+	after, _ := m.ListRemoteEntries(ctx, dstPrefix, dst)
 	if got, want := relNames(after), relNames(srcEntries); !slices.Equal(got, want) {
 		t.Errorf("destination = %v, want it to match the source %v", got, want)
 	}
@@ -486,7 +496,7 @@ func TestIntegrationRemoteToRemote(t *testing.T) {
 	}
 
 	t.Run("the source is untouched", func(t *testing.T) {
-		again, _ := m.ListRemoteEntries(srcPrefix, src)
+		again, _ := m.ListRemoteEntries(ctx, srcPrefix, src)
 		if len(again) != 3 {
 			t.Errorf("source now has %d objects, want its original 3", len(again))
 		}
@@ -534,10 +544,10 @@ func TestIntegrationDeleteNonEmptyBucket(t *testing.T) {
 
 	// The delete flow promises the objects go too: EmptyBucket then
 	// DeleteBucket must succeed on a non-empty (unversioned) bucket.
-	if err := m.EmptyBucket(bucket); err != nil {
+	if err := m.EmptyBucket(context.Background(), bucket); err != nil {
 		t.Fatalf("EmptyBucket: %v", err)
 	}
-	if err := m.DeleteBucket(bucket.Key); err != nil {
+	if err := m.DeleteBucket(context.Background(), bucket.Key); err != nil {
 		t.Fatalf("DeleteBucket after emptying: %v", err)
 	}
 }
@@ -561,7 +571,7 @@ func TestIntegrationUploadMatchesPreparedKeys(t *testing.T) {
 	if err != nil {
 		t.Fatalf("PrepareUpload: %v", err)
 	}
-	if err := m.Upload(ctx, root, "pre", bucket, nil, nil); err != nil {
+	if err := m.Upload(ctx, root, "pre", bucket, nil, nil, nil); err != nil {
 		t.Fatalf("Upload: %v", err)
 	}
 
@@ -583,10 +593,10 @@ func TestIntegrationEmptyDirUpload(t *testing.T) {
 	if err := os.MkdirAll(empty, 0755); err != nil {
 		t.Fatal(err)
 	}
-	if err := m.Upload(ctx, empty, "", bucket, nil, nil); err != nil {
+	if err := m.Upload(ctx, empty, "", bucket, nil, nil, nil); err != nil {
 		t.Fatalf("Upload of an empty dir: %v", err)
 	}
-	objs, err := m.ListObjects("", bucket)
+	objs, err := m.ListObjects(ctx, "", bucket)
 	if err != nil || len(objs) != 1 {
 		t.Fatalf("objs = %d, err = %v; want exactly the folder marker", len(objs), err)
 	}
@@ -606,7 +616,7 @@ func TestIntegrationDuplicateETags(t *testing.T) {
 	putObject(t, m, bucket, "b/copy2.bin", "identical duplicate content")
 	putObject(t, m, bucket, "c/other.bin", "completely different content!")
 
-	objs, err := m.ListObjects("", bucket)
+	objs, err := m.ListObjects(context.Background(), "", bucket)
 	if err != nil {
 		t.Fatalf("ListObjects: %v", err)
 	}
@@ -931,7 +941,7 @@ func TestIntegrationUploadSkip(t *testing.T) {
 	skip := map[string]bool{"proj/keep.txt": true}
 	if err := m.Upload(ctx, tree, "", bucket, skip, func(n, total int64, i, count int, _, _ string) {
 		lastSent, lastTotal, lastCount = n, total, count
-	}); err != nil {
+	}, nil); err != nil {
 		t.Fatalf("Upload with a skip: %v", err)
 	}
 
@@ -1016,7 +1026,7 @@ func TestIntegrationConflicts(t *testing.T) {
 		putObject(t, m, bucket, "src/tree/one.txt", "1")
 		putObject(t, m, bucket, "src/tree/sub/two.txt", "2")
 
-		planned, err := m.PlannedCopyKeys(bucket, bucket, "src/tree/", "dst/tree/", true)
+		planned, err := m.PlannedCopyKeys(ctx, bucket, bucket, "src/tree/", "dst/tree/", true)
 		if err != nil {
 			t.Fatalf("PlannedCopyKeys: %v", err)
 		}
@@ -0,0 +1,420 @@
+package model
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// inventoryManifestMax bounds the manifest read into memory. Real manifests
+// list a few thousand data files at most and stay well under a megabyte.
+const inventoryManifestMax = 16 << 20
+
+// Inventory is an S3 Inventory report of one bucket, loaded into memory as a
+// sorted index. A model carrying one (WithInventory) browses, searches,
+// summarizes and scans that bucket from the index instead of listing it, so a
+// bucket with hundreds of millions of keys is as quick to walk as a small one
+// — as of Created, not now.
+type Inventory struct {
+	// Bucket is the bucket the report describes.
+	Bucket string
+	// Created is when S3 produced the report.
+	Created time.Time
+	// Manifest is where the report was loaded from, as s3://bucket/key.
+	Manifest string
+
+	entries []indexEntry // sorted by key, one per current object
+}
+
+// indexEntry is one object of an inventory report or a local index
+// (index.go). Kept small: there may be hundreds of millions of them.
+type indexEntry struct {
+	key, etag, class string
+	size             int64
+	mod              int64 // Unix milliseconds; 0 when the report lacks it
+}
+
+// Len returns the number of objects in the report.
+func (inv *Inventory) Len() int { return len(inv.entries) }
+
+// inventoryManifest is the part of manifest.json the loader uses.
+type inventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	CreationTimestamp string `json:"creationTimestamp"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// parseInventoryManifest decodes manifest.json and checks it is a report the
+// loader can read: CSV or ORC data files with at least a key column. The
+// columns of a CSV report are returned by name; an ORC file names its own,
+// so for one the map is nil.
+func parseInventoryManifest(data []byte) (inventoryManifest, map[string]int, error) {
+	var man inventoryManifest
+	if err := json.Unmarshal(data, &man); err != nil {
+		return man, nil, fmt.Errorf("not an inventory manifest: %w", err)
+	}
+	if man.SourceBucket == "" {
+		return man, nil, errors.New("not an inventory manifest: no sourceBucket")
+	}
+	if strings.EqualFold(man.FileFormat, "ORC") {
+		// The schema is a Hive type: struct<bucket:string,key:string,...>.
+		fields := strings.TrimSuffix(strings.TrimPrefix(man.FileSchema, "struct<"), ">")
+		for _, f := range strings.Split(fields, ",") {
+			if name, _, _ := strings.Cut(f, ":"); strings.TrimSpace(name) == "key" {
+				return man, nil, nil
+			}
+		}
+		return man, nil, fmt.Errorf("inventory schema %q has no key field", man.FileSchema)
+	}
+	if !strings.EqualFold(man.FileFormat, "CSV") {
+		return man, nil, fmt.Errorf("inventory format %q is not supported; configure the report as CSV or ORC", man.FileFormat)
+	}
+	cols := map[string]int{}
+	for i, c := range strings.Split(man.FileSchema, ",") {
+		cols[strings.TrimSpace(c)] = i
+	}
+	if _, ok := cols["Key"]; !ok {
+		return man, nil, fmt.Errorf("inventory schema %q has no Key column", man.FileSchema)
+	}
+	return man, cols, nil
+}
+
+// created is the report's creation time; creationTimestamp is Unix
+// milliseconds as a string. Zero if missing or malformed.
+func (man inventoryManifest) created() time.Time {
+	ms, err := strconv.ParseInt(man.CreationTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// destination is the bucket holding the data files: destinationBucket is an
+// ARN ("arn:aws:s3:::name"), which some S3-compatible services leave bare.
+func (man inventoryManifest) destination(fallback string) string {
+	b := man.DestinationBucket
+	if i := strings.LastIndex(b, ":"); i >= 0 {
+		b = b[i+1:]
+	}
+	if b == "" {
+		return fallback
+	}
+	return b
+}
+
+// readInventoryCSV decodes one data file — gzip'd or plain, sniffed from its
+// first bytes — whose columns are laid out as cols, calling add for each row
+// describing a current object. Noncurrent versions and delete markers (in a
+// report that includes versions) are skipped; keys are URL-decoded as the CSV
+// format requires.
+func readInventoryCSV(r io.Reader, cols map[string]int, add func(indexEntry)) error {
+	src, err := gunzipped(r)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	cr := csv.NewReader(src)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	field := func(rec []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if field(rec, "IsLatest") == "false" || field(rec, "IsDeleteMarker") == "true" {
+			continue
+		}
+		key, err := url.QueryUnescape(field(rec, "Key"))
+		if err != nil || key == "" {
+			continue
+		}
+		e := indexEntry{key: key, etag: field(rec, "ETag"), class: field(rec, "StorageClass")}
+		e.size, _ = strconv.ParseInt(field(rec, "Size"), 10, 64)
+		if t, err := time.Parse(time.RFC3339, field(rec, "LastModifiedDate")); err == nil {
+			e.mod = t.UnixMilli()
+		}
+		add(e)
+	}
+}
+
+// LoadInventory reads the S3 Inventory report whose manifest.json is key in
+// bucket, with every data file it lists, into an Inventory. Data files are
+// fetched on up to shardWorkers goroutines; loaded, if set, is called with the
+// running number of objects read. CSV and ORC reports can be read; Parquet
+// ones are refused with an error saying so.
+func (m *Model) LoadInventory(ctx context.Context, bucket, key string, loaded func(total int)) (*Inventory, error) {
+	out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(out.Body, inventoryManifestMax))
+	out.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	man, cols, err := parseInventoryManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	dest := man.destination(bucket)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var total atomic.Int64
+	parts := make([][]indexEntry, len(man.Files))
+	classes := newInterner()
+	err = eachShard(ctx, cancel, len(man.Files), func(i int) error {
+		// Progress is reported every 1000 rows, not per row.
+		n := 0
+		add := func(e indexEntry) {
+			e.class = classes.intern(e.class)
+			parts[i] = append(parts[i], e)
+			if n++; n == 1000 {
+				t := total.Add(int64(n))
+				n = 0
+				if loaded != nil {
+					loaded(int(t))
+				}
+			}
+		}
+		var err error
+		if cols == nil {
+			err = m.readInventoryORC(ctx, dest, man.Files[i].Key, add)
+		} else {
+			err = m.readInventoryCSV(ctx, dest, man.Files[i].Key, cols, add)
+		}
+		total.Add(int64(n))
+		if err != nil {
+			return fmt.Errorf("%s: %w", man.Files[i].Key, err)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if loaded != nil {
+		loaded(int(total.Load()))
+	}
+
+	inv := &Inventory{
+		Bucket:   man.SourceBucket,
+		Created:  man.created(),
+		Manifest: "s3://" + bucket + "/" + key,
+		entries:  make([]indexEntry, 0, total.Load()),
+	}
+	for _, p := range parts {
+		inv.entries = append(inv.entries, p...)
+	}
+	sort.Slice(inv.entries, func(i, j int) bool { return inv.entries[i].key < inv.entries[j].key })
+	return inv, nil
+}
+
+// readInventoryCSV fetches one CSV data file with a single GET.
+func (m *Model) readInventoryCSV(ctx context.Context, bucket, key string, cols map[string]int, add func(indexEntry)) error {
+	out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return err
+	}
+	defer out.Body.Close()
+	return readInventoryCSV(out.Body, cols, add)
+}
+
+// readInventoryORC reads one ORC data file with ranged GETs: its tail first,
+// then a stripe at a time.
+func (m *Model) readInventoryORC(ctx context.Context, bucket, key string, add func(indexEntry)) error {
+	b := &Object{Key: aws.String(bucket)}
+	head, err := m.ReadRange(ctx, b, key, 0, int64(len(orcMagic)))
+	if err != nil {
+		return err
+	}
+	if string(head.Data) != orcMagic {
+		return errors.New("not an ORC file")
+	}
+	return readInventoryORC(&rangeReaderAt{ctx: ctx, m: m, bucket: b, key: key}, head.Total, add)
+}
+
+// rangeReaderAt reads an object through ReadRange, one GET per ReadAt.
+type rangeReaderAt struct {
+	ctx    context.Context
+	m      *Model
+	bucket *Object
+	key    string
+}
+
+func (r *rangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	got, err := r.m.ReadRange(r.ctx, r.bucket, r.key, off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	n := copy(p, got.Data)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// interner shares one copy of each repeated string (storage classes) across
+// the goroutines reading data files.
+type interner struct {
+	mu   sync.Mutex
+	seen map[string]string
+}
+
+func newInterner() *interner {
+	return &interner{seen: map[string]string{}}
+}
+
+func (in *interner) intern(s string) string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if v, ok := in.seen[s]; ok {
+		return v
+	}
+	in.seen[s] = s
+	return s
+}
+
+// object renders an entry the way ListObjectsV2 reports one.
+func (e indexEntry) object() s3t.Object {
+	o := s3t.Object{
+		Key:          aws.String(e.key),
+		Size:         e.size,
+		StorageClass: s3t.ObjectStorageClass(e.class),
+	}
+	if e.etag != "" {
+		o.ETag = aws.String(`"` + e.etag + `"`)
+	}
+	if e.mod != 0 {
+		t := time.UnixMilli(e.mod).UTC()
+		o.LastModified = &t
+	}
+	return o
+}
+
+// search returns the index of the first entry whose key is at least key.
+func (inv *Inventory) search(key string) int {
+	return sort.Search(len(inv.entries), func(i int) bool { return inv.entries[i].key >= key })
+}
+
+// objects returns every object under prefix, in key order.
+func (inv *Inventory) objects(prefix string) []s3t.Object {
+	var out []s3t.Object
+	for i := inv.search(prefix); i < len(inv.entries) && strings.HasPrefix(inv.entries[i].key, prefix); i++ {
+		out = append(out, inv.entries[i].object())
+	}
+	return out
+}
+
+// ListObjectsV2 answers a listing of the report's bucket from the index, so
+// the SDK's paginator (and with it ListPager) pages through an inventory as
+// it would through the bucket. Prefix, Delimiter, StartAfter and MaxKeys are
+// honoured; continuation tokens are positions in the index.
+func (inv *Inventory) ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	prefix, delim := aws.ToString(in.Prefix), aws.ToString(in.Delimiter)
+	limit := 1000
+	if in.MaxKeys > 0 && in.MaxKeys < 1000 {
+		limit = int(in.MaxKeys)
+	}
+	after := aws.ToString(in.StartAfter)
+	i := max(inv.search(prefix), sort.Search(len(inv.entries), func(i int) bool { return inv.entries[i].key > after }))
+	if tok := aws.ToString(in.ContinuationToken); tok != "" {
+		n, err := strconv.Atoi(tok)
+		if err != nil || n < 0 || n > len(inv.entries) {
+			return nil, fmt.Errorf("invalid continuation token %q", tok)
+		}
+		i = n
+	}
+
+	out := &s3.ListObjectsV2Output{
+		Name:              in.Bucket,
+		Prefix:            in.Prefix,
+		Delimiter:         in.Delimiter,
+		ContinuationToken: in.ContinuationToken,
+		MaxKeys:           int32(limit),
+	}
+	n := 0
+	for i < len(inv.entries) && strings.HasPrefix(inv.entries[i].key, prefix) {
+		if n == limit {
+			out.IsTruncated = true
+			out.NextContinuationToken = aws.String(strconv.Itoa(i))
+			break
+		}
+		e := inv.entries[i]
+		if delim != "" {
+			if j := strings.Index(e.key[len(prefix):], delim); j >= 0 {
+				cp := e.key[:len(prefix)+j+len(delim)]
+				out.CommonPrefixes = append(out.CommonPrefixes, s3t.CommonPrefix{Prefix: aws.String(cp)})
+				n++
+				// Skip the rest of the folder in one step.
+				i += sort.Search(len(inv.entries)-i, func(k int) bool {
+					return !strings.HasPrefix(inv.entries[i+k].key, cp)
+				})
+				continue
+			}
+		}
+		out.Contents = append(out.Contents, e.object())
+		n++
+		i++
+	}
+	out.KeyCount = int32(n)
+	return out, nil
+}
+
+// WithInventory returns a model like m whose browsing and scans of inv's
+// bucket — NewListPager, ListObjectsSharded — read inv instead of the bucket.
+// Everything else (downloads, writes, exact listings such as ListObjects for
+// delete sizing) still goes to the bucket through m's client. Entering a
+// bucket (ForBucket) or leaving for the bucket list (Home) gives back a live
+// model, so the report is left behind by navigating away from it.
+func (m *Model) WithInventory(inv *Inventory) *Model {
+	w := *m
+	w.inv = inv
+	return &w
+}
+
+// Inventory returns the report m browses from, or nil for a live model.
+func (m *Model) Inventory() *Inventory {
+	if m == nil {
+		return nil
+	}
+	return m.inv
+}
+
+// fromInventory reports whether listings of bucket are served by m's report.
+func (m *Model) fromInventory(bucket *Object) bool {
+	return m.inv != nil && bucket != nil && bucket.Key != nil && *bucket.Key == m.inv.Bucket
+}
@@ -0,0 +1,260 @@
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+const testManifest = `{
+  "sourceBucket": "big",
+  "destinationBucket": "arn:aws:s3:::reports",
+  "creationTimestamp": "1760662800000",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key, VersionId, IsLatest, IsDeleteMarker, Size, LastModifiedDate, ETag, StorageClass",
+  "files": [{"key": "inv/big/data/1.csv.gz"}, {"key": "inv/big/data/2.csv"}]
+}`
+
+func TestParseInventoryManifest(t *testing.T) {
+	man, cols, err := parseInventoryManifest([]byte(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if man.SourceBucket != "big" || man.destination("x") != "reports" || cols["Key"] != 1 || cols["StorageClass"] != 8 {
+		t.Errorf("got %+v, cols %v", man, cols)
+	}
+	if got, want := man.created(), time.Date(2025, 10, 17, 1, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("created %v, want %v", got, want)
+	}
+
+	orc := `{"sourceBucket":"b","fileFormat":"ORC","fileSchema":"struct<bucket:string,key:string,size:bigint>"}`
+	if man, cols, err := parseInventoryManifest([]byte(orc)); err != nil || cols != nil || man.FileFormat != "ORC" {
+		t.Errorf("ORC: %+v, cols %v, %v", man, cols, err)
+	}
+
+	for name, doc := range map[string]string{
+		"Parquet":    `{"sourceBucket":"b","fileFormat":"Parquet","fileSchema":"message s3.inventory { required binary key (UTF8); }"}`,
+		"no key":     `{"sourceBucket":"b","fileFormat":"CSV","fileSchema":"Bucket, Size"}`,
+		"ORC no key": `{"sourceBucket":"b","fileFormat":"ORC","fileSchema":"struct<bucket:string,size:bigint>"}`,
+		"json":       `{"sourceBucket":`,
+		"other":      `{"name":"not a manifest"}`,
+	} {
+		if _, _, err := parseInventoryManifest([]byte(doc)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestReadInventoryCSV(t *testing.T) {
+	_, cols, _ := parseInventoryManifest([]byte(testManifest))
+	rows := `"big","logs/a%20b.txt","v2","true","false","10","2025-10-01T12:00:00.000Z","e1","STANDARD"
+"big","logs/a%20b.txt","v1","false","false","7","2025-09-01T12:00:00.000Z","e0","STANDARD"
+"big","gone","v3","true","true","","","",""
+"big","x%2By+z","","","","3","2025-10-02T00:00:00.000Z","e2","GLACIER"
+`
+	for _, gz := range []bool{false, true} {
+		data := []byte(rows)
+		if gz {
+			data = gzipped(t, rows)
+		}
+		var got []indexEntry
+		if err := readInventoryCSV(bytes.NewReader(data), cols, func(e indexEntry) { got = append(got, e) }); err != nil {
+			t.Fatalf("gzip=%v: %v", gz, err)
+		}
+		want := []indexEntry{
+			{key: "logs/a b.txt", etag: "e1", class: "STANDARD", size: 10, mod: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC).UnixMilli()},
+			{key: "x+y z", etag: "e2", class: "GLACIER", size: 3, mod: time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC).UnixMilli()},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("gzip=%v:\n got %+v\nwant %+v", gz, got, want)
+		}
+	}
+}
+
+func TestLoadInventory(t *testing.T) {
+	tr := newFakeS3(map[string][]byte{
+		"reports/inv/big/2025-10-17T01-00Z/manifest.json": []byte(testManifest),
+		"reports/inv/big/data/1.csv.gz":                   gzipped(t, "\"big\",\"b/2\",\"\",\"\",\"\",\"2\",\"\",\"e\",\"STANDARD\"\n"),
+		"reports/inv/big/data/2.csv":                      []byte("\"big\",\"a\",\"\",\"\",\"\",\"1\",\"\",\"e\",\"STANDARD\"\n\"big\",\"b/1\",\"\",\"\",\"\",\"5\",\"\",\"e\",\"STANDARD\"\n"),
+	})
+	m := newFakeModel(t, tr)
+	last := 0
+	inv, err := m.LoadInventory(context.Background(), "reports", "inv/big/2025-10-17T01-00Z/manifest.json", func(n int) { last = n })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Bucket != "big" || inv.Len() != 3 || last != 3 || inv.Manifest != "s3://reports/inv/big/2025-10-17T01-00Z/manifest.json" {
+		t.Errorf("loaded %+v (%d objects, progress %d)", inv, inv.Len(), last)
+	}
+	var keys []string
+	for _, e := range inv.entries {
+		keys = append(keys, e.key)
+	}
+	if want := []string{"a", "b/1", "b/2"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys %q, want %q", keys, want)
+	}
+
+	tr.remove("reports/inv/big/data/2.csv")
+	if _, err := m.LoadInventory(context.Background(), "reports", "inv/big/2025-10-17T01-00Z/manifest.json", nil); err == nil {
+		t.Error("a missing data file loaded")
+	}
+}
+
+func TestLoadInventoryORC(t *testing.T) {
+	tr := newFakeS3(map[string][]byte{
+		"reports/inv/big/2025-10-17T01-00Z/manifest.json": []byte(`{
+  "sourceBucket": "big",
+  "destinationBucket": "arn:aws:s3:::reports",
+  "fileFormat": "ORC",
+  "fileSchema": "struct<bucket:string,key:string,version_id:string,is_latest:boolean,is_delete_marker:boolean,size:bigint,last_modified_date:timestamp,e_tag:string,storage_class:string>",
+  "files": [{"key": "inv/big/data/1.orc"}, {"key": "inv/big/data/2.orc"}]
+}`),
+		"reports/inv/big/data/1.orc": testORCReport(orcWriter{codec: orcCodecZlib, v2: true, dict: true}),
+		"reports/inv/big/data/2.orc": (&orcWriter{names: []string{"key", "size"}, kinds: []uint64{orcString, orcLong}}).build(orcRows{{"b/1", int64(5)}}),
+	})
+	m := newFakeModel(t, tr)
+	inv, err := m.LoadInventory(context.Background(), "reports", "inv/big/2025-10-17T01-00Z/manifest.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, e := range inv.entries {
+		keys = append(keys, e.key)
+	}
+	if want := []string{"a", "b/1", "x+y z"}; !reflect.DeepEqual(keys, want) || inv.entries[1].size != 5 {
+		t.Errorf("loaded %+v, want keys %q", inv.entries, want)
+	}
+
+	tr.put("reports/inv/big/data/2.orc", "\"big\",\"a\"\n")
+	if _, err := m.LoadInventory(context.Background(), "reports", "inv/big/2025-10-17T01-00Z/manifest.json", nil); err == nil {
+		t.Error("a CSV file in an ORC report loaded")
+	}
+}
+
+// inventoryOf builds an index over keys, as LoadInventory would.
+func inventoryOf(bucket string, keys []string) *Inventory {
+	inv := &Inventory{Bucket: bucket}
+	for _, k := range keys {
+		inv.entries = append(inv.entries, indexEntry{key: k, size: 1})
+	}
+	return inv
+}
+
+// Browsing and scanning an inventory of a bucket give what listing the bucket
+// gives, page size and folder shape notwithstanding.
+func TestInventoryListsLikeTheBucket(t *testing.T) {
+	keys := treeKeys()
+	sort.Strings(keys)
+	fs := newFakeS3(nil)
+	fs.fill("b", keys...)
+	live := newFakeModel(t, fs)
+	inv := live.WithInventory(inventoryOf("b", keys))
+	bucket := &Object{Key: strPtr("b")}
+
+	for _, path := range []string{"", "a/", "logs/", "logs/1/", "nothing/"} {
+		want, err := live.List(context.Background(), path, bucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lp, _ := inv.NewListPager(path, bucket)
+		var got []*Object
+		for lp.More() {
+			page, err := lp.Next(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, page...)
+		}
+		if !reflect.DeepEqual(names(got), names(want)) {
+			t.Errorf("%q:\n got %q\nwant %q", path, names(got), names(want))
+		}
+	}
+	requests := fs.stats().requests
+	if got := keysOf(t, inv, true, "a/"); !reflect.DeepEqual(got, keysOf(t, live, false, "a/")) {
+		t.Errorf("scan of a/ from the inventory: %q", got)
+	}
+	if n := fs.stats().requests - requests; n != 1 {
+		t.Errorf("the inventory scan reached the bucket (%d requests)", n-1)
+	}
+}
+
+func names(objs []*Object) []string {
+	out := make([]string, len(objs))
+	for i, o := range objs {
+		out[i] = aws.ToString(o.FullPath)
+	}
+	return out
+}
+
+func TestInventoryListObjectsV2Pages(t *testing.T) {
+	var keys []string
+	for _, d := range []string{"a", "b", "c"} {
+		for _, f := range []string{"1", "2", "3"} {
+			keys = append(keys, d+"/"+f)
+		}
+	}
+	inv := inventoryOf("b", append(keys, "z"))
+	p := s3.NewListObjectsV2Paginator(inv, &s3.ListObjectsV2Input{Bucket: aws.String("b"), Delimiter: aws.String("/")}, func(o *s3.ListObjectsV2PaginatorOptions) { o.Limit = 2 })
+	var got []string
+	for p.HasMorePages() {
+		out, err := p.NextPage(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cp := range out.CommonPrefixes {
+			got = append(got, *cp.Prefix)
+		}
+		for _, o := range out.Contents {
+			got = append(got, *o.Key)
+		}
+	}
+	if want := []string{"a/", "b/", "c/", "z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	out, _ := inv.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{Prefix: aws.String("b/"), StartAfter: aws.String("b/1")})
+	if len(out.Contents) != 2 || *out.Contents[0].Key != "b/2" {
+		t.Errorf("start-after: %+v", out.Contents)
+	}
+}
+
+// A model browsing a report neither reads nor fills the listing cache, and
+// entering a bucket hands back a live model.
+func TestWithInventoryStaysOutOfTheCache(t *testing.T) {
+	m, _ := newCachingModel(t, time.Minute)
+	bucket := &Object{Key: strPtr("big")}
+	m.CacheList(m.ListingEpoch(), bucket, "", []*Object{{Key: strPtr("live")}}, false)
+
+	w := m.WithInventory(inventoryOf("big", []string{"old"}))
+	if _, ok := w.CachedList("big", ""); ok {
+		t.Error("the inventory model read the live cache")
+	}
+	w.CacheList(w.ListingEpoch(), bucket, "p/", nil, false)
+	if _, ok := m.CachedList("big", "p/"); ok {
+		t.Error("an inventory listing was cached")
+	}
+	if r, err := w.ForRegion(*w.Cf.Region); err != nil || r.Inventory() != nil {
+		t.Errorf("ForRegion kept the inventory (%v)", err)
+	}
+	if w.Home().Inventory() != nil {
+		t.Error("Home kept the inventory")
+	}
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// pagedBucket is a bucket "b" listed two objects a page, holding pages pages
+// of them under "p/".
+func pagedBucket(pages int) *fakeS3 {
+	fs := newFakeS3(nil)
+	fs.pageSize = 2
+	for page := 1; page <= pages; page++ {
+		fs.fill("b", fmt.Sprintf("p/k%d-0", page), fmt.Sprintf("p/k%d-1", page))
+	}
+	return fs
+}
+
+func TestListObjectsPagesThrough(t *testing.T) {
+	fs := pagedBucket(3)
+	m := newFakeModel(t, fs)
+	objs, err := m.ListObjects(context.Background(), "p/", &Object{Key: strPtr("b")})
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	if lists := fs.stats().lists; len(objs) != 6 || lists != 3 {
+		t.Errorf("got %d objects in %d requests, want 6 in 3", len(objs), lists)
+	}
+}
+
+func TestListPagerFetchesOnDemand(t *testing.T) {
+	fs := pagedBucket(3)
+	lp, err := newFakeModel(t, fs).NewListPager("p/", &Object{Key: strPtr("b")})
+	if err != nil {
+		t.Fatalf("NewListPager: %v", err)
+	}
+	if lists := fs.stats().lists; lists != 0 || !lp.More() {
+		t.Fatalf("before Next: %d requests, More=%v; want 0 and true", lists, lp.More())
+	}
+	for page := 1; lp.More(); page++ {
+		objs, err := lp.Next(context.Background())
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		if lists := fs.stats().lists; len(objs) != 2 || lists != page {
+			t.Errorf("page %d: %d objects after %d requests", page, len(objs), lists)
+		}
+	}
+	if lists := fs.stats().lists; lists != 3 {
+		t.Errorf("%d requests, want 3", lists)
+	}
+}
+
+// Cancelling after the first page must stop every listing path there: no
+// second page is requested, nothing is deleted, and the error says why.
+func TestListingStopsPagingOnCancel(t *testing.T) {
+	bucket := &Object{Key: strPtr("b"), Ot: Bucket}
+	cases := []struct {
+		name string
+		run  func(ctx context.Context, m *Model) error
+	}{
+		{"ListObjects", func(ctx context.Context, m *Model) error {
+			_, err := m.ListObjects(ctx, "p/", bucket)
+			return err
+		}},
+		{"List", func(ctx context.Context, m *Model) error {
+			_, err := m.List(ctx, "p/", bucket)
+			return err
+		}},
+		{"ListRemoteEntries", func(ctx context.Context, m *Model) error {
+			_, err := m.ListRemoteEntries(ctx, "p/", bucket)
+			return err
+		}},
+		{"ResolveDownloadObjects", func(ctx context.Context, m *Model) error {
+			_, _, err := m.ResolveDownloadObjects(ctx, "p/", true, nil, bucket)
+			return err
+		}},
+		{"Delete folder", func(ctx context.Context, m *Model) error {
+			return m.Delete(ctx, strPtr("p/"), bucket)
+		}},
+		{"EmptyBucket", func(ctx context.Context, m *Model) error {
+			return m.EmptyBucket(ctx, bucket)
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fs := pagedBucket(5)
+			fs.onList = func(page int) {
+				if page == 1 {
+					cancel()
+				}
+			}
+			err := tc.run(ctx, newFakeModel(t, fs))
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("err = %v, want context.Canceled", err)
+			}
+			if st := fs.stats(); st.lists != 1 || st.deletes != 0 {
+				t.Errorf("after cancel: %d list and %d delete requests, want 1 and 0", st.lists, st.deletes)
+			}
+		})
+	}
+}
+
+func TestDeleteObjectIDsStopsBetweenBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fs := newFakeS3(nil)
+	fs.onDelete = func(int) { cancel() }
+	m := newFakeModel(t, fs)
+
+	ids := make([]s3t.ObjectIdentifier, 2500) // three batches of up to 1000
+	for i := range ids {
+		ids[i] = s3t.ObjectIdentifier{Key: aws.String(fmt.Sprintf("k%d", i))}
+	}
+	err := m.deleteObjectIDs(ctx, &Object{Key: strPtr("b")}, ids)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if deletes := fs.stats().deletes; deletes != 1 {
+		t.Errorf("%d delete batches sent, want 1", deletes)
+	}
+}
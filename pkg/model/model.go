@@ -34,6 +34,10 @@ import (
 // the overwrite prompt, sync — must remove it first; errors.Is identifies it.
 var ErrFileExists = errors.New("file exists")
 
+// ErrObjectExists is returned (wrapped, with the key) when a write that may
+// only create a key finds it taken; see CreateBytes.
+var ErrObjectExists = errors.New("object exists")
+
 type optsFunc = func(*config.LoadOptions) error
 
 type FType int8
@@ -60,6 +64,9 @@ type Config struct {
 	SessionToken   string
 	SSl            bool
 	MaxBytesPerSec int64 // 0 = unlimited
+	// ListCacheTTL is how long a folder listing is reused when the location
+	// is revisited. 0 = no listing cache.
+	ListCacheTTL time.Duration
 }
 
 // rateLimiter is a token-bucket throttle shared across all transfer workers of
@@ -124,8 +131,13 @@ func (l *rateLimiter) wait(n int) {
 }
 
 type DownloadTarget struct {
-	Key  string
-	Size int64
+	Key      string
+	Size     int64
+	Modified time.Time // zero when not known
+	// Archive, when set, makes Key the name of a member of that archive
+	// (Member), extracted rather than downloaded.
+	Archive *Archive
+	Member  *ArchiveMember
 }
 
 type Object struct {
@@ -144,6 +156,24 @@ type Model struct {
 	Downloader *s3m.Downloader
 	Cf         *Config
 	Limiter    *rateLimiter
+
+	pool *clientPool
+	// inv, when set, serves browsing and scans of its bucket (WithInventory).
+	inv *Inventory
+}
+
+// SameEndpoint reports whether a and b reach the same service with the same
+// credentials — the condition for a server-side copy between their buckets.
+// Two models of one profile qualify even when pinned to different regions;
+// anything else has to stream through this process (CrossCopy).
+func SameEndpoint(a, b *Model) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil || a.Cf == nil || b.Cf == nil {
+		return false
+	}
+	return a.Cf.Url == b.Cf.Url && a.Cf.AccessKey == b.Cf.AccessKey
 }
 
 type progressReader struct {
@@ -196,15 +226,22 @@ func uploadRetryer() aws.Retryer {
 	})
 }
 
+// retryUploads is the client option that puts a request under uploadRetryer.
+func retryUploads(o *s3.Options) {
+	o.Retryer = uploadRetryer()
+}
+
+// uploadPartSize is the multipart uploader's part size. CrossMove relies on it
+// to predict the ETag of a streamed copy.
+const uploadPartSize = 5 * 1024 * 1024
+
 // newUploader builds the shared multipart uploader used by both Upload and
 // UploadFile, so the two paths can't drift on part size or retry policy.
 func newUploader(client *s3.Client) *s3m.Uploader {
 	return s3m.NewUploader(client, func(u *s3m.Uploader) {
-		u.PartSize = 5 * 1024 * 1024
+		u.PartSize = uploadPartSize
 		u.LeavePartsOnError = false
-		u.ClientOptions = append(u.ClientOptions, func(o *s3.Options) {
-			o.Retryer = uploadRetryer()
-		})
+		u.ClientOptions = append(u.ClientOptions, retryUploads)
 	})
 }
 
@@ -296,53 +333,56 @@ func NewModel(cf Config) (*Model, error) {
 		Cf:         &cf,
 		Limiter:    newRateLimiter(cf.MaxBytesPerSec),
 	}
+	m.pool = newClientPool(&m)
 	return &m, nil
 }
 
-// RefreshClient re-pins the client to the bucket's actual region (AWS only —
-// GetConfig ignores the region rebuild for custom endpoints). On any failure it
-// leaves the existing client and region untouched and reports the error:
-// mutating them on a failed lookup used to pin every later call — and even
-// CreateBucket — to a wrong us-east-1 default, turning one denied
-// s3:GetBucketLocation into a stream of baffling redirect errors.
-func (m *Model) RefreshClient(bucket *string) error {
-	region, err := m.GetBucketLocation(bucket)
-	if err != nil {
-		return fmt.Errorf("resolving region of %s: %w", aws.ToString(bucket), err)
-	}
-
-	cf := *m.Cf
-	cf.Region = region
-	cfg, err := GetConfig(cf, true)
-	if err != nil {
-		return fmt.Errorf("rebuilding client for region %s: %w", aws.ToString(region), err)
-	}
-
-	m.Cf.Region = region
-	m.Client = s3.NewFromConfig(cfg)
-	m.Downloader = GetDownloader(m.Client)
-	return nil
+// ListObjects returns every object under key, paging through the whole
+// prefix. ctx is checked before each page, so a cancelled transfer, scan or
+// search stops listing at once instead of walking the rest of a large bucket.
+func (m *Model) ListObjects(ctx context.Context, key string, bucket *Object) ([]s3t.Object, error) {
+	objs, _, err := m.listPages(ctx, key, bucket, "", nil)
+	return objs, err
 }
-func (m *Model) ListObjects(key string, bucket *Object) ([]s3t.Object, error) {
+
+// listPages pages through a listing of key, returning its objects and, with a
+// delimiter, its common prefixes. ctx is checked before each page; scanned, if
+// set, is told how many objects each page brought.
+func (m *Model) listPages(ctx context.Context, key string, bucket *Object, delimiter string, scanned func(n int)) ([]s3t.Object, []string, error) {
 	if bucket == nil || bucket.Key == nil {
-		return nil, fmt.Errorf("bucket is nil")
+		return nil, nil, fmt.Errorf("bucket is nil")
 	}
 
 	var objects []s3t.Object
+	var prefixes []string
 	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(*bucket.Key),
 		Prefix: aws.String(key),
 	}
+	if delimiter != "" {
+		input.Delimiter = aws.String(delimiter)
+	}
 
 	paginator := s3.NewListObjectsV2Paginator(m.Client, input)
 	for paginator.HasMorePages() {
-		output, err := paginator.NextPage(context.TODO())
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		objects = append(objects, output.Contents...)
+		for _, p := range output.CommonPrefixes {
+			if p.Prefix != nil {
+				prefixes = append(prefixes, *p.Prefix)
+			}
+		}
+		if scanned != nil {
+			scanned(len(output.Contents))
+		}
 	}
-	return objects, nil
+	return objects, prefixes, nil
 }
 
 func (m *Model) GetBucketLocation(name *string) (*string, error) {
@@ -375,85 +415,128 @@ func normalizeBucketLocation(constraint string) string {
 	}
 }
 
-func (m *Model) List(path string, bucket *Object) ([]*Object, error) {
+// List returns one level of path in bucket — its folders and files — paging
+// through all of it. The browser pages through a ListPager instead, so a huge
+// flat prefix shows its first page at once.
+func (m *Model) List(ctx context.Context, path string, bucket *Object) ([]*Object, error) {
+	lp, err := m.NewListPager(path, bucket)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]*Object, 0)
+	for lp.More() {
+		page, err := lp.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, page...)
+	}
+	return objs, nil
+}
+
+// ListPager lists one level of a prefix a page (up to 1000 keys) at a time.
+// It is not safe for concurrent use: one Next at a time.
+type ListPager struct {
+	path string
+	p    *s3.ListObjectsV2Paginator
+}
+
+// NewListPager starts a listing of path in bucket. Nothing is fetched until
+// the first Next.
+func (m *Model) NewListPager(path string, bucket *Object) (*ListPager, error) {
 	if bucket == nil || bucket.Key == nil {
 		return nil, fmt.Errorf("bucket is nil")
 	}
-	objs := make([]*Object, 0)
 	input := &s3.ListObjectsV2Input{
 		Bucket:    aws.String(*bucket.Key),
 		Delimiter: aws.String("/"),
 		Prefix:    aws.String(path),
 	}
+	var client s3.ListObjectsV2APIClient = m.Client
+	if m.fromInventory(bucket) {
+		client = m.inv
+	}
+	return &ListPager{path: path, p: s3.NewListObjectsV2Paginator(client, input)}, nil
+}
 
-	paginator := s3.NewListObjectsV2Paginator(m.Client, input)
+// More reports whether another page remains. True before the first Next.
+func (lp *ListPager) More() bool { return lp.p.HasMorePages() }
 
-	for paginator.HasMorePages() {
-		output, err := paginator.NextPage(context.TODO())
-		if err != nil {
-			return nil, err
-		}
+// Next fetches the next page as folders and files.
+func (lp *ListPager) Next(ctx context.Context) ([]*Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	output, err := lp.p.NextPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pageObjects(output, lp.path), nil
+}
 
-		for _, p := range output.CommonPrefixes {
-			if p.Prefix == nil {
-				continue
-			}
-			fields := strings.FieldsFunc(strings.TrimSpace(*p.Prefix), u.SplitFunc)
-			var appKey string
-			if len(fields) != 0 {
-				appKey = fields[len(fields)-1]
-			} else {
-				appKey = "/"
-			}
+// pageObjects converts one ListObjectsV2 page of path into browser objects:
+// common prefixes as folders, keys as files, the folder's own marker skipped.
+func pageObjects(output *s3.ListObjectsV2Output, path string) []*Object {
+	objs := make([]*Object, 0, len(output.CommonPrefixes)+len(output.Contents))
+	for _, p := range output.CommonPrefixes {
+		if p.Prefix == nil {
+			continue
+		}
+		fields := strings.FieldsFunc(strings.TrimSpace(*p.Prefix), u.SplitFunc)
+		var appKey string
+		if len(fields) != 0 {
+			appKey = fields[len(fields)-1]
+		} else {
+			appKey = "/"
+		}
 
-			ko := &Object{
-				&appKey,
-				Folder,
-				nil,
-				nil,
-				nil,
-				nil,
-				p.Prefix,
-			}
-			objs = append(objs, ko)
+		ko := &Object{
+			&appKey,
+			Folder,
+			nil,
+			nil,
+			nil,
+			nil,
+			p.Prefix,
 		}
-		for _, o := range output.Contents {
-			if o.Key == nil || *o.Key == path {
-				continue
-			}
-			fields := strings.FieldsFunc(strings.TrimSpace(*o.Key), u.SplitFunc)
-			if len(fields) == 0 {
-				continue
-			}
-			appKey := fields[len(fields)-1]
-			ts := strings.Trim(aws.ToString(o.ETag), "\"")
-			size := o.Size
-
-			var sc *string
-			if o.StorageClass != "" {
-				s := string(o.StorageClass)
-				sc = &s
-			}
-			ko := &Object{
-				&appKey,
-				File,
-				&ts,
-				&size,
-				sc,
-				o.LastModified,
-				o.Key,
-			}
-			objs = append(objs, ko)
+		objs = append(objs, ko)
+	}
+	for _, o := range output.Contents {
+		if o.Key == nil || *o.Key == path {
+			continue
 		}
+		fields := strings.FieldsFunc(strings.TrimSpace(*o.Key), u.SplitFunc)
+		if len(fields) == 0 {
+			continue
+		}
+		appKey := fields[len(fields)-1]
+		ts := strings.Trim(aws.ToString(o.ETag), "\"")
+		size := o.Size
+
+		var sc *string
+		if o.StorageClass != "" {
+			s := string(o.StorageClass)
+			sc = &s
+		}
+		ko := &Object{
+			&appKey,
+			File,
+			&ts,
+			&size,
+			sc,
+			o.LastModified,
+			o.Key,
+		}
+		objs = append(objs, ko)
 	}
-	return objs, nil
+	return objs
 }
 
-func (m *Model) ListBuckets() ([]*Object, error) {
+func (m *Model) ListBuckets(ctx context.Context) ([]*Object, error) {
 	// A generous whole-call bound only: hung connections are already cut by the
 	// per-phase transport timeouts, and 5s here used to make profiles unusable
 	// over slow links (high-latency VPNs, huge bucket lists).
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
 	objs := make([]*Object, 0)
@@ -479,7 +562,7 @@ func (m *Model) ListBuckets() ([]*Object, error) {
 	return objs, nil
 }
 
-func (m *Model) Delete(key *string, bucket *Object) error {
+func (m *Model) Delete(ctx context.Context, key *string, bucket *Object) error {
 	if bucket == nil || bucket.Key == nil {
 		return fmt.Errorf("bucket is nil")
 	}
@@ -490,7 +573,7 @@ func (m *Model) Delete(key *string, bucket *Object) error {
 	var objectIds []s3t.ObjectIdentifier
 
 	if strings.HasSuffix(*key, "/") {
-		ks, err := m.ListObjects(*key, bucket)
+		ks, err := m.ListObjects(ctx, *key, bucket)
 
 		if err != nil {
 			return err
@@ -505,20 +588,30 @@ func (m *Model) Delete(key *string, bucket *Object) error {
 		objectIds = append(objectIds, s3t.ObjectIdentifier{Key: aws.String(*key)})
 	}
 
-	return m.deleteObjectIDs(bucket, objectIds)
+	return m.deleteObjectIDs(ctx, bucket, objectIds)
 }
 
 // deleteObjectIDs removes the given objects in DeleteObjects batches of 1000
 // (the S3 API maximum), failing on the first per-key error the service reports.
-func (m *Model) deleteObjectIDs(bucket *Object, objectIds []s3t.ObjectIdentifier) error {
+// A cancelled ctx stops it between batches.
+func (m *Model) deleteObjectIDs(ctx context.Context, bucket *Object, objectIds []s3t.ObjectIdentifier) error {
 	if len(objectIds) == 0 {
 		return nil
 	}
 
 	const maxDelete = 1000
-	ctx := context.TODO()
+
+	// Even a failed run may have removed some batches already.
+	keys := make([]string, len(objectIds))
+	for i, id := range objectIds {
+		keys[i] = aws.ToString(id.Key)
+	}
+	defer m.invalidateLists(*bucket.Key, CommonPrefix(keys))
 
 	for i := 0; i < len(objectIds); i += maxDelete {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		end := i + maxDelete
 		if end > len(objectIds) {
 			end = len(objectIds)
@@ -548,11 +641,11 @@ func (m *Model) deleteObjectIDs(bucket *Object, objectIds []s3t.ObjectIdentifier
 // has already promised the user exactly this removal. On a versioned bucket
 // this writes delete markers only: old versions survive, and the subsequent
 // DeleteBucket still fails with BucketNotEmpty (see DESIGN.md).
-func (m *Model) EmptyBucket(bucket *Object) error {
+func (m *Model) EmptyBucket(ctx context.Context, bucket *Object) error {
 	if bucket == nil || bucket.Key == nil {
 		return fmt.Errorf("bucket is nil")
 	}
-	objs, err := m.ListObjects("", bucket)
+	objs, err := m.ListObjects(ctx, "", bucket)
 	if err != nil {
 		return err
 	}
@@ -562,14 +655,15 @@ func (m *Model) EmptyBucket(bucket *Object) error {
 			ids = append(ids, s3t.ObjectIdentifier{Key: o.Key})
 		}
 	}
-	return m.deleteObjectIDs(bucket, ids)
+	return m.deleteObjectIDs(ctx, bucket, ids)
 }
 
-func (m *Model) DeleteBucket(name *string) error {
+func (m *Model) DeleteBucket(ctx context.Context, name *string) error {
 	// No logging here: stdout/stderr writes corrupt the tview display. The
 	// error is returned and surfaced by the controller's delete report.
-	_, err := m.Client.DeleteBucket(context.TODO(), &s3.DeleteBucketInput{
+	_, err := m.Client.DeleteBucket(ctx, &s3.DeleteBucketInput{
 		Bucket: aws.String(*name)})
+	m.invalidateLists(*name, "")
 	return err
 }
 
@@ -625,8 +719,8 @@ func (m *Model) BucketConfig(bucket *Object) (BucketConfigInfo, error) {
 	ctx := context.TODO()
 	info := BucketConfigInfo{Region: "us-east-1", Versioning: "off", Encryption: "none", ObjectLock: "off"}
 
-	if loc, err := m.GetBucketLocation(bucket.Key); err == nil && loc != nil && *loc != "" {
-		info.Region = *loc
+	if loc, err := m.BucketRegion(*bucket.Key); err == nil && loc != "" {
+		info.Region = loc
 	}
 	if v, err := m.Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: name}); err == nil {
 		if s := string(v.Status); s != "" {
@@ -694,6 +788,7 @@ func (m *Model) CreateFolder(name *string, bucket *Object) error {
 		Bucket: aws.String(*bucket.Key),
 		Key:    aws.String(*name),
 	})
+	m.invalidateLists(*bucket.Key, *name)
 	return err
 }
 
@@ -717,9 +812,6 @@ func walkFollowingRoot(localPath string, fn filepath.WalkFunc) error {
 	})
 }
 
-// skip, when non-nil, names remote keys the user chose to keep: those files
-// are walked and counted but never sent. The keys match PrepareUpload's
-// RemotePath exactly — the two key derivations are pinned together by test.
 // uploadKey derives the remote key for one walked local file. Upload and the
 // skip filter both go through it, and it must keep matching PrepareUpload's
 // RemotePath — TestIntegrationUploadMatchesPreparedKeys pins the two together.
@@ -732,18 +824,33 @@ func uploadKey(localPath, s3Prefix, fpath string, isDir bool) string {
 	return path.Join(s3Prefix, filepath.Base(fpath))
 }
 
+// Upload sends the file or tree at localPath to keys under s3Prefix. skip,
+// when non-nil, names remote keys the user chose to keep: those files are
+// walked but never sent. The keys match PrepareUpload's RemotePath exactly —
+// the two key derivations are pinned together by test. fileDone, when
+// non-nil, is told each file's key and outcome as it goes, and a file that
+// fails is reported there instead of stopping the upload; without it the
+// first failing file ends the run with its error. Either way a cancellation,
+// an unreadable tree or a folder marker that can't be written is returned.
 func (m *Model) Upload(
 	ctx context.Context,
 	localPath, s3Prefix string,
 	bucket *Object,
 	skip map[string]bool,
 	progressCb func(current, total int64, i, count int, local, remote string),
+	fileDone func(remote string, err error),
 ) error {
 	info, err := os.Stat(localPath)
 	if err != nil {
 		return err
 	}
 
+	if bucket != nil && bucket.Key != nil {
+		// Markers and files all land under s3Prefix, whatever part of the
+		// upload gets done.
+		defer m.invalidateLists(*bucket.Key, s3Prefix)
+	}
+
 	isDir := info.IsDir()
 	var files []string
 	var dirs []string
@@ -855,53 +962,62 @@ func (m *Model) Upload(
 		}
 
 		s3Key := uploadKey(localPath, s3Prefix, fpath, isDir)
-
-		stat, err := os.Stat(fpath)
-		if err != nil {
-			return fmt.Errorf("stat failed for %s: %w", fpath, err)
-		}
-
-		fp, err := os.Open(fpath)
-		if err != nil {
-			return fmt.Errorf("failed to open file %s: %w", fpath, err)
+		n, err := m.uploadOne(ctx, uploader, bucket, fpath, s3Key, func(written int64) {
+			if progressCb != nil {
+				progressCb(uploadedTotal+written, totalSize, i+1, len(files), fpath, s3Key)
+			}
+		})
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("upload canceled for %s", fpath)
 		}
-
-		reader := &progressReader{
-			r:     fp,
-			total: stat.Size(),
-			update: func(written, _ int64) {
-				if progressCb != nil {
-					progressCb(uploadedTotal+written, totalSize, i+1, len(files), fpath, s3Key)
-				}
-			},
-			limiter: m.Limiter,
+		if fileDone == nil {
+			if err != nil {
+				return err
+			}
+		} else {
+			fileDone(s3Key, err)
 		}
+		uploadedTotal += n
+	}
 
-		uploadCtx, cancel := context.WithCancel(ctx)
+	return nil
+}
 
-		_, err = uploader.Upload(uploadCtx, &s3.PutObjectInput{
-			Bucket: aws.String(*bucket.Key),
-			Key:    aws.String(s3Key),
-			Body:   reader,
-		})
-		fp.Close()
-		cancel() // release per-file context immediately, not at Upload() return
+// uploadOne sends the local file fpath to s3Key and returns its size.
+func (m *Model) uploadOne(ctx context.Context, uploader *s3m.Uploader, bucket *Object, fpath, s3Key string, progress func(written int64)) (int64, error) {
+	stat, err := os.Stat(fpath)
+	if err != nil {
+		return 0, fmt.Errorf("stat failed for %s: %w", fpath, err)
+	}
 
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				return fmt.Errorf("upload canceled for %s", fpath)
-			}
-			var apiErr smithy.APIError
-			if errors.As(err, &apiErr) {
-				return fmt.Errorf("upload failed for %s: %s - %s", fpath, apiErr.ErrorCode(), apiErr.ErrorMessage())
-			}
-			return fmt.Errorf("upload failed for %s: %w", fpath, err)
-		}
+	fp, err := os.Open(fpath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file %s: %w", fpath, err)
+	}
+	defer fp.Close()
 
-		uploadedTotal += stat.Size()
+	reader := &progressReader{
+		r:     fp,
+		total: stat.Size(),
+		update: func(written, _ int64) {
+			progress(written)
+		},
+		limiter: m.Limiter,
 	}
 
-	return nil
+	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(*bucket.Key),
+		Key:    aws.String(s3Key),
+		Body:   reader,
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return 0, fmt.Errorf("upload failed for %s: %s - %s", fpath, apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return 0, fmt.Errorf("upload failed for %s: %w", fpath, err)
+	}
+	return stat.Size(), nil
 }
 
 // PrepareUpload returns list of files to upload with remote keys and total size.
@@ -983,12 +1099,12 @@ func (m *Model) MakeBucketPublic(bucketName string) error {
 // ResolveDownloadObjects resolves the objects to be downloaded.
 // If `isFolder` is true, it performs a prefix-based list.
 // If false, returns a single exact match using the key and size.
-func (m *Model) ResolveDownloadObjects(key string, isFolder bool, size *int64, bucket *Object) ([]DownloadTarget, int64, error) {
+func (m *Model) ResolveDownloadObjects(ctx context.Context, key string, isFolder bool, size *int64, bucket *Object) ([]DownloadTarget, int64, error) {
 	if isFolder {
 		if !strings.HasSuffix(key, "/") {
 			key += "/"
 		}
-		objs, err := m.ListObjects(key, bucket)
+		objs, err := m.ListObjects(ctx, key, bucket)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -999,10 +1115,11 @@ func (m *Model) ResolveDownloadObjects(key string, isFolder bool, size *int64, b
 			if obj.Key == nil {
 				continue
 			}
-			out = append(out, DownloadTarget{
-				Key:  *obj.Key,
-				Size: obj.Size,
-			})
+			t := DownloadTarget{Key: *obj.Key, Size: obj.Size}
+			if obj.LastModified != nil {
+				t.Modified = *obj.LastModified
+			}
+			out = append(out, t)
 			total += obj.Size
 		}
 		return out, total, nil
@@ -1086,10 +1203,17 @@ func (m *Model) DownloadTarget(
 		limiter: m.Limiter,
 	}
 
-	n, err := m.Downloader.Download(ctx, writerAt, &s3.GetObjectInput{
-		Bucket: bucket,
-		Key:    aws.String(t.Key),
-	})
+	var n int64
+	if t.Archive != nil && t.Member != nil {
+		// The compressed bytes are throttled as they arrive.
+		writerAt.limiter = nil
+		n, err = m.ExtractMember(ctx, t.Archive, *t.Member, io.NewOffsetWriter(writerAt, 0))
+	} else {
+		n, err = m.Downloader.Download(ctx, writerAt, &s3.GetObjectInput{
+			Bucket: bucket,
+			Key:    aws.String(t.Key),
+		})
+	}
 	if ctx.Err() != nil {
 		fp.Close()
 		_ = os.Remove(tmpPath)
@@ -1221,11 +1345,11 @@ func (m *Model) CopyObjectSized(ctx context.Context, srcBucket, dstBucket *Objec
 // is true it recursively copies every object under srcKey/ into dstKey/. Returns
 // the number of objects copied.
 //
-// Cross-bucket copy uses the server-side CopyObject and therefore assumes both
-// buckets are reachable through the currently configured endpoint (always true
-// for a single S3-compatible endpoint such as MinIO/Ceph, and for same-region
-// AWS buckets). Copying between AWS buckets in different regions is not handled
-// here.
+// Cross-bucket copy uses the server-side CopyObject, so both buckets must be
+// reachable through the configured endpoint. On AWS a destination bucket in
+// another region gets a client pinned to that region for the writes (see
+// copyVia), and objects the service refuses to copy across regions are
+// streamed through this process instead.
 // skip, when non-nil, names destination keys the user chose to keep: those
 // objects are not written. A skipped destination also leaves its source in
 // place on a move, because copyKeysTracked reports only what it actually
@@ -1258,11 +1382,17 @@ func (m *Model) copyKeysTracked(
 		return nil, fmt.Errorf("bucket is nil")
 	}
 
+	// Resolved once per call: every object lands in the same bucket.
+	w, err := m.forBucket(dstBucket)
+	if err != nil {
+		return nil, err
+	}
+
 	if !isFolder {
 		if skip[dstKey] {
 			return nil, nil
 		}
-		if err := m.CopyObject(ctx, srcBucket, dstBucket, srcKey, dstKey); err != nil {
+		if err := m.copyVia(ctx, w, srcBucket, dstBucket, srcKey, dstKey, SizeUnknown); err != nil {
 			return nil, err
 		}
 		if progressCb != nil {
@@ -1277,7 +1407,7 @@ func (m *Model) copyKeysTracked(
 		return nil, err
 	}
 
-	objs, err := m.ListObjects(src, srcBucket)
+	objs, err := m.ListObjects(ctx, src, srcBucket)
 	if err != nil {
 		return nil, err
 	}
@@ -1299,7 +1429,7 @@ func (m *Model) copyKeysTracked(
 		}
 		// The listing already carries the size, so the copy never has to
 		// HeadObject to find out whether it must go multipart.
-		if err := m.CopyObjectSized(ctx, srcBucket, dstBucket, *o.Key, newKey, o.Size); err != nil {
+		if err := m.copyVia(ctx, w, srcBucket, dstBucket, *o.Key, newKey, o.Size); err != nil {
 			return copied, fmt.Errorf("copy %s: %w", *o.Key, err)
 		}
 		copied = append(copied, *o.Key)
@@ -1334,7 +1464,7 @@ func (m *Model) MoveKeys(
 	for _, k := range copied {
 		ids = append(ids, s3t.ObjectIdentifier{Key: aws.String(k)})
 	}
-	if err := m.deleteObjectIDs(srcBucket, ids); err != nil {
+	if err := m.deleteObjectIDs(ctx, srcBucket, ids); err != nil {
 		return len(copied), fmt.Errorf("copied ok, but failed to delete source: %w", err)
 	}
 	return len(copied), nil
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -9,6 +10,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
 // PresignGetURL is pure local crypto (no network), so it can be exercised
@@ -23,6 +27,25 @@ func newTestModel(t *testing.T, cf Config) *Model {
 	return m
 }
 
+// newFakeModel is a model whose client talks to rt, path-style and without
+// retries, instead of the network.
+func newFakeModel(t *testing.T, rt http.RoundTripper) *Model {
+	t.Helper()
+	m := newTestModel(t, NewConfig("http://s3.test", strPtr("us-east-1"), "ak", "sk", "", false, 0))
+	useTransport(m, rt)
+	return m
+}
+
+// useTransport points m's client at rt, for a test whose model needs a config
+// of its own.
+func useTransport(m *Model, rt http.RoundTripper) {
+	m.Client = s3.NewFromConfig(*m.Config, func(o *s3.Options) {
+		o.HTTPClient = &http.Client{Transport: rt}
+		o.UsePathStyle = true
+		o.Retryer = aws.NopRetryer{}
+	})
+}
+
 func TestPresignGetURL(t *testing.T) {
 	region := "us-east-1"
 	m := newTestModel(t, NewConfig("https://s3.example.com", &region, "ak", "sk", "", true, 0))
@@ -514,3 +537,81 @@ func TestUploadKeyMatchesPrepareUpload(t *testing.T) {
 		t.Errorf("uploadKey = %q, PrepareUpload said %q", got, targets[0].RemotePath)
 	}
 }
+
+func TestUploadReportsEachFile(t *testing.T) {
+	tree := filepath.Join(t.TempDir(), "proj")
+	for rel, body := range map[string]string{"a.txt": "one", "sub/b.txt": "two", "sub/c.txt": "three"} {
+		p := filepath.Join(tree, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bucket := &Object{Key: strPtr("b")}
+	refuse := func(req *http.Request) (int, string) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/sub/b.txt") {
+			return http.StatusForbidden, "AccessDenied"
+		}
+		return 0, ""
+	}
+
+	fs := newFakeS3(nil)
+	fs.fail = refuse
+	m := newFakeModel(t, fs)
+	outcomes := map[string]bool{}
+	err := m.Upload(context.Background(), tree, "", bucket, nil, nil, func(key string, err error) {
+		outcomes[key] = err == nil
+	})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	want := map[string]bool{"proj/a.txt": true, "proj/sub/b.txt": false, "proj/sub/c.txt": true}
+	if len(outcomes) != len(want) {
+		t.Errorf("outcomes = %v, want %v", outcomes, want)
+	}
+	for k, ok := range want {
+		if outcomes[k] != ok {
+			t.Errorf("%s: ok = %v, want %v", k, outcomes[k], ok)
+		}
+	}
+	if _, ok := fs.object("b/proj/sub/c.txt"); !ok {
+		t.Error("the file after the failure was not sent")
+	}
+
+	// Without fileDone the first failure ends the run.
+	fs = newFakeS3(nil)
+	fs.fail = refuse
+	m = newFakeModel(t, fs)
+	if err := m.Upload(context.Background(), tree, "", bucket, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "b.txt") {
+		t.Errorf("Upload without fileDone = %v, want b.txt's error", err)
+	}
+	if _, ok := fs.object("b/proj/sub/c.txt"); ok {
+		t.Error("the upload went on past the failure")
+	}
+}
+
+func TestSameEndpoint(t *testing.T) {
+	mk := func(url, key, region string) *Model {
+		return &Model{Cf: &Config{Url: url, AccessKey: key, Region: &region}}
+	}
+	a := mk("https://s3.amazonaws.com", "AK1", "us-east-1")
+	cases := []struct {
+		name string
+		b    *Model
+		want bool
+	}{
+		{"same model", a, true},
+		{"same profile, other region", mk("https://s3.amazonaws.com", "AK1", "eu-west-1"), true},
+		{"other credentials", mk("https://s3.amazonaws.com", "AK2", "us-east-1"), false},
+		{"other endpoint", mk("http://minio:9000", "AK1", "us-east-1"), false},
+		{"nil", nil, false},
+		{"no config", &Model{}, false},
+	}
+	for _, tc := range cases {
+		if got := SameEndpoint(a, tc.b); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
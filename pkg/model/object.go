@@ -132,6 +132,35 @@ func RestoreSummary(storageClass, restoreHeader string) string {
 	return "restored"
 }
 
+// ParseS3URI splits "s3://bucket/key" into its bucket and key. Surrounding
+// space is ignored; a URI naming only a bucket is refused.
+func ParseS3URI(s string) (bucket, key string, err error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("%q is not an s3://bucket/key URI", s)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("%q names no object", s)
+	}
+	return bucket, key, nil
+}
+
+// ObjectExists reports whether key is in bucket. Only S3's "not found" means
+// no: a HEAD refused for want of ListBucket comes back 403 whether or not the
+// key is there, so that — or throttling, or a dropped connection — is an
+// error rather than an answer.
+func (m *Model) ObjectExists(ctx context.Context, bucket *Object, key string) (bool, error) {
+	_, err := m.HeadObject(ctx, bucket, key)
+	switch {
+	case err == nil:
+		return true, nil
+	case isNotFound(err):
+		return false, nil
+	}
+	return false, err
+}
+
 // HeadObject fetches an object's metadata without downloading its body.
 func (m *Model) HeadObject(ctx context.Context, bucket *Object, key string) (ObjectMeta, error) {
 	if bucket == nil || bucket.Key == nil {
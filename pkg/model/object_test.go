@@ -6,6 +6,18 @@ import (
 	"testing"
 )
 
+func TestParseS3URI(t *testing.T) {
+	b, k, err := ParseS3URI(" s3://reports/inv/big/2025-10-17T01-00Z/manifest.json ")
+	if err != nil || b != "reports" || k != "inv/big/2025-10-17T01-00Z/manifest.json" {
+		t.Errorf("got %q %q %v", b, k, err)
+	}
+	for _, bad := range []string{"reports/manifest.json", "s3://", "s3://reports", "s3://reports/", "s3:///key"} {
+		if _, _, err := ParseS3URI(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
 func TestParseRestoreStatus(t *testing.T) {
 	t.Run("completed restore with an expiry", func(t *testing.T) {
 		ongoing, expiry, ok := ParseRestoreStatus(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`)
@@ -0,0 +1,1009 @@
+package model
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// An Apache ORC reader for S3 Inventory data files, which S3 writes as ORC as
+// readily as CSV. It reads the postscript, footer and stripe footers, whose
+// protobuf is taken apart with protowire rather than generated code for three
+// messages; the NONE, ZLIB, SNAPPY and ZSTD codecs; and the run-length
+// encodings behind the column types an inventory uses — strings, integers,
+// booleans and timestamps. Columns of other types are passed over. A file is
+// read a stripe at a time, so memory follows the stripe size (64 MiB as S3
+// writes them), not the file's.
+
+const (
+	orcMagic = "ORC"
+	// orcTail is how much of a file's end is read first: the postscript and,
+	// for any file S3 writes, the footer.
+	orcTail = 16 << 10
+	// orcMaxStripe bounds one stripe's data and footer, read into memory
+	// whole; orcMaxFooter bounds the file footer.
+	orcMaxStripe = 1 << 30
+	orcMaxFooter = 64 << 20
+	// orcBlockSize is a codec's chunk size when the postscript doesn't say;
+	// orcMaxBlock is the most a chunk header can give the length of.
+	orcBlockSize = 256 << 10
+	orcMaxBlock  = 1<<23 - 1
+)
+
+// Compression codecs, type kinds, stream kinds and column encodings, as
+// numbered in orc_proto.proto.
+const (
+	orcCodecNone   = 0
+	orcCodecZlib   = 1
+	orcCodecSnappy = 2
+	orcCodecZstd   = 5
+
+	orcBoolean   = 0
+	orcByte      = 1
+	orcLong      = 4 // SHORT, INT and LONG are 2, 3 and 4, all encoded alike
+	orcString    = 7
+	orcBinary    = 8
+	orcTimestamp = 9
+	orcStruct    = 12
+	orcVarchar   = 16
+	orcChar      = 17
+	orcInstant   = 18 // TIMESTAMP_INSTANT
+
+	orcPresent        = 0
+	orcData           = 1
+	orcLength         = 2
+	orcDictionaryData = 3
+	orcSecondary      = 5
+
+	orcDirect     = 0
+	orcDictionary = 1 // with DIRECT_V2 (2) and DICTIONARY_V2 (3): +2 means RLE v2
+)
+
+var errORCCorrupt = errors.New("orc: corrupt file")
+
+// orcEpoch is where ORC timestamps count seconds from, in the writer's zone.
+var orcEpoch = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// orcFile is what a file's tail says about it.
+type orcFile struct {
+	codec     uint64
+	blockSize int
+	stripes   []orcStripe
+	types     []orcType
+}
+
+type orcStripe struct {
+	offset, indexLen, dataLen, footerLen, rows uint64
+}
+
+type orcType struct {
+	kind  uint64
+	sub   []uint64
+	names []string
+}
+
+type orcStream struct {
+	kind, column, length uint64
+}
+
+type orcStripeFooter struct {
+	streams   []orcStream
+	encodings []orcEncoding // by column
+	zone      string
+}
+
+type orcEncoding struct {
+	kind, dictSize uint64
+}
+
+// readInventoryORC decodes one ORC data file of size bytes read through r,
+// calling add for each row describing a current object, as readInventoryCSV
+// does for a CSV one. The columns are found by the names S3 gives them (key,
+// size, last_modified_date, e_tag, storage_class, is_latest,
+// is_delete_marker); only key is required. Keys are taken as they are: ORC
+// reports don't URL-encode them the way CSV ones do.
+func readInventoryORC(r io.ReaderAt, size int64, add func(indexEntry)) error {
+	f, err := readORCTail(r, size)
+	if err != nil {
+		return err
+	}
+	if len(f.types) == 0 || f.types[0].kind != orcStruct || len(f.types[0].sub) != len(f.types[0].names) {
+		return errors.New("orc: the file's root type is not a struct")
+	}
+	cols := map[string]uint64{}
+	for i, name := range f.types[0].names {
+		if c := f.types[0].sub[i]; c < uint64(len(f.types)) {
+			cols[name] = c
+		}
+	}
+	key, ok := cols["key"]
+	if !ok {
+		return errors.New("orc: the file has no key column")
+	}
+
+	for _, st := range f.stripes {
+		if st.dataLen+st.footerLen > orcMaxStripe {
+			return fmt.Errorf("orc: a stripe of %d bytes is over the limit", st.dataLen+st.footerLen)
+		}
+		// No encoding packs more than a thousand or so rows into a byte, so
+		// a row count past that is corrupt, not an allocation to make.
+		if st.rows > 1024*(st.dataLen+1) {
+			return errORCCorrupt
+		}
+		buf := make([]byte, st.dataLen+st.footerLen)
+		if err := readAtFull(r, buf, int64(st.offset+st.indexLen)); err != nil {
+			return err
+		}
+		raw, err := orcDecompress(f.codec, f.blockSize, buf[st.dataLen:])
+		if err != nil {
+			return err
+		}
+		sf, err := parseORCStripeFooter(raw)
+		if err != nil {
+			return err
+		}
+		s := &orcStripeReader{f: f, sf: sf, rows: int(st.rows), streams: map[[2]uint64][]byte{}}
+		// The streams lie end to end in the order listed, the index ones
+		// (which aren't read) first.
+		pos, end := uint64(0), st.indexLen+st.dataLen
+		for _, stream := range sf.streams {
+			if stream.length > end-pos {
+				return errORCCorrupt
+			}
+			if pos >= st.indexLen {
+				s.streams[[2]uint64{stream.column, stream.kind}] = buf[pos-st.indexLen : pos-st.indexLen+stream.length]
+			}
+			pos += stream.length
+		}
+
+		keys, err := s.column(key)
+		if err != nil {
+			return fmt.Errorf("orc: key: %w", err)
+		}
+		var other [6]*orcColumn
+		for i, name := range []string{"size", "last_modified_date", "e_tag", "storage_class", "is_latest", "is_delete_marker"} {
+			if c, ok := cols[name]; ok {
+				if other[i], err = s.column(c); err != nil {
+					return fmt.Errorf("orc: %s: %w", name, err)
+				}
+			}
+		}
+		sizes, mods, etags, classes, latest, deleted := other[0], other[1], other[2], other[3], other[4], other[5]
+		for row := 0; row < s.rows; row++ {
+			if v, ok := latest.bool(row); ok && !v {
+				continue
+			}
+			if v, ok := deleted.bool(row); ok && v {
+				continue
+			}
+			e := indexEntry{key: keys.str(row)}
+			if e.key == "" {
+				continue
+			}
+			e.etag, e.class = etags.str(row), classes.str(row)
+			e.size, _ = sizes.int(row)
+			e.mod, _ = mods.int(row)
+			add(e)
+		}
+	}
+	return nil
+}
+
+// readAtFull fills p from r at off.
+func readAtFull(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readORCTail reads the postscript and footer from the end of a file.
+func readORCTail(r io.ReaderAt, size int64) (*orcFile, error) {
+	if size < int64(len(orcMagic))+2 {
+		return nil, errors.New("orc: not an ORC file")
+	}
+	tail := make([]byte, min(size, orcTail))
+	if err := readAtFull(r, tail, size-int64(len(tail))); err != nil {
+		return nil, err
+	}
+	psLen := int(tail[len(tail)-1])
+	if psLen+1 > len(tail) {
+		return nil, errORCCorrupt
+	}
+	ps := tail[len(tail)-1-psLen : len(tail)-1]
+	f := &orcFile{blockSize: orcBlockSize}
+	var footerLen uint64
+	magic := false
+	err := pbEach(ps, func(fd pbField) error {
+		switch fd.num {
+		case 1:
+			footerLen = fd.v
+		case 2:
+			f.codec = fd.v
+		case 3:
+			if fd.v > 0 && fd.v <= orcMaxBlock {
+				f.blockSize = int(fd.v)
+			}
+		case 8000:
+			magic = string(fd.data) == orcMagic
+		}
+		return nil
+	})
+	if err != nil || !magic {
+		return nil, errors.New("orc: not an ORC file")
+	}
+	if footerLen > orcMaxFooter || int64(footerLen)+int64(psLen)+1 > size {
+		return nil, errORCCorrupt
+	}
+	var footer []byte
+	if end := len(tail) - 1 - psLen; int(footerLen) <= end {
+		footer = tail[end-int(footerLen) : end]
+	} else {
+		footer = make([]byte, footerLen)
+		if err := readAtFull(r, footer, size-1-int64(psLen)-int64(footerLen)); err != nil {
+			return nil, err
+		}
+	}
+	if footer, err = orcDecompress(f.codec, f.blockSize, footer); err != nil {
+		return nil, err
+	}
+	err = pbEach(footer, func(fd pbField) error {
+		switch fd.num {
+		case 3:
+			var st orcStripe
+			err := pbEach(fd.data, func(fd pbField) error {
+				switch fd.num {
+				case 1:
+					st.offset = fd.v
+				case 2:
+					st.indexLen = fd.v
+				case 3:
+					st.dataLen = fd.v
+				case 4:
+					st.footerLen = fd.v
+				case 5:
+					st.rows = fd.v
+				}
+				return nil
+			})
+			f.stripes = append(f.stripes, st)
+			return err
+		case 4:
+			var t orcType
+			err := pbEach(fd.data, func(fd pbField) error {
+				switch fd.num {
+				case 1:
+					t.kind = fd.v
+				case 2:
+					sub, err := fd.uints()
+					t.sub = append(t.sub, sub...)
+					return err
+				case 3:
+					t.names = append(t.names, string(fd.data))
+				}
+				return nil
+			})
+			f.types = append(f.types, t)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range f.stripes {
+		left := uint64(size)
+		for _, n := range []uint64{st.offset, st.indexLen, st.dataLen, st.footerLen} {
+			if n > left {
+				return nil, errORCCorrupt
+			}
+			left -= n
+		}
+	}
+	return f, nil
+}
+
+func parseORCStripeFooter(b []byte) (*orcStripeFooter, error) {
+	sf := &orcStripeFooter{}
+	err := pbEach(b, func(fd pbField) error {
+		switch fd.num {
+		case 1:
+			var s orcStream
+			err := pbEach(fd.data, func(fd pbField) error {
+				switch fd.num {
+				case 1:
+					s.kind = fd.v
+				case 2:
+					s.column = fd.v
+				case 3:
+					s.length = fd.v
+				}
+				return nil
+			})
+			sf.streams = append(sf.streams, s)
+			return err
+		case 2:
+			var e orcEncoding
+			err := pbEach(fd.data, func(fd pbField) error {
+				switch fd.num {
+				case 1:
+					e.kind = fd.v
+				case 2:
+					e.dictSize = fd.v
+				}
+				return nil
+			})
+			sf.encodings = append(sf.encodings, e)
+			return err
+		case 3:
+			sf.zone = string(fd.data)
+		}
+		return nil
+	})
+	return sf, err
+}
+
+// orcStripeReader decodes the columns of one stripe from its streams.
+type orcStripeReader struct {
+	f       *orcFile
+	sf      *orcStripeFooter
+	rows    int
+	streams map[[2]uint64][]byte // by column and stream kind, still compressed
+}
+
+// orcColumn is one column of a stripe. Only the rows present have a value,
+// so the values are indexed through present; a nil present means every row
+// has one. A nil column has no values at all.
+type orcColumn struct {
+	present []bool
+	index   []int // row → value, built from present
+	strs    []string
+	ints    []int64
+	bools   []bool
+}
+
+func (c *orcColumn) at(row int) (int, bool) {
+	if c == nil {
+		return 0, false
+	}
+	if c.present == nil {
+		return row, true
+	}
+	return c.index[row], c.present[row]
+}
+
+func (c *orcColumn) str(row int) string {
+	if i, ok := c.at(row); ok && i < len(c.strs) {
+		return c.strs[i]
+	}
+	return ""
+}
+
+func (c *orcColumn) int(row int) (int64, bool) {
+	if i, ok := c.at(row); ok && i < len(c.ints) {
+		return c.ints[i], true
+	}
+	return 0, false
+}
+
+func (c *orcColumn) bool(row int) (bool, bool) {
+	if i, ok := c.at(row); ok && i < len(c.bools) {
+		return c.bools[i], true
+	}
+	return false, false
+}
+
+// stream returns a stream of column, decompressed; nil if there is none.
+func (s *orcStripeReader) stream(column, kind uint64) ([]byte, error) {
+	b, ok := s.streams[[2]uint64{column, kind}]
+	if !ok {
+		return nil, nil
+	}
+	return orcDecompress(s.f.codec, s.f.blockSize, b)
+}
+
+// column decodes one column; nil, without an error, for a type it doesn't
+// read.
+func (s *orcStripeReader) column(col uint64) (*orcColumn, error) {
+	c := &orcColumn{}
+	n := s.rows
+	if present, err := s.stream(col, orcPresent); err != nil {
+		return nil, err
+	} else if present != nil {
+		if c.present, err = orcBools(present, s.rows); err != nil {
+			return nil, err
+		}
+		c.index = make([]int, s.rows)
+		n = 0
+		for row, ok := range c.present {
+			c.index[row] = n
+			if ok {
+				n++
+			}
+		}
+	}
+	var enc orcEncoding
+	if col < uint64(len(s.sf.encodings)) {
+		enc = s.sf.encodings[col]
+	}
+	v2 := enc.kind >= 2
+	data, err := s.stream(col, orcData)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind := s.f.types[col].kind; kind {
+	case orcBoolean:
+		c.bools, err = orcBools(data, n)
+	case orcByte:
+		var b []byte
+		b, err = orcBytes(data, n)
+		for _, v := range b {
+			c.ints = append(c.ints, int64(int8(v)))
+		}
+	case 2, 3, orcLong:
+		c.ints, err = orcInts(data, n, true, v2)
+	case orcString, orcBinary, orcVarchar, orcChar:
+		c.strs, err = s.strings(col, enc, data, n)
+	case orcTimestamp, orcInstant:
+		c.ints, err = s.timestamps(col, kind, v2, data, n)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// strings decodes n strings, stored directly — their bytes end to end in
+// data, their lengths in LENGTH — or as indexes into a dictionary.
+func (s *orcStripeReader) strings(col uint64, enc orcEncoding, data []byte, n int) ([]string, error) {
+	v2 := enc.kind >= 2
+	lengths, err := s.stream(col, orcLength)
+	if err != nil {
+		return nil, err
+	}
+	if enc.kind&1 == orcDirect {
+		lens, err := orcInts(lengths, n, false, v2)
+		if err != nil {
+			return nil, err
+		}
+		return orcSplit(data, lens)
+	}
+	dictData, err := s.stream(col, orcDictionaryData)
+	if err != nil {
+		return nil, err
+	}
+	if enc.dictSize > uint64(len(dictData)+1) {
+		return nil, errORCCorrupt
+	}
+	lens, err := orcInts(lengths, int(enc.dictSize), false, v2)
+	if err != nil {
+		return nil, err
+	}
+	dict, err := orcSplit(dictData, lens)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := orcInts(data, n, false, v2)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, n)
+	for i, d := range idx {
+		if d < 0 || d >= int64(len(dict)) {
+			return nil, errORCCorrupt
+		}
+		out[i] = dict[d]
+	}
+	return out, nil
+}
+
+// orcSplit cuts b into strings of lens bytes.
+func orcSplit(b []byte, lens []int64) ([]string, error) {
+	out := make([]string, len(lens))
+	for i, l := range lens {
+		if l < 0 || l > int64(len(b)) {
+			return nil, errORCCorrupt
+		}
+		out[i], b = string(b[:l]), b[l:]
+	}
+	return out, nil
+}
+
+// timestamps decodes n timestamps as Unix milliseconds: seconds from
+// orcEpoch in DATA, and in SECONDARY nanoseconds, their trailing zeros
+// counted in the low three bits. A plain TIMESTAMP counts from the epoch in
+// the writer's zone, which S3 leaves as UTC; one that can't be loaded is
+// taken as UTC too.
+func (s *orcStripeReader) timestamps(col, kind uint64, v2 bool, data []byte, n int) ([]int64, error) {
+	secs, err := orcInts(data, n, true, v2)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := s.stream(col, orcSecondary)
+	if err != nil {
+		return nil, err
+	}
+	nanos, err := orcInts(secondary, n, false, v2)
+	if err != nil {
+		return nil, err
+	}
+	epoch := orcEpoch
+	if kind == orcTimestamp && s.sf.zone != "" {
+		if loc, err := time.LoadLocation(s.sf.zone); err == nil {
+			epoch = time.Date(2015, 1, 1, 0, 0, 0, 0, loc)
+		}
+	}
+	out := make([]int64, n)
+	for i := range out {
+		ns := uint64(nanos[i]) >> 3
+		if zeros := nanos[i] & 7; zeros != 0 {
+			for ; zeros >= 0; zeros-- {
+				ns *= 10
+			}
+		}
+		out[i] = (epoch.Unix()+secs[i])*1000 + int64(ns/1e6)
+	}
+	return out, nil
+}
+
+// orcDecompress undoes a stream's codec. A compressed stream is a run of
+// chunks, each behind a three-byte header holding its length and whether it
+// was left as it was; each decompresses to at most blockSize bytes.
+func orcDecompress(codec uint64, blockSize int, b []byte) ([]byte, error) {
+	if codec == orcCodecNone {
+		return b, nil
+	}
+	var out []byte
+	for len(b) > 0 {
+		if len(b) < 3 {
+			return nil, errORCCorrupt
+		}
+		h := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+		n := h >> 1
+		if n > len(b)-3 {
+			return nil, errORCCorrupt
+		}
+		chunk := b[3 : 3+n]
+		b = b[3+n:]
+		if h&1 == 1 {
+			out = append(out, chunk...)
+			continue
+		}
+		var r io.ReadCloser
+		switch codec {
+		case orcCodecZlib:
+			r = flate.NewReader(bytes.NewReader(chunk))
+		case orcCodecZstd:
+			zr, err := newZstdReader(bytes.NewReader(chunk))
+			if err != nil {
+				return nil, err
+			}
+			r = zr
+		case orcCodecSnappy:
+			d, err := snappyDecode(chunk, blockSize)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, d...)
+			continue
+		default:
+			return nil, fmt.Errorf("orc: compression codec %d is not supported", codec)
+		}
+		start := len(out)
+		buf := bytes.NewBuffer(out)
+		_, err := buf.ReadFrom(io.LimitReader(r, int64(blockSize)+1))
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("orc: %w", err)
+		}
+		if out = buf.Bytes(); len(out)-start > blockSize {
+			return nil, errORCCorrupt
+		}
+	}
+	return out, nil
+}
+
+// snappyDecode decodes one block in Snappy's raw format, as ORC stores it,
+// refusing one whose header claims more than limit bytes before anything is
+// allocated for it.
+func snappyDecode(src []byte, limit int) ([]byte, error) {
+	if n, err := snappy.DecodedLen(src); err != nil || n > limit {
+		return nil, errORCCorrupt
+	}
+	d, err := snappy.Decode(nil, src)
+	if err != nil {
+		return nil, errORCCorrupt
+	}
+	return d, nil
+}
+
+// orcBytes decodes n bytes of byte run-length encoding: a header under 128
+// repeats the next byte header+3 times, one above is followed by 256-header
+// bytes as they are.
+func orcBytes(b []byte, n int) ([]byte, error) {
+	out := make([]byte, 0, min(n, 1<<20))
+	for len(out) < n {
+		if len(b) < 2 {
+			return nil, errORCCorrupt
+		}
+		if h := int(b[0]); h < 128 {
+			for i := 0; i < h+3; i++ {
+				out = append(out, b[1])
+			}
+			b = b[2:]
+		} else {
+			k := 256 - h
+			if len(b) < 1+k {
+				return nil, errORCCorrupt
+			}
+			out = append(out, b[1:1+k]...)
+			b = b[1+k:]
+		}
+	}
+	return out[:n], nil
+}
+
+// orcBools decodes n booleans: bytes, as orcBytes decodes them, of eight
+// bits each, the highest first.
+func orcBools(b []byte, n int) ([]bool, error) {
+	bits, err := orcBytes(b, (n+7)/8)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]bool, n)
+	for i := range out {
+		out[i] = bits[i/8]&(0x80>>(i%8)) != 0
+	}
+	return out, nil
+}
+
+// orcInts decodes n integers of run-length encoding, version 1 or 2; signed
+// ones are zigzag-encoded.
+func orcInts(b []byte, n int, signed, v2 bool) ([]int64, error) {
+	d := &orcIntDecoder{b: b, signed: signed}
+	out := make([]int64, 0, min(n, 1<<20))
+	for len(out) < n {
+		if len(d.b) == 0 {
+			return nil, errORCCorrupt
+		}
+		var err error
+		if v2 {
+			out, err = d.runV2(out)
+		} else {
+			out, err = d.runV1(out)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out[:n], nil
+}
+
+type orcIntDecoder struct {
+	b      []byte
+	signed bool
+}
+
+func (d *orcIntDecoder) uvarint() (uint64, error) {
+	v, k := binary.Uvarint(d.b)
+	if k <= 0 {
+		return 0, errORCCorrupt
+	}
+	d.b = d.b[k:]
+	return v, nil
+}
+
+func unzigzag(v uint64) int64 { return int64(v>>1) ^ -int64(v&1) }
+
+// varint reads a base-128 varint, zigzag-decoding it if the column is signed
+// or signed is set.
+func (d *orcIntDecoder) varint(signed bool) (int64, error) {
+	v, err := d.uvarint()
+	if signed {
+		return unzigzag(v), err
+	}
+	return int64(v), err
+}
+
+// runV1 decodes one run of version 1: a header under 128 is a run of
+// header+3 values from a base varint by a one-byte delta, one above is
+// followed by 256-header varints.
+func (d *orcIntDecoder) runV1(out []int64) ([]int64, error) {
+	h := int(d.b[0])
+	if h < 128 {
+		if len(d.b) < 2 {
+			return nil, errORCCorrupt
+		}
+		delta := int64(int8(d.b[1]))
+		d.b = d.b[2:]
+		base, err := d.varint(d.signed)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < h+3; i++ {
+			out = append(out, base+int64(i)*delta)
+		}
+		return out, nil
+	}
+	d.b = d.b[1:]
+	for i := 0; i < 256-h; i++ {
+		v, err := d.varint(d.signed)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// orcWidths maps RLE v2's five-bit width codes to bit widths.
+var orcWidths = [32]int{
+	1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16,
+	17, 18, 19, 20, 21, 22, 23, 24, 26, 28, 30, 32, 40, 48, 56, 64,
+}
+
+// orcFixedWidth rounds a bit width up to one RLE v2 can code.
+func orcFixedWidth(n int) int {
+	for _, w := range orcWidths {
+		if w >= n {
+			return w
+		}
+	}
+	return 64
+}
+
+// runV2 decodes one run of version 2, whose header's top two bits say which
+// of its four encodings follows.
+func (d *orcIntDecoder) runV2(out []int64) ([]int64, error) {
+	h := d.b[0]
+	switch h >> 6 {
+	case 0: // short repeat: a value of 1-8 bytes, 3-10 times
+		w, count := int(h>>3&7)+1, int(h&7)+3
+		if len(d.b) < 1+w {
+			return nil, errORCCorrupt
+		}
+		var v uint64
+		for _, c := range d.b[1 : 1+w] {
+			v = v<<8 | uint64(c)
+		}
+		d.b = d.b[1+w:]
+		x := int64(v)
+		if d.signed {
+			x = unzigzag(v)
+		}
+		for i := 0; i < count; i++ {
+			out = append(out, x)
+		}
+		return out, nil
+	case 1: // direct: bit-packed values
+		if len(d.b) < 2 {
+			return nil, errORCCorrupt
+		}
+		w, count := orcWidths[h>>1&31], (int(h&1)<<8|int(d.b[1]))+1
+		d.b = d.b[2:]
+		vals, err := d.unpack(count, w)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vals {
+			if d.signed {
+				out = append(out, unzigzag(v))
+			} else {
+				out = append(out, int64(v))
+			}
+		}
+		return out, nil
+	case 2:
+		return d.patchedBase(out)
+	default:
+		return d.delta(out)
+	}
+}
+
+// patchedBase decodes a run of values close to a base but for a few
+// outliers, whose high bits come in a patch list after the packed values.
+func (d *orcIntDecoder) patchedBase(out []int64) ([]int64, error) {
+	if len(d.b) < 4 {
+		return nil, errORCCorrupt
+	}
+	h0, h1, h2, h3 := d.b[0], d.b[1], d.b[2], d.b[3]
+	w, count := orcWidths[h0>>1&31], (int(h0&1)<<8|int(h1))+1
+	bw, pw := int(h2>>5&7)+1, orcWidths[h2&31]
+	pgw, pl := int(h3>>5&7)+1, int(h3&31)
+	d.b = d.b[4:]
+	if len(d.b) < bw || pw+pgw > 64 {
+		return nil, errORCCorrupt
+	}
+	var base uint64
+	for _, c := range d.b[:bw] {
+		base = base<<8 | uint64(c)
+	}
+	d.b = d.b[bw:]
+	// The base is sign and magnitude, the sign its top bit.
+	b := int64(base)
+	if sign := uint64(1) << (bw*8 - 1); base&sign != 0 {
+		b = -int64(base &^ sign)
+	}
+	vals, err := d.unpack(count, w)
+	if err != nil {
+		return nil, err
+	}
+	patches, err := d.unpack(pl, orcFixedWidth(pw+pgw))
+	if err != nil {
+		return nil, err
+	}
+	// Each patch entry is a gap from the last patched value and the bits
+	// above w to give the value at the end of it; a gap over 255 takes
+	// entries of 255 with no patch bits first.
+	next := 0
+	patch := func(at int) (int, uint64, bool) {
+		for next < len(patches) {
+			e := patches[next]
+			next++
+			gap, bits := int(e>>pw), e&(1<<pw-1)
+			at += gap
+			if gap == 255 && bits == 0 {
+				continue
+			}
+			return at, bits, true
+		}
+		return 0, 0, false
+	}
+	at, bits, ok := patch(0)
+	for i, v := range vals {
+		if ok && i == at {
+			v |= bits << w
+			at, bits, ok = patch(at)
+		}
+		out = append(out, b+int64(v))
+	}
+	return out, nil
+}
+
+// delta decodes a run of a base value followed by deltas: one fixed delta,
+// or a first delta whose sign all the bit-packed ones after it share.
+func (d *orcIntDecoder) delta(out []int64) ([]int64, error) {
+	if len(d.b) < 2 {
+		return nil, errORCCorrupt
+	}
+	h := d.b[0]
+	w, count := 0, (int(h&1)<<8|int(d.b[1]))+1
+	if code := h >> 1 & 31; code != 0 {
+		w = orcWidths[code]
+	}
+	d.b = d.b[2:]
+	v, err := d.varint(d.signed)
+	if err != nil {
+		return nil, err
+	}
+	step, err := d.varint(true)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, v)
+	if w == 0 {
+		for i := 1; i < count; i++ {
+			v += step
+			out = append(out, v)
+		}
+		return out, nil
+	}
+	if count < 2 {
+		return nil, errORCCorrupt
+	}
+	v += step
+	out = append(out, v)
+	deltas, err := d.unpack(count-2, w)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range deltas {
+		if step < 0 {
+			v -= int64(x)
+		} else {
+			v += int64(x)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// unpack reads count values of w bits each, big-endian and packed end to
+// end, from the whole bytes they take.
+func (d *orcIntDecoder) unpack(count, w int) ([]uint64, error) {
+	need := (count*w + 7) / 8
+	if need > len(d.b) {
+		return nil, errORCCorrupt
+	}
+	src := d.b[:need]
+	d.b = d.b[need:]
+	out := make([]uint64, count)
+	bit := 0
+	for i := range out {
+		var v uint64
+		for left := w; left > 0; {
+			c := src[bit/8]
+			avail := 8 - bit%8
+			take := min(avail, left)
+			v = v<<take | uint64(c>>(avail-take))&(1<<take-1)
+			bit += take
+			left -= take
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// pbField is one field of a protobuf message: its number, and its value if
+// a number or its bytes if length-delimited.
+type pbField struct {
+	num  protowire.Number
+	wire protowire.Type
+	v    uint64
+	data []byte
+}
+
+// uints reads a repeated unsigned field, packed or not.
+func (fd pbField) uints() ([]uint64, error) {
+	if fd.wire != protowire.BytesType {
+		return []uint64{fd.v}, nil
+	}
+	var out []uint64
+	for b := fd.data; len(b) > 0; {
+		v, k := protowire.ConsumeVarint(b)
+		if k < 0 {
+			return nil, errORCCorrupt
+		}
+		out = append(out, v)
+		b = b[k:]
+	}
+	return out, nil
+}
+
+// pbEach calls f with each field of the protobuf message b, in order.
+func pbEach(b []byte, f func(pbField) error) error {
+	for len(b) > 0 {
+		num, wire, k := protowire.ConsumeTag(b)
+		if k < 0 {
+			return errORCCorrupt
+		}
+		b = b[k:]
+		fd := pbField{num: num, wire: wire}
+		switch wire {
+		case protowire.VarintType:
+			fd.v, k = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			fd.v, k = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			fd.data, k = protowire.ConsumeBytes(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, k = protowire.ConsumeFixed32(b)
+			fd.v = uint64(v)
+		default:
+			return errORCCorrupt
+		}
+		if k < 0 {
+			return errORCCorrupt
+		}
+		b = b[k:]
+		if err := f(fd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
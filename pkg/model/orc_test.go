@@ -0,0 +1,436 @@
+package model
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The run-length examples of the ORC specification.
+func TestORCRunLengths(t *testing.T) {
+	if got, _ := orcBytes([]byte{0x61, 0x00}, 100); !bytes.Equal(got, make([]byte, 100)) {
+		t.Errorf("byte run: %v", got)
+	}
+	if got, _ := orcBytes([]byte{0xfe, 0x44, 0x45}, 2); !bytes.Equal(got, []byte{0x44, 0x45}) {
+		t.Errorf("byte literals: %v", got)
+	}
+	if got, _ := orcBools([]byte{0xff, 0x80}, 8); !reflect.DeepEqual(got, []bool{true, false, false, false, false, false, false, false}) {
+		t.Errorf("booleans: %v", got)
+	}
+
+	repeat := func(v int64, n int) []int64 {
+		out := make([]int64, n)
+		for i := range out {
+			out[i] = v
+		}
+		return out
+	}
+	seq := func(from, to, step int64) []int64 {
+		var out []int64
+		for v := from; v != to+step; v += step {
+			out = append(out, v)
+		}
+		return out
+	}
+	patched := []int64{2030, 2000, 2020, 1000000}
+	patched = append(patched, seq(2040, 2190, 10)...)
+	for _, tc := range []struct {
+		name   string
+		v2     bool
+		signed bool
+		in     []byte
+		want   []int64
+	}{
+		{"v1 literals", false, false, []byte{0xfb, 0x02, 0x03, 0x04, 0x07, 0x0b}, []int64{2, 3, 4, 7, 11}},
+		{"v1 run", false, false, []byte{0x61, 0x00, 0x07}, repeat(7, 100)},
+		{"v1 falling run", false, false, []byte{0x61, 0xff, 0x64}, seq(100, 1, -1)},
+		{"v2 short repeat", true, false, []byte{0x0a, 0x27, 0x10}, repeat(10000, 5)},
+		{"v2 direct", true, false, []byte{0x5e, 0x03, 0x5c, 0xa1, 0xab, 0x1e, 0xde, 0xad, 0xbe, 0xef}, []int64{23713, 43806, 57005, 48879}},
+		{"v2 patched base", true, true, []byte{
+			0x8e, 0x13, 0x2b, 0x21, 0x07, 0xd0, 0x1e, 0x00, 0x14, 0x70, 0x28, 0x32, 0x3c, 0x46, 0x50,
+			0x5a, 0x64, 0x6e, 0x78, 0x82, 0x8c, 0x96, 0xa0, 0xaa, 0xb4, 0xbe, 0xfc, 0xe8,
+		}, patched},
+		{"v2 delta", true, false, []byte{0xc6, 0x09, 0x02, 0x02, 0x22, 0x42, 0x42, 0x46}, []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}},
+	} {
+		got, err := orcInts(tc.in, len(tc.want), tc.signed, tc.v2)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\n got %v\nwant %v", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := orcInts([]byte{0x5e, 0x03, 0x5c}, 4, false, true); err == nil {
+		t.Error("a truncated run decoded")
+	}
+}
+
+func TestORCCodecs(t *testing.T) {
+	chunk := func(data []byte, original bool) []byte {
+		h := len(data) << 1
+		if original {
+			h |= 1
+		}
+		return append([]byte{byte(h), byte(h >> 8), byte(h >> 16)}, data...)
+	}
+
+	snap := []byte{0x0c, 0x08, 'a', 'b', 'c', 0x15, 0x03}
+	zst, _ := base64.StdEncoding.DecodeString(zstdFixtureFast)
+	var zlib bytes.Buffer
+	zw, _ := flate.NewWriter(&zlib, flate.BestCompression)
+	zw.Write([]byte(zstdFixtureText()))
+	zw.Close()
+
+	for _, tc := range []struct {
+		name  string
+		codec uint64
+		in    []byte
+		want  string
+	}{
+		{"snappy", orcCodecSnappy, chunk(snap, false), "abcabcabcabc"},
+		{"snappy chunks", orcCodecSnappy, append(chunk(snap, false), chunk([]byte("xyz"), true)...), "abcabcabcabcxyz"},
+		{"zlib", orcCodecZlib, chunk(zlib.Bytes(), false), zstdFixtureText()},
+		{"zstd", orcCodecZstd, chunk(zst, false), zstdFixtureText()},
+	} {
+		got, err := orcDecompress(tc.codec, orcBlockSize, tc.in)
+		if err != nil || string(got) != tc.want {
+			t.Errorf("%s: %q, %v", tc.name, got, err)
+		}
+	}
+
+	if _, err := orcDecompress(orcCodecZlib, 100, chunk(zlib.Bytes(), false)); err == nil {
+		t.Error("a chunk over the block size decoded")
+	}
+	if _, err := orcDecompress(4, orcBlockSize, chunk([]byte{1}, false)); err == nil {
+		t.Error("LZ4 decoded")
+	}
+	if _, err := snappyDecode([]byte{0x0c, 0x08, 'a', 'b', 'c', 0x15, 0x04}, orcBlockSize); err == nil {
+		t.Error("a snappy copy from before the start decoded")
+	}
+}
+
+// orcWriter builds small ORC files to read back: one struct of columns, in
+// stripes, the integers in run-length literals of version 1 or 2 and the
+// strings direct or by dictionary, compressed with any codec but LZO and LZ4.
+type orcWriter struct {
+	codec uint64
+	v2    bool
+	dict  bool
+	names []string
+	kinds []uint64
+}
+
+// orcRows is one stripe: a value per column per row, nil for a null.
+type orcRows [][]any
+
+func pbVarint(b []byte, num int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(num)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+func pbBytes(b []byte, num int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(num)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func (w *orcWriter) compress(b []byte) []byte {
+	if w.codec == orcCodecNone {
+		return b
+	}
+	var out []byte
+	for len(b) > 0 {
+		n := min(len(b), 1000) // several chunks for a long stream
+		var c []byte
+		switch w.codec {
+		case orcCodecZlib:
+			var buf bytes.Buffer
+			zw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+			zw.Write(b[:n])
+			zw.Close()
+			c = buf.Bytes()
+		case orcCodecSnappy:
+			c = snappy.Encode(nil, b[:n])
+		case orcCodecZstd:
+			enc, _ := zstd.NewWriter(nil)
+			c = enc.EncodeAll(b[:n], nil)
+		}
+		h := len(c) << 1
+		out = append(out, byte(h), byte(h>>8), byte(h>>16))
+		out = append(out, c...)
+		b = b[n:]
+	}
+	return out
+}
+
+func (w *orcWriter) ints(vals []int64, signed bool) []byte {
+	var out []byte
+	for len(vals) > 0 {
+		if w.v2 {
+			n := min(len(vals), 512)
+			out = append(out, 0x40|31<<1|byte((n-1)>>8), byte(n-1))
+			for _, v := range vals[:n] {
+				u := uint64(v)
+				if signed {
+					u = uint64(v<<1 ^ v>>63)
+				}
+				out = binary.BigEndian.AppendUint64(out, u)
+			}
+			vals = vals[n:]
+			continue
+		}
+		n := min(len(vals), 128)
+		out = append(out, byte(256-n))
+		for _, v := range vals[:n] {
+			if signed {
+				out = binary.AppendVarint(out, v)
+			} else {
+				out = binary.AppendUvarint(out, uint64(v))
+			}
+		}
+		vals = vals[n:]
+	}
+	return out
+}
+
+func orcByteLiterals(b []byte) []byte {
+	var out []byte
+	for len(b) > 0 {
+		n := min(len(b), 128)
+		out = append(append(out, byte(256-n)), b[:n]...)
+		b = b[n:]
+	}
+	return out
+}
+
+func orcBoolBits(vals []bool) []byte {
+	bits := make([]byte, (len(vals)+7)/8)
+	for i, v := range vals {
+		if v {
+			bits[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return orcByteLiterals(bits)
+}
+
+// build lays out stripes as a file.
+func (w *orcWriter) build(stripes ...orcRows) []byte {
+	file := []byte(orcMagic)
+	var footer []byte
+	for _, rows := range stripes {
+		var data, sfooter []byte
+		stream := func(kind, col int, b []byte) {
+			b = w.compress(b)
+			data = append(data, b...)
+			sfooter = pbBytes(sfooter, 1, pbVarint(pbVarint(pbVarint(nil, 1, uint64(kind)), 2, uint64(col)), 3, uint64(len(b))))
+		}
+		// An index stream first, which the reader passes over.
+		stream(6, 0, []byte("row index"))
+		index := len(data)
+		encodings := [][]byte{pbVarint(nil, 1, 0)}
+
+		for c, kind := range w.kinds {
+			col := c + 1
+			var present []bool
+			var vals []any
+			hasNull := false
+			for _, row := range rows {
+				present = append(present, row[c] != nil)
+				if row[c] == nil {
+					hasNull = true
+				} else {
+					vals = append(vals, row[c])
+				}
+			}
+			if hasNull {
+				stream(orcPresent, col, orcBoolBits(present))
+			}
+			enc := uint64(orcDirect)
+			switch kind {
+			case orcString:
+				if w.dict {
+					enc = orcDictionary
+					var dict []string
+					at := map[string]int{}
+					var idx, lens []int64
+					var text []byte
+					for _, v := range vals {
+						s := v.(string)
+						if _, ok := at[s]; !ok {
+							at[s] = len(dict)
+							dict = append(dict, s)
+							lens = append(lens, int64(len(s)))
+							text = append(text, s...)
+						}
+						idx = append(idx, int64(at[s]))
+					}
+					stream(orcData, col, w.ints(idx, false))
+					stream(orcDictionaryData, col, text)
+					stream(orcLength, col, w.ints(lens, false))
+					if w.v2 {
+						enc += 2
+					}
+					encodings = append(encodings, pbVarint(pbVarint(nil, 1, enc), 2, uint64(len(dict))))
+					continue
+				}
+				var lens []int64
+				var text []byte
+				for _, v := range vals {
+					lens = append(lens, int64(len(v.(string))))
+					text = append(text, v.(string)...)
+				}
+				stream(orcData, col, text)
+				stream(orcLength, col, w.ints(lens, false))
+			case orcLong:
+				var ints []int64
+				for _, v := range vals {
+					ints = append(ints, v.(int64))
+				}
+				stream(orcData, col, w.ints(ints, true))
+			case orcBoolean:
+				var bools []bool
+				for _, v := range vals {
+					bools = append(bools, v.(bool))
+				}
+				stream(orcData, col, orcBoolBits(bools))
+			case orcTimestamp:
+				var secs, nanos []int64
+				for _, v := range vals {
+					ts := v.(time.Time)
+					secs = append(secs, ts.Unix()-orcEpoch.Unix())
+					ns, zeros := int64(ts.Nanosecond()), int64(0)
+					for ns != 0 && ns%10 == 0 && zeros < 8 {
+						ns /= 10
+						zeros++
+					}
+					if zeros >= 2 {
+						nanos = append(nanos, ns<<3|(zeros-1))
+					} else {
+						for ; zeros > 0; zeros-- {
+							ns *= 10
+						}
+						nanos = append(nanos, ns<<3)
+					}
+				}
+				stream(orcData, col, w.ints(secs, true))
+				stream(orcSecondary, col, w.ints(nanos, false))
+			}
+			if w.v2 {
+				enc += 2
+			}
+			encodings = append(encodings, pbVarint(nil, 1, enc))
+		}
+		for _, e := range encodings {
+			sfooter = pbBytes(sfooter, 2, e)
+		}
+		sfooter = pbBytes(sfooter, 3, []byte("UTC"))
+		sfooter = w.compress(sfooter)
+
+		info := pbVarint(nil, 1, uint64(len(file)))
+		info = pbVarint(info, 2, uint64(index))
+		info = pbVarint(info, 3, uint64(len(data)-index))
+		info = pbVarint(info, 4, uint64(len(sfooter)))
+		info = pbVarint(info, 5, uint64(len(rows)))
+		footer = pbBytes(footer, 3, info)
+		file = append(append(file, data...), sfooter...)
+	}
+
+	root := pbVarint(nil, 1, orcStruct)
+	var sub []byte
+	for i := range w.kinds {
+		sub = binary.AppendUvarint(sub, uint64(i+1))
+	}
+	root = pbBytes(root, 2, sub) // packed
+	for _, name := range w.names {
+		root = pbBytes(root, 3, []byte(name))
+	}
+	footer = pbBytes(footer, 4, root)
+	for _, kind := range w.kinds {
+		footer = pbBytes(footer, 4, pbVarint(nil, 1, kind))
+	}
+	footer = w.compress(footer)
+	file = append(file, footer...)
+
+	ps := pbVarint(nil, 1, uint64(len(footer)))
+	ps = pbVarint(ps, 2, w.codec)
+	ps = pbVarint(ps, 3, 1000)
+	ps = pbBytes(ps, 8000, []byte(orcMagic))
+	return append(append(file, ps...), byte(len(ps)))
+}
+
+// testORCReport is a report of a versioned bucket "big" in two stripes, in
+// which only "a" and "x+y z" are current objects.
+func testORCReport(w orcWriter) []byte {
+	w.names = []string{"bucket", "key", "version_id", "is_latest", "is_delete_marker", "size", "last_modified_date", "e_tag", "storage_class"}
+	w.kinds = []uint64{orcString, orcString, orcString, orcBoolean, orcBoolean, orcLong, orcTimestamp, orcString, orcString}
+	mod := time.Date(2025, 10, 1, 12, 0, 0, 250_000_000, time.UTC)
+	return w.build(orcRows{
+		{"big", "a", "v2", true, false, int64(10), mod, "e1", "STANDARD"},
+		{"big", "a", "v1", false, false, int64(7), mod, "e0", "STANDARD"},
+		{"big", "gone", "v3", true, true, nil, nil, nil, nil},
+	}, orcRows{
+		{"big", "x+y z", nil, nil, nil, int64(3), time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC), "e2", "GLACIER"},
+	})
+}
+
+func TestReadInventoryORC(t *testing.T) {
+	want := []indexEntry{
+		{key: "a", etag: "e1", class: "STANDARD", size: 10, mod: time.Date(2025, 10, 1, 12, 0, 0, 250_000_000, time.UTC).UnixMilli()},
+		{key: "x+y z", etag: "e2", class: "GLACIER", size: 3, mod: time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC).UnixMilli()},
+	}
+	for _, w := range []orcWriter{
+		{codec: orcCodecNone},
+		{codec: orcCodecZlib, v2: true},
+		{codec: orcCodecZlib, dict: true},
+		{codec: orcCodecNone, v2: true, dict: true},
+		{codec: orcCodecSnappy, v2: true},
+		{codec: orcCodecZstd, dict: true},
+	} {
+		file := testORCReport(w)
+		var got []indexEntry
+		if err := readInventoryORC(bytes.NewReader(file), int64(len(file)), func(e indexEntry) { got = append(got, e) }); err != nil {
+			t.Errorf("%+v: %v", w, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%+v:\n got %+v\nwant %+v", w, got, want)
+		}
+	}
+
+	// Only the key is needed.
+	file := (&orcWriter{names: []string{"key"}, kinds: []uint64{orcString}}).build(orcRows{{"k"}})
+	var got []indexEntry
+	if err := readInventoryORC(bytes.NewReader(file), int64(len(file)), func(e indexEntry) { got = append(got, e) }); err != nil || len(got) != 1 || got[0].key != "k" {
+		t.Errorf("key alone: %+v, %v", got, err)
+	}
+
+	file = testORCReport(orcWriter{codec: orcCodecZlib})
+	for _, n := range []int{0, 3, len(file) / 2, len(file) - 1} {
+		if err := readInventoryORC(bytes.NewReader(file[:n]), int64(n), func(indexEntry) {}); err == nil {
+			t.Errorf("the first %d bytes read", n)
+		}
+	}
+	file = (&orcWriter{names: []string{"Key"}, kinds: []uint64{orcString}}).build(orcRows{{"k"}})
+	if err := readInventoryORC(bytes.NewReader(file), int64(len(file)), func(indexEntry) {}); err == nil {
+		t.Error("a file without a key column read")
+	}
+}
+
+func FuzzReadInventoryORC(f *testing.F) {
+	f.Add(testORCReport(orcWriter{codec: orcCodecNone}))
+	f.Add(testORCReport(orcWriter{codec: orcCodecZlib, v2: true, dict: true}))
+	f.Add(testORCReport(orcWriter{codec: orcCodecSnappy, v2: true}))
+	f.Add(testORCReport(orcWriter{codec: orcCodecZstd, dict: true}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		readInventoryORC(bytes.NewReader(data), int64(len(data)), func(indexEntry) {})
+	})
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// clientPool is shared by every model of one profile. It holds one client per
+// region, built on first use and never changed afterwards, a cache of bucket →
+// region lookups, and the profile's folder-listing cache. Handing out a
+// different model, rather than re-pinning one in place, is what lets a
+// running transfer keep the client it started with while the user hops to a
+// bucket in another region.
+type clientPool struct {
+	home *Model
+
+	mu       sync.Mutex
+	regional map[string]*Model
+	located  map[string]string
+
+	lists *listCache
+}
+
+func newClientPool(home *Model) *clientPool {
+	p := &clientPool{home: home, regional: map[string]*Model{}, located: map[string]string{}}
+	if home.Cf != nil {
+		p.lists = newListCache(home.Cf.ListCacheTTL)
+	}
+	return p
+}
+
+// Home returns the model the profile was opened with — the one to use away
+// from any bucket (bucket list, CreateBucket), whichever bucket's client m is.
+func (m *Model) Home() *Model {
+	if m.pool == nil {
+		return m
+	}
+	return m.pool.home
+}
+
+// ForRegion returns the profile's model pinned to region: the receiver or the
+// profile's own model when either already is, otherwise the pool's client for
+// that region, built on first use. No model is ever modified, and all of them
+// share the bandwidth limiter, so a cross-region copy is throttled as one
+// transfer. The result is always a live model, never one browsing an
+// inventory report.
+func (m *Model) ForRegion(region string) (*Model, error) {
+	if pinnedTo(m, region) && m.inv == nil {
+		return m, nil
+	}
+	p := m.pool
+	if p == nil {
+		if m.inv != nil && pinnedTo(m, region) {
+			return m.WithInventory(nil), nil
+		}
+		return m.buildRegional(region)
+	}
+	if pinnedTo(p.home, region) {
+		return p.home, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w, ok := p.regional[region]; ok {
+		return w, nil
+	}
+	w, err := p.home.buildRegional(region)
+	if err != nil {
+		return nil, err
+	}
+	w.pool = p
+	p.regional[region] = w
+	return w, nil
+}
+
+func pinnedTo(m *Model, region string) bool {
+	return m.Cf != nil && m.Cf.Region != nil && *m.Cf.Region == region
+}
+
+// buildRegional builds a fresh model on m's profile pinned to region.
+func (m *Model) buildRegional(region string) (*Model, error) {
+	cf := *m.Cf
+	cf.Region = aws.String(region)
+	cfg, err := GetConfig(cf, true)
+	if err != nil {
+		return nil, fmt.Errorf("building client for region %s: %w", region, err)
+	}
+	client := s3.NewFromConfig(cfg)
+	return &Model{
+		Config:     &cfg,
+		Client:     client,
+		Downloader: GetDownloader(client),
+		Cf:         &cf,
+		Limiter:    m.Limiter,
+	}, nil
+}
+
+// BucketRegion is GetBucketLocation with the profile-wide cache in front of
+// it: a bucket's region is looked up once per session. Failures are not
+// cached, so a transient error or a since-granted permission is retried on
+// the next visit.
+func (m *Model) BucketRegion(name string) (string, error) {
+	p := m.pool
+	if p != nil {
+		p.mu.Lock()
+		region, ok := p.located[name]
+		p.mu.Unlock()
+		if ok {
+			return region, nil
+		}
+	}
+	loc, err := m.GetBucketLocation(aws.String(name))
+	if err != nil {
+		return "", err
+	}
+	if loc == nil {
+		return "", fmt.Errorf("no location reported for %s", name)
+	}
+	if p != nil {
+		p.mu.Lock()
+		p.located[name] = *loc
+		p.mu.Unlock()
+	}
+	return *loc, nil
+}
+
+// ForBucket returns the profile's model for the bucket's region, for browsing
+// it and starting transfers in it. Callers hold on to the result: it is never
+// re-pinned, so whatever runs through it keeps working while other code moves
+// on to other buckets. On a failed lookup it returns the receiver along with
+// the error — still usable, just possibly in the wrong region — rather than
+// guessing one, so a caller can carry on with the result either way; it
+// should still report the error, which is otherwise only seen as a confusing
+// redirect later.
+func (m *Model) ForBucket(name string) (*Model, error) {
+	region, err := m.BucketRegion(name)
+	if err != nil {
+		return m, fmt.Errorf("resolving region of %s: %w", name, err)
+	}
+	w, err := m.ForRegion(region)
+	if err != nil {
+		return m, err
+	}
+	return w, nil
+}
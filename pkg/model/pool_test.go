@@ -0,0 +1,66 @@
+package model
+
+import "testing"
+
+func TestForRegionPooled(t *testing.T) {
+	m := newTestModel(t, NewConfig("https://s3.us-east-1.amazonaws.com", strPtr("us-east-1"), "ak", "sk", "", true, 0))
+
+	eu, err := m.ForRegion("eu-west-1")
+	if err != nil {
+		t.Fatalf("ForRegion: %v", err)
+	}
+	again, _ := m.ForRegion("eu-west-1")
+	if again != eu {
+		t.Error("a region's client must be built once and reused")
+	}
+	// Asking from a regional model goes through the same pool.
+	if w, _ := eu.ForRegion("eu-west-1"); w != eu {
+		t.Error("a model already pinned to the region should return itself")
+	}
+	if w, _ := eu.ForRegion("us-east-1"); w != m {
+		t.Error("the profile's own region should map back to its own model")
+	}
+	if eu.Home() != m || m.Home() != m {
+		t.Error("Home must be the model the profile was opened with")
+	}
+}
+
+func TestForBucketUsesCachedRegion(t *testing.T) {
+	m := newTestModel(t, NewConfig("https://s3.us-east-1.amazonaws.com", strPtr("us-east-1"), "ak", "sk", "", true, 0))
+	// A cached location answers without a GetBucketLocation call; the test
+	// has no network, so a lookup would fail.
+	m.pool.located["logs"] = "ap-south-1"
+
+	w, err := m.ForBucket("logs")
+	if err != nil {
+		t.Fatalf("ForBucket: %v", err)
+	}
+	if got := *w.Cf.Region; got != "ap-south-1" {
+		t.Errorf("bucket client region = %q, want ap-south-1", got)
+	}
+	if got := *m.Cf.Region; got != "us-east-1" {
+		t.Errorf("receiver re-pinned to %q", got)
+	}
+	if w2, _ := w.ForBucket("logs"); w2 != w {
+		t.Error("resolving the same bucket again should hand back the same client")
+	}
+}
+
+func TestBucketRegionCacheSharedAcrossPool(t *testing.T) {
+	m := newTestModel(t, NewConfig("https://s3.us-east-1.amazonaws.com", strPtr("us-east-1"), "ak", "sk", "", true, 0))
+	eu, err := m.ForRegion("eu-west-1")
+	if err != nil {
+		t.Fatalf("ForRegion: %v", err)
+	}
+	m.pool.located["data"] = "eu-west-1"
+	if got, err := eu.BucketRegion("data"); err != nil || got != "eu-west-1" {
+		t.Errorf("BucketRegion via a regional model = %q, %v", got, err)
+	}
+}
+
+func TestHomeWithoutPool(t *testing.T) {
+	m := &Model{}
+	if m.Home() != m {
+		t.Error("a model built without NewModel is its own home")
+	}
+}
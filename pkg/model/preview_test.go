@@ -0,0 +1,47 @@
+package model
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadRange(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		ignoreRange bool
+	}{
+		{"ranged", false},
+		{"range ignored", true},
+	} {
+		fs := newFakeS3(map[string][]byte{"b/log": []byte("0123456789abcdef"), "b/empty": {}})
+		fs.ignoreRange = tc.ignoreRange
+		fs.setType("b/log", "text/plain")
+		fs.setType("b/empty", "text/plain")
+		m := newFakeModel(t, fs)
+		bucket := &Object{Key: strPtr("b")}
+		for _, r := range []struct {
+			key       string
+			off, n    int64
+			want      string
+			wantTotal int64
+		}{
+			{"log", 0, 4, "0123", 16},
+			{"log", 10, 4, "abcd", 16},
+			{"log", 14, 8, "ef", 16},
+			{"log", 16, 8, "", 16},
+			{"empty", 0, 8, "", 0},
+		} {
+			got, err := m.ReadRange(context.Background(), bucket, r.key, r.off, r.n)
+			if err != nil {
+				t.Errorf("%s: %s@%d: %v", tc.name, r.key, r.off, err)
+				continue
+			}
+			if string(got.Data) != r.want || got.Total != r.wantTotal {
+				t.Errorf("%s: %s@%d+%d = %q of %d, want %q of %d", tc.name, r.key, r.off, r.n, got.Data, got.Total, r.want, r.wantTotal)
+			}
+			if tc.name == "ranged" && got.ContentType != "text/plain" {
+				t.Errorf("%s: %s@%d: content type %q", tc.name, r.key, r.off, got.ContentType)
+			}
+		}
+	}
+}
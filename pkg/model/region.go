@@ -0,0 +1,114 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/aws/smithy-go"
+)
+
+// regionLookupWorkers bounds the concurrent GetBucketLocation calls the bucket
+// picker makes to label its entries.
+const regionLookupWorkers = 8
+
+// onAWS reports whether the profile talks to AWS itself. Only there do buckets
+// live in regions that need their own client; a custom endpoint (MinIO, Ceph)
+// serves every bucket from the one URL, and GetConfig ignores the region for
+// it anyway.
+func (m *Model) onAWS() bool {
+	return m.Cf != nil && strings.Contains(m.Cf.Url, "amazonaws.com")
+}
+
+// forBucket returns a model whose client can write to bucket: the receiver
+// itself when the endpoint isn't AWS, otherwise the profile's model for the
+// bucket's region (which is the receiver when it is already pinned there).
+func (m *Model) forBucket(bucket *Object) (*Model, error) {
+	if bucket == nil || bucket.Key == nil {
+		return nil, errors.New("bucket is nil")
+	}
+	if !m.onAWS() {
+		return m, nil
+	}
+	w, err := m.ForBucket(*bucket.Key)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// BucketRegions looks up the region of each named bucket, for labelling a
+// bucket picker. Buckets whose lookup fails are left out rather than failing
+// the whole call; on a non-AWS endpoint the map is empty, since a region there
+// says nothing about where the bucket is reachable.
+func (m *Model) BucketRegions(names []string) map[string]string {
+	out := make(map[string]string, len(names))
+	if !m.onAWS() {
+		return out
+	}
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, regionLookupWorkers)
+	)
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			region, err := m.BucketRegion(name)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			out[name] = region
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+	return out
+}
+
+// serverCopyRefused reports whether a server-side copy failed because the
+// service can't perform it between these two buckets — an S3-compatible
+// backend without cross-region copy, an opt-in region the source isn't
+// reachable from — as opposed to an ordinary failure (missing key, denied
+// write) that streaming through the client would hit just the same.
+func serverCopyRefused(err error) bool {
+	var api smithy.APIError
+	if !errors.As(err, &api) {
+		return false
+	}
+	switch api.ErrorCode() {
+	case "NotImplemented", "PermanentRedirect", "AuthorizationHeaderMalformed",
+		"IllegalLocationConstraintException", "CrossLocationLoggingProhibited":
+		return true
+	}
+	return false
+}
+
+// copyVia copies one object from srcBucket (reachable through m) into
+// dstBucket through w, the destination-region model. When w is m this is the
+// ordinary server-side copy. Across regions the copy is still server-side —
+// issued in the destination's region, with the source's attributes read
+// through m — and falls back to streaming the bytes through this process
+// when the service refuses it.
+func (m *Model) copyVia(ctx context.Context, w *Model, srcBucket, dstBucket *Object, srcKey, dstKey string, size int64) error {
+	if w == m {
+		return m.CopyObjectSized(ctx, srcBucket, dstBucket, srcKey, dstKey, size)
+	}
+	err := w.runCopy(ctx, copySpec{
+		srcBucket: *srcBucket.Key,
+		srcKey:    srcKey,
+		dstBucket: *dstBucket.Key,
+		dstKey:    dstKey,
+		srcSize:   size,
+		srcClient: m.Client,
+	})
+	if err == nil || !serverCopyRefused(err) {
+		return err
+	}
+	return CrossCopy(ctx, m, srcBucket, srcKey, w, dstBucket, dstKey, nil)
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+func TestServerCopyRefused(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"backend without cross-region copy", &smithy.GenericAPIError{Code: "NotImplemented"}, true},
+		{"wrong-region redirect", fmt.Errorf("copy: %w", &smithy.GenericAPIError{Code: "PermanentRedirect"}), true},
+		{"signing region mismatch", &smithy.GenericAPIError{Code: "AuthorizationHeaderMalformed"}, true},
+		// Ordinary failures would fail a streamed copy too; retrying them that
+		// way would only double the time to the same error.
+		{"denied", &smithy.GenericAPIError{Code: "AccessDenied"}, false},
+		{"missing source", &smithy.GenericAPIError{Code: "NoSuchKey"}, false},
+		{"not an API error", errors.New("connection reset"), false},
+		{"nil", nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := serverCopyRefused(tc.err); got != tc.want {
+				t.Errorf("serverCopyRefused(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestForRegion(t *testing.T) {
+	region := "us-east-1"
+	m := newTestModel(t, NewConfig("https://s3.us-east-1.amazonaws.com", &region, "ak", "sk", "", true, 1<<20))
+
+	w, err := m.ForRegion("eu-west-1")
+	if err != nil {
+		t.Fatalf("ForRegion: %v", err)
+	}
+	if w == m || w.Client == m.Client {
+		t.Fatal("ForRegion must build a new model and client")
+	}
+	if got := *w.Cf.Region; got != "eu-west-1" {
+		t.Errorf("new model region = %q, want eu-west-1", got)
+	}
+	// The receiver is left alone: a running transfer holding it must not have
+	// its region changed underneath it.
+	if got := *m.Cf.Region; got != "us-east-1" {
+		t.Errorf("receiver region changed to %q", got)
+	}
+	if w.Limiter != m.Limiter {
+		t.Error("the regional model must share the profile's bandwidth limiter")
+	}
+}
+
+func TestForBucketOffAWS(t *testing.T) {
+	region := "us-east-1"
+	m := newTestModel(t, NewConfig("https://minio.example.com", &region, "ak", "sk", "", true, 0))
+
+	// A custom endpoint serves every bucket itself: no lookup, same model.
+	w, err := m.forBucket(&Object{Key: strPtr("other")})
+	if err != nil || w != m {
+		t.Errorf("forBucket off AWS = %p, %v; want the receiver", w, err)
+	}
+	if _, err := m.forBucket(nil); err == nil {
+		t.Error("forBucket(nil) should fail")
+	}
+	if got := m.BucketRegions([]string{"a", "b"}); len(got) != 0 {
+		t.Errorf("BucketRegions off AWS = %v, want empty", got)
+	}
+}
+
+func TestCopySpecSourceClient(t *testing.T) {
+	m := newTestModel(t, NewConfig("https://minio.example.com", strPtr("us-east-1"), "ak", "sk", "", true, 0))
+	if got := (copySpec{}).sourceClient(m); got != m.Client {
+		t.Error("a spec without srcClient should read through the copying model")
+	}
+	other := &s3.Client{}
+	if got := (copySpec{srcClient: other}).sourceClient(m); got != other {
+		t.Error("srcClient should be used to read the source when set")
+	}
+}
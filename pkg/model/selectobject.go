@@ -0,0 +1,269 @@
+package model
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// Input formats of an S3 Select query.
+const (
+	SelectCSV          = "CSV"
+	SelectJSONLines    = "JSON lines"
+	SelectJSONDocument = "JSON document"
+	SelectParquet      = "Parquet"
+)
+
+// SelectFormats lists the input formats, in the order a form offers them.
+var SelectFormats = []string{SelectCSV, SelectJSONLines, SelectJSONDocument, SelectParquet}
+
+// SelectCompressions lists the input compressions S3 Select understands.
+var SelectCompressions = []string{string(s3t.CompressionTypeNone), string(s3t.CompressionTypeGzip), string(s3t.CompressionTypeBzip2)}
+
+// SelectInput describes a query and how to read the object it runs against.
+type SelectInput struct {
+	Expression  string
+	Format      string // one of SelectFormats
+	Header      bool   // CSV: the first line names the columns
+	Delimiter   string // CSV field delimiter; "" = ","
+	Compression string // one of SelectCompressions; "" = NONE
+}
+
+// serialization is the input as SelectObjectContent takes it.
+func (in SelectInput) serialization() *s3t.InputSerialization {
+	ser := &s3t.InputSerialization{CompressionType: s3t.CompressionType(in.Compression)}
+	if in.Compression == "" {
+		ser.CompressionType = s3t.CompressionTypeNone
+	}
+	switch in.Format {
+	case SelectJSONLines:
+		ser.JSON = &s3t.JSONInput{Type: s3t.JSONTypeLines}
+	case SelectJSONDocument:
+		ser.JSON = &s3t.JSONInput{Type: s3t.JSONTypeDocument}
+	case SelectParquet:
+		ser.Parquet = &s3t.ParquetInput{}
+	default:
+		ser.CSV = &s3t.CSVInput{FileHeaderInfo: s3t.FileHeaderInfoNone, FieldDelimiter: aws.String(in.delimiter())}
+		if in.Header {
+			ser.CSV.FileHeaderInfo = s3t.FileHeaderInfoUse
+		}
+	}
+	return ser
+}
+
+func (in SelectInput) delimiter() string {
+	if in.Delimiter == "" {
+		return ","
+	}
+	return in.Delimiter
+}
+
+// SelectFormatFor guesses the format and compression of key from its name:
+// .csv/.tsv, .json/.jsonl/.ndjson and .parquet, under an optional .gz or .bz2.
+// Anything else is taken for CSV.
+func SelectFormatFor(key string) (format, compression, delimiter string) {
+	k := strings.ToLower(key)
+	compression = string(s3t.CompressionTypeNone)
+	switch {
+	case strings.HasSuffix(k, ".gz"):
+		compression, k = string(s3t.CompressionTypeGzip), strings.TrimSuffix(k, ".gz")
+	case strings.HasSuffix(k, ".bz2"):
+		compression, k = string(s3t.CompressionTypeBzip2), strings.TrimSuffix(k, ".bz2")
+	}
+	switch {
+	case strings.HasSuffix(k, ".parquet"):
+		return SelectParquet, string(s3t.CompressionTypeNone), ","
+	case strings.HasSuffix(k, ".json"):
+		return SelectJSONDocument, compression, ","
+	case strings.HasSuffix(k, ".jsonl"), strings.HasSuffix(k, ".ndjson"):
+		return SelectJSONLines, compression, ","
+	case strings.HasSuffix(k, ".tsv"):
+		return SelectCSV, compression, "\t"
+	}
+	return SelectCSV, compression, ","
+}
+
+// selectRefused reports whether a SelectObjectContent failed because the
+// endpoint doesn't offer it — MinIO does, most other S3-compatible services
+// don't — rather than because of the query or the object.
+func selectRefused(err error) bool {
+	var re *awshttp.ResponseError
+	if errors.As(err, &re) {
+		switch re.HTTPStatusCode() {
+		case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+			return true
+		}
+	}
+	var api smithy.APIError
+	if errors.As(err, &api) {
+		switch api.ErrorCode() {
+		case "NotImplemented", "XNotImplemented", "MethodNotAllowed", "UnsupportedOperation":
+			return true
+		}
+	}
+	return false
+}
+
+// SelectObject runs in.Expression against key in bucket and passes each
+// result row to emit until it returns false. It asks the endpoint first
+// (SelectObjectContent); when the endpoint has no S3 Select, CSV and JSON
+// objects are streamed down and queried here instead, with the SQL subset
+// parseSelect understands, and local reports that. Parquet needs the server.
+func (m *Model) SelectObject(ctx context.Context, bucket *Object, key string, in SelectInput, emit func(SelectRecord) bool) (local bool, err error) {
+	if bucket == nil || bucket.Key == nil {
+		return false, fmt.Errorf("bucket is nil")
+	}
+	out, err := m.Client.SelectObjectContent(ctx, &s3.SelectObjectContentInput{
+		Bucket:              aws.String(*bucket.Key),
+		Key:                 aws.String(key),
+		Expression:          aws.String(in.Expression),
+		ExpressionType:      s3t.ExpressionTypeSql,
+		InputSerialization:  in.serialization(),
+		OutputSerialization: &s3t.OutputSerialization{JSON: &s3t.JSONOutput{RecordDelimiter: aws.String("\n")}},
+	})
+	if err != nil {
+		if !selectRefused(err) {
+			return false, err
+		}
+		if in.Format == SelectParquet {
+			return true, fmt.Errorf("this endpoint has no S3 Select, and Parquet can't be queried locally: %w", err)
+		}
+		return true, m.selectLocal(ctx, *bucket.Key, key, in, emit)
+	}
+	stream := out.GetStream()
+	defer stream.Close()
+
+	var buf []byte
+	for ev := range stream.Events() {
+		rec, ok := ev.(*s3t.SelectObjectContentEventStreamMemberRecords)
+		if !ok {
+			continue // progress, stats, continuation, end
+		}
+		buf = append(buf, rec.Value.Payload...)
+		for {
+			i := bytes.IndexByte(buf, '\n')
+			if i < 0 {
+				break
+			}
+			line := buf[:i]
+			buf = buf[i+1:]
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			r, err := parseJSONRecord(line)
+			if err != nil {
+				return false, fmt.Errorf("reading the result: %w", err)
+			}
+			if !emit(r) {
+				return false, nil
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return false, err
+	}
+	if len(bytes.TrimSpace(buf)) > 0 {
+		r, err := parseJSONRecord(buf)
+		if err != nil {
+			return false, fmt.Errorf("reading the result: %w", err)
+		}
+		emit(r)
+	}
+	return false, nil
+}
+
+// selectLocal is SelectObject for an endpoint without S3 Select: the query is
+// parsed first, so a query outside the subset costs no download, then the
+// object is streamed through it and only matching rows are kept.
+func (m *Model) selectLocal(ctx context.Context, bucket, key string, in SelectInput, emit func(SelectRecord) bool) error {
+	st, err := parseSelect(in.Expression)
+	if err != nil {
+		return fmt.Errorf("evaluating locally: %w", err)
+	}
+	out, err := m.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return err
+	}
+	defer out.Body.Close()
+	body, err := decompressed(&progressReader{r: out.Body, update: func(int64, int64) {}, limiter: m.Limiter}, in.Compression)
+	if err != nil {
+		return err
+	}
+	next, err := in.records(body)
+	if err != nil {
+		return err
+	}
+	return st.run(next, emit)
+}
+
+// decompressed wraps r for the given S3 Select compression type.
+func decompressed(r io.Reader, compression string) (io.Reader, error) {
+	switch s3t.CompressionType(compression) {
+	case s3t.CompressionTypeGzip:
+		return gzip.NewReader(r)
+	case s3t.CompressionTypeBzip2:
+		return bzip2.NewReader(r), nil
+	}
+	return r, nil
+}
+
+// records returns an iterator over the records of r in the input's format:
+// CSV rows as objects keyed by the header (or _1, _2, …), JSON values as
+// decoded. It ends with io.EOF.
+func (in SelectInput) records(r io.Reader) (func() (any, error), error) {
+	switch in.Format {
+	case SelectJSONLines, SelectJSONDocument:
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		return func() (any, error) { return decodeJSON(dec) }, nil
+	}
+	delim, size := utf8.DecodeRuneInString(in.delimiter())
+	if size != len(in.delimiter()) {
+		return nil, fmt.Errorf("the CSV delimiter must be one character, not %q", in.Delimiter)
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	var header []string
+	if in.Header {
+		h, err := cr.Read()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		header = append(header, h...)
+	}
+	return func() (any, error) {
+		rec, err := cr.Read()
+		if err != nil {
+			return nil, err
+		}
+		row := &jsonObject{keys: make([]string, len(rec)), vals: make(map[string]any, len(rec))}
+		for i, v := range rec {
+			name := fmt.Sprintf("_%d", i+1)
+			if i < len(header) && header[i] != "" {
+				if _, dup := row.vals[header[i]]; !dup {
+					name = header[i]
+				}
+			}
+			row.keys[i] = name
+			row.vals[name] = v
+		}
+		return row, nil
+	}, nil
+}
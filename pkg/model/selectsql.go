@@ -0,0 +1,999 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file is the client-side stand-in for S3 Select: a small evaluator of
+// the subset of its SQL that console queries use, run over a streamed GET when
+// the endpoint has no SelectObjectContent. It covers
+//
+//	SELECT * | expr [AS name], … | COUNT(*), SUM(expr), MIN, MAX, AVG
+//	FROM S3Object[[*]] [[AS] alias]
+//	[WHERE cond] [LIMIT n]
+//
+// where an expr is a path (s.name, s."Quoted Name", s._3, s.a.b[0]), a
+// 'string' or number literal, LOWER/UPPER/TRIM/CHAR_LENGTH(expr) or
+// CAST(expr AS type), and a cond combines comparisons (= != <> < <= > >=),
+// [NOT] LIKE, IS [NOT] NULL and [NOT] IN (…) with AND, OR, NOT and
+// parentheses. Comparisons are numeric when both sides read as numbers — more
+// lenient than S3, which wants a CAST for CSV text — and false when either
+// side is missing. Anything outside the subset is a parse error naming it,
+// never a silently different answer.
+
+// SelectField is one column of a query result.
+type SelectField struct {
+	Name  string
+	Value string
+}
+
+// SelectRecord is one result row, columns in the order the query produced
+// them.
+type SelectRecord []SelectField
+
+// jsonObject is a JSON object that remembers its key order, so "SELECT *"
+// lists a record's fields the way the document wrote them.
+type jsonObject struct {
+	keys []string
+	vals map[string]any
+}
+
+// get looks name up: exactly when quoted, else case-insensitively. "_N" falls
+// back to the N-th field, which is how S3 Select addresses CSV columns.
+func (o *jsonObject) get(name string, quoted bool) (any, bool) {
+	if v, ok := o.vals[name]; ok {
+		return v, true
+	}
+	if !quoted {
+		for _, k := range o.keys {
+			if strings.EqualFold(k, name) {
+				return o.vals[k], true
+			}
+		}
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(name, "_")); err == nil && strings.HasPrefix(name, "_") && n >= 1 && n <= len(o.keys) {
+		return o.vals[o.keys[n-1]], true
+	}
+	return nil, false
+}
+
+// decodeJSON reads the next JSON value from dec, objects as *jsonObject and
+// numbers as json.Number. dec must have UseNumber set.
+func decodeJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			o := &jsonObject{vals: map[string]any{}}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				k, _ := kt.(string)
+				v, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				if _, dup := o.vals[k]; !dup {
+					o.keys = append(o.keys, k)
+				}
+				o.vals[k] = v
+			}
+			_, err := dec.Token() // '}'
+			return o, err
+		case '[':
+			arr := []any{}
+			for dec.More() {
+				v, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+			_, err := dec.Token() // ']'
+			return arr, err
+		}
+		return nil, fmt.Errorf("unexpected %v", t)
+	default:
+		return tok, nil
+	}
+}
+
+// encodeJSON writes v compactly, objects in their own key order.
+func encodeJSON(buf *bytes.Buffer, v any) {
+	switch t := v.(type) {
+	case *jsonObject:
+		buf.WriteByte('{')
+		for i, k := range t.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			kb, _ := json.Marshal(k)
+			buf.Write(kb)
+			buf.WriteByte(':')
+			encodeJSON(buf, t.vals[k])
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, e := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodeJSON(buf, e)
+		}
+		buf.WriteByte(']')
+	default:
+		b, _ := json.Marshal(t)
+		buf.Write(b)
+	}
+}
+
+// valueText renders a value for a result cell: scalars as themselves, missing
+// and null as "", objects and arrays as compact JSON.
+func valueText(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		var buf bytes.Buffer
+		encodeJSON(&buf, t)
+		return buf.String()
+	}
+}
+
+// recordOf flattens a top-level result value into fields.
+func recordOf(v any) SelectRecord {
+	if o, ok := v.(*jsonObject); ok {
+		rec := make(SelectRecord, 0, len(o.keys))
+		for _, k := range o.keys {
+			rec = append(rec, SelectField{Name: k, Value: valueText(o.vals[k])})
+		}
+		return rec
+	}
+	return SelectRecord{{Name: "_1", Value: valueText(v)}}
+}
+
+// parseJSONRecord decodes one line of S3 Select's JSON output.
+func parseJSONRecord(line []byte) (SelectRecord, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	v, err := decodeJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	return recordOf(v), nil
+}
+
+// --- lexer ---
+
+type sqlTokKind int
+
+const (
+	tokEOF    sqlTokKind = iota
+	tokIdent             // name or keyword
+	tokQuoted            // "quoted identifier"
+	tokString            // 'string literal'
+	tokNumber
+	tokPunct // operators and ( ) , . [ ] *
+)
+
+type sqlTok struct {
+	kind sqlTokKind
+	text string
+}
+
+func lexSQL(s string) ([]sqlTok, error) {
+	var toks []sqlTok
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(rs); j++ {
+				if rs[j] == r {
+					if j+1 < len(rs) && rs[j+1] == r { // doubled quote escapes itself
+						b.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("unterminated %c", r)
+			}
+			kind := tokString
+			if r == '"' {
+				kind = tokQuoted
+			}
+			toks = append(toks, sqlTok{kind, b.String()})
+			i = j + 1
+		case unicode.IsDigit(r) || r == '-' && i+1 < len(rs) && unicode.IsDigit(rs[i+1]) && !lastIsOperand(toks):
+			j := i + 1
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.' || rs[j] == 'e' || rs[j] == 'E') {
+				j++
+			}
+			toks = append(toks, sqlTok{tokNumber, string(rs[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_') {
+				j++
+			}
+			toks = append(toks, sqlTok{tokIdent, string(rs[i:j])})
+			i = j
+		default:
+			op := string(r)
+			if i+1 < len(rs) {
+				if two := string(rs[i : i+2]); two == "<=" || two == ">=" || two == "<>" || two == "!=" {
+					op = two
+				}
+			}
+			if !strings.Contains("=<>!(),.[]*", op[:1]) || op == "!" {
+				return nil, fmt.Errorf("unexpected %q", op)
+			}
+			toks = append(toks, sqlTok{tokPunct, op})
+			i += len([]rune(op))
+		}
+	}
+	return append(toks, sqlTok{kind: tokEOF}), nil
+}
+
+// lastIsOperand reports whether the token before a "-" ends an operand, in
+// which case the "-" can't start a negative number. The subset has no
+// arithmetic, so this only keeps "-1" after an operator a literal.
+func lastIsOperand(toks []sqlTok) bool {
+	if len(toks) == 0 {
+		return false
+	}
+	t := toks[len(toks)-1]
+	return t.kind != tokPunct || t.text == ")" || t.text == "]"
+}
+
+// --- AST ---
+
+type sqlExpr interface {
+	eval(row any) any
+}
+
+type sqlLit struct{ v any }
+
+func (e sqlLit) eval(any) any { return e.v }
+
+type pathStep struct {
+	name   string
+	quoted bool
+	index  int // array index; -1 for a name
+}
+
+type sqlPath struct{ steps []pathStep }
+
+func (e sqlPath) eval(row any) any {
+	v := row
+	for _, s := range e.steps {
+		switch t := v.(type) {
+		case *jsonObject:
+			if s.index >= 0 {
+				return nil
+			}
+			var ok bool
+			if v, ok = t.get(s.name, s.quoted); !ok {
+				return nil
+			}
+		case []any:
+			if s.index < 0 || s.index >= len(t) {
+				return nil
+			}
+			v = t[s.index]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+type sqlCmp struct {
+	op   string
+	l, r sqlExpr
+}
+
+func (e sqlCmp) eval(row any) any {
+	a, b := e.l.eval(row), e.r.eval(row)
+	if a == nil || b == nil {
+		return false
+	}
+	c, ok := compareValues(a, b)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=", "<>":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+type sqlLogic struct {
+	and  bool
+	l, r sqlExpr
+}
+
+func (e sqlLogic) eval(row any) any {
+	if e.and {
+		return truthy(e.l.eval(row)) && truthy(e.r.eval(row))
+	}
+	return truthy(e.l.eval(row)) || truthy(e.r.eval(row))
+}
+
+type sqlNot struct{ e sqlExpr }
+
+func (e sqlNot) eval(row any) any { return !truthy(e.e.eval(row)) }
+
+type sqlLike struct {
+	e   sqlExpr
+	re  *regexp.Regexp
+	not bool
+}
+
+func (e sqlLike) eval(row any) any {
+	v := e.e.eval(row)
+	if v == nil {
+		return false
+	}
+	return e.re.MatchString(valueText(v)) != e.not
+}
+
+type sqlIsNull struct {
+	e   sqlExpr
+	not bool
+}
+
+func (e sqlIsNull) eval(row any) any { return (e.e.eval(row) == nil) != e.not }
+
+type sqlIn struct {
+	e    sqlExpr
+	list []sqlExpr
+	not  bool
+}
+
+func (e sqlIn) eval(row any) any {
+	v := e.e.eval(row)
+	if v == nil {
+		return false
+	}
+	for _, x := range e.list {
+		if c, ok := compareValues(v, x.eval(row)); ok && c == 0 {
+			return !e.not
+		}
+	}
+	return e.not
+}
+
+type sqlFunc struct {
+	name string
+	arg  sqlExpr
+}
+
+func (e sqlFunc) eval(row any) any {
+	v := e.arg.eval(row)
+	if v == nil {
+		return nil
+	}
+	s := valueText(v)
+	switch e.name {
+	case "LOWER":
+		return strings.ToLower(s)
+	case "UPPER":
+		return strings.ToUpper(s)
+	case "TRIM":
+		return strings.TrimSpace(s)
+	case "CHAR_LENGTH", "CHARACTER_LENGTH":
+		return float64(len([]rune(s)))
+	case "INT", "INTEGER", "BIGINT":
+		if f, ok := toNumber(v); ok {
+			return math.Trunc(f)
+		}
+		return nil
+	case "FLOAT", "DECIMAL", "NUMERIC", "DOUBLE":
+		if f, ok := toNumber(v); ok {
+			return f
+		}
+		return nil
+	case "BOOL", "BOOLEAN":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil
+		}
+		return b
+	default: // CAST to STRING and friends
+		return s
+	}
+}
+
+func truthy(v any) bool {
+	b, ok := v.(bool)
+	if ok {
+		return b
+	}
+	s, ok := v.(string)
+	return ok && strings.EqualFold(s, "true")
+}
+
+func toNumber(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// compareValues orders a and b: numerically when both read as numbers, else
+// as text. ok is false for values that don't compare (objects, arrays).
+func compareValues(a, b any) (int, bool) {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	for _, v := range []any{a, b} {
+		switch v.(type) {
+		case *jsonObject, []any:
+			return 0, false
+		}
+	}
+	return strings.Compare(valueText(a), valueText(b)), true
+}
+
+// --- statement ---
+
+type selectItem struct {
+	expr sqlExpr
+	name string
+	agg  string // COUNT, SUM, MIN, MAX, AVG; "" for a plain column
+	star bool   // COUNT(*)
+}
+
+type selectStmt struct {
+	star    bool // SELECT *
+	items   []selectItem
+	each    bool // FROM S3Object[*]: a top-level array is a list of records
+	where   sqlExpr
+	limit   int // < 0: none
+	aggrows bool
+}
+
+type sqlParser struct {
+	toks  []sqlTok
+	pos   int
+	alias string
+}
+
+func (p *sqlParser) peek() sqlTok { return p.toks[p.pos] }
+
+// next consumes a token; at the end it keeps returning EOF.
+func (p *sqlParser) next() sqlTok {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports (and consumes) the next token when it is the keyword kw.
+func (p *sqlParser) keyword(kw string) bool {
+	if t := p.peek(); t.kind == tokIdent && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) punct(s string) bool {
+	if t := p.peek(); t.kind == tokPunct && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expect(s string) error {
+	if p.punct(s) || p.keyword(s) {
+		return nil
+	}
+	return p.unexpected("expected " + s)
+}
+
+func (p *sqlParser) unexpected(what string) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return fmt.Errorf("%s at the end of the query", what)
+	}
+	return fmt.Errorf("%s, found %q", what, t.text)
+}
+
+var sqlReserved = map[string]bool{"FROM": true, "WHERE": true, "LIMIT": true, "AND": true, "OR": true, "NOT": true,
+	"AS": true, "LIKE": true, "IS": true, "IN": true, "NULL": true, "SELECT": true}
+
+// parseSelect parses a query in the subset above.
+func parseSelect(query string) (*selectStmt, error) {
+	toks, err := lexSQL(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &sqlParser{toks: toks}
+	st := &selectStmt{limit: -1}
+	if !p.keyword("SELECT") {
+		return nil, p.unexpected("expected SELECT")
+	}
+	if p.punct("*") {
+		st.star = true
+	} else {
+		for {
+			it, err := p.selectItem(len(st.items) + 1)
+			if err != nil {
+				return nil, err
+			}
+			st.items = append(st.items, it)
+			if !p.punct(",") {
+				break
+			}
+		}
+	}
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokIdent || !strings.EqualFold(t.text, "S3Object") {
+		return nil, fmt.Errorf("only FROM S3Object is supported, found %q", t.text)
+	}
+	if p.punct("[") {
+		if !p.punct("*") || !p.punct("]") {
+			return nil, fmt.Errorf("only S3Object[*] is supported after FROM when evaluated locally")
+		}
+		st.each = true
+	}
+	if p.punct(".") {
+		return nil, fmt.Errorf("paths after S3Object in FROM are not supported when evaluated locally")
+	}
+	p.keyword("AS")
+	if t := p.peek(); t.kind == tokIdent && !sqlReserved[strings.ToUpper(t.text)] {
+		p.alias = p.next().text
+	}
+	if p.keyword("WHERE") {
+		if st.where, err = p.orExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.keyword("LIMIT") {
+		t := p.next()
+		n, err := strconv.Atoi(t.text)
+		if t.kind != tokNumber || err != nil || n < 0 {
+			return nil, fmt.Errorf("LIMIT needs a whole number, found %q", t.text)
+		}
+		st.limit = n
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected("expected the end of the query")
+	}
+
+	// Paths were parsed before the alias was known; drop it from them now.
+	aggs := 0
+	for i := range st.items {
+		st.items[i].expr = p.unalias(st.items[i].expr)
+		if st.items[i].agg != "" {
+			aggs++
+		}
+	}
+	st.where = p.unalias(st.where)
+	if aggs > 0 && aggs != len(st.items) {
+		return nil, fmt.Errorf("aggregates can't be mixed with plain columns (there is no GROUP BY)")
+	}
+	st.aggrows = aggs > 0
+	return st, nil
+}
+
+func (p *sqlParser) selectItem(pos int) (selectItem, error) {
+	var it selectItem
+	if t := p.peek(); t.kind == tokIdent && p.toks[p.pos+1].text == "(" {
+		switch fn := strings.ToUpper(t.text); fn {
+		case "COUNT", "SUM", "MIN", "MAX", "AVG":
+			p.pos += 2
+			it.agg = fn
+			if fn == "COUNT" && p.punct("*") {
+				it.star = true
+			} else {
+				e, err := p.value()
+				if err != nil {
+					return it, err
+				}
+				it.expr = e
+			}
+			if err := p.expect(")"); err != nil {
+				return it, err
+			}
+		}
+	}
+	if it.agg == "" {
+		e, err := p.value()
+		if err != nil {
+			return it, err
+		}
+		it.expr = e
+	}
+	it.name = fmt.Sprintf("_%d", pos)
+	if path, ok := it.expr.(sqlPath); ok && it.agg == "" && len(path.steps) > 0 && path.steps[len(path.steps)-1].index < 0 {
+		it.name = path.steps[len(path.steps)-1].name
+	}
+	if p.keyword("AS") {
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokQuoted {
+			return it, fmt.Errorf("AS needs a name, found %q", t.text)
+		}
+		it.name = t.text
+	} else if t := p.peek(); t.kind == tokQuoted || t.kind == tokIdent && !sqlReserved[strings.ToUpper(t.text)] {
+		it.name = p.next().text
+	}
+	return it, nil
+}
+
+// unalias strips a leading alias step from every path in e.
+func (p *sqlParser) unalias(e sqlExpr) sqlExpr {
+	switch t := e.(type) {
+	case sqlPath:
+		if p.alias != "" && len(t.steps) > 0 && t.steps[0].index < 0 && strings.EqualFold(t.steps[0].name, p.alias) {
+			return sqlPath{steps: t.steps[1:]}
+		}
+		return t
+	case sqlCmp:
+		return sqlCmp{t.op, p.unalias(t.l), p.unalias(t.r)}
+	case sqlLogic:
+		return sqlLogic{t.and, p.unalias(t.l), p.unalias(t.r)}
+	case sqlNot:
+		return sqlNot{p.unalias(t.e)}
+	case sqlLike:
+		return sqlLike{p.unalias(t.e), t.re, t.not}
+	case sqlIsNull:
+		return sqlIsNull{p.unalias(t.e), t.not}
+	case sqlIn:
+		list := make([]sqlExpr, len(t.list))
+		for i, x := range t.list {
+			list[i] = p.unalias(x)
+		}
+		return sqlIn{p.unalias(t.e), list, t.not}
+	case sqlFunc:
+		return sqlFunc{t.name, p.unalias(t.arg)}
+	}
+	return e
+}
+
+func (p *sqlParser) orExpr() (sqlExpr, error) {
+	l, err := p.andExpr()
+	for err == nil && p.keyword("OR") {
+		var r sqlExpr
+		if r, err = p.andExpr(); err == nil {
+			l = sqlLogic{false, l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *sqlParser) andExpr() (sqlExpr, error) {
+	l, err := p.notExpr()
+	for err == nil && p.keyword("AND") {
+		var r sqlExpr
+		if r, err = p.notExpr(); err == nil {
+			l = sqlLogic{true, l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *sqlParser) notExpr() (sqlExpr, error) {
+	if p.keyword("NOT") {
+		e, err := p.notExpr()
+		return sqlNot{e}, err
+	}
+	return p.predicate()
+}
+
+func (p *sqlParser) predicate() (sqlExpr, error) {
+	l, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokPunct {
+		switch t.text {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.pos++
+			r, err := p.value()
+			return sqlCmp{t.text, l, r}, err
+		}
+	}
+	if p.keyword("IS") {
+		not := p.keyword("NOT")
+		if !p.keyword("NULL") && !p.keyword("MISSING") {
+			return nil, p.unexpected("expected NULL")
+		}
+		return sqlIsNull{l, not}, nil
+	}
+	not := p.keyword("NOT")
+	switch {
+	case p.keyword("LIKE"):
+		t := p.next()
+		if t.kind != tokString {
+			return nil, fmt.Errorf("LIKE needs a 'pattern', found %q", t.text)
+		}
+		return sqlLike{l, likePattern(t.text), not}, nil
+	case p.keyword("IN"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var list []sqlExpr
+		for {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+			if !p.punct(",") {
+				break
+			}
+		}
+		return sqlIn{l, list, not}, p.expect(")")
+	case not:
+		return nil, p.unexpected("expected LIKE or IN after NOT")
+	}
+	return l, nil
+}
+
+// likePattern compiles a LIKE pattern: % is any run, _ any one character.
+func likePattern(pat string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^(?s)")
+	for _, r := range pat {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func (p *sqlParser) value() (sqlExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return sqlLit{t.text}, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q", t.text)
+		}
+		return sqlLit{f}, nil
+	case tokPunct:
+		if t.text == "(" {
+			e, err := p.orExpr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+	case tokIdent:
+		up := strings.ToUpper(t.text)
+		switch {
+		case up == "TRUE" || up == "FALSE":
+			return sqlLit{up == "TRUE"}, nil
+		case up == "NULL":
+			return sqlLit{nil}, nil
+		case p.punct("("):
+			switch up {
+			case "LOWER", "UPPER", "TRIM", "CHAR_LENGTH", "CHARACTER_LENGTH":
+				arg, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				return sqlFunc{up, arg}, p.expect(")")
+			case "CAST":
+				arg, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect("AS"); err != nil {
+					return nil, err
+				}
+				typ := p.next()
+				if typ.kind != tokIdent {
+					return nil, fmt.Errorf("CAST needs a type, found %q", typ.text)
+				}
+				return sqlFunc{strings.ToUpper(typ.text), arg}, p.expect(")")
+			}
+			return nil, fmt.Errorf("function %s is not supported when evaluated locally", up)
+		case sqlReserved[up]:
+			p.pos--
+			return nil, p.unexpected("expected a value")
+		}
+		return p.path(pathStep{name: t.text, index: -1})
+	case tokQuoted:
+		return p.path(pathStep{name: t.text, quoted: true, index: -1})
+	}
+	if t.kind != tokEOF {
+		p.pos--
+	}
+	return nil, p.unexpected("expected a value")
+}
+
+func (p *sqlParser) path(first pathStep) (sqlExpr, error) {
+	steps := []pathStep{first}
+	for {
+		switch {
+		case p.punct("."):
+			t := p.next()
+			if t.kind != tokIdent && t.kind != tokQuoted {
+				return nil, fmt.Errorf("expected a name after \".\", found %q", t.text)
+			}
+			steps = append(steps, pathStep{name: t.text, quoted: t.kind == tokQuoted, index: -1})
+		case p.punct("["):
+			t := p.next()
+			n, err := strconv.Atoi(t.text)
+			if t.kind != tokNumber || err != nil || n < 0 {
+				return nil, fmt.Errorf("expected an array index, found %q", t.text)
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			steps = append(steps, pathStep{index: n})
+		default:
+			return sqlPath{steps: steps}, nil
+		}
+	}
+}
+
+// run evaluates the statement over the records next yields (io.EOF ends
+// them), passing each result to emit until it returns false.
+func (st *selectStmt) run(next func() (any, error), emit func(SelectRecord) bool) error {
+	type acc struct {
+		n        int
+		sum      float64
+		min, max any
+	}
+	accs := make([]acc, len(st.items))
+	emitted := 0
+	for st.limit < 0 || st.aggrows || emitted < st.limit {
+		top, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows := []any{top}
+		if arr, ok := top.([]any); ok && st.each {
+			rows = arr
+		}
+		for _, row := range rows {
+			if st.where != nil && !truthy(st.where.eval(row)) {
+				continue
+			}
+			if st.aggrows {
+				for i, it := range st.items {
+					a := &accs[i]
+					if it.star {
+						a.n++
+						continue
+					}
+					v := it.expr.eval(row)
+					if v == nil {
+						continue
+					}
+					a.n++
+					if f, ok := toNumber(v); ok {
+						a.sum += f
+					}
+					if c, ok := compareValues(v, a.min); a.min == nil || ok && c < 0 {
+						a.min = v
+					}
+					if c, ok := compareValues(v, a.max); a.max == nil || ok && c > 0 {
+						a.max = v
+					}
+				}
+				continue
+			}
+			if st.limit >= 0 && emitted >= st.limit {
+				break
+			}
+			emitted++
+			if !emit(st.project(row)) {
+				return nil
+			}
+		}
+	}
+	if !st.aggrows {
+		return nil
+	}
+	rec := make(SelectRecord, len(st.items))
+	for i, it := range st.items {
+		a := accs[i]
+		var v any
+		switch it.agg {
+		case "COUNT":
+			v = float64(a.n)
+		case "SUM":
+			v = a.sum
+		case "AVG":
+			if a.n > 0 {
+				v = a.sum / float64(a.n)
+			}
+		case "MIN":
+			v = a.min
+		case "MAX":
+			v = a.max
+		}
+		rec[i] = SelectField{Name: it.name, Value: valueText(v)}
+	}
+	emit(rec)
+	return nil
+}
+
+// project builds the result row of one matching record.
+func (st *selectStmt) project(row any) SelectRecord {
+	if st.star {
+		return recordOf(row)
+	}
+	rec := make(SelectRecord, len(st.items))
+	for i, it := range st.items {
+		rec[i] = SelectField{Name: it.name, Value: valueText(it.expr.eval(row))}
+	}
+	return rec
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runLocal evaluates query over data as selectLocal would, flattening rows to
+// "name=value" strings.
+func runLocal(t *testing.T, query string, in SelectInput, data string) []string {
+	t.Helper()
+	st, err := parseSelect(query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	next, err := in.records(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	if err := st.run(next, func(r SelectRecord) bool {
+		var cells []string
+		for _, f := range r {
+			cells = append(cells, f.Name+"="+f.Value)
+		}
+		out = append(out, strings.Join(cells, " "))
+		return true
+	}); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return out
+}
+
+const peopleCSV = "name,age,city\nann,34,Oslo\nbob,9,Rome\ncy,51,oslo\n"
+
+func TestSelectLocalCSV(t *testing.T) {
+	csvIn := SelectInput{Format: SelectCSV, Header: true}
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"SELECT * FROM S3Object", []string{"name=ann age=34 city=Oslo", "name=bob age=9 city=Rome", "name=cy age=51 city=oslo"}},
+		{"select s.name from S3Object s where s.age > 10", []string{"name=ann", "name=cy"}}, // numeric, not "9" > "10"
+		{`SELECT s."name", s._2 AS years FROM S3Object s WHERE LOWER(s.city) = 'oslo' LIMIT 1`, []string{"name=ann years=34"}},
+		{"SELECT name FROM S3Object WHERE city LIKE 'R%' OR age IN (51)", []string{"name=bob", "name=cy"}},
+		{"SELECT name FROM S3Object WHERE NOT (age >= 34) AND name <> 'x'", []string{"name=bob"}},
+		{"SELECT COUNT(*), SUM(CAST(s.age AS INT)), MAX(s.age) FROM S3Object s WHERE s.city IS NOT NULL", []string{"_1=3 _2=94 _3=51"}},
+		{"SELECT s.zip FROM S3Object s WHERE s.zip IS NULL LIMIT 1", []string{"zip="}},
+	}
+	for _, tc := range cases {
+		if got := runLocal(t, tc.query, csvIn, peopleCSV); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tc.query, got, tc.want)
+		}
+	}
+
+	// Without a header the columns are _1, _2, …; the header is then a row.
+	got := runLocal(t, "SELECT s._1 FROM S3Object s WHERE s._3 = 'Rome'", SelectInput{Format: SelectCSV}, peopleCSV)
+	if want := []string{"_1=bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("no header: got %q", got)
+	}
+	got = runLocal(t, "SELECT * FROM S3Object LIMIT 1", SelectInput{Format: SelectCSV, Delimiter: "\t"}, "a\tb\n")
+	if want := []string{"_1=a _2=b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tab-separated: got %q", got)
+	}
+}
+
+func TestSelectLocalJSON(t *testing.T) {
+	lines := `{"id":1,"user":{"name":"ann","tags":["a","b"]},"ok":true}
+{"id":2,"user":{"name":"bob"},"ok":false}
+`
+	in := SelectInput{Format: SelectJSONLines}
+	got := runLocal(t, "SELECT s.id, s.user.name, s.user.tags[1] FROM S3Object s WHERE s.ok = true", in, lines)
+	if want := []string{"id=1 name=ann _3=b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got = runLocal(t, "SELECT * FROM S3Object s WHERE s.id = 2", in, lines)
+	if want := []string{`id=2 user={"name":"bob"} ok=false`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	doc := `[{"k":"x"},{"k":"y"}]`
+	got = runLocal(t, "SELECT s.k FROM S3Object[*] s", SelectInput{Format: SelectJSONDocument}, doc)
+	if want := []string{"k=x", "k=y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("document: got %q, want %q", got, want)
+	}
+}
+
+func TestParseSelectRejects(t *testing.T) {
+	for _, q := range []string{
+		"",
+		"SELECT FROM S3Object",
+		"SELECT * FROM other",
+		"SELECT * FROM S3Object s WHERE",
+		"SELECT * FROM S3Object WHERE a = 'open",
+		"SELECT * FROM S3Object LIMIT x",
+		"SELECT COUNT(*), name FROM S3Object",
+		"SELECT * FROM S3Object GROUP BY a",
+		"SELECT SUBSTRING(a, 1) FROM S3Object",
+		"SELECT * FROM S3Object[*].a",
+	} {
+		if _, err := parseSelect(q); err == nil {
+			t.Errorf("%q parsed", q)
+		}
+	}
+}
+
+func TestSelectFormatFor(t *testing.T) {
+	for key, want := range map[string][3]string{
+		"a/b.csv":        {SelectCSV, "NONE", ","},
+		"a/b.tsv.gz":     {SelectCSV, "GZIP", "\t"},
+		"x.jsonl.bz2":    {SelectJSONLines, "BZIP2", ","},
+		"x.JSON":         {SelectJSONDocument, "NONE", ","},
+		"t.parquet":      {SelectParquet, "NONE", ","},
+		"no-extension":   {SelectCSV, "NONE", ","},
+		"events.ndjson":  {SelectJSONLines, "NONE", ","},
+		"old.log.gz":     {SelectCSV, "GZIP", ","},
+		"report.parquet": {SelectParquet, "NONE", ","},
+	} {
+		f, c, d := SelectFormatFor(key)
+		if got := [3]string{f, c, d}; got != want {
+			t.Errorf("%s: got %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSelectObjectFallsBackWithoutSelect(t *testing.T) {
+	// The fake answers S3 Select as an endpoint without it does.
+	m := newFakeModel(t, newFakeS3(map[string][]byte{
+		"b/people.csv.gz": gzipped(t, peopleCSV),
+		"b/t.parquet":     []byte("PAR1"),
+	}))
+	bucket := &Object{Key: strPtr("b")}
+
+	var names []string
+	local, err := m.SelectObject(context.Background(), bucket, "people.csv.gz",
+		SelectInput{Expression: "SELECT s.name FROM S3Object s WHERE s.age > 30", Format: SelectCSV, Header: true, Compression: "GZIP"},
+		func(r SelectRecord) bool { names = append(names, r[0].Value); return true })
+	if err != nil || !local {
+		t.Fatalf("local=%v err=%v", local, err)
+	}
+	if want := []string{"ann", "cy"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %q, want %q", names, want)
+	}
+
+	// Stopping early ends the stream.
+	n := 0
+	if _, err := m.SelectObject(context.Background(), bucket, "people.csv.gz",
+		SelectInput{Expression: "SELECT * FROM S3Object", Format: SelectCSV, Header: true, Compression: "GZIP"},
+		func(SelectRecord) bool { n++; return false }); err != nil || n != 1 {
+		t.Errorf("stop: %d rows, %v", n, err)
+	}
+
+	if _, err := m.SelectObject(context.Background(), bucket, "t.parquet",
+		SelectInput{Expression: "SELECT * FROM S3Object", Format: SelectParquet}, func(SelectRecord) bool { return true }); err == nil {
+		t.Error("Parquet was queried without S3 Select")
+	}
+}
+
+func TestParseJSONRecordKeepsOrder(t *testing.T) {
+	r, err := parseJSONRecord([]byte(`{"z":1,"a":"x","m":null,"n":{"b":2,"a":1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SelectRecord{{"z", "1"}, {"a", "x"}, {"m", ""}, {"n", `{"b":2,"a":1}`}}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("got %v, want %v", r, want)
+	}
+}
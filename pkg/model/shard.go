@@ -0,0 +1,208 @@
+package model
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// shardWorkers bounds the concurrent listings of ListObjectsSharded.
+	shardWorkers = 8
+	// shardRanges is how many key ranges the shards are cut into: a few per
+	// worker, so one slow range doesn't leave the others idle at the end.
+	shardRanges = shardWorkers * 4
+	// shardMaxDepth is how many folder levels discovery descends looking for
+	// enough shards to keep every worker busy.
+	shardMaxDepth = 3
+)
+
+// ListObjectsSharded returns every object under prefix in key order, like
+// ListObjects, but lists it several pages at a time.
+//
+// Discovery lists prefix with the "/" delimiter: the objects directly under it
+// are kept, and each subfolder becomes a shard. While there are fewer shards
+// than workers it descends into them the same way, up to shardMaxDepth levels.
+// The shards are then cut into contiguous key ranges, each listed in one pass
+// from its first shard (StartAfter) to the next range, on up to shardWorkers
+// goroutines. Listing a range rather than each folder keeps the request count
+// near a plain listing's even with thousands of small folders.
+//
+// A flat prefix with no subfolders gains nothing — it is one listing either
+// way. scanned, if set, is called with the running total of objects listed,
+// from the listing goroutines and possibly concurrently. The first error
+// cancels the other listings and is returned.
+//
+// A model browsing an inventory report of bucket reads the report instead.
+func (m *Model) ListObjectsSharded(ctx context.Context, prefix string, bucket *Object, scanned func(total int)) ([]s3t.Object, error) {
+	if m.fromInventory(bucket) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		objs := m.inv.objects(prefix)
+		if scanned != nil {
+			scanned(len(objs))
+		}
+		return objs, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var total atomic.Int64
+	count := func(n int) {
+		t := total.Add(int64(n))
+		if scanned != nil {
+			scanned(int(t))
+		}
+	}
+
+	var objs []s3t.Object
+	shards := []string{prefix}
+	for depth := 0; depth < shardMaxDepth && len(shards) > 0 && len(shards) < shardWorkers; depth++ {
+		direct := make([][]s3t.Object, len(shards))
+		subs := make([][]string, len(shards))
+		err := eachShard(ctx, cancel, len(shards), func(i int) error {
+			var err error
+			direct[i], subs[i], err = m.listPages(ctx, shards[i], bucket, "/", count)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		var next []string
+		for i := range shards {
+			objs = append(objs, direct[i]...)
+			next = append(next, subs[i]...)
+		}
+		shards = next
+	}
+	sort.Strings(shards)
+
+	ranges := splitShards(shards, shardRanges)
+	found := make([][]s3t.Object, len(ranges))
+	err := eachShard(ctx, cancel, len(ranges), func(i int) error {
+		var err error
+		found[i], err = m.listShardRange(ctx, prefix, ranges[i], bucket, count)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range found {
+		objs = append(objs, f...)
+	}
+	// Shards are disjoint, so this is a merge, not a dedupe. Go compares
+	// strings bytewise, which is S3's UTF-8 binary key order.
+	sort.Slice(objs, func(i, j int) bool {
+		return aws.ToString(objs[i].Key) < aws.ToString(objs[j].Key)
+	})
+	return objs, nil
+}
+
+// splitShards cuts sorted shards into at most n contiguous, near-equal runs.
+func splitShards(shards []string, n int) [][]string {
+	if len(shards) == 0 {
+		return nil
+	}
+	if n > len(shards) {
+		n = len(shards)
+	}
+	out := make([][]string, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, shards[i*len(shards)/n:(i+1)*len(shards)/n])
+	}
+	return out
+}
+
+// shardStartAfter is a StartAfter value just below folder prefix p (which ends
+// in "/"): "a/" becomes "a.\U0010FFFF", so the listing begins at the folder's
+// marker or first key rather than at the front of the parent.
+func shardStartAfter(p string) string {
+	return strings.TrimSuffix(p, "/") + ".\U0010FFFF"
+}
+
+// listShardRange lists, in one pass under within, every key inside the sorted
+// folder prefixes shards. Keys that fall between them — objects directly under
+// a level discovery already listed — are skipped, and the listing stops at the
+// first key past the last shard.
+func (m *Model) listShardRange(ctx context.Context, within string, shards []string, bucket *Object, scanned func(n int)) ([]s3t.Object, error) {
+	if len(shards) == 0 {
+		return nil, nil
+	}
+	paginator := s3.NewListObjectsV2Paginator(m.Client, &s3.ListObjectsV2Input{
+		Bucket:     aws.String(*bucket.Key),
+		Prefix:     aws.String(within),
+		StartAfter: aws.String(shardStartAfter(shards[0])),
+	})
+	var out []s3t.Object
+	i := 0
+	for paginator.HasMorePages() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		kept := 0
+		for _, o := range output.Contents {
+			k := aws.ToString(o.Key)
+			// Past shard i once a key sorts after it without being in it.
+			for i < len(shards) && k > shards[i] && !strings.HasPrefix(k, shards[i]) {
+				i++
+			}
+			if i == len(shards) {
+				break
+			}
+			if strings.HasPrefix(k, shards[i]) {
+				out = append(out, o)
+				kept++
+			}
+		}
+		scanned(kept)
+		if i == len(shards) {
+			break
+		}
+	}
+	return out, nil
+}
+
+// eachShard runs fn(0..n-1) on up to shardWorkers goroutines and returns the
+// first error, cancelling the rest through cancel. It stops starting new work
+// once ctx is done and then reports ctx's error.
+func eachShard(ctx context.Context, cancel context.CancelFunc, n int, fn func(i int) error) error {
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, shardWorkers)
+	)
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// treeKeys is a bucket laid out in folders, with files and folder markers at
+// every level and some names that sort between folders ("a.txt" < "a/").
+func treeKeys() []string {
+	keys := []string{"root.txt", "zz.txt", "a.txt", "logs/", "logs/index.html"}
+	for _, top := range []string{"a", "b", "logs"} {
+		for d := 0; d < 3; d++ {
+			dir := fmt.Sprintf("%s/%d/", top, d)
+			keys = append(keys, dir, strings.TrimSuffix(dir, "/")+".txt")
+			for f := 0; f < 7; f++ {
+				keys = append(keys, dir+"f"+strconv.Itoa(f))
+			}
+			keys = append(keys, dir+"deep/x", dir+"deep/y")
+		}
+	}
+	return keys
+}
+
+func keysOf(t *testing.T, m *Model, sharded bool, prefix string) []string {
+	t.Helper()
+	bucket := &Object{Key: strPtr("b")}
+	list := m.ListObjects
+	if sharded {
+		list = func(ctx context.Context, p string, b *Object) ([]s3t.Object, error) {
+			return m.ListObjectsSharded(ctx, p, b, nil)
+		}
+	}
+	objs, err := list(context.Background(), prefix, bucket)
+	if err != nil {
+		t.Fatalf("listing %q: %v", prefix, err)
+	}
+	out := make([]string, len(objs))
+	for i, o := range objs {
+		out[i] = aws.ToString(o.Key)
+	}
+	return out
+}
+
+// The sharded listing is the plain listing, in the same order, whatever the
+// prefix and however the folders split into ranges.
+func TestListObjectsShardedMatchesListObjects(t *testing.T) {
+	for _, pageSize := range []int{1, 3, 1000} {
+		fs := newFakeS3(nil)
+		fs.pageSize = pageSize
+		fs.fill("b", treeKeys()...)
+		m := newFakeModel(t, fs)
+		for _, prefix := range []string{"", "a/", "logs/", "a", "logs/1/", "nothing/"} {
+			want := keysOf(t, m, false, prefix)
+			if got := keysOf(t, m, true, prefix); !reflect.DeepEqual(got, want) {
+				t.Errorf("page %d, prefix %q:\n got %q\nwant %q", pageSize, prefix, got, want)
+			}
+		}
+	}
+}
+
+func TestListObjectsShardedBoundsConcurrency(t *testing.T) {
+	var keys []string
+	for d := 0; d < 40; d++ {
+		for f := 0; f < 5; f++ {
+			keys = append(keys, fmt.Sprintf("d%02d/f%d", d, f))
+		}
+	}
+	fs := newFakeS3(nil)
+	fs.pageSize, fs.delay = 4, 2*time.Millisecond
+	fs.fill("b", keys...)
+	m := newFakeModel(t, fs)
+
+	var mu sync.Mutex
+	last := 0
+	objs, err := m.ListObjectsSharded(context.Background(), "", &Object{Key: strPtr("b")}, func(total int) {
+		mu.Lock()
+		if total > last {
+			last = total
+		}
+		mu.Unlock()
+	})
+	if err != nil || len(objs) != len(keys) {
+		t.Fatalf("got %d objects (%v), want %d", len(objs), err, len(keys))
+	}
+	if last != len(keys) {
+		t.Errorf("progress ended at %d, want %d", last, len(keys))
+	}
+	st := fs.stats()
+	if st.peak < 2 || st.peak > shardWorkers {
+		t.Errorf("peak concurrency %d, want 2..%d", st.peak, shardWorkers)
+	}
+	// 40 tiny folders cost one discovery pass plus a few pages per range, not
+	// a listing each.
+	if plain := len(keys)/4 + 1; st.requests > plain+10+shardRanges {
+		t.Errorf("%d requests; a plain listing takes %d", st.requests, plain)
+	}
+}
+
+func TestListObjectsShardedCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fs := newFakeS3(nil)
+	fs.pageSize = 2
+	fs.fill("b", treeKeys()...)
+	m := newFakeModel(t, fs)
+	if _, err := m.ListObjectsSharded(ctx, "", &Object{Key: strPtr("b")}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestSplitShards(t *testing.T) {
+	shards := []string{"a/", "b/", "c/", "d/", "e/"}
+	for n, want := range map[int][][]string{
+		1: {shards},
+		2: {{"a/", "b/"}, {"c/", "d/", "e/"}},
+		9: {{"a/"}, {"b/"}, {"c/"}, {"d/"}, {"e/"}},
+	} {
+		if got := splitShards(shards, n); !reflect.DeepEqual(got, want) {
+			t.Errorf("n=%d: got %q, want %q", n, got, want)
+		}
+	}
+	if got := splitShards(nil, 4); got != nil {
+		t.Errorf("no shards: got %q", got)
+	}
+}
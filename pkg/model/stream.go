@@ -0,0 +1,199 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3t "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// streamPartSize is the size PutStream's parts start at.
+	streamPartSize = 16 << 20
+	// streamPartGrowth is how many parts PutStream sends at one size before
+	// doubling it.
+	streamPartGrowth = 1000
+	// streamPartMaxSize is the largest part S3 accepts.
+	streamPartMaxSize = 5 << 30
+	// streamMaxSize is the largest object S3 stores.
+	streamMaxSize = 5 << 40
+)
+
+// streamPartSizeAt is the size of part n (from 1) of a streamed upload. A
+// stream's length is unknown up front, so no one part size fits it: a size
+// that reaches S3's largest object in 10,000 parts would buffer half a GiB
+// for a pipe of a few lines, and 16 MiB throughout stops at 156 GiB. So the
+// parts start at 16 MiB and double every thousand, up to S3's 5 GiB cap: the
+// 10,000 parts then reach past 5 TiB, and a stream buffers only as much as
+// its own length has called for. With two parts held, that is 32 MiB for the
+// first 15.6 GiB, 1 GiB past 484 GiB, and 8 GiB from 3.9 TiB on, where the
+// parts are 4 GiB; 5 TiB ends before they would reach the 5 GiB cap.
+func streamPartSizeAt(n int32) int64 {
+	return min(int64(streamPartSize)<<((n-1)/streamPartGrowth), streamPartMaxSize)
+}
+
+// PutStream writes r to a key until it ends, as one PUT if it ends within the
+// first part and as a multipart upload otherwise — a pipe of any length S3
+// can store, never held whole in memory: at most two parts are, one read
+// while the one before it uploads. As parts grow with the stream that is
+// still up to 8 GiB near the 5 TiB limit (streamPartSizeAt). contentType may
+// be empty. It returns the bytes read; a failed upload leaves no object and
+// no parts behind.
+func (m *Model) PutStream(ctx context.Context, bucket *Object, key string, r io.Reader, contentType string) (int64, error) {
+	if bucket == nil || bucket.Key == nil {
+		return 0, fmt.Errorf("bucket is nil")
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return 0, fmt.Errorf("%q is not an object key", key)
+	}
+	defer m.invalidateLists(*bucket.Key, key)
+	reader := &progressReader{r: r, update: func(int64, int64) {}, limiter: m.Limiter}
+
+	first := make([]byte, streamPartSizeAt(1))
+	n, err := io.ReadFull(reader, first)
+	switch {
+	case err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF):
+		in := &s3.PutObjectInput{
+			Bucket: aws.String(*bucket.Key),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(first[:n]),
+		}
+		if contentType != "" {
+			in.ContentType = aws.String(contentType)
+		}
+		_, err = m.Client.PutObject(ctx, in, retryUploads)
+	case err == nil:
+		err = m.putStreamParts(ctx, *bucket.Key, key, reader, first, contentType)
+	}
+	if err != nil {
+		return reader.written, fmt.Errorf("writing %s: %w", key, err)
+	}
+	return reader.written, nil
+}
+
+// putStreamParts sends first and then the rest of r as a multipart upload,
+// aborting it on any failure.
+func (m *Model) putStreamParts(ctx context.Context, bucket, key string, r *progressReader, first []byte, contentType string) error {
+	create := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if contentType != "" {
+		create.ContentType = aws.String(contentType)
+	}
+	started, err := m.Client.CreateMultipartUpload(ctx, create)
+	if err != nil {
+		return err
+	}
+	uploadID := aws.ToString(started.UploadId)
+
+	parts, err := m.streamParts(ctx, bucket, key, uploadID, r, first)
+	if err == nil {
+		_, err = m.Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(key),
+			UploadId:        aws.String(uploadID),
+			MultipartUpload: &s3t.CompletedMultipartUpload{Parts: parts},
+		})
+	}
+	if err != nil {
+		m.abortMultipart(ctx, bucket, key, uploadID)
+		return err
+	}
+	return nil
+}
+
+// sentPart is how the upload of one part went; buf is its buffer, free again.
+type sentPart struct {
+	part s3t.CompletedPart
+	buf  []byte
+	err  error
+}
+
+// streamParts uploads part after part of r, starting with first, each one
+// while the next is read. It returns once every part is in, or on the first
+// failure with nothing left in flight, so an abort afterwards is final.
+func (m *Model) streamParts(ctx context.Context, bucket, key, uploadID string, r *progressReader, first []byte) ([]s3t.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var parts []s3t.CompletedPart
+	var inflight chan sentPart
+	wait := func(sent chan sentPart) ([]byte, error) {
+		s := <-sent
+		if s.err != nil {
+			return nil, s.err
+		}
+		parts = append(parts, s.part)
+		return s.buf, nil
+	}
+	fail := func(err error) ([]s3t.CompletedPart, error) {
+		cancel()
+		<-inflight
+		return nil, err
+	}
+
+	body, last := first, false
+	for n := int32(1); ; n++ {
+		sent := make(chan sentPart, 1)
+		go func(n int32, body []byte) {
+			out, err := m.Client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        aws.String(bucket),
+				Key:           aws.String(key),
+				UploadId:      aws.String(uploadID),
+				PartNumber:    n,
+				Body:          bytes.NewReader(body),
+				ContentLength: int64(len(body)),
+			}, retryUploads)
+			if err != nil {
+				sent <- sentPart{err: fmt.Errorf("part %d: %w", n, err)}
+				return
+			}
+			sent <- sentPart{part: s3t.CompletedPart{ETag: out.ETag, PartNumber: n}, buf: body}
+		}(n, body)
+
+		// The part before this one is done with its buffer once it is in,
+		// which bounds the stream to two buffers.
+		var spare []byte
+		prev := inflight
+		inflight = sent
+		if prev != nil {
+			buf, err := wait(prev)
+			if err != nil {
+				return fail(err)
+			}
+			spare = buf
+		}
+		if last {
+			break
+		}
+
+		size := streamPartSizeAt(n + 1)
+		if int64(cap(spare)) < size {
+			spare = make([]byte, size)
+		}
+		k, err := io.ReadFull(r, spare[:size])
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			last = true
+		} else if err != nil {
+			return fail(fmt.Errorf("reading: %w", err))
+		}
+		if r.written > streamMaxSize {
+			return fail(fmt.Errorf("the stream passed %d TiB, the most S3 keeps in one object", streamMaxSize>>40))
+		}
+		body = spare[:k]
+	}
+	if _, err := wait(inflight); err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamPartSizeAt(t *testing.T) {
+	for n, want := range map[int32]int64{
+		1:     16 << 20,
+		1000:  16 << 20,
+		1001:  32 << 20,
+		7001:  2 << 30,
+		8001:  4 << 30,
+		9001:  5 << 30, // 4 GiB doubled is past the cap
+		10000: 5 << 30,
+	} {
+		if got := streamPartSizeAt(n); got != want {
+			t.Errorf("part %d: %d bytes, want %d", n, got, want)
+		}
+	}
+	var total int64
+	for n := int32(1); n <= copyMaxParts; n++ {
+		total += streamPartSizeAt(n)
+	}
+	if total < streamMaxSize {
+		t.Errorf("10,000 parts reach %d bytes, short of S3's largest object", total)
+	}
+}
+
+func TestPutStream(t *testing.T) {
+	fs := newFakeS3(nil)
+	m := newFakeModel(t, fs)
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+
+	// An io.Reader only — no Seek, no Len — as stdin is.
+	r := io.MultiReader(strings.NewReader("{\"a\":"), strings.NewReader("1}\n"))
+	n, err := m.PutStream(ctx, bucket, "in/doc.json", r, GuessContentType("in/doc.json"))
+	if err != nil || n != 8 {
+		t.Fatalf("PutStream = %d, %v", n, err)
+	}
+	if o, _ := fs.object("b/in/doc.json"); string(o.data) != "{\"a\":1}\n" || o.contentType != "application/json" {
+		t.Errorf("stored %q as %q", o.data, o.contentType)
+	}
+
+	if n, err := m.PutStream(ctx, bucket, "empty", strings.NewReader(""), ""); err != nil || n != 0 {
+		t.Errorf("an empty stream = %d, %v", n, err)
+	} else if _, ok := fs.object("b/empty"); !ok {
+		t.Error("an empty stream wrote nothing")
+	}
+
+	if _, err := m.PutStream(ctx, bucket, "in/", strings.NewReader("x"), ""); err == nil {
+		t.Error("a folder key should be refused")
+	}
+}
+
+func TestPutStreamMultipart(t *testing.T) {
+	ctx := context.Background()
+	bucket := &Object{Key: strPtr("b")}
+	data := bytes.Repeat([]byte("0123456789abcdef"), (streamPartSize+1<<20)/16+1) // 17 MiB and a bit
+
+	for _, tc := range []struct {
+		name  string
+		size  int
+		parts []int
+	}{
+		{"a part and a short one", len(data), []int{streamPartSize, len(data) - streamPartSize}},
+		{"exactly one part", streamPartSize, []int{streamPartSize}},
+	} {
+		fs := newFakeS3(nil)
+		m := newFakeModel(t, fs)
+		n, err := m.PutStream(ctx, bucket, "big.log", io.MultiReader(bytes.NewReader(data[:tc.size])), "text/plain")
+		if err != nil || n != int64(tc.size) {
+			t.Fatalf("%s: PutStream = %d, %v", tc.name, n, err)
+		}
+		o, _ := fs.object("b/big.log")
+		if !bytes.Equal(o.data, data[:tc.size]) || o.contentType != "text/plain" {
+			t.Errorf("%s: stored %d byte(s) as %q", tc.name, len(o.data), o.contentType)
+		}
+		if got := fs.stats().parts; !reflect.DeepEqual(got, tc.parts) {
+			t.Errorf("%s: parts %v, want %v", tc.name, got, tc.parts)
+		}
+	}
+
+	t.Run("a failed part aborts the upload", func(t *testing.T) {
+		fs := newFakeS3(nil)
+		fs.fail = func(req *http.Request) (int, string) {
+			if req.URL.Query().Get("partNumber") == "2" {
+				return http.StatusForbidden, "AccessDenied"
+			}
+			return 0, ""
+		}
+		m := newFakeModel(t, fs)
+		if _, err := m.PutStream(ctx, bucket, "big.log", bytes.NewReader(data), ""); err == nil || !strings.Contains(err.Error(), "part 2") {
+			t.Errorf("err = %v, want part 2's", err)
+		}
+		if _, ok := fs.object("b/big.log"); ok || fs.stats().aborts != 1 {
+			t.Errorf("left an object (%v) or %d abort(s), want none and 1", ok, fs.stats().aborts)
+		}
+	})
+}
@@ -65,9 +65,9 @@ func WalkLocal(root string) ([]SyncEntry, error) {
 // ListRemoteEntries lists the objects under prefix as SyncEntries keyed by the
 // path relative to that prefix. Folder-marker keys (ending in "/") are skipped:
 // they are an encoding of a directory, not a file to transfer.
-func (m *Model) ListRemoteEntries(prefix string, bucket *Object) ([]SyncEntry, error) {
+func (m *Model) ListRemoteEntries(ctx context.Context, prefix string, bucket *Object) ([]SyncEntry, error) {
 	prefix = NormalizePrefix(prefix)
-	objs, err := m.ListObjects(prefix, bucket)
+	objs, err := m.ListObjects(ctx, prefix, bucket)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +123,7 @@ func (m *Model) UploadFile(ctx context.Context, localPath, key string, bucket *O
 	defer fp.Close()
 
 	uploader := newUploader(m.Client)
+	defer m.invalidateLists(*bucket.Key, key)
 
 	reader := &progressReader{
 		r:     fp,
@@ -167,5 +168,6 @@ func (m *Model) DeleteKey(ctx context.Context, key string, bucket *Object) error
 		Bucket: aws.String(*bucket.Key),
 		Key:    aws.String(key),
 	})
+	m.invalidateLists(*bucket.Key, key)
 	return err
 }
@@ -158,6 +158,7 @@ func (m *Model) DeleteVersion(ctx context.Context, bucket *Object, key, versionI
 		Key:       aws.String(key),
 		VersionId: aws.String(versionID),
 	})
+	m.invalidateLists(*bucket.Key, key)
 	return err
 }
 
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdMaxWindow is the largest window a frame may ask for: 128 MiB, zstd's
+// own default limit for decoding (--long=27). A frame asking for more is
+// refused rather than allowed to allocate it.
+const zstdMaxWindow = 1 << 27
+
+// newZstdReader returns a reader of r's Zstandard frames, decompressed, for
+// .tar.zst archives, zip members and ORC chunks. It decodes on the reader's
+// goroutine, a block at a time, so it starts nothing that would need
+// stopping; Close only returns its buffers.
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r,
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderLowmem(true),
+		zstd.WithDecoderMaxWindow(zstdMaxWindow))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
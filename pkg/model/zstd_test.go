@@ -0,0 +1,159 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Both compress zstdFixtureText with the zstd CLI: one frame at -19 with a
+// checksum, and at --fast=3 -B1024 --no-check, in several blocks.
+const (
+	zstdFixture19   = "KLUv/WQCFjUHAGKPIxSwp9jd0BoRhsGwRGT3Timl7gQOBFY0dyl3VjR3KXZWNHcpdVY0dyl0VjR3KXNWNHcpPyuauxSfFc1dSs+K5i6FZ0VzlyLCs6K5S7mzorlLsbOiuUups6K5S6GzorlLmbOiuUv5WdHcpfisaO5SelY0d8mzorlLgDE4BIWROBAHwjAYhyJQCAqFFAbhGASAyKgh4Gf/N9BTljURJAT/KwQd3Qe7S2JtIMO3dHzLxqtp+JaOb9l4NQ3f0vEtm+OnEJBoWcg8P6q3MXv8SN5G7vkpPSuZ50dlwM7BOeZkzrJWHiBVcdpNyQ=="
+	zstdFixtureFast = "KLUv/WACFiUWAAQdbGluZSAwIG9mIGEgcmVwZXRpdGl2ZSBmaWxlCmxpbmUgMTIgbzMgbzQgbzUgbzYgbzcgbzggbzkgbzExMTExMTExMTIwIDIxIDIyIDIzIDI0IDI1IDI2IDI3IDI4IDI5IDMwIDMxIDMyIDMzIDM0IDM1IDM2IDM3IDM4IDM5IDQwIDQxIDQyIDQzIDQ0IDQ1IDQ2IDQ3IDQ4IDQ5IDUwIDUxIDUyIDUzIDU0IDU1IDU2IDU3IDU4IDU5IDYwIDYxIDYyIDYzIDY0IDY1IDY2IDY3IDY4IDY5IDcwIDcxIDcyIDczIDc0IDc1IDc2IDc3IDc4IDc5IDgwIDgxIDgyIDgzIDg0IDg1IDg2IDg3IDg4IDg5IDkwIDkxIDkyIDkzIDk0IDk1IDk2IDk3IDk4IDk5IDEwMDEwMTIgbzMgbzQgbzUgbzYgbzcgbzggbzkgbzEwIDEgbzIgbzMgbzQgbzUgbzYgbzcgbzggbzkgbzIwIDEgbzIgbzMgbzQgbzUgbzYgbzcgbzggbzExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTExMTGAx6ghaAG//x0gQ5Fo0gMR/L8CsaXyAbur7lTujDvxdmq7q12i3Z3dZDfFbmF3r0uuu60b66bVreoudQl193Qz3VS6Jd2NLonuhm6gmz63nrvOJc7dzU1zU+YWc3e5ZLlbuVFumtxK7iKXIHcfN8dNjVvG3eKS4m7ihrjpcGu4K1wi3B3cBDcFbgF3f0t+u30b36a9rd4ubwlv926zW27jtc6YM4ucHGNnrS0TpKSUNEGISUxvkTx7kazbkSzfkiz7oizbIy3bMjnbolnbIl3eIkgl1UiqGakmUj2kOpDqH9U8qtuOiqMW2KvALCJ2omdOFQ=="
+)
+
+// More frames from the zstd CLI at -3, each decoding to its generator below:
+// zstdFixtureText 50 times over, piped in so the frame has no content size,
+// in three compressed blocks; noise(1000) with --no-check, which zstd leaves
+// as one raw block; and 200,000 a's, a compressed block and then an RLE one.
+const (
+	zstdFixtureMulti = "KLUv/QRYFA4Ack8kFqAlaQP2z9/GI8ur6onI3ill8qAON2NnRXd5VnSXd3d3d3dmZmZmZlVVVVVVRERERET///+/bdu23bZt25IkSZIzMzMzIyIiIiIyd3d3d3dmZmZmZlVVVVVVRERERET///+/bdu23bZt25IkSZIzMzMzc1Z0lzMGAlEADAUxGEMBUSAKw8BAMALGQGDIGAhEEYEsqDH19v8MkYYi0UR+Evj/Taz6Yf9/A6zdBVdNK6ehpxHUcNRIajA1TDVWNViNXw1hjbLGWmNbI67hrpHX4GsYbCxsEBseGyMblI3Nxs4GaONpI7XB2jjbqG3kNvw2BDfEjcuNzY3oxnVDdoO7YbxR3kBveG+Ib+gb9hv6GwCOA4cEB4PDhKPCgeHw4RDikDisOLQ4YBw3DjkOHochR5EDyeHJIcqhcthy6HKAOc4c0hxsDnOOrBKgdeAN09FjzGOOYQ3PjVryllKccfRgjxhYUEgRTBhoEEUMLCgsXrKLLf2oPmKPwCPYEeuIHCGOcCPqNsKsScID8V9oqDDrrggP8M80qAnOn4IV4rXToCbwuiRPkjeSEcmGZEEyH1keOR0Zx6U4znjmNUt6Jl4FTAAACCABAPz/ORACRQAACCABAGD+DoSB+5iA"
+	zstdFixtureRaw   = "KLUv/WDoAkEfACEBxU/R0BqyJXTLN4qu9bEICJEZM7nrT/IppeTbPlcUASjg9PrifgfxGkMnt+lFVK2FO7PM1bTU1FTTjW0mAMdgsNRK7cyOkRBg3AU2zZ/QhRTGwARMB085ezhdv8nA2pvhkPe8oEgKu9PqoXAYZQ15EXGQGFpXpqrGnUDc1pot2oHBLWjsYAsv7pKUvG5LbrbVAgr5/e5d4JHIlN/3LlmoIqT/o8rGMudllBYVmE/YrkWeuCH5Mxty+RFQ1y8nTyc/9tbTuALThdTJLWwS+rB4bshgB8KmcPV2qO0zLlcjuCY7Ljf1rSjpJtZL59s4Qi1XJ5Yr/Q4Lhjb8Z7sm/Tx53qbKw3L+vbM15tjmX3Lq18ZZIipNPYZUVzBfh/y6kPCZIg70WH+u//oe8iL5cASO70jLRpjBZmvzlvf9AN0asz/8Yzf+GISo+g632E6kDhL4bvt/JoAAwLKfJUg6iabP5SvONgRf/g4b7Y8VdsCiPYb8Uo8r1cwXDgU8qhSVgGW2/hAok6DB2IwMQylfgAoQX8681oOevODQm84SxT3/u9jhs5izD+R3q0svVeHzOD1K8PBeJwSTlX4fLHlPugDnD9CMaaJQuiLazTGswryiZeYMK+KLCGGCGM1IqJ8BuNv5a5I52EkpuyTftarxa9ctePczbkkl6w8DSblpRQy+kTPqdVjQBAy5MOrmsc1BvZ3mFrmsuvPfeNNCFRXyTSMGv01G7ac9/FHB+bJvHUSfLSIPV+HhqAWzbYn7fY/mOlv4W9vDHWRUd4GI4ANAS0SS9dF9vKr7NTdPUxd7ar0s/rMOkruSPJAd/ojLinguZLHIq2zE7rUl2n+vYO8dvo9TJnflV0W3hylNU4dZHVVD7KmjpvWF8HCv7KixR2EpHmPYyqRar/cehmT67Yv7Pa6EYL6F9hK3VLT6g8q5nWzY3H7HJt8iZXqvle9O5WsgZR/KpjIOh8osAz/GxQZDY1G+Rq5Wkmx/etpcTLpONBZTmLY1wpwqafzz+i2mbcB7gC+TARerL6p6M2jvPl0S8TDx8J+gwy/wQT1d/mZAQeuCD+TwLZeskv5HydDJthbGKTe2VbnvKmAQLunUdH26ZjsknfMqIJa/j8mKdxM30aWxEb0aDL02OLftQHjXFakkFyVgjdmEywdWDUyfgWvcFXIhXKKn4xd8wx09lBGLJUuDpHeQVkoQjO7tIk5VZRYNSUM8EyMpshRvCUjLND3nmgPZYpTXqrPqnsqhGFn+nmrdvvIvB+pmh9wFwCAsPql3AHOkm4f801CoUmaoMrI3AFcZHoFDYCKQmVe+AHGLLzQTjPr00GMi9ic="
+	zstdFixtureRLE   = "KLUv/aRADQMAVAAAEGFhAQD7/znAAgNqCGGDVryY"
+)
+
+func zstdFixtureText() string {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "line %d of a repetitive file\n", i)
+	}
+	return b.String()
+}
+
+// noise is n bytes of xorshift32 from a seed of 1: incompressible, and the
+// same everywhere.
+func noise(n int) []byte {
+	out := make([]byte, n)
+	x := uint32(1)
+	for i := range out {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		out[i] = byte(x)
+	}
+	return out
+}
+
+// readZstd reads r through newZstdReader to the end.
+func readZstd(r io.Reader) ([]byte, error) {
+	z, err := newZstdReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	return io.ReadAll(z)
+}
+
+func TestZstdReader(t *testing.T) {
+	want := zstdFixtureText()
+	for _, fx := range []string{zstdFixture19, zstdFixtureFast} {
+		frame, _ := base64.StdEncoding.DecodeString(fx)
+		for name, r := range map[string]io.Reader{
+			"whole":     bytes.NewReader(frame),
+			"byte-wise": iotest.OneByteReader(bytes.NewReader(frame)),
+			// Two frames back to back, with a skippable frame between.
+			"concatenated": io.MultiReader(bytes.NewReader(frame),
+				bytes.NewReader([]byte{0x50, 0x2a, 0x4d, 0x18, 3, 0, 0, 0, 'x', 'y', 'z'}),
+				bytes.NewReader(frame)),
+		} {
+			got, err := readZstd((r))
+			exp := want
+			if name == "concatenated" {
+				exp += want
+			}
+			if err != nil || string(got) != exp {
+				t.Errorf("%s: %d bytes, err %v", name, len(got), err)
+			}
+		}
+	}
+
+	frame, _ := base64.StdEncoding.DecodeString(zstdFixture19)
+	bad := bytes.Clone(frame)
+	bad[len(bad)-1] ^= 1 // the checksum
+	if _, err := readZstd((bytes.NewReader(bad))); err == nil {
+		t.Error("a bad checksum went unnoticed")
+	}
+	if _, err := readZstd((bytes.NewReader(frame[:len(frame)/2]))); err == nil {
+		t.Error("a truncated frame went unnoticed")
+	}
+	if _, err := readZstd((strings.NewReader("not zstd at all"))); err == nil {
+		t.Error("garbage went unnoticed")
+	}
+}
+
+func TestZstdBlockKinds(t *testing.T) {
+	for _, tc := range []struct {
+		name, frame string
+		want        []byte
+	}{
+		{"multi-block, no content size", zstdFixtureMulti, []byte(strings.Repeat(zstdFixtureText(), 50))},
+		{"raw block, no checksum", zstdFixtureRaw, noise(1000)},
+		{"RLE block", zstdFixtureRLE, bytes.Repeat([]byte("a"), 200000)},
+	} {
+		frame, _ := base64.StdEncoding.DecodeString(tc.frame)
+		for name, r := range map[string]io.Reader{
+			"whole":     bytes.NewReader(frame),
+			"byte-wise": iotest.OneByteReader(bytes.NewReader(frame)),
+		} {
+			got, err := readZstd((r))
+			if err != nil || !bytes.Equal(got, tc.want) {
+				t.Errorf("%s, %s: %d bytes of %d, err %v", tc.name, name, len(got), len(tc.want), err)
+			}
+		}
+	}
+}
+
+// A frame may ask for a window of up to 3.75 TB; one over zstdMaxWindow is
+// refused before anything is allocated for it.
+func TestZstdWindowLimit(t *testing.T) {
+	frame := func(exp byte) []byte {
+		// Window descriptor 10+exp as its log, then one raw block of "x".
+		return []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00, exp << 3, 0x09, 0x00, 0x00, 'x'}
+	}
+	if got, err := readZstd(bytes.NewReader(frame(17))); err != nil || string(got) != "x" {
+		t.Errorf("128 MiB window: %q, %v", got, err)
+	}
+	if _, err := readZstd(bytes.NewReader(frame(18))); err == nil {
+		t.Error("a 256 MiB window was accepted")
+	}
+}
+
+// FuzzZstd feeds the decoder what it was never meant to see. Whatever the
+// input it must end in an error or EOF, not a panic.
+func FuzzZstd(f *testing.F) {
+	for _, fx := range []string{zstdFixture19, zstdFixtureFast, zstdFixtureMulti, zstdFixtureRaw, zstdFixtureRLE} {
+		frame, _ := base64.StdEncoding.DecodeString(fx)
+		f.Add(frame)
+	}
+	f.Add([]byte{0x50, 0x2a, 0x4d, 0x18, 3, 0, 0, 0, 'x', 'y', 'z'})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		z, err := newZstdReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer z.Close()
+		buf := make([]byte, 32<<10)
+		for total := 0; total < 64<<20; {
+			n, err := z.Read(buf)
+			total += n
+			if err != nil {
+				return
+			}
+		}
+	})
+}
@@ -14,14 +14,23 @@ const versionText = "S3Duck 🦆 TUI v.0.9.0"
 
 // View ...
 type View struct {
-	App       *tview.Application
-	Frame     *tview.Frame
-	Pages     *tview.Pages
-	List      *tview.List       // the active pane's list (repointed on pane switch)
-	Filter    *tview.InputField // the active pane's filter
-	Header    *tview.TextView   // the active pane's column header
-	Details   *tview.TextView
-	ModalEdit func(p tview.Primitive, width, height int) tview.Primitive
+	App     *tview.Application
+	Frame   *tview.Frame
+	Pages   *tview.Pages
+	List    *tview.List       // the active pane's list (repointed on pane switch)
+	Filter  *tview.InputField // the active pane's filter
+	Header  *tview.TextView   // the active pane's column header
+	Details *tview.TextView
+	// The object preview, under Details when shown: PreviewPages holds the
+	// text (also hex, Markdown and half-block images), tree and table views,
+	// one in front, and an empty box that sixel and kitty images are drawn
+	// over.
+	Preview      *tview.TextView
+	PreviewTree  *tview.TreeView
+	PreviewTable *tview.Table
+	PreviewImage *tview.Box
+	PreviewPages *tview.Pages
+	ModalEdit    func(p tview.Primitive, width, height int) tview.Primitive
 
 	// Dual-pane widgets. Pane i always owns lists[i]/filters[i]/cols[i]; the
 	// active pane's widgets are mirrored into List/Filter. main is the root
@@ -31,6 +40,9 @@ type View struct {
 	headers [2]*tview.TextView
 	cols    [2]*tview.Flex
 	main    *tview.Flex
+	// side is the single-pane layout's right column: Details, with Preview
+	// beneath it while the preview is on.
+	side *tview.Flex
 
 	// Terminal size as of the last draw. tview's Application exposes no size
 	// getter in this version, and overlays that can outgrow the terminal (the
@@ -120,7 +132,7 @@ func (v *View) PaneHeader(i int) *tview.TextView   { return v.headers[i] }
 func (v *View) ShowSinglePane() {
 	v.main.Clear()
 	v.main.AddItem(v.cols[0], 0, 4, true)
-	v.main.AddItem(v.Details, 0, 3, false)
+	v.main.AddItem(v.side, 0, 3, false)
 	v.lists[0].SetBorderColor(tcell.ColorWhite)
 }
 
@@ -131,6 +143,18 @@ func (v *View) ShowDualPane() {
 	v.main.AddItem(v.cols[1], 0, 1, false)
 }
 
+// ShowPreview shows or hides the preview panel under the details. Details
+// keeps the room its few lines need; the preview gets the rest.
+func (v *View) ShowPreview(on bool) {
+	v.side.Clear()
+	if !on {
+		v.side.AddItem(v.Details, 0, 1, false)
+		return
+	}
+	v.side.AddItem(v.Details, 11, 0, false)
+	v.side.AddItem(v.PreviewPages, 0, 1, false)
+}
+
 // SetActivePane highlights the active pane's border (dual-pane only).
 func (v *View) SetActivePane(active int) {
 	for i, l := range v.lists {
@@ -158,10 +182,25 @@ func NewView() *View {
 		})
 	tv.SetBorder(true)
 
+	preview := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+	tree := tview.NewTreeView().SetGraphicsColor(tcell.ColorGray)
+	table := tview.NewTable().SetFixed(1, 0)
+	image := tview.NewBox()
+	previewPages := tview.NewPages().
+		AddPage("text", preview, true, true).
+		AddPage("tree", tree, true, false).
+		AddPage("table", table, true, false).
+		AddPage("image", image, true, false)
+	previewPages.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+
 	// Start in single-pane layout: primary pane beside the details panel.
+	side := tview.NewFlex().SetDirection(tview.FlexRow)
+	side.AddItem(tv, 0, 1, false)
 	main := tview.NewFlex()
 	main.AddItem(col0, 0, 4, true)
-	main.AddItem(tv, 0, 3, false)
+	main.AddItem(side, 0, 3, false)
 
 	pages := tview.NewPages().
 		AddPage("main", main, true, true)
@@ -170,19 +209,25 @@ func NewView() *View {
 	app.SetRoot(frame, true)
 
 	v := &View{
-		App:       app,
-		Frame:     frame,
-		Pages:     pages,
-		List:      list0,
-		Filter:    filter0,
-		Header:    header0,
-		Details:   tv,
-		ModalEdit: centerModal,
-		lists:     [2]*tview.List{list0, list1},
-		filters:   [2]*tview.InputField{filter0, filter1},
-		headers:   [2]*tview.TextView{header0, header1},
-		cols:      [2]*tview.Flex{col0, col1},
-		main:      main,
+		App:          app,
+		Frame:        frame,
+		Pages:        pages,
+		List:         list0,
+		Filter:       filter0,
+		Header:       header0,
+		Details:      tv,
+		Preview:      preview,
+		PreviewTree:  tree,
+		PreviewTable: table,
+		PreviewImage: image,
+		PreviewPages: previewPages,
+		ModalEdit:    centerModal,
+		lists:        [2]*tview.List{list0, list1},
+		filters:      [2]*tview.InputField{filter0, filter1},
+		headers:      [2]*tview.TextView{header0, header1},
+		cols:         [2]*tview.Flex{col0, col1},
+		main:         main,
+		side:         side,
 	}
 
 	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
@@ -230,6 +275,7 @@ func (v *View) NewCreateProfileForm(header string) *tview.Form {
 	form.AddInputField("Download dir", "", 52, nil, nil)
 	form.AddCheckbox("Disable ssl check", false, func(bool) {})
 	form.AddInputField("Max bytes/sec (0=unltd)", "", 52, nil, nil)
+	form.AddInputField("List cache secs (-1=off)", "", 52, nil, nil)
 	form.SetBorder(true)
 	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
@@ -257,13 +303,18 @@ func (v *View) NewInputForm(header, label, value string) *tview.Form {
 	return form
 }
 
-// NewSearchForm builds the recursive-search form: a query input (item 0) and an
-// "All buckets" checkbox (item 1). Esc closes the "modal" page.
-func (v *View) NewSearchForm(header string) *tview.Form {
+// NewSearchForm builds the recursive-search form: a query input (item 0), an
+// "All buckets" checkbox (item 1) and, when a local index covers the location,
+// a "Local index" checkbox (item 2, on). Esc closes the "modal" page.
+func (v *View) NewSearchForm(header string, withIndex bool) *tview.Form {
 	form := tview.NewForm()
 	form.SetTitle(header)
 	form.AddInputField("Query", "", 50, nil, nil)
+	form.GetFormItem(0).(*tview.InputField).SetPlaceholder("e.g. *.gz size>100M modified<2025-01-01 class:GLACIER")
 	form.AddCheckbox("All buckets", false, nil)
+	if withIndex {
+		form.AddCheckbox("Local index", true, nil)
+	}
 	form.SetBorder(true)
 	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if event.Key() == tcell.KeyEsc {
@@ -506,40 +557,48 @@ const helpProfiles = `
 const helpBrowser = `
   [::b]Navigation[::-]
     [↓,↑]         Down / up
-    Enter         Open folder / select
+    Enter         Open folder / select; browse inside zip and tar archives
     Backspace     Up ([..])
     [ / ]         History back / forward (also Alt+left/right)
     Ctrl+O        Toggle dual-pane
-    Tab           Switch active pane (dual-pane)
+    Tab           Switch active pane (dual-pane) / focus the preview
+    F3            Preview pane: text or hex dump, ranged GETs on scroll
+                  (JSON/YAML trees, CSV table, Markdown, images; v raw)
     Ctrl+B        Bookmarks (go / add / remove)
     Ctrl+K        Command palette (abort uploads, bucket config, log…)
     Ctrl+P        Show Profiles
 
   [::b]Actions[::-]
     Ctrl+N        Create bucket / folder
-    Ctrl+D        Download file/folder (for files and folders)
+    Ctrl+D        Download files/folders, as they are or into a zip/tar.gz
     Ctrl+R        Rename (pattern rename when >1 marked)
     Ctrl+Y        Copy selected/marked to a destination bucket/prefix
     Ctrl+T        Move selected/marked to a destination bucket/prefix
     Ctrl+G        Bucket/folder summary
     Ctrl+L        File properties (size, ETag, link)
-    v             Version history (restore / download / delete)
+    v             Version history (restore / download / delete / diff)
     m             Edit metadata & object tags
     c             Storage class / Glacier restore
     Ctrl+W        Copy presigned (time-limited) share link
-    Ctrl+U        Open local file manager (for upload)
+    Ctrl+U        Local file manager: upload files, or an archive unpacked
     Ctrl+E        Sync: local ⇄ this prefix, or this prefix → another
-    =             Compare the two panes (dual-pane, read-only)
+    =             Compare the two panes; one pane: diff against a file
     D             Find duplicates under this prefix (size + ETag)
     e             Edit object in $EDITOR (small text objects)
-    >             Copy marked items to another profile
+    n             New object: write it in $EDITOR, upload to this folder
+    f             Follow an object, or a folder's newest key (tail -f)
+    |             Pipe object(s) through a shell command, page the output
+    >             Copy, move or sync marked items to another profile
+    P             Open another profile in this pane
     y / x / p     Clipboard: copy / cut / paste objects
     u             Undo last move/rename
-    t             Transfers panel (background jobs)
-    /             Filter the current listing (live)
+    t             Transfers panel (results / retry failed / export)
+    /             Filter the current listing (live, Ctrl+F queries)
     s / S         Sort: cycle name/size/date / reverse direction
     r / F5        Refresh the current listing
-    Ctrl+F        Recursive search (checkbox: all buckets)
+    Esc           Stop loading the listing
+    Ctrl+F        Search: text *.glob re: ext: class: size> modified<
+    g             Search object contents (regex, gzip-aware grep)
     Space         Select object for download
     Ctrl+S        Select all objects for download
     Ctrl+X        Unselect all objects for download